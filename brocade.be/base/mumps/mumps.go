@@ -244,13 +244,19 @@ func Print(w io.Writer, mumps MUMPS) {
 
 // PipeTo writes M instructions to M
 func PipeTo(mdb string, buffers []*bytes.Buffer) (err error) {
+	_, err = PipeOut(mdb, buffers)
+	return err
+}
+
+// PipeOut writes M instructions to M and returns the output of M
+func PipeOut(mdb string, buffers []*bytes.Buffer) (out []byte, err error) {
 	cmd, err := newMCMD(mdb)
 	if err != nil {
 		return
 	}
 	stdin, e := cmd.StdinPipe()
 	if e != nil {
-		return e
+		return nil, e
 	}
 	go func() {
 		defer stdin.Close()
@@ -262,14 +268,14 @@ func PipeTo(mdb string, buffers []*bytes.Buffer) (err error) {
 		}
 		io.WriteString(stdin, "\n\nq\nh\n")
 	}()
-	out, e := cmd.CombinedOutput()
+	out, e = cmd.CombinedOutput()
 	if e != nil {
 		eurl := getErrorURL(out)
 		if eurl != "" {
 			e = errors.New(e.Error() + ": see " + eurl)
 		}
 	}
-	return e
+	return out, e
 }
 
 // PipeLineTo writes M instructions to M
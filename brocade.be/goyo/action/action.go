@@ -1,5 +1,11 @@
 package action
 
+import (
+	"fmt"
+
+	qutil "brocade.be/goyo/lib/util"
+)
+
 type HelpData struct {
 	Ref   string
 	Short string
@@ -54,22 +60,82 @@ without argument, the REPL reads lines from stdin and exexutes them,
 one by one`,
 	},
 	"exit": {Ref: "bye"},
+	"diff": {
+		Ref:   "",
+		Short: "Compare two globals",
+		Long: `Compare two globals (or subtrees): the arguments are references
+or files in ZWR format (as written by 'extract').
+The differences are shown in ZWR notation: '-' lines are only in
+the first tree, '+' lines only in the second tree. A changed node
+is a '-' line followed by a '+' line.
+With a third argument, the differences are written to this file.
+Example:
+
+    diff ^BCONF ^BCONFTEST
+    diff ^BCONF BCONF.zwr BCONF.diff`,
+	},
+	"export": {
+		Ref:   "",
+		Short: "Export a global to a CSV or JSON file",
+		Long: `Export the nodes of a global to a table in CSV or JSON format
+(by the extension of the file, default: CSV on stdout).
+The first argument is a mapping: literal subscripts select the nodes,
+names are the key columns and the subscript with '@' gives the
+other columns. Without '@', the values are in the column 'value'.
+Example:
+
+    export ^BCAT(loi,@field) BCAT.csv
+    export ^BCAT(loi,"ti") BCAT.json`,
+	},
 	"extract": {
 		Ref:   "",
 		Short: "Extract an M global to a file",
 		Long: `Extract an M global to a file.
 Example:
 
-    extract BCAT`,
+    extract BCAT
+
+With a memory store (GOYO_STORE), the global is written in ZWR format
+to the file in the second argument (default: BCAT.zwr)`,
 	},
 
+	"import": {
+		Ref:   "",
+		Short: "Import a CSV or JSON file in a global",
+		Long: `Import a table in CSV or JSON format (as written by 'export')
+in a global. The first argument is the mapping of 'export'.
+Every cell is a node: an empty cell removes the node (with '@').
+Nodes which are not in the table are left alone.
+The changes are shown in the format of 'diff' (or written to the file
+of --report=) and can be applied later on with 'merge'.
+With --dry-run, the global is not changed.
+Example:
+
+    import ^BCAT(loi,@field) BCAT.csv --dry-run --report=BCAT.diff
+    import ^BCAT(loi,@field) BCAT.csv`,
+	},
 	"load": {
 		Ref:   "",
 		Short: "Load a global from a file",
 		Long: `Load an M global to a file.
 Example:
 
-    load BCAT.zwr`,
+    load BCAT.zwr
+
+With a memory store (GOYO_STORE), the files should be in ZWR format`,
+	},
+	"merge": {
+		Ref:   "",
+		Short: "Apply a diff to a global",
+		Long: `Apply a file written by 'diff' to a global: by default the
+first tree of the diff, otherwise the reference in the second argument.
+A change is only applied if the node still has the value of the first tree:
+the other changes are shown as conflicts. Changes which are already
+applied are skipped.
+Example:
+
+    merge BCONF.diff
+    merge BCONF.diff ^BCONFPROD`,
 	},
 	"quit": {Ref: "bye"},
 
@@ -136,6 +202,14 @@ func RunAction(key string, text string) []string {
 		return Load(text)
 	case "extract":
 		return Extract(text)
+	case "export":
+		return Export(text)
+	case "import":
+		return Import(text)
+	case "diff":
+		return Diff(text)
+	case "merge":
+		return Merge(text)
 	case "set":
 		return Set(text)
 	case "awk":
@@ -159,3 +233,46 @@ func RunAction(key string, text string) []string {
 	}
 	return nil
 }
+
+// RunActionE executes an action without prompts and returns its error instead of showing it.
+// Actions that cannot fail are executed with RunAction.
+func RunActionE(key string, text string) ([]string, error) {
+	switch key {
+	case "cd":
+		return cd(text)
+	case "load":
+		return load(text)
+	case "extract":
+		return extract(text)
+	case "export":
+		return exportTable(text)
+	case "import":
+		return importTable(text)
+	case "diff":
+		return diff(text)
+	case "merge":
+		return merge(text)
+	case "get":
+		return get(text)
+	}
+	return RunAction(key, text), nil
+}
+
+// usage is the error of an action without the right arguments
+type usage string
+
+func (u usage) Error() string {
+	return "usage: " + string(u)
+}
+
+// shown shows the error of an action and returns the history
+func shown(history []string, err error) []string {
+	switch err := err.(type) {
+	case nil:
+	case usage:
+		fmt.Println(string(err))
+	default:
+		qutil.Error(err)
+	}
+	return history
+}
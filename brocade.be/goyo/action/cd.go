@@ -6,10 +6,13 @@ import (
 	"path/filepath"
 
 	qfs "brocade.be/base/fs"
-	qutil "brocade.be/goyo/lib/util"
 )
 
 func Cd(text string) []string {
+	return shown(cd(text))
+}
+
+func cd(text string) ([]string, error) {
 
 	home, _ := os.UserHomeDir()
 	dir := ""
@@ -31,13 +34,11 @@ func Cd(text string) []string {
 	}
 
 	err := os.Chdir(dir)
-	if err == nil {
-		cwd, _ := os.Getwd()
-		fmt.Println(cwd)
-		return []string{"cd " + cwd}
-	} else {
-		qutil.Error(err)
-		return nil
+	if err != nil {
+		return nil, err
 	}
+	cwd, _ := os.Getwd()
+	fmt.Println(cwd)
+	return []string{"cd " + cwd}, nil
 
 }
@@ -0,0 +1,79 @@
+package action
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+)
+
+// Diff compares two trees: references or files in ZWR format
+func Diff(text string) []string {
+	return shown(diff(text))
+}
+
+func diff(text string) ([]string, error) {
+	argums := strings.Fields(text)
+	if len(argums) < 2 {
+		return nil, usage("diff ^A ^B [file-name]")
+	}
+	storea, glvna, err := diffSide(argums[0], argums[1])
+	if err != nil {
+		return nil, err
+	}
+	storeb, glvnb, err := diffSide(argums[1], argums[0])
+	if err != nil {
+		return nil, err
+	}
+	var w io.Writer = os.Stdout
+	if len(argums) > 2 {
+		f, err := os.Create(argums[2])
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		w = f
+	}
+	count, err := qgstore.WriteDiff(storea, glvna, storeb, glvnb, w)
+	if len(argums) > 2 {
+		fmt.Printf("%d changes written to %s\n", count, argums[2])
+	}
+	return []string{"diff " + text}, err
+}
+
+// diffSide returns the store and the reference of an argument of diff.
+// A file in ZWR format is loaded in a memory store: if it contains several globals,
+// the global of the other argument is used.
+func diffSide(argum string, other string) (qgstore.GlobalStore, string, error) {
+	info, err := os.Stat(argum)
+	if err != nil || info.IsDir() {
+		return Store, argum, nil
+	}
+	f, err := os.Open(argum)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	store := qgstore.NewMemory()
+	if _, err := qgstore.Import(store, f); err != nil {
+		return nil, "", fmt.Errorf("%s: %s", argum, err)
+	}
+	globals := store.Globals()
+	switch len(globals) {
+	case 0:
+		return nil, "", fmt.Errorf("%s: contains no globals", argum)
+	case 1:
+		return store, globals[0], nil
+	}
+	subs := qgstore.QS(other)
+	if len(subs) != 0 {
+		for _, global := range globals {
+			if global == subs[0] {
+				return store, global, nil
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("%s: contains several globals (%s)", argum, strings.Join(globals, ", "))
+}
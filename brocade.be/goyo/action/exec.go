@@ -6,13 +6,12 @@ import (
 
 	qhistory "brocade.be/goyo/lib/history"
 	qutil "brocade.be/goyo/lib/util"
-	qyottadb "brocade.be/goyo/lib/yottadb"
 	qliner "github.com/peterh/liner"
 )
 
 func Exec(text string) []string {
 	if text != "" {
-		err := qyottadb.Execw(text)
+		err := Store.Exec(text)
 		if err != nil {
 			qutil.Error(err)
 			return nil
@@ -40,7 +39,7 @@ func Exec(text string) []string {
 		if ltext == "bye" || ltext == "exit" || ltext == "h" || ltext == "halt" {
 			break
 		}
-		err = qyottadb.Execw(text)
+		err = Store.Exec(text)
 		deflt = ""
 		if err != nil {
 			qutil.Error(err)
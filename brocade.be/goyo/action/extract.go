@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"strings"
 
-	qmupip "brocade.be/goyo/lib/mupip"
+	qgstore "brocade.be/goyo/lib/gstore"
 	qliner "github.com/peterh/liner"
 )
 
 func Extract(text string) []string {
+	if store, ok := Store.(*qgstore.Memory); ok {
+		return shown(extractZWR(store, text))
+	}
 	ask := true
 	history := ""
 	line := qliner.NewLiner()
@@ -36,27 +40,11 @@ func Extract(text string) []string {
 		case "":
 		default:
 			ask = false
-			args := []string{"EXTRACT", "-FO=Z"}
-			argums := strings.Fields(text)
-			if len(argums) > 1 {
-				glo := strings.TrimPrefix(argums[0], "^")
-				args = append(args, "-SE="+glo, "-ST="+argums[1])
-			}
-			if len(argums) != 0 {
-				glo := strings.TrimPrefix(argums[0], "^")
-				args = append(args, "-SE="+glo, glo+".zwr")
-			}
-			if len(argums) == 0 {
+			args := extractArgs(text)
+			if args == nil {
 				continue
 			}
-			ask = false
-			stdout, stderr, err := qmupip.MUPIP(args, "")
-			if strings.TrimSpace(stderr) != "" {
-				fmt.Println(stderr)
-			}
-			if strings.TrimSpace(stdout) != "" {
-				fmt.Println(stdout)
-			}
+			err := mupip(args)
 			if err != nil {
 				fmt.Println(err)
 				ask = true
@@ -82,3 +70,57 @@ func Extract(text string) []string {
 	return []string{history}
 
 }
+
+func extract(text string) ([]string, error) {
+	if store, ok := Store.(*qgstore.Memory); ok {
+		return extractZWR(store, text)
+	}
+	args := extractArgs(text)
+	if args == nil || text == "?" {
+		return nil, usage("extract global [file-name]")
+	}
+	if err := mupip(args); err != nil {
+		return nil, err
+	}
+	return []string{"extract " + text}, nil
+}
+
+// extractArgs returns the arguments of `mupip extract`: nil without a global
+func extractArgs(text string) []string {
+	argums := strings.Fields(text)
+	if len(argums) == 0 {
+		return nil
+	}
+	glo := strings.TrimPrefix(argums[0], "^")
+	args := []string{"EXTRACT", "-FO=Z"}
+	if len(argums) > 1 {
+		args = append(args, "-SE="+glo, "-ST="+argums[1])
+	}
+	return append(args, "-SE="+glo, glo+".zwr")
+}
+
+// extractZWR writes a global of a Memory store to a file in ZWR format
+func extractZWR(store *qgstore.Memory, text string) ([]string, error) {
+	argums := strings.Fields(text)
+	if len(argums) == 0 || argums[0] == "?" {
+		return nil, usage("extract global [file-name]")
+	}
+	glo := "^" + strings.TrimPrefix(argums[0], "^")
+	fname := strings.TrimPrefix(glo, "^") + ".zwr"
+	if len(argums) > 1 {
+		fname = argums[1]
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return nil, err
+	}
+	count, err := qgstore.Export(store, glo, f, true)
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("%s: %d nodes extracted to %s\n", glo, count, fname)
+	return []string{"extract " + text}, nil
+}
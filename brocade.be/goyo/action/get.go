@@ -3,22 +3,24 @@ package action
 import (
 	"fmt"
 
-	qutil "brocade.be/goyo/lib/util"
-	qyottadb "brocade.be/goyo/lib/yottadb"
+	qgstore "brocade.be/goyo/lib/gstore"
 )
 
 func Get(text string) []string {
+	return shown(get(text))
+}
+
+func get(text string) ([]string, error) {
 	gloref, _ := SplitRefValue(text)
-	value, err := qyottadb.G(gloref, false)
+	value, err := qgstore.G(Store, gloref)
 	if err != nil {
-		qutil.Error(err)
-		return nil
+		return nil, err
 	}
 	fmt.Println(value)
-	gloref2 := qyottadb.N(gloref)
+	gloref2 := Store.Name(gloref)
 	h := []string{"get " + gloref}
 	if gloref2 != gloref {
 		h = append(h, "get "+gloref2)
 	}
-	return h
+	return h, nil
 }
@@ -5,8 +5,8 @@ import (
 	"log"
 	"strings"
 
+	qgstore "brocade.be/goyo/lib/gstore"
 	qutil "brocade.be/goyo/lib/util"
-	qyottadb "brocade.be/goyo/lib/yottadb"
 	qliner "github.com/peterh/liner"
 )
 
@@ -37,12 +37,12 @@ func Kill(text string, tree bool) []string {
 			continue
 		}
 
-		err = qyottadb.Kill(gloref, tree)
+		err = qgstore.Kill(Store, gloref, tree)
 		if err != nil {
 			qutil.Error(err)
 			continue
 		}
-		gloref2 := qyottadb.N(gloref)
+		gloref2 := Store.Name(gloref)
 		h := []string{way + " " + gloref}
 		if gloref2 != gloref {
 			h = append(h, way+" "+gloref2+"="+value)
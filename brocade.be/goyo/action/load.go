@@ -4,13 +4,18 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"os"
 	"strings"
 
+	qgstore "brocade.be/goyo/lib/gstore"
 	qmupip "brocade.be/goyo/lib/mupip"
 	qliner "github.com/peterh/liner"
 )
 
 func Load(text string) []string {
+	if store, ok := Store.(*qgstore.Memory); ok {
+		return shown(loadZWR(store, text))
+	}
 	ask := true
 	history := ""
 	line := qliner.NewLiner()
@@ -33,14 +38,7 @@ func Load(text string) []string {
 		case "":
 		default:
 			ask = false
-			argums := append([]string{"LOAD"}, strings.Fields(text)...)
-			stdout, stderr, err := qmupip.MUPIP(argums, "")
-			if strings.TrimSpace(stderr) != "" {
-				fmt.Println(stderr)
-			}
-			if strings.TrimSpace(stdout) != "" {
-				fmt.Println(stdout)
-			}
+			err := mupip(append([]string{"LOAD"}, strings.Fields(text)...))
 			if err != nil {
 				fmt.Println(err)
 				ask = true
@@ -66,3 +64,54 @@ func Load(text string) []string {
 	return []string{history}
 
 }
+
+func load(text string) ([]string, error) {
+	if store, ok := Store.(*qgstore.Memory); ok {
+		return loadZWR(store, text)
+	}
+	if text == "" || text == "?" {
+		return nil, usage("load [-qualifiers] file-name")
+	}
+	if err := mupip(append([]string{"LOAD"}, strings.Fields(text)...)); err != nil {
+		return nil, err
+	}
+	return []string{"load " + text}, nil
+}
+
+// mupip executes mupip and shows its output
+func mupip(args []string) error {
+	stdout, stderr, err := qmupip.MUPIP(args, "")
+	if strings.TrimSpace(stderr) != "" {
+		fmt.Println(stderr)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		fmt.Println(stdout)
+	}
+	return err
+}
+
+// loadZWR sets the nodes of files in ZWR format in a Memory store: the qualifiers of `mupip load` are ignored
+func loadZWR(store *qgstore.Memory, text string) ([]string, error) {
+	fnames := make([]string, 0)
+	for _, argum := range strings.Fields(text) {
+		if !strings.HasPrefix(argum, "-") {
+			fnames = append(fnames, argum)
+		}
+	}
+	if len(fnames) == 0 || fnames[0] == "?" {
+		return nil, usage("load file-name ...")
+	}
+	for _, fname := range fnames {
+		f, err := os.Open(fname)
+		if err != nil {
+			return nil, err
+		}
+		count, err := qgstore.Import(store, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", fname, err)
+		}
+		fmt.Printf("%s: %d nodes loaded\n", fname, count)
+	}
+	return []string{"load " + text}, nil
+}
@@ -0,0 +1,53 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+)
+
+// Merge applies a diff to a tree: by default, the tree after `---` in the diff.
+// Conflicts are reported as an error.
+func Merge(text string) []string {
+	return shown(merge(text))
+}
+
+func merge(text string) ([]string, error) {
+	argums := strings.Fields(text)
+	if len(argums) == 0 {
+		return nil, usage("merge file-name [^A]")
+	}
+	f, err := os.Open(argums[0])
+	if err != nil {
+		return nil, err
+	}
+	top, changes, err := qgstore.ReadDiff(f)
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", argums[0], err)
+	}
+	if len(argums) > 1 {
+		top = argums[1]
+	}
+	applied, conflicts, err := qgstore.Merge(Store, top, changes)
+	base := qgstore.MArgs(qgstore.QS(Store.Name(top)))
+	for _, conflict := range conflicts {
+		subs := append(append([]string{}, base...), conflict.Change.Subs...)
+		expect := "does not exist"
+		if conflict.Change.Op != "add" {
+			expect = "= " + qgstore.ZWRLiteral(conflict.Change.Old)
+		}
+		found := qgstore.EUnQS(subs) + " does not exist"
+		if conflict.Exists {
+			found = qgstore.ZWRLine(subs, conflict.Found)
+		}
+		fmt.Printf("! %s (expected: %s)\n", found, expect)
+	}
+	fmt.Printf("%s: %d changes applied, %d conflicts\n", top, applied, len(conflicts))
+	if err == nil && len(conflicts) != 0 {
+		err = fmt.Errorf("%s: %d conflicts", top, len(conflicts))
+	}
+	return []string{"merge " + text}, err
+}
@@ -0,0 +1,457 @@
+package action
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+	qutil "brocade.be/goyo/lib/util"
+)
+
+// A goyo script is a list of actions, one per line, executed without prompts.
+// Next to the actions, a script knows:
+//
+//	let k = expr                  set a script variable
+//	for each k in ^G("x")         loop over the subscripts below ^G("x") (up to `end`)
+//	if cond / else / end          conditional execution
+//	assert cond                   check a condition: a failure is reported and counted
+//	print expr ...                show the values of expressions
+//
+// `{k}` is replaced by the value of the variable k as an M literal: `^G("x",{k})`.
+// An expression is a concatenation with `_` of terms: "string", number, `{k}`,
+// `get REF` ($GET), `data REF` ($DATA) and `order REF` ($ORDER).
+// A condition compares 2 expressions with ==, !=, <, <=, >, >= or =~ (regexp);
+// without an operator, a condition is true if the expression is not "" or 0.
+// `set REF=expr` and `kill REF` work on the store: `walk`, `awk` and `edit` are not available.
+
+// ScriptReport is the result of a script
+type ScriptReport struct {
+	Asserts  int
+	Failures []string
+}
+
+// statement is a line in a script: `if` and `for each` have a body (and `if` an alternative)
+type statement struct {
+	lineno int
+	key    string
+	text   string
+	body   []*statement
+	alt    []*statement
+}
+
+// script executes statements
+type script struct {
+	name   string
+	vars   map[string]string
+	report *ScriptReport
+}
+
+var rscriptvar = regexp.MustCompile(`\{([A-Za-z%][A-Za-z0-9_]*)\}`)
+
+var rscriptname = regexp.MustCompile(`^[A-Za-z%][A-Za-z0-9_]*$`)
+
+var scriptops = []string{"==", "!=", "<=", ">=", "=~", "<", ">"}
+
+// RunScript executes a script: name is used in the messages.
+// An error stops the script, a failing assertion does not.
+func RunScript(name string, source string) (report *ScriptReport, err error) {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	statements, lineno, err := parseScript(name, lines, 0, false)
+	if err == nil && lineno < len(lines) {
+		err = fmt.Errorf("%s:%d: `%s` without `if` or `for each`", name, lineno+1, strings.TrimSpace(lines[lineno]))
+	}
+	report = new(ScriptReport)
+	if err != nil {
+		return report, err
+	}
+	run := &script{name: name, vars: make(map[string]string), report: report}
+	return report, run.block(statements)
+}
+
+// parseScript parses lines up to `else` or `end` (if inblock)
+func parseScript(name string, lines []string, start int, inblock bool) (statements []*statement, next int, err error) {
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, text := qutil.KeyText(line)
+		if key == "else" || key == "end" {
+			if !inblock {
+				return nil, i, fmt.Errorf("%s:%d: `%s` without `if` or `for each`", name, i+1, key)
+			}
+			return statements, i, nil
+		}
+		stmt := &statement{lineno: i + 1, key: key, text: text}
+		statements = append(statements, stmt)
+		if key != "if" && key != "for" {
+			continue
+		}
+		if key == "for" && !strings.HasPrefix(text, "each ") {
+			return nil, i, fmt.Errorf("%s:%d: `for` should be `for each k in REF`", name, i+1)
+		}
+		stmt.body, i, err = parseScript(name, lines, i+1, true)
+		if err != nil {
+			return nil, i, err
+		}
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "else" {
+			if key != "if" {
+				return nil, i, fmt.Errorf("%s:%d: `else` without `if`", name, i+1)
+			}
+			stmt.alt, i, err = parseScript(name, lines, i+1, true)
+			if err != nil {
+				return nil, i, err
+			}
+		}
+		if i >= len(lines) || strings.TrimSpace(lines[i]) != "end" {
+			return nil, i, fmt.Errorf("%s:%d: `%s` without `end`", name, stmt.lineno, key)
+		}
+	}
+	if inblock {
+		return nil, len(lines), fmt.Errorf("%s:%d: missing `end`", name, len(lines))
+	}
+	return statements, len(lines), nil
+}
+
+func (run *script) block(statements []*statement) error {
+	for _, stmt := range statements {
+		if err := run.exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (run *script) exec(stmt *statement) (err error) {
+	defer func() {
+		if err != nil && !strings.HasPrefix(err.Error(), run.name+":") {
+			err = fmt.Errorf("%s:%d: %s", run.name, stmt.lineno, err)
+		}
+	}()
+	text := stmt.text
+	if stmt.key != "for" {
+		text, err = run.substitute(text)
+		if err != nil {
+			return err
+		}
+	}
+	switch stmt.key {
+	case "let":
+		name, expr, ok := strings.Cut(text, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !rscriptname.MatchString(name) {
+			return fmt.Errorf("`let` should be `let k = expr`")
+		}
+		value, err := run.expression(expr)
+		if err != nil {
+			return err
+		}
+		run.vars[name] = value
+	case "print":
+		values := make([]string, 0)
+		rest := strings.TrimSpace(text)
+		for rest != "" {
+			value, after, err := run.concat(rest)
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+			rest = strings.TrimSpace(after)
+		}
+		fmt.Println(strings.Join(values, " "))
+	case "assert":
+		run.report.Asserts++
+		ok, msg, err := run.condition(text)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			failure := fmt.Sprintf("%s:%d: assert %s (%s)", run.name, stmt.lineno, text, msg)
+			run.report.Failures = append(run.report.Failures, failure)
+			fmt.Println("FAIL", failure)
+		}
+	case "if":
+		ok, _, err := run.condition(text)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return run.block(stmt.body)
+		}
+		return run.block(stmt.alt)
+	case "for":
+		return run.each(stmt)
+	case "set":
+		gloref, expr, err := run.splitSet(text)
+		if err != nil {
+			return err
+		}
+		value, err := run.expression(expr)
+		if err != nil {
+			return err
+		}
+		return qgstore.Set(Store, gloref, value)
+	case "kill", "killtree", "killnode":
+		return qgstore.Kill(Store, text, stmt.key != "killnode")
+	case "exec":
+		if text == "" {
+			return fmt.Errorf("`exec` needs M code")
+		}
+		return Store.Exec(text)
+	case "walk", "awk", "edit", "bye", "exit", "quit":
+		return fmt.Errorf("`%s` cannot be used in a script", stmt.key)
+	default:
+		if _, ok := Actions[stmt.key]; !ok {
+			return fmt.Errorf("unknown action `%s`", stmt.key)
+		}
+		_, err := RunActionE(stmt.key, text)
+		return err
+	}
+	return nil
+}
+
+// each executes `for each k in REF`: k gets the subscripts below REF
+func (run *script) each(stmt *statement) error {
+	name, ref, ok := strings.Cut(strings.TrimPrefix(stmt.text, "each "), " in ")
+	name = strings.TrimSpace(name)
+	if !ok || !rscriptname.MatchString(name) {
+		return fmt.Errorf("`for` should be `for each k in REF`")
+	}
+	ref, err := run.substitute(ref)
+	if err != nil {
+		return err
+	}
+	gloref, err := qgstore.Right(Store.Name(strings.TrimSpace(ref)))
+	if err != nil {
+		return err
+	}
+	for {
+		next, sub, err := qgstore.Next(Store, gloref)
+		if err != nil {
+			return err
+		}
+		if sub == "" {
+			return nil
+		}
+		gloref = next
+		run.vars[name] = sub
+		if err := run.block(stmt.body); err != nil {
+			return err
+		}
+	}
+}
+
+// substitute replaces `{k}` by the value of k as an M literal
+func (run *script) substitute(text string) (string, error) {
+	var err error
+	text = rscriptvar.ReplaceAllStringFunc(text, func(m string) string {
+		name := m[1 : len(m)-1]
+		value, ok := run.vars[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("unknown variable `%s`", name)
+			}
+			return m
+		}
+		return qgstore.MakeArg(value)
+	})
+	return text, err
+}
+
+// splitSet splits `REF=expr`: the `=` is the first one outside strings and parentheses
+func (run *script) splitSet(text string) (gloref string, expr string, err error) {
+	_, rest, err := scriptRef(text)
+	if err != nil {
+		return "", "", err
+	}
+	if !strings.HasPrefix(strings.TrimSpace(rest), "=") {
+		return "", "", fmt.Errorf("`set` should be `set REF=expr`")
+	}
+	gloref = strings.TrimSpace(text[:len(text)-len(rest)])
+	return gloref, strings.TrimPrefix(strings.TrimSpace(rest), "="), nil
+}
+
+// condition evaluates `expr op expr` or `expr`: msg describes the values
+func (run *script) condition(text string) (ok bool, msg string, err error) {
+	left, rest, err := run.concat(text)
+	if err != nil {
+		return false, "", err
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return left != "" && left != "0", "found " + qgstore.MakeArg(left), nil
+	}
+	op := ""
+	for _, o := range scriptops {
+		if strings.HasPrefix(rest, o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		return false, "", fmt.Errorf("unexpected `%s`", rest)
+	}
+	right, err := run.expression(rest[len(op):])
+	if err != nil {
+		return false, "", err
+	}
+	msg = "found " + qgstore.MakeArg(left)
+	if op == "=~" {
+		rex, err := regexp.Compile(right)
+		if err != nil {
+			return false, "", err
+		}
+		return rex.MatchString(left), msg, nil
+	}
+	c := scriptCompare(left, right)
+	switch op {
+	case "==":
+		ok = c == 0
+	case "!=":
+		ok = c != 0
+	case "<":
+		ok = c < 0
+	case "<=":
+		ok = c <= 0
+	case ">":
+		ok = c > 0
+	case ">=":
+		ok = c >= 0
+	}
+	return ok, msg, nil
+}
+
+// scriptCompare compares numerically if both values are numbers
+func scriptCompare(a, b string) int {
+	x, okx := new(big.Rat).SetString(a)
+	y, oky := new(big.Rat).SetString(b)
+	if okx && oky && a != "" && b != "" {
+		return x.Cmp(y)
+	}
+	return strings.Compare(a, b)
+}
+
+// expression evaluates a complete expression
+func (run *script) expression(text string) (string, error) {
+	value, rest, err := run.concat(text)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return "", fmt.Errorf("unexpected `%s`", strings.TrimSpace(rest))
+	}
+	return value, nil
+}
+
+// concat evaluates terms joined by `_`
+func (run *script) concat(text string) (value string, rest string, err error) {
+	rest = text
+	for {
+		var v string
+		v, rest, err = run.term(strings.TrimSpace(rest))
+		if err != nil {
+			return "", "", err
+		}
+		value += v
+		after := strings.TrimSpace(rest)
+		if !strings.HasPrefix(after, "_") {
+			return value, rest, nil
+		}
+		rest = after[1:]
+	}
+}
+
+// term evaluates a string, a number or a function of a reference
+func (run *script) term(text string) (value string, rest string, err error) {
+	switch {
+	case text == "":
+		return "", "", fmt.Errorf("missing expression")
+	case text[0] == '"':
+		k := 1
+		for {
+			e := strings.IndexByte(text[k:], '"')
+			if e < 0 {
+				return "", "", fmt.Errorf("unterminated string in `%s`", text)
+			}
+			k += e + 1
+			if !strings.HasPrefix(text[k:], `"`) {
+				break
+			}
+			k++
+		}
+		return strings.ReplaceAll(text[1:k-1], `""`, `"`), text[k:], nil
+	case strings.ContainsRune("+-.0123456789", rune(text[0])):
+		k := strings.IndexFunc(text, func(r rune) bool { return !strings.ContainsRune("+-.0123456789", r) })
+		if k < 0 {
+			k = len(text)
+		}
+		number := text[:k]
+		if _, ok := new(big.Rat).SetString(number); !ok {
+			return "", "", fmt.Errorf("invalid number `%s`", number)
+		}
+		return number, text[k:], nil
+	}
+	fn, after := qutil.KeyText(text)
+	if fn != "get" && fn != "data" && fn != "order" {
+		return "", "", fmt.Errorf("invalid expression `%s`", text)
+	}
+	_, rest, err = scriptRef(after)
+	if err != nil {
+		return "", "", err
+	}
+	glvn := strings.TrimSpace(after[:len(after)-len(rest)])
+	switch fn {
+	case "get":
+		subs, err := qgstore.Ref(Store, glvn)
+		if err != nil {
+			return "", "", err
+		}
+		if d, _ := Store.Data(subs); d%2 == 0 {
+			return "", rest, nil
+		}
+		value, err = Store.Get(subs)
+		return value, rest, err
+	case "data":
+		d, err := qgstore.D(Store, glvn)
+		return fmt.Sprint(d), rest, err
+	}
+	_, next, err := qgstore.Next(Store, glvn)
+	return next, rest, err
+}
+
+// scriptRef finds the reference at the start of text: a name with optional subscripts
+func scriptRef(text string) (ref string, rest string, err error) {
+	text = strings.TrimLeft(text, " \t")
+	k := 0
+	if strings.HasPrefix(text, "^") {
+		k = 1
+	}
+	for k < len(text) && (text[k] == '%' || text[k] >= 'A' && text[k] <= 'Z' || text[k] >= 'a' && text[k] <= 'z' || text[k] >= '0' && text[k] <= '9') {
+		k++
+	}
+	if k == 0 || text[:k] == "^" {
+		return "", "", fmt.Errorf("missing reference in `%s`", text)
+	}
+	if k == len(text) || text[k] != '(' {
+		return text[:k], text[k:], nil
+	}
+	level := 0
+	quoted := false
+	for i := k; i < len(text); i++ {
+		switch {
+		case text[i] == '"':
+			quoted = !quoted
+		case quoted:
+		case text[i] == '(':
+			level++
+		case text[i] == ')':
+			level--
+			if level == 0 {
+				return text[:i+1], text[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unbalanced parentheses in `%s`", text)
+}
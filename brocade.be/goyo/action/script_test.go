@@ -0,0 +1,70 @@
+package action
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+)
+
+func TestRunScript(t *testing.T) {
+	Store = qgstore.NewMemory()
+	script := `
+# copy ^A to ^B
+set ^A(1)="one"
+set ^A(2,"x")="two"
+set ^A("z")=3
+let count = 0
+for each k in ^A
+    let count = {count}_"x"
+    if data ^A({k}) != 10
+        set ^B({k})=get ^A({k})_"!"
+    else
+        set ^B({k})="?"
+    end
+end
+assert get ^B(1) == "one!"
+assert get ^B(2) == "?"
+assert get ^B("z") == "3!"
+assert order ^B(2) == "z"
+assert get ^B(3) == ""
+assert get ^B(1) =~ "^o"
+killnode ^B(1)
+assert data ^B(1) == 0
+assert {count} == "0xxx"
+`
+	report, err := RunScript("test.goyo", script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Asserts != 8 || len(report.Failures) != 0 {
+		t.Errorf("%d assertions, failures: %v", report.Asserts, report.Failures)
+	}
+
+	report, err = RunScript("test.goyo", "assert get ^B(2) == \"!\"\nassert data ^B")
+	if err != nil || len(report.Failures) != 1 || !strings.Contains(report.Failures[0], `found "?"`) {
+		t.Errorf("failing assertion: %v %v", report.Failures, err)
+	}
+
+	_, err = RunScript("test.goyo", "let n = 1\nlet n = {n}+1")
+	if err == nil || !strings.HasPrefix(err.Error(), "test.goyo:2:") {
+		t.Errorf("`{n}+1` should fail on line 2: %v", err)
+	}
+
+	diff := filepath.Join(t.TempDir(), "a.diff")
+	script = "set ^C(1)=\"x\"\nset ^D(1)=\"y\"\ndiff ^C ^D " + diff + "\nmerge " + diff + "\nassert get ^C(1) == \"y\"\nset ^C(1)=\"z\"\nmerge " + diff + "\nset ^C(2)=1"
+	_, err = RunScript("merge.goyo", script)
+	if err == nil || !strings.HasPrefix(err.Error(), "merge.goyo:7:") || !strings.Contains(err.Error(), "1 conflicts") {
+		t.Errorf("a conflict should stop the script on line 7: %v", err)
+	}
+	if data, _ := qgstore.D(Store, "^C(2)"); data != 0 {
+		t.Errorf("the script should stop at the conflict")
+	}
+
+	for _, bad := range []string{"if 1\nprint 1", "end", "for k in ^A\nend", "print {x}", "walk ^A", "set ^A(1)", "load /nonexistent/a.zwr", "merge"} {
+		if _, err := RunScript("bad.goyo", bad); err == nil {
+			t.Errorf("`%s` should fail", bad)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package action
 
 import (
-	qyottadb "brocade.be/goyo/lib/yottadb"
+	qgstore "brocade.be/goyo/lib/gstore"
 )
 
 func Search(text string, needle string, forward bool) string {
@@ -9,9 +9,9 @@ func Search(text string, needle string, forward bool) string {
 		return ""
 	}
 	gloref, _ := SplitRefValue(text)
-	gloref2 := qyottadb.N(gloref)
-	show := make(chan qyottadb.VarReport)
-	go qyottadb.ZWR(gloref2, show, needle, forward)
+	gloref2 := Store.Name(gloref)
+	show := make(chan qgstore.VarReport)
+	go qgstore.ZWR(Store, gloref2, show, needle, forward)
 	report := <-show
 	return report.Gloref
 }
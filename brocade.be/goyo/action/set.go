@@ -5,8 +5,8 @@ import (
 	"log"
 	"strings"
 
+	qgstore "brocade.be/goyo/lib/gstore"
 	qutil "brocade.be/goyo/lib/util"
-	qyottadb "brocade.be/goyo/lib/yottadb"
 	qliner "github.com/peterh/liner"
 )
 
@@ -33,12 +33,12 @@ func Set(text string) []string {
 			continue
 		}
 		gloref, value = SplitRefValue(setme)
-		err = qyottadb.Set(gloref, value)
+		err = qgstore.Set(Store, gloref, value)
 		if err != nil {
 			qutil.Error(err)
 			continue
 		}
-		gloref2 := qyottadb.N(gloref)
+		gloref2 := Store.Name(gloref)
 		h := []string{"set " + gloref + "=" + value}
 		if gloref2 != gloref {
 			h = append(h, "set "+gloref2+"="+value)
@@ -53,8 +53,8 @@ func SplitRefValue(text string) (ref string, value string) {
 	for {
 		k := strings.Index(text[start:], "=")
 		if k < 0 {
-			ref = qyottadb.UnQS(qyottadb.QS(text))
-			value, _ = qyottadb.G(ref, false)
+			ref = qgstore.UnQS(qgstore.QS(text))
+			value, _ = qgstore.G(Store, ref)
 			break
 		}
 		k = start + k
@@ -64,7 +64,7 @@ func SplitRefValue(text string) (ref string, value string) {
 		}
 
 		value = text[k+1:]
-		ref = qyottadb.UnQS(qyottadb.QS(text[:k]))
+		ref = qgstore.UnQS(qgstore.QS(text[:k]))
 		break
 	}
 	return
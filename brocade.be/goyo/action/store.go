@@ -0,0 +1,8 @@
+package action
+
+import (
+	qgstore "brocade.be/goyo/lib/gstore"
+)
+
+// Store is the M environment of the actions: YottaDB or a Memory store (set by main)
+var Store qgstore.GlobalStore = qgstore.NewMemory()
@@ -0,0 +1,133 @@
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+)
+
+// Export writes the nodes of a mapping to a CSV or JSON file
+func Export(text string) []string {
+	return shown(exportTable(text))
+}
+
+func exportTable(text string) ([]string, error) {
+	argums := strings.Fields(text)
+	if len(argums) == 0 {
+		return nil, usage("export ^BCAT(loi,@field) [file-name.csv|file-name.json]")
+	}
+	mapping, err := qgstore.ParseMapping(argums[0])
+	if err != nil {
+		return nil, err
+	}
+	table, err := mapping.Table(Store)
+	if err != nil {
+		return nil, err
+	}
+	fname := "-"
+	if len(argums) > 1 {
+		fname = argums[1]
+	}
+	var w io.Writer = os.Stdout
+	if fname != "-" {
+		f, err := os.Create(fname)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		w = f
+	}
+	if tableFormat(fname) == "json" {
+		err = table.WriteJSON(w)
+	} else {
+		err = table.WriteCSV(w)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if fname != "-" {
+		fmt.Printf("%d rows written to %s\n", len(table.Rows), fname)
+	}
+	return []string{"export " + text}, nil
+}
+
+// Import applies a CSV or JSON file to the nodes of a mapping.
+// The changes are reported in the format of 'diff': with --dry-run, nothing is changed.
+func Import(text string) []string {
+	return shown(importTable(text))
+}
+
+func importTable(text string) ([]string, error) {
+	argums := make([]string, 0)
+	dryrun := false
+	report := ""
+	for _, argum := range strings.Fields(text) {
+		switch {
+		case argum == "--dry-run":
+			dryrun = true
+		case strings.HasPrefix(argum, "--report="):
+			report = strings.TrimPrefix(argum, "--report=")
+		default:
+			argums = append(argums, argum)
+		}
+	}
+	if len(argums) < 2 {
+		return nil, usage("import ^BCAT(loi,@field) file-name.csv|file-name.json [--dry-run] [--report=file-name]")
+	}
+	mapping, err := qgstore.ParseMapping(argums[0])
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(argums[1])
+	if err != nil {
+		return nil, err
+	}
+	var table *qgstore.Table
+	if tableFormat(argums[1]) == "json" {
+		table, err = qgstore.ReadJSON(f)
+	} else {
+		table, err = qgstore.ReadCSV(f)
+	}
+	f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", argums[1], err)
+	}
+	changes, err := mapping.Import(Store, table, dryrun)
+	if err != nil {
+		return nil, err
+	}
+
+	// change report
+	base := []string{mapping.Name}
+	buffer := new(bytes.Buffer)
+	fmt.Fprintf(buffer, "--- %s\n+++ %s\n", mapping.Name, mapping.Name)
+	count := make(map[string]int)
+	for _, change := range changes {
+		count[change.Op]++
+		buffer.WriteString(qgstore.ChangeLines(base, base, change))
+	}
+	if report == "" {
+		fmt.Print(buffer.String())
+	} else if err := os.WriteFile(report, buffer.Bytes(), 0o644); err != nil {
+		return nil, err
+	}
+	verb := "applied"
+	if dryrun {
+		verb = "found (dry-run)"
+	}
+	fmt.Printf("%s: %d changes %s: %d added, %d changed, %d removed\n", mapping.Name, len(changes), verb, count["add"], count["change"], count["remove"])
+	return []string{"import " + text}, nil
+}
+
+// tableFormat returns the format of a table file: "json" or "csv"
+func tableFormat(fname string) string {
+	if strings.ToLower(filepath.Ext(fname)) == ".json" {
+		return "json"
+	}
+	return "csv"
+}
@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	qgstore "brocade.be/goyo/lib/gstore"
 	qutil "brocade.be/goyo/lib/util"
-	qyottadb "brocade.be/goyo/lib/yottadb"
 	"github.com/eiannone/keyboard"
 )
 
@@ -20,17 +20,17 @@ func walk(text string) []string {
 		_ = keyboard.Close()
 	}()
 	gloref, _ := SplitRefValue(text)
-	gloref2 := qyottadb.N(gloref)
+	gloref2 := Store.Name(gloref)
 	gloref1 := gloref
 	gloref = gloref2
 	stop := false
 
 	for !stop {
-		d, _ := qyottadb.D(gloref)
+		d, _ := qgstore.D(Store, gloref)
 		if d < 1 || d == 10 {
 			fmt.Println(gloref, fmt.Sprintf("$D=%d", d))
 		} else {
-			value, _ := qyottadb.G(gloref, true)
+			value, _ := qgstore.G(Store, gloref)
 			fmt.Println(gloref+"="+value, fmt.Sprintf("$D=%d", d))
 		}
 		char, key, err := keyboard.GetKey()
@@ -53,35 +53,35 @@ z(wr)                              : show node in ZWR
 c(sv)                              : show node in CSV
 e(dit)                             : edit node=value
 /(search)                          : searches both on needle and regexp of needle`)
-			gloref, _, err = qyottadb.Next(gloref)
+			gloref, _, err = qgstore.Next(Store, gloref)
 			if err != nil {
 				qutil.Error(err)
 			}
 			continue
 		}
 		if (char == 'd') || (char == 'n') || (char == 'o') || (char == 0 && fmt.Sprintf("%X", key) == "FFEC") {
-			gloref, _, err = qyottadb.Next(gloref)
+			gloref, _, err = qgstore.Next(Store, gloref)
 			if err != nil {
 				qutil.Error(err)
 			}
 			continue
 		}
 		if (char == 'u') || (char == 'p') || (char == 0 && fmt.Sprintf("%X", key) == "FFED") {
-			gloref, _, err = qyottadb.Prev(gloref)
+			gloref, _, err = qgstore.Prev(Store, gloref)
 			if err != nil {
 				qutil.Error(err)
 			}
 			continue
 		}
 		if (char == 'r') || (char == 0 && fmt.Sprintf("%X", key) == "FFEA") {
-			gloref, err = qyottadb.Right(gloref)
+			gloref, err = qgstore.Right(gloref)
 			if err != nil {
 				qutil.Error(err)
 			}
 			continue
 		}
 		if (char == 'l') || (char == 0 && fmt.Sprintf("%X", key) == "FFEB") {
-			gloref, err = qyottadb.Left(gloref)
+			gloref, err = qgstore.Left(gloref)
 			if err != nil {
 				qutil.Error(err)
 			}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"os"
 
+	qgstore "brocade.be/goyo/lib/gstore"
 	qutil "brocade.be/goyo/lib/util"
-	qyottadb "brocade.be/goyo/lib/yottadb"
 )
 
 func ZWR(text string) []string {
@@ -14,9 +14,9 @@ func ZWR(text string) []string {
 		return nil
 	}
 	gloref, _ := SplitRefValue(text)
-	gloref2 := qyottadb.N(gloref)
-	show := make(chan qyottadb.VarReport, 100)
-	go qyottadb.ZWR(gloref2, show, "", true)
+	gloref2 := Store.Name(gloref)
+	show := make(chan qgstore.VarReport, 100)
+	go qgstore.ZWR(Store, gloref2, show, "", true)
 	for report := range show {
 		if report.Err != nil {
 			qutil.Error(report.Err)
@@ -49,9 +49,9 @@ func CSV(text string, output string) []string {
 		w = csv.NewWriter(f)
 	}
 	gloref, _ := SplitRefValue(text)
-	gloref2 := qyottadb.N(gloref)
-	show := make(chan qyottadb.SubReport, 100)
-	go qyottadb.CSV(gloref2, show, "", true)
+	gloref2 := Store.Name(gloref)
+	show := make(chan qgstore.SubReport, 100)
+	go qgstore.CSV(Store, gloref2, show, "", true)
 	for report := range show {
 		if report.Err != nil {
 			qutil.Error(report.Err)
@@ -7,7 +7,8 @@ import (
 	"os/user"
 	"time"
 
-	qyottadb "brocade.be/goyo/lib/yottadb"
+	qaction "brocade.be/goyo/action"
+	qgstore "brocade.be/goyo/lib/gstore"
 	"github.com/spf13/cobra"
 )
 
@@ -37,7 +38,7 @@ func about(cmd *cobra.Command, args []string) error {
 		msg["user.username"] = user.Username
 	}
 
-	err = qyottadb.Set("/zgoya/hello", "Hello World")
+	err = qgstore.Set(qaction.Store, "/zgoya/hello", "Hello World")
 	if err != nil {
 		msg["error on set"] = err.Error()
 	}
@@ -66,8 +67,8 @@ func AboutText() string {
 
 	h := time.Now()
 	now := h.Format(time.RFC3339)
-	qyottadb.Set("/zgoya/last/"+user.Name, now)
-	dbnow, _ := qyottadb.G("/zgoya/last/"+user.Name, false)
+	qgstore.Set(qaction.Store, "/zgoya/last/"+user.Name, now)
+	dbnow, _ := qgstore.G(qaction.Store, "/zgoya/last/"+user.Name)
 	if now == dbnow {
 		msg["status"] = "Connected successfully to YottaDB!"
 	} else {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/hex"
+	"fmt"
 	"os"
 	"strings"
 
@@ -19,19 +20,29 @@ var BuildHost = ""
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
-func Execute(buildTime string, goVersion string, buildHost string, args []string) {
+func Execute(buildTime string, goVersion string, buildHost string, args []string) error {
 	BuildTime = buildTime
 	BuildHost = buildHost
 	GoVersion = goVersion
-	rootCmd.Execute()
+	err := rootCmd.Execute()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+	}
+	return err
 }
 
 var rootCmd = &cobra.Command{
-	Use:               "goyo",
-	Short:             "YottaDB REPL",
-	SilenceUsage:      true,
-	SilenceErrors:     true,
-	Long:              `goyo is a REPL on YottaDB`,
+	Use:           "goyo",
+	Short:         "YottaDB REPL",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	Long: `goyo is a REPL on YottaDB.
+
+With the environment variable GOYO_STORE, goyo works without YottaDB:
+'memory' keeps the globals in memory, a file name loads the globals from
+a file in ZWR format and writes them back at the end.
+Built with the tag 'noyottadb', goyo does not need libyottadb:
+GOYO_STORE is then required.`,
 	PersistentPreRunE: preRun,
 }
 
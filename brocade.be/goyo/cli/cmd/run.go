@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	qaction "brocade.be/goyo/action"
+	"github.com/spf13/cobra"
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run a goyo script",
+	Long: `Run a goyo script: the actions of the REPL, one per line, without prompts.
+
+Next to the actions, a script knows:
+
+    let k = expr                  set a script variable
+    for each k in ^G("x")         loop over the subscripts below ^G("x") (up to 'end')
+    if cond / else / end          conditional execution
+    assert cond                   check a condition
+    print expr ...                show the values of expressions
+
+'{k}' is replaced by the value of the variable k as an M literal: '^G("x",{k})'.
+An expression is a concatenation with '_' of terms: "string", number, '{k}',
+'get REF' ($GET), 'data REF' ($DATA) and 'order REF' ($ORDER).
+A condition compares 2 expressions with ==, !=, <, <=, >, >= or =~ (regexp).
+
+'set REF=expr' sets a node to the value of an expression. 'walk', 'awk' and
+'edit' cannot be used. Lines starting with '#' are comments.
+
+The script stops on the first error, also when an action fails (e.g. 'load' of
+a missing file or 'merge' with conflicts). Failing assertions are reported at
+the end: goyo exits with a non-zero status if the script fails.`,
+	Args: cobra.ExactArgs(1),
+	Example: `goyo run migrate.goyo
+GOYO_STORE=test.zwr goyo run smoke.goyo`,
+	RunE: run,
+}
+
+func init() {
+	rootCmd.AddCommand(runCmd)
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	source, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	report, err := qaction.RunScript(args[0], string(source))
+	if err != nil {
+		return err
+	}
+	if len(report.Failures) != 0 {
+		return fmt.Errorf("%d of %d assertions failed", len(report.Failures), report.Asserts)
+	}
+	return nil
+}
@@ -18,28 +18,23 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
-	"io/ioutil"
 	"log"
 	"math/rand"
 	"net/http"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
-	qfs "brocade.be/base/fs"
+	qaction "brocade.be/goyo/action"
 	"brocade.be/goyo/cli/cmd"
-	qyottadb "brocade.be/goyo/lib/yottadb"
-	ydb "lang.yottadb.com/go/yottadb"
+	qgstore "brocade.be/goyo/lib/gstore"
 )
 
 var buildTime string
 var goVersion string
 var buildHost string
 
-var RoDir = ""
-
 func main() {
 	// Copyright © 2018 NAME HERE <EMAIL ADDRESS>
 	//
@@ -55,9 +50,30 @@ func main() {
 	// See the License for the specific language governing permissions and
 	// limitations under the License.
 
-	defer qyottadb.Exit()
-	RoDir = Setup()
-	defer os.RemoveAll(RoDir)
+	// runs last: the other deferred functions should run first
+	status := 0
+	defer func() {
+		if status != 0 {
+			os.Exit(status)
+		}
+	}()
+
+	store := os.Getenv("GOYO_STORE")
+	if store == "" || store == "yottadb" {
+		ystore, exit, err := openYottaDB()
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer exit()
+		qaction.Store = ystore
+	} else {
+		mstore, err := qgstore.Open(store)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer mstore.Close()
+		qaction.Store = mstore
+	}
 
 	if len(os.Args) == 1 {
 		os.Args = append(os.Args, "repl")
@@ -161,89 +177,9 @@ func main() {
 	}
 
 	rand.Seed(time.Now().UTC().UnixNano())
-	cmd.Execute(buildTime, goVersion, buildHost, os.Args)
-	exec.Command("stty", "sane").Run()
-
-}
-
-func Setup() string {
-	dir, err := ioutil.TempDir("", "goyo.")
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	ydbaccess := `ydbaccess ; entry points to access YottaDB
- quit
- ;
-get(var,value)
- set value=@var
- quit
- ;
-kill(var)
- kill @var
- quit
- ;
-lock(var)
- lock @var
- quit
- ;
-order(var,value)
- set value=$order(@var)
- quit
- ;
-query(var,value)
- set value=$query(@var)
- quit
- ;
-set(var,value)
- set @var=value
- quit
- ;
-xecutew(var,err)
- new %endofexec,$etrap,$estack
- set %iopipe="/tmp/goyo.pipe"
- o %iopipe:fifo
- u %iopipe
- s %endofexec="<[<end>]>"
- set $etrap="w %endofexec,! c %iopipe quit:$estack  set $ecode="""",err=$zstatus quit:$quit +err quit"
- xecute var
- w %endofexec,!
- c %iopipe
- quit
-
-xecute(var,err)
- new $etrap,$estack
- set $etrap="quit:$estack  set $ecode="""",err=$zstatus quit:$quit +err quit"
- xecute var
- quit
- ;
-`
-
-	mfile := filepath.Join(dir, "ydbaccess.m")
-	qfs.Store(mfile, ydbaccess, "process")
-
-	ydb_routines := os.Getenv("ydb_routines")
-	zro := ydb_routines
-	if zro != "" {
-		zro = " "
-	}
-	zro += dir
-	err = ydb.SetValE(ydb.NOTTP, nil, zro, "$ZRO", nil)
-	if err != nil {
-		log.Fatal(err)
+	if cmd.Execute(buildTime, goVersion, buildHost, os.Args) != nil {
+		status = 1
 	}
+	exec.Command("stty", "sane").Run()
 
-	ydbaccess_ci := `get     : void get^ydbaccess(I:ydb_char_t*, O:ydb_string_t*)
-kill    : void kill^ydbaccess(I:ydb_char_t*)
-lock    : void lock^ydbaccess(I:ydb_char_t*)
-order   : void order^ydbaccess(I:ydb_char_t*, O:ydb_string_t*)
-query   : void query^ydbaccess(I:ydb_char_t*, O:ydb_string_t*)
-set     : void set^ydbaccess(I:ydb_char_t*, I:ydb_string_t*)
-xecute  : void xecute^ydbaccess(I:ydb_string_t*, O:ydb_string_t*)
-xecutew : void xecutew^ydbaccess(I:ydb_string_t*, O:ydb_string_t*)
-`
-	mci := filepath.Join(dir, "ydbaccess.ci")
-	qfs.Store(mci, ydbaccess_ci, "process")
-	os.Setenv("GOYO_DIR", dir)
-	return dir
 }
@@ -0,0 +1,15 @@
+//go:build noyottadb
+// +build noyottadb
+
+package main
+
+import (
+	"errors"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+)
+
+// openYottaDB fails: goyo is built without YottaDB
+func openYottaDB() (qgstore.GlobalStore, func(), error) {
+	return nil, nil, errors.New("goyo is built without YottaDB: use GOYO_STORE")
+}
@@ -0,0 +1,109 @@
+//go:build !noyottadb
+// +build !noyottadb
+
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	qfs "brocade.be/base/fs"
+	qgstore "brocade.be/goyo/lib/gstore"
+	qyottadb "brocade.be/goyo/lib/yottadb"
+	ydb "lang.yottadb.com/go/yottadb"
+)
+
+// openYottaDB returns the YottaDB store and the function to call on exit.
+// Build with the tag `noyottadb` to work without libyottadb (GOYO_STORE only).
+func openYottaDB() (qgstore.GlobalStore, func(), error) {
+	dir := Setup()
+	exit := func() {
+		os.RemoveAll(dir)
+		qyottadb.Exit()
+	}
+	return qyottadb.Store{}, exit, nil
+}
+
+func Setup() string {
+	dir, err := ioutil.TempDir("", "goyo.")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ydbaccess := `ydbaccess ; entry points to access YottaDB
+ quit
+ ;
+get(var,value)
+ set value=@var
+ quit
+ ;
+kill(var)
+ kill @var
+ quit
+ ;
+lock(var)
+ lock @var
+ quit
+ ;
+order(var,value)
+ set value=$order(@var)
+ quit
+ ;
+query(var,value)
+ set value=$query(@var)
+ quit
+ ;
+set(var,value)
+ set @var=value
+ quit
+ ;
+xecutew(var,err)
+ new %endofexec,$etrap,$estack
+ set %iopipe="/tmp/goyo.pipe"
+ o %iopipe:fifo
+ u %iopipe
+ s %endofexec="<[<end>]>"
+ set $etrap="w %endofexec,! c %iopipe quit:$estack  set $ecode="""",err=$zstatus quit:$quit +err quit"
+ xecute var
+ w %endofexec,!
+ c %iopipe
+ quit
+
+xecute(var,err)
+ new $etrap,$estack
+ set $etrap="quit:$estack  set $ecode="""",err=$zstatus quit:$quit +err quit"
+ xecute var
+ quit
+ ;
+`
+
+	mfile := filepath.Join(dir, "ydbaccess.m")
+	qfs.Store(mfile, ydbaccess, "process")
+
+	ydb_routines := os.Getenv("ydb_routines")
+	zro := ydb_routines
+	if zro != "" {
+		zro = " "
+	}
+	zro += dir
+	err = ydb.SetValE(ydb.NOTTP, nil, zro, "$ZRO", nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ydbaccess_ci := `get     : void get^ydbaccess(I:ydb_char_t*, O:ydb_string_t*)
+kill    : void kill^ydbaccess(I:ydb_char_t*)
+lock    : void lock^ydbaccess(I:ydb_char_t*)
+order   : void order^ydbaccess(I:ydb_char_t*, O:ydb_string_t*)
+query   : void query^ydbaccess(I:ydb_char_t*, O:ydb_string_t*)
+set     : void set^ydbaccess(I:ydb_char_t*, I:ydb_string_t*)
+xecute  : void xecute^ydbaccess(I:ydb_string_t*, O:ydb_string_t*)
+xecutew : void xecutew^ydbaccess(I:ydb_string_t*, O:ydb_string_t*)
+`
+	mci := filepath.Join(dir, "ydbaccess.ci")
+	qfs.Store(mci, ydbaccess_ci, "process")
+	os.Setenv("GOYO_DIR", dir)
+	return dir
+}
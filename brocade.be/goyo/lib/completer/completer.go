@@ -9,12 +9,16 @@ import (
 var actions = map[string]bool{
 	"bye":      true,
 	"cd":       true,
+	"diff":     true,
 	"echo":     true,
 	"exec":     true,
 	"exit":     true,
+	"export":   true,
 	"extract":  true,
 	"greet":    true,
+	"import":   true,
 	"load":     true,
+	"merge":    true,
 	"quit":     true,
 	"repl":     true,
 	"set":      true,
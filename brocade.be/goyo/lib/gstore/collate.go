@@ -0,0 +1,55 @@
+package gstore
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var rcanonic = regexp.MustCompile(`^(0|-?([1-9][0-9]*(\.[0-9]*[1-9])?|\.[0-9]*[1-9]))$`)
+
+// Canonic checks if s is a canonical number: `12`, `-1.5`, `.5` but not `012`, `1.50` or `0.5`
+func Canonic(s string) bool {
+	return rcanonic.MatchString(s)
+}
+
+// Collate compares two subscripts in M collation order:
+// canonical numbers come first (in numerical order), then the strings (in byte order).
+// The result is -1, 0 or 1.
+func Collate(a, b string) int {
+	na := Canonic(a)
+	nb := Canonic(b)
+	switch {
+	case na && nb:
+		x, _ := new(big.Rat).SetString(a)
+		y, _ := new(big.Rat).SetString(b)
+		return x.Cmp(y)
+	case na:
+		return -1
+	case nb:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// canonize returns the canonical form of a numeric literal: `+01.50` becomes `1.5`
+func canonize(s string) string {
+	sign := ""
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign = "-"
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+	whole = strings.TrimLeft(whole, "0")
+	fraction = strings.TrimRight(fraction, "0")
+	if fraction != "" {
+		fraction = "." + fraction
+	}
+	if whole+fraction == "" {
+		return "0"
+	}
+	return sign + whole + fraction
+}
@@ -0,0 +1,282 @@
+package gstore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A diff between two trees is a list of changes in ZWR notation, in M collation order:
+//
+//	--- ^A
+//	+++ ^B
+//	- ^A(2)="removed"
+//	+ ^B(3)="added"
+//	- ^A(4)="old"
+//	+ ^B(4)="new"
+//
+// A changed node is a `-` line followed by a `+` line with the same subscripts.
+
+// Change is a difference between two trees: Subs are relative to the top of the trees
+type Change struct {
+	Op   string   // "add", "remove" or "change"
+	Subs []string // subscripts below the top
+	Old  string   // value in the first tree (remove, change)
+	New  string   // value in the second tree (add, change)
+}
+
+// Conflict is a change which cannot be merged
+type Conflict struct {
+	Change Change
+	Exists bool   // the node has a value in the target
+	Found  string // the value in the target
+}
+
+// CompareSubs compares two lists of subscripts in $QUERY order
+func CompareSubs(a, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := Collate(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// cursor walks the nodes with a value of a tree in $QUERY order
+type cursor struct {
+	store GlobalStore
+	base  []string
+	subs  []string
+	done  bool
+}
+
+func newCursor(store GlobalStore, glvn string) (*cursor, error) {
+	base, err := Ref(store, glvn)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor{store: store, base: base}, nil
+}
+
+// next returns the subscripts below the top and the value of the next node: nil at the end
+func (cur *cursor) next() (rel []string, value string, err error) {
+	if cur.done {
+		return nil, "", nil
+	}
+	if cur.subs == nil {
+		cur.subs = cur.base
+		d, err := cur.store.Data(cur.base)
+		if err != nil {
+			return nil, "", err
+		}
+		if d%2 == 1 {
+			value, err = cur.store.Get(cur.base)
+			return []string{}, value, err
+		}
+	}
+	next, err := cur.store.Query(cur.subs)
+	if err != nil || !within(next, cur.base) {
+		cur.done = true
+		return nil, "", err
+	}
+	cur.subs = next
+	value, err = cur.store.Get(next)
+	return next[len(cur.base):], value, err
+}
+
+// Diff streams the differences between the tree glvna in storea and the tree glvnb in storeb to fn.
+// The trees are compared on the subscripts below their top: `^A("cfg")` can be compared with `^B`.
+func Diff(storea GlobalStore, glvna string, storeb GlobalStore, glvnb string, fn func(change Change) error) error {
+	cura, err := newCursor(storea, glvna)
+	if err != nil {
+		return err
+	}
+	curb, err := newCursor(storeb, glvnb)
+	if err != nil {
+		return err
+	}
+	rela, valuea, err := cura.next()
+	if err != nil {
+		return err
+	}
+	relb, valueb, err := curb.next()
+	if err != nil {
+		return err
+	}
+	for rela != nil || relb != nil {
+		c := 0
+		switch {
+		case rela == nil:
+			c = 1
+		case relb == nil:
+			c = -1
+		default:
+			c = CompareSubs(rela, relb)
+		}
+		switch {
+		case c < 0:
+			err = fn(Change{Op: "remove", Subs: rela, Old: valuea})
+		case c > 0:
+			err = fn(Change{Op: "add", Subs: relb, New: valueb})
+		case valuea != valueb:
+			err = fn(Change{Op: "change", Subs: rela, Old: valuea, New: valueb})
+		}
+		if err != nil {
+			return err
+		}
+		if c <= 0 {
+			rela, valuea, err = cura.next()
+			if err != nil {
+				return err
+			}
+		}
+		if c >= 0 {
+			relb, valueb, err = curb.next()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteDiff streams the differences between 2 trees to w and returns the number of changes
+func WriteDiff(storea GlobalStore, glvna string, storeb GlobalStore, glvnb string, w io.Writer) (count int, err error) {
+	basea, err := Ref(storea, glvna)
+	if err != nil {
+		return 0, err
+	}
+	baseb, err := Ref(storeb, glvnb)
+	if err != nil {
+		return 0, err
+	}
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", EUnQS(basea), EUnQS(baseb))
+	err = Diff(storea, glvna, storeb, glvnb, func(change Change) error {
+		count++
+		_, err := buf.WriteString(ChangeLines(basea, baseb, change))
+		return err
+	})
+	if e := buf.Flush(); err == nil {
+		err = e
+	}
+	return count, err
+}
+
+// ChangeLines returns a change in the format of WriteDiff
+func ChangeLines(basea []string, baseb []string, change Change) string {
+	lines := ""
+	if change.Op != "add" {
+		lines += "- " + ZWRLine(append(append([]string{}, basea...), change.Subs...), change.Old) + "\n"
+	}
+	if change.Op != "remove" {
+		lines += "+ " + ZWRLine(append(append([]string{}, baseb...), change.Subs...), change.New) + "\n"
+	}
+	return lines
+}
+
+// ReadDiff reads a diff made by WriteDiff: top is the reference after `---`
+func ReadDiff(r io.Reader) (top string, changes []Change, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var basea, baseb []string
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			continue
+		case strings.HasPrefix(line, "--- "):
+			top = strings.TrimSpace(line[4:])
+			basea = MArgs(QS(top))
+			continue
+		case strings.HasPrefix(line, "+++ "):
+			baseb = MArgs(QS(strings.TrimSpace(line[4:])))
+			continue
+		case !strings.HasPrefix(line, "- ") && !strings.HasPrefix(line, "+ "):
+			return top, nil, fmt.Errorf("line %d: `%s` is not a change", lineno, line)
+		}
+		base := basea
+		if line[0] == '+' {
+			base = baseb
+		}
+		if base == nil {
+			return top, nil, fmt.Errorf("line %d: `---` and `+++` lines are missing", lineno)
+		}
+		subs, value, err := ParseZWR(line[2:])
+		if err != nil {
+			return top, nil, fmt.Errorf("line %d: %s", lineno, err)
+		}
+		if !within(subs, base) && EUnQS(subs) != EUnQS(base) {
+			return top, nil, fmt.Errorf("line %d: `%s` is not in `%s`", lineno, EUnQS(subs), EUnQS(base))
+		}
+		rel := subs[len(base):]
+		if line[0] == '-' {
+			changes = append(changes, Change{Op: "remove", Subs: rel, Old: value})
+			continue
+		}
+		last := len(changes) - 1
+		if last >= 0 && changes[last].Op == "remove" && CompareSubs(changes[last].Subs, rel) == 0 {
+			changes[last].Op = "change"
+			changes[last].New = value
+			continue
+		}
+		changes = append(changes, Change{Op: "add", Subs: rel, New: value})
+	}
+	return top, changes, scanner.Err()
+}
+
+// Merge applies changes to the tree glvn in store. A change is applied if the node
+// has the value of the first tree: otherwise it is a conflict and the node is left alone.
+// Changes which are already in the tree are skipped.
+func Merge(store GlobalStore, glvn string, changes []Change) (applied int, conflicts []Conflict, err error) {
+	base, err := Ref(store, glvn)
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, change := range changes {
+		subs := append(append([]string{}, base...), change.Subs...)
+		d, err := store.Data(subs)
+		if err != nil {
+			return applied, conflicts, err
+		}
+		exists := d%2 == 1
+		found := ""
+		if exists {
+			found, err = store.Get(subs)
+			if err != nil {
+				return applied, conflicts, err
+			}
+		}
+		switch {
+		case change.Op == "remove" && !exists:
+			continue
+		case change.Op != "remove" && exists && found == change.New:
+			continue
+		case change.Op == "add" && exists:
+			conflicts = append(conflicts, Conflict{Change: change, Exists: true, Found: found})
+			continue
+		case change.Op != "add" && (!exists || found != change.Old):
+			conflicts = append(conflicts, Conflict{Change: change, Exists: exists, Found: found})
+			continue
+		}
+		if change.Op == "remove" {
+			err = store.Kill(subs, false)
+		} else {
+			err = store.Set(subs, change.New)
+		}
+		if err != nil {
+			return applied, conflicts, err
+		}
+		applied++
+	}
+	return applied, conflicts, nil
+}
@@ -0,0 +1,407 @@
+package gstore
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GlobalStore is the M environment goyo works on: YottaDB or a Memory store.
+// A reference is given as subscripts: subs[0] is the name of the variable
+// (`^BCAT`), the other elements are the values of the subscripts (without quotes).
+type GlobalStore interface {
+	// Data returns $DATA: 0, 1, 10 or 11
+	Data(subs []string) (int, error)
+	// Get returns the value of a node (error if the node has no value)
+	Get(subs []string) (string, error)
+	// Set sets the value of a node
+	Set(subs []string, value string) error
+	// Kill removes a node: with tree, the descendants are removed too
+	Kill(subs []string, tree bool) error
+	// Order returns $ORDER(ref,1) or $ORDER(ref,-1): "" at the end
+	Order(subs []string, forward bool) (string, error)
+	// Query returns the subscripts of $QUERY(ref): nil at the end
+	Query(subs []string) ([]string, error)
+	// Name returns $NAME(glvn): the canonical form of the reference
+	Name(glvn string) string
+	// Exec executes M code
+	Exec(text string) error
+	// Close releases the store
+	Close() error
+}
+
+var rnumber = regexp.MustCompile(`^[+-]?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))$`)
+
+// QS splits a reference in the name and the subscripts (as M code)
+func QS(glvn string) (subs []string) {
+
+	glvn = strings.TrimSpace(glvn)
+	if glvn == "" {
+		return nil
+	}
+	k := strings.Index(glvn, "(")
+	if k == -1 {
+		subs = append(subs, glvn)
+		return
+	}
+	subs = append(subs, glvn[:k])
+	glvn = strings.TrimSuffix(glvn[k+1:], ")")
+	glvn = strings.TrimSpace(glvn)
+	glvn = strings.TrimSuffix(glvn, ",")
+
+	if glvn == "" {
+		return nil
+	}
+	level := 0
+	sub := ""
+	even := true
+	for _, r := range glvn {
+		if r < 32 || r > 127 {
+			sub += string(r)
+			continue
+		}
+		switch r {
+		case '"':
+			sub += string(r)
+			even = !even
+			continue
+		case '(':
+			sub += string(r)
+			if even {
+				level++
+			}
+			continue
+		case ')':
+			sub += string(r)
+			if even {
+				level--
+			}
+			continue
+		case ',':
+			if !even || level != 0 {
+				sub += string(r)
+				continue
+			}
+			subs = append(subs, sub)
+			level = 0
+			even = true
+			sub = ""
+			continue
+		default:
+			sub += string(r)
+			continue
+		}
+	}
+
+	if sub != "" {
+		if strings.Count(sub, `"`)%2 == 1 {
+			sub += `"`
+		}
+		if level > 0 {
+			sub += strings.Repeat(")", level)
+		}
+		subs = append(subs, sub)
+	}
+	for i, sub := range subs {
+		sub = strings.TrimSpace(sub)
+		if !strings.HasPrefix(sub, `"`) && strings.Contains(sub, "(") {
+			subsubs := QS(sub)
+			sub = UnQS(subsubs)
+		}
+		subs[i] = sub
+	}
+	return
+}
+
+// UnQS is the inverse of QS
+func UnQS(subs []string) (glvn string) {
+	switch len(subs) {
+	case 0:
+		return ""
+	case 1:
+		return subs[0]
+	default:
+		return subs[0] + `(` + strings.Join(subs[1:], ",") + `)`
+	}
+}
+
+// EUnQS builds a reference from the name and the values of the subscripts
+func EUnQS(subs []string) (glvn string) {
+	switch len(subs) {
+	case 0:
+		return ""
+	case 1:
+		return subs[0]
+	default:
+		args := make([]string, len(subs))
+		args[0] = subs[0]
+		for i := 1; i < len(subs); i++ {
+			args[i] = MakeArg(subs[i])
+		}
+		return UnQS(args)
+	}
+}
+
+// MakeArg makes an M literal of a value.
+// Only canonical numbers are left unquoted: `01`, `1.0` and `+1` are strings
+// (unquoted, M would turn them into the number 1).
+func MakeArg(s string) string {
+	if isNumber(s) {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// MArgs removes the quotes of the string literals in the subscripts
+func MArgs(subs []string) []string {
+	if len(subs) == 0 {
+		return subs
+	}
+	argums := make([]string, len(subs))
+	for i := 0; i < len(subs); i++ {
+		sub := subs[i]
+		if i == 0 {
+			argums[i] = sub
+			continue
+		}
+		if !strings.HasPrefix(sub, `"`) {
+			argums[i] = sub
+			continue
+		}
+		if len(sub) < 2 {
+			argums[i] = sub
+			continue
+		}
+		if !strings.HasSuffix(sub, `"`) {
+			argums[i] = sub
+			continue
+		}
+		argums[i] = strings.ReplaceAll(sub[1:len(sub)-1], `""`, `"`)
+	}
+	return argums
+}
+
+func isNumber(term string) bool {
+	return Canonic(term)
+}
+
+// Ref returns the subscripts of a reference in the store
+func Ref(store GlobalStore, glvn string) ([]string, error) {
+	subs := QS(store.Name(glvn))
+	if len(subs) == 0 {
+		return nil, errors.New("invalid reference")
+	}
+	for _, sub := range subs[1:] {
+		if strings.HasPrefix(sub, `"`) || rnumber.MatchString(sub) {
+			continue
+		}
+		return nil, fmt.Errorf("subscript `%s` is not a literal", sub)
+	}
+	return MArgs(subs), nil
+}
+
+// N returns $NAME(glvn)
+func N(store GlobalStore, glvn string) string {
+	return store.Name(glvn)
+}
+
+// D returns $DATA(glvn) (-1 for an invalid reference)
+func D(store GlobalStore, glvn string) (int, error) {
+	subs, err := Ref(store, glvn)
+	if err != nil {
+		return -1, err
+	}
+	return store.Data(subs)
+}
+
+// G returns the value of glvn
+func G(store GlobalStore, glvn string) (string, error) {
+	subs, err := Ref(store, glvn)
+	if err != nil {
+		return "", err
+	}
+	return store.Get(subs)
+}
+
+// Set sets glvn to value
+func Set(store GlobalStore, glvn string, value string) error {
+	subs, err := Ref(store, glvn)
+	if err != nil {
+		return err
+	}
+	return store.Set(subs, value)
+}
+
+// Kill kills glvn: the node or the tree
+func Kill(store GlobalStore, glvn string, tree bool) error {
+	subs, err := Ref(store, glvn)
+	if err != nil {
+		return err
+	}
+	return store.Kill(subs, tree)
+}
+
+// Next = $ORDER(glvn): the reference is returned unchanged at the end
+func Next(store GlobalStore, glvn string) (nglvn, next string, err error) {
+	return order(store, glvn, true)
+}
+
+// Prev = $ORDER(glvn,-1): the reference is returned unchanged at the start
+func Prev(store GlobalStore, glvn string) (pglvn, prev string, err error) {
+	return order(store, glvn, false)
+}
+
+func order(store GlobalStore, glvn string, forward bool) (string, string, error) {
+	subs, err := Ref(store, glvn)
+	if err != nil {
+		return glvn, "", err
+	}
+	if len(subs) < 2 {
+		return glvn, "", errors.New("cannot determine next")
+	}
+	z, err := store.Order(subs, forward)
+	if err != nil || z == "" {
+		return glvn, "", err
+	}
+	subs[len(subs)-1] = z
+	return EUnQS(subs), z, nil
+}
+
+// Right adds a subscript level
+func Right(glvn string) (rglvn string, err error) {
+	subs := MArgs(QS(glvn))
+	if len(subs) == 0 {
+		return glvn, errors.New("cannot determine right")
+	}
+	if len(subs) > 1 && subs[len(subs)-1] == "" {
+		return glvn, err
+	}
+	subs = append(subs, "")
+	return EUnQS(subs), nil
+}
+
+// Left removes a subscript level
+func Left(glvn string) (rglvn string, err error) {
+	subs := MArgs(QS(glvn))
+	if len(subs) < 2 {
+		return glvn, errors.New("cannot determine left")
+	}
+	subs = subs[:len(subs)-1]
+	return EUnQS(subs), nil
+}
+
+// VarReport is a node in ZWR notation
+type VarReport struct {
+	Gloref string
+	Value  string
+	Err    error
+}
+
+// SubReport is a node as a list of subscripts
+type SubReport struct {
+	Subs  []string
+	Value string
+	Err   error
+}
+
+// walk sends the nodes of a tree with a value to fn: it stops if fn returns false.
+// With a needle, only the first node after gloref matching the needle is sent.
+func walk(store GlobalStore, gloref string, needle string, fn func(subs []string, value string, err error) bool) {
+	rex := new(regexp.Regexp)
+	var err error
+	if needle != "" {
+		rex, err = regexp.Compile(needle)
+		if err != nil {
+			rex = nil
+		}
+	}
+	base, err := Ref(store, gloref)
+	d := 0
+	if err == nil {
+		d, err = store.Data(base)
+	}
+	if err == nil && d == 0 {
+		err = fmt.Errorf("`%s` does not exist", store.Name(gloref))
+	}
+	if err != nil {
+		fn(nil, "", err)
+		return
+	}
+	if needle == "" && (d == 1 || d == 11) {
+		value, _ := store.Get(base)
+		if !fn(base, value, nil) {
+			return
+		}
+	}
+	subs := base
+	for {
+		next, err := store.Query(subs)
+		if err != nil || !within(next, base) {
+			fn(nil, "", err)
+			return
+		}
+		subs = next
+		value, _ := store.Get(subs)
+		if needle != "" {
+			pair := EUnQS(subs) + "=" + value
+			found := strings.Contains(pair, needle)
+			if !found && rex != nil {
+				found = rex.FindStringIndex(pair) != nil
+			}
+			if !found {
+				continue
+			}
+		}
+		if !fn(subs, value, nil) || needle != "" {
+			return
+		}
+	}
+}
+
+// within checks if subs is a descendant of base
+func within(subs []string, base []string) bool {
+	if len(subs) <= len(base) {
+		return false
+	}
+	for i, sub := range base {
+		if subs[i] != sub {
+			return false
+		}
+	}
+	return true
+}
+
+// ZWR sends the nodes of gloref in ZWR notation to report.
+// The last report has an empty Gloref.
+func ZWR(store GlobalStore, gloref string, report chan VarReport, needle string, forward bool) {
+	walk(store, gloref, needle, func(subs []string, value string, err error) bool {
+		if subs == nil {
+			report <- VarReport{Err: err}
+			close(report)
+			return false
+		}
+		report <- VarReport{Gloref: EUnQS(subs), Value: value}
+		if needle != "" {
+			close(report)
+		}
+		return true
+	})
+}
+
+// CSV sends the nodes of gloref as a list of subscripts to report.
+// The last report has no subscripts.
+func CSV(store GlobalStore, gloref string, report chan SubReport, needle string, forward bool) {
+	walk(store, gloref, needle, func(subs []string, value string, err error) bool {
+		if subs == nil {
+			report <- SubReport{Err: err}
+			close(report)
+			return false
+		}
+		report <- SubReport{Subs: subs, Value: value}
+		if needle != "" {
+			close(report)
+		}
+		return true
+	})
+}
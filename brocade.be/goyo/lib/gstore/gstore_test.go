@@ -0,0 +1,392 @@
+package gstore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollate(t *testing.T) {
+	order := []string{"-10", "-1.5", "-.5", "0", ".25", "1", "2", "10", "100.5", " ", "+1", "01", "1.50", "A", "B", "a", "ab", "b"}
+	for i := 0; i < len(order)-1; i++ {
+		a, b := order[i], order[i+1]
+		if Collate(a, b) != -1 || Collate(b, a) != 1 {
+			t.Errorf("`%s` should collate before `%s`", a, b)
+		}
+		if Collate(a, a) != 0 {
+			t.Errorf("`%s` should collate equal to itself", a)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	store := NewMemory()
+	tests := map[string]string{
+		`^A`:                 `^A`,
+		`^A(1,"x")`:          `^A(1,"x")`,
+		`^A("1","01",+1.50)`: `^A(1,"01",1.5)`,
+		`^A(0.5,-0,"a""b")`:  `^A(.5,0,"a""b")`,
+		`^A(x)`:              `^A(x)`,
+		`^A( "a" , 2 `:       `^A("a",2)`,
+	}
+	for glvn, expect := range tests {
+		if calc := store.Name(glvn); calc != expect {
+			t.Errorf("Name(%s): expected `%s`, found `%s`", glvn, expect, calc)
+		}
+	}
+	if _, err := Ref(store, "^A(x)"); err == nil {
+		t.Errorf("Ref(^A(x)) should fail")
+	}
+}
+
+func fill(t *testing.T) *Memory {
+	store := NewMemory()
+	nodes := []string{
+		`^A=0`,
+		`^A(1)="one"`,
+		`^A(1,"x")="1x"`,
+		`^A(2,"y","z")="2yz"`,
+		`^A(10)="ten"`,
+		`^A("b")="b"`,
+		`^A("a")="a"`,
+		`^B(1)="b1"`,
+	}
+	for _, node := range nodes {
+		glvn, value, _ := strings.Cut(node, "=")
+		if err := Set(store, glvn, strings.Trim(value, `"`)); err != nil {
+			t.Fatalf("Set(%s): %s", glvn, err)
+		}
+	}
+	return store
+}
+
+func TestData(t *testing.T) {
+	store := fill(t)
+	tests := map[string]int{
+		`^A`:            11,
+		`^A(1)`:         11,
+		`^A(1,"x")`:     1,
+		`^A(2)`:         10,
+		`^A(2,"y")`:     10,
+		`^A(3)`:         0,
+		`^C`:            0,
+		`^A("a")`:       1,
+		`^A(2,"y","z")`: 1,
+	}
+	for glvn, expect := range tests {
+		d, err := D(store, glvn)
+		if err != nil || d != expect {
+			t.Errorf("$D(%s): expected %d, found %d (%v)", glvn, expect, d, err)
+		}
+	}
+	if _, err := G(store, `^A(2)`); err == nil {
+		t.Errorf("$G(^A(2)) should fail")
+	}
+	if err := Set(store, `^A(3,"")`, "x"); err == nil {
+		t.Errorf("Set with an empty subscript should fail")
+	}
+}
+
+func TestOrder(t *testing.T) {
+	store := fill(t)
+	expect := []string{"1", "2", "10", "a", "b"}
+	sub := ""
+	for _, e := range expect {
+		next, err := store.Order([]string{"^A", sub}, true)
+		if err != nil || next != e {
+			t.Fatalf("$O(^A(%s)): expected `%s`, found `%s`", sub, e, next)
+		}
+		sub = next
+	}
+	if next, _ := store.Order([]string{"^A", sub}, true); next != "" {
+		t.Errorf("$O(^A(%s)) should be at the end, found `%s`", sub, next)
+	}
+	for i := len(expect) - 1; i >= 0; i-- {
+		prev, _ := store.Order([]string{"^A", sub}, false)
+		if i == len(expect)-1 {
+			prev, _ = store.Order([]string{"^A", ""}, false)
+		}
+		if prev != expect[i] {
+			t.Fatalf("$O(^A(%s),-1): expected `%s`, found `%s`", sub, expect[i], prev)
+		}
+		sub = prev
+	}
+	if next, _ := store.Order([]string{"^A", "5"}, true); next != "10" {
+		t.Errorf("$O(^A(5)): expected `10`, found `%s`", next)
+	}
+	glvn, next, _ := Next(store, `^A(2)`)
+	if glvn != `^A(10)` || next != "10" {
+		t.Errorf("Next(^A(2)): found `%s`, `%s`", glvn, next)
+	}
+	glvn, _, _ = Prev(store, `^A(1)`)
+	if glvn != `^A(1)` {
+		t.Errorf("Prev(^A(1)): found `%s`", glvn)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	store := fill(t)
+	expect := []string{`^A(1)`, `^A(1,"x")`, `^A(2,"y","z")`, `^A(10)`, `^A("a")`, `^A("b")`}
+	subs := []string{"^A"}
+	for _, e := range expect {
+		next, err := store.Query(subs)
+		if err != nil || EUnQS(next) != e {
+			t.Fatalf("$Q(%s): expected `%s`, found `%s`", EUnQS(subs), e, EUnQS(next))
+		}
+		subs = next
+	}
+	if next, _ := store.Query(subs); next != nil {
+		t.Errorf("$Q(%s) should be at the end, found `%s`", EUnQS(subs), EUnQS(next))
+	}
+	if next, _ := store.Query([]string{"^A", "2", "a"}); EUnQS(next) != `^A(2,"y","z")` {
+		t.Errorf("$Q(^A(2,\"a\")): found `%s`", EUnQS(next))
+	}
+
+	show := make(chan VarReport, 100)
+	go ZWR(store, `^A(1)`, show, "", true)
+	found := make([]string, 0)
+	for report := range show {
+		if report.Gloref == "" {
+			break
+		}
+		found = append(found, report.Gloref+"="+report.Value)
+	}
+	if strings.Join(found, "\n") != "^A(1)=one\n^A(1,\"x\")=1x" {
+		t.Errorf("ZWR(^A(1)): found\n%s", strings.Join(found, "\n"))
+	}
+}
+
+func TestKill(t *testing.T) {
+	store := fill(t)
+	if err := Kill(store, `^A(2,"y","z")`, true); err != nil {
+		t.Fatal(err)
+	}
+	if d, _ := D(store, `^A(2)`); d != 0 {
+		t.Errorf("$D(^A(2)) after kill: expected 0, found %d", d)
+	}
+	if next, _ := store.Order([]string{"^A", "1"}, true); next != "10" {
+		t.Errorf("$O(^A(1)) after kill: expected `10`, found `%s`", next)
+	}
+	Kill(store, `^A(1)`, false)
+	if d, _ := D(store, `^A(1)`); d != 10 {
+		t.Errorf("$D(^A(1)) after killnode: expected 10, found %d", d)
+	}
+	Kill(store, `^A`, true)
+	if d, _ := D(store, `^A`); d != 0 {
+		t.Errorf("$D(^A) after kill: expected 0, found %d", d)
+	}
+	if d, _ := D(store, `^B`); d != 10 {
+		t.Errorf("$D(^B) after kill of ^A: expected 10, found %d", d)
+	}
+}
+
+func TestZWR(t *testing.T) {
+	tests := []struct {
+		subs  []string
+		value string
+		line  string
+	}{
+		{[]string{"^A"}, "12", `^A=12`},
+		{[]string{"^A", "1", "x"}, `a"b`, `^A(1,"x")="a""b"`},
+		{[]string{"^A", "01"}, "", `^A("01")=""`},
+		{[]string{"^A", "-1.5"}, "a\tb\r\n", `^A(-1.5)="a"_$C(9)_"b"_$C(13,10)`},
+		{[]string{"^A", "\x01"}, "été", `^A($C(1))="été"`},
+	}
+	for _, test := range tests {
+		line := ZWRLine(test.subs, test.value)
+		if line != test.line {
+			t.Errorf("ZWRLine: expected `%s`, found `%s`", test.line, line)
+		}
+		subs, value, err := ParseZWR(line)
+		if err != nil || EUnQS(subs) != EUnQS(test.subs) || value != test.value {
+			t.Errorf("ParseZWR(%s): found %q=%q (%v)", line, subs, value, err)
+		}
+	}
+	if _, _, err := ParseZWR(`^A(1="x"`); err == nil {
+		t.Errorf("ParseZWR should fail")
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "goyo.zwr")
+	store := fill(t)
+	buffer := new(bytes.Buffer)
+	count, err := Export(store, "^A", buffer, true)
+	if err != nil || count != 7 {
+		t.Fatalf("Export: %d nodes (%v)", count, err)
+	}
+	os.WriteFile(file, buffer.Bytes(), 0600)
+
+	store, err = Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := G(store, `^A(2,"y","z")`); value != "2yz" {
+		t.Errorf("Open: found `%s`", value)
+	}
+	Set(store, `^C("new")`, "x")
+	Set(store, `local`, "not stored")
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err = Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if globals := strings.Join(store.Globals(), ","); globals != "^A,^C" {
+		t.Errorf("Globals: found `%s`", globals)
+	}
+	if d, _ := D(store, "local"); d != 0 {
+		t.Errorf("local variables should not be stored")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	store := fill(t)
+	other := NewMemory()
+	for _, node := range []string{`^C(1)=one`, `^C(1,"x")=changed`, `^C(2,"y")=new`, `^C(10)=ten`, `^C("b")=b`, `^C("c")=c`} {
+		glvn, value, _ := strings.Cut(node, "=")
+		Set(other, glvn, value)
+	}
+	buffer := new(bytes.Buffer)
+	count, err := WriteDiff(store, "^A", other, "^C", buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `--- ^A
++++ ^C
+- ^A=0
+- ^A(1,"x")="1x"
++ ^C(1,"x")="changed"
++ ^C(2,"y")="new"
+- ^A(2,"y","z")="2yz"
+- ^A("a")="a"
++ ^C("c")="c"
+`
+	if buffer.String() != expect || count != 6 {
+		t.Errorf("WriteDiff: %d changes, found\n%s", count, buffer.String())
+	}
+
+	top, changes, err := ReadDiff(strings.NewReader(buffer.String()))
+	if err != nil || top != "^A" || len(changes) != 6 {
+		t.Fatalf("ReadDiff: %s %d (%v)", top, len(changes), err)
+	}
+	if changes[1].Op != "change" || changes[1].Old != "1x" || changes[1].New != "changed" {
+		t.Errorf("ReadDiff: found %#v", changes[1])
+	}
+
+	Set(store, `^A("a")`, "local change")
+	applied, conflicts, err := Merge(store, "^A", changes)
+	if err != nil || applied != 5 || len(conflicts) != 1 {
+		t.Fatalf("Merge: %d applied, %d conflicts (%v)", applied, len(conflicts), err)
+	}
+	if conflicts[0].Found != "local change" || conflicts[0].Change.Old != "a" {
+		t.Errorf("Merge: found conflict %#v", conflicts[0])
+	}
+	Kill(store, `^A("a")`, true)
+	count, _ = WriteDiff(store, "^A", other, "^C", new(bytes.Buffer))
+	if count != 0 {
+		t.Errorf("Merge: %d changes left", count)
+	}
+	applied, conflicts, _ = Merge(store, "^A", changes)
+	if applied != 0 || len(conflicts) != 0 {
+		t.Errorf("Merge twice: %d applied, %d conflicts", applied, len(conflicts))
+	}
+}
+
+func TestMapping(t *testing.T) {
+	store := NewMemory()
+	for _, node := range []string{`^BCAT("c:lvd:1","ti")=Title 1`, `^BCAT("c:lvd:1","au")=Author`, `^BCAT("c:lvd:2","ti")=Title "2"`,
+		`^BCAT("c:lvd:2","py")=-.5`, `^BCAT("c:lvd:2","ti","x")=deeper`, `^BCAT("c:lvd:3")=no field`} {
+		glvn, value, _ := strings.Cut(node, "=")
+		Set(store, glvn, value)
+	}
+	for _, bad := range []string{"^BCAT", "BCAT(loi)", `^BCAT("x")`, "^BCAT(@a,@b)", "^BCAT(a,a)", "^BCAT(value)", "^BCAT($H)"} {
+		if _, err := ParseMapping(bad); err == nil {
+			t.Errorf("ParseMapping(%s) should fail", bad)
+		}
+	}
+	mapping, err := ParseMapping("^BCAT(loi,@field)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := mapping.Table(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer := new(bytes.Buffer)
+	table.WriteCSV(buffer)
+	expect := "loi,au,py,ti\nc:lvd:1,Author,,Title 1\nc:lvd:2,,-.5,\"Title \"\"2\"\"\"\n"
+	if buffer.String() != expect {
+		t.Errorf("WriteCSV: found\n%s", buffer.String())
+	}
+	buffer.Reset()
+	table.WriteJSON(buffer)
+	expect = "[\n  {\"loi\": \"c:lvd:1\", \"au\": \"Author\", \"ti\": \"Title 1\"},\n  {\"loi\": \"c:lvd:2\", \"py\": -0.5, \"ti\": \"Title \\\"2\\\"\"}\n]\n"
+	if buffer.String() != expect {
+		t.Errorf("WriteJSON: found\n%s", buffer.String())
+	}
+	table, err = ReadJSON(strings.NewReader(buffer.String()))
+	if err != nil || len(table.Rows) != 2 || table.Rows[1]["py"] != "-.5" || strings.Join(table.Columns, ",") != "loi,au,ti,py" {
+		t.Errorf("ReadJSON: %#v (%v)", table, err)
+	}
+	changes, err := mapping.Changes(store, table)
+	if err != nil || len(changes) != 0 {
+		t.Errorf("Changes after a round trip: %v (%v)", changes, err)
+	}
+
+	corrected := "loi,ti,au,new\nc:lvd:1,Title one,,x\nc:lvd:4,Title 4,,\n"
+	table, err = ReadCSV(strings.NewReader(corrected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err = mapping.Import(store, table, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buffer.Reset()
+	for _, change := range changes {
+		buffer.WriteString(ChangeLines([]string{"^BCAT"}, []string{"^BCAT"}, change))
+	}
+	expect = `- ^BCAT("c:lvd:1","ti")="Title 1"
++ ^BCAT("c:lvd:1","ti")="Title one"
+- ^BCAT("c:lvd:1","au")="Author"
++ ^BCAT("c:lvd:1","new")="x"
++ ^BCAT("c:lvd:4","ti")="Title 4"
+`
+	if buffer.String() != expect {
+		t.Errorf("Import: found\n%s", buffer.String())
+	}
+	if value, _ := G(store, `^BCAT("c:lvd:1","ti")`); value != "Title 1" {
+		t.Errorf("dry-run should not change the global")
+	}
+	if _, err = mapping.Import(store, table, false); err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ = mapping.Changes(store, table); len(changes) != 0 {
+		t.Errorf("Import: %d changes left", len(changes))
+	}
+	if d, _ := D(store, `^BCAT("c:lvd:1","au")`); d != 0 {
+		t.Errorf("Import: an empty cell should remove the node")
+	}
+
+	mapping, _ = ParseMapping(`^BCAT(loi,"ti")`)
+	table, _ = mapping.Table(store)
+	if len(table.Rows) != 3 || table.Rows[0]["value"] != "Title one" || strings.Join(table.Columns, ",") != "loi,value" {
+		t.Errorf("Table without @: %#v", table)
+	}
+	if _, err = mapping.Changes(store, &Table{Columns: []string{"loi"}}); err == nil {
+		t.Errorf("Changes without a value column should fail")
+	}
+	table, _ = ReadCSV(strings.NewReader("loi,value\nc:lvd:5,Title 5\nc:lvd:1,Title 1\nc:lvd:5,Title five\n"))
+	if _, err = mapping.Import(store, table, false); err == nil || !strings.Contains(err.Error(), "row 3") {
+		t.Errorf("Import with duplicate keys should fail: %v", err)
+	}
+	if d, _ := D(store, `^BCAT("c:lvd:5")`); d != 0 {
+		t.Errorf("Import with duplicate keys should not change the global")
+	}
+}
@@ -0,0 +1,400 @@
+package gstore
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// A Mapping describes how the nodes of a global correspond to the records of a table
+// (a CSV file or a JSON array of objects). It is written as a reference:
+//
+//	^BCAT(loi,@field)
+//
+// Literal subscripts (strings and numbers) select the nodes, every name is a key column
+// of the table and the subscript with `@` gives the other columns: the value of a node
+// is the cell in the row of its keys and in the column of its `@` subscript.
+// Without `@`, every node is a row and the value is in the column `value`.
+
+// Mapping of a global on a table
+type Mapping struct {
+	Name  string   // name of the global
+	Subs  []string // literal values and names of the subscripts
+	Lits  []bool   // the subscript is a literal
+	Keys  []string // the names of the key columns
+	Field int      // position of the `@` subscript in Subs (-1 without `@`)
+}
+
+var rmapname = regexp.MustCompile(`^[A-Za-z%][A-Za-z0-9_]*$`)
+
+// ParseMapping parses a mapping: `^BCAT(loi,@field)`
+func ParseMapping(spec string) (*Mapping, error) {
+	subs := QS(spec)
+	if len(subs) < 2 || !strings.HasPrefix(subs[0], "^") {
+		return nil, fmt.Errorf("mapping `%s` should be a global with subscripts: ^BCAT(loi,@field)", spec)
+	}
+	mapping := &Mapping{Name: subs[0], Field: -1}
+	seen := make(map[string]bool)
+	for i, sub := range subs[1:] {
+		lit := false
+		switch {
+		case strings.HasPrefix(sub, `"`):
+			lit = true
+			sub = MArgs([]string{"", sub})[1]
+		case rnumber.MatchString(sub):
+			lit = true
+			sub = canonize(sub)
+		case strings.HasPrefix(sub, "@"):
+			if mapping.Field >= 0 {
+				return nil, fmt.Errorf("mapping `%s` has more than one `@` subscript", spec)
+			}
+			sub = sub[1:]
+			mapping.Field = i
+		default:
+			mapping.Keys = append(mapping.Keys, sub)
+		}
+		if !lit && !rmapname.MatchString(sub) {
+			return nil, fmt.Errorf("mapping `%s`: `%s` is not a name", spec, sub)
+		}
+		if !lit && seen[sub] {
+			return nil, fmt.Errorf("mapping `%s`: `%s` is used twice", spec, sub)
+		}
+		seen[sub] = !lit
+		mapping.Subs = append(mapping.Subs, sub)
+		mapping.Lits = append(mapping.Lits, lit)
+	}
+	if len(mapping.Keys) == 0 && mapping.Field < 0 {
+		return nil, fmt.Errorf("mapping `%s` has no names", spec)
+	}
+	if mapping.Field < 0 && seen["value"] {
+		return nil, fmt.Errorf("mapping `%s`: `value` is the column of the values", spec)
+	}
+	return mapping, nil
+}
+
+// top returns the reference of the literal subscripts before the first name
+func (mapping *Mapping) top() []string {
+	top := []string{mapping.Name}
+	for i, sub := range mapping.Subs {
+		if !mapping.Lits[i] {
+			break
+		}
+		top = append(top, sub)
+	}
+	return top
+}
+
+// Table is the content of a CSV file or a JSON array of objects
+type Table struct {
+	Columns []string
+	Rows    []map[string]string
+}
+
+// Table returns the nodes of the mapping as a table: the rows and the columns are
+// in M collation order. Nodes which do not fit the mapping are skipped.
+func (mapping *Mapping) Table(store GlobalStore) (table *Table, err error) {
+	table = &Table{Columns: append([]string{}, mapping.Keys...)}
+	if mapping.Field < 0 {
+		table.Columns = append(table.Columns, "value")
+	}
+	rows := make(map[string]map[string]string)
+	rowkeys := make([][]string, 0)
+	fields := make([]string, 0)
+	seen := make(map[string]bool)
+	top := mapping.top()
+	d, err := store.Data(top)
+	if err != nil || d == 0 {
+		return table, err
+	}
+	walk(store, EUnQS(top), "", func(subs []string, value string, e error) bool {
+		if subs == nil {
+			err = e
+			return false
+		}
+		if len(subs) != len(mapping.Subs)+1 {
+			return true
+		}
+		keys := make([]string, 0, len(mapping.Keys))
+		field := "value"
+		for i, sub := range subs[1:] {
+			switch {
+			case mapping.Lits[i] && sub != mapping.Subs[i]:
+				return true
+			case mapping.Lits[i]:
+			case i == mapping.Field:
+				field = sub
+			default:
+				keys = append(keys, sub)
+			}
+		}
+		id := EUnQS(append([]string{""}, keys...))
+		row := rows[id]
+		if row == nil {
+			row = make(map[string]string)
+			for i, key := range mapping.Keys {
+				row[key] = keys[i]
+			}
+			rows[id] = row
+			rowkeys = append(rowkeys, keys)
+		}
+		row[field] = value
+		if mapping.Field >= 0 && !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+		return true
+	})
+	sort.SliceStable(rowkeys, func(i, j int) bool { return CompareSubs(rowkeys[i], rowkeys[j]) < 0 })
+	sort.SliceStable(fields, func(i, j int) bool { return Collate(fields[i], fields[j]) < 0 })
+	table.Columns = append(table.Columns, fields...)
+	for _, keys := range rowkeys {
+		table.Rows = append(table.Rows, rows[EUnQS(append([]string{""}, keys...))])
+	}
+	return table, err
+}
+
+// Changes returns the changes which make the global correspond to the table.
+// Every cell gives a node: an empty cell removes the node (with `@`), an empty value
+// is set as such (without `@`). Nodes which are not in the table are left alone.
+// The keys of a row should not be empty and should differ from the keys of the other rows.
+// The subscripts of the changes are below the name of the global.
+func (mapping *Mapping) Changes(store GlobalStore, table *Table) (changes []Change, err error) {
+	keys := make(map[string]bool)
+	for _, key := range mapping.Keys {
+		keys[key] = true
+	}
+	for _, key := range mapping.Keys {
+		if !containsString(table.Columns, key) {
+			return nil, fmt.Errorf("column `%s` is missing", key)
+		}
+	}
+	if mapping.Field < 0 && !containsString(table.Columns, "value") {
+		return nil, fmt.Errorf("column `value` is missing")
+	}
+	rowids := make(map[string]int)
+	for r, row := range table.Rows {
+		keyvals := make([]string, len(mapping.Keys))
+		for i, key := range mapping.Keys {
+			keyvals[i] = row[key]
+			if keyvals[i] == "" {
+				return nil, fmt.Errorf("row %d: `%s` is empty", r+1, key)
+			}
+		}
+		id := EUnQS(append([]string{""}, keyvals...))
+		if first, ok := rowids[id]; ok {
+			return nil, fmt.Errorf("row %d: the keys are the same as in row %d", r+1, first)
+		}
+		rowids[id] = r + 1
+		for _, column := range table.Columns {
+			if keys[column] || (mapping.Field < 0 && column != "value") {
+				continue
+			}
+			subs := make([]string, len(mapping.Subs))
+			for i, sub := range mapping.Subs {
+				switch {
+				case mapping.Lits[i]:
+					subs[i] = sub
+				case i == mapping.Field:
+					subs[i] = column
+				default:
+					subs[i] = row[sub]
+				}
+			}
+			value, ok := row[column]
+			if !ok {
+				continue
+			}
+			remove := mapping.Field >= 0 && value == ""
+			node := append([]string{mapping.Name}, subs...)
+			d, err := store.Data(node)
+			if err != nil {
+				return nil, err
+			}
+			old := ""
+			if d%2 == 1 {
+				old, err = store.Get(node)
+				if err != nil {
+					return nil, err
+				}
+			}
+			switch {
+			case d%2 == 0 && remove:
+			case d%2 == 0:
+				changes = append(changes, Change{Op: "add", Subs: subs, New: value})
+			case remove:
+				changes = append(changes, Change{Op: "remove", Subs: subs, Old: old})
+			case old != value:
+				changes = append(changes, Change{Op: "change", Subs: subs, Old: old, New: value})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// Import applies a table to the global: with dryrun, only the changes are returned
+func (mapping *Mapping) Import(store GlobalStore, table *Table, dryrun bool) (changes []Change, err error) {
+	changes, err = mapping.Changes(store, table)
+	if err != nil || dryrun {
+		return changes, err
+	}
+	_, conflicts, err := Merge(store, mapping.Name, changes)
+	if err == nil && len(conflicts) != 0 {
+		err = fmt.Errorf("%d nodes were changed during the import", len(conflicts))
+	}
+	return changes, err
+}
+
+// WriteCSV writes the table in CSV format with a header line
+func (table *Table) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	writer.Write(table.Columns)
+	for _, row := range table.Rows {
+		record := make([]string, len(table.Columns))
+		for i, column := range table.Columns {
+			record[i] = row[column]
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSON writes the table as a JSON array of objects: the keys are in the order
+// of the columns, canonical numbers are JSON numbers.
+func (table *Table) WriteJSON(w io.Writer) error {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("[")
+	for r, row := range table.Rows {
+		if r != 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n  {")
+		first := true
+		for _, column := range table.Columns {
+			value, ok := row[column]
+			if !ok {
+				continue
+			}
+			if !first {
+				buffer.WriteString(", ")
+			}
+			first = false
+			key, _ := json.Marshal(column)
+			buffer.Write(key)
+			buffer.WriteString(": ")
+			if Canonic(value) {
+				buffer.WriteString(jsonNumber(value))
+				continue
+			}
+			blob, _ := json.Marshal(value)
+			buffer.Write(blob)
+		}
+		buffer.WriteString("}")
+	}
+	buffer.WriteString("\n]\n")
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// jsonNumber writes a canonical number as a JSON number: `.5` becomes `0.5`
+func jsonNumber(value string) string {
+	switch {
+	case strings.HasPrefix(value, "."):
+		return "0" + value
+	case strings.HasPrefix(value, "-."):
+		return "-0" + value[1:]
+	}
+	return value
+}
+
+// ReadCSV reads a table in CSV format: the first line contains the columns
+func ReadCSV(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("header line is missing")
+	}
+	table := &Table{Columns: records[0]}
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, column := range table.Columns {
+			if i < len(record) {
+				row[column] = record[i]
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+// ReadJSON reads a table as a JSON array of objects: the columns are the keys in the
+// order of their first appearance. null is an empty cell, booleans are 1 and 0.
+func ReadJSON(r io.Reader) (*Table, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	objects := make([]json.RawMessage, 0)
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, err
+	}
+	table := new(Table)
+	for i, blob := range objects {
+		dec := json.NewDecoder(bytes.NewReader(blob))
+		dec.UseNumber()
+		token, err := dec.Token()
+		if err != nil || token != json.Delim('{') {
+			return nil, fmt.Errorf("element %d is not an object", i+1)
+		}
+		row := make(map[string]string)
+		for dec.More() {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			column := token.(string)
+			var value interface{}
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			switch v := value.(type) {
+			case nil:
+				row[column] = ""
+			case string:
+				row[column] = v
+			case json.Number:
+				row[column] = canonize(v.String())
+				if !rnumber.MatchString(v.String()) {
+					row[column] = v.String()
+				}
+			case bool:
+				row[column] = "0"
+				if v {
+					row[column] = "1"
+				}
+			default:
+				return nil, fmt.Errorf("element %d: `%s` is not a string or a number", i+1, column)
+			}
+			if !containsString(table.Columns, column) {
+				table.Columns = append(table.Columns, column)
+			}
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,358 @@
+package gstore
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Memory is a GlobalStore in Go: local and global variables live in memory.
+// If the store is opened on a file, the globals are loaded from this file in ZWR format
+// and written back on Close. M code cannot be executed.
+type Memory struct {
+	mu    sync.Mutex
+	vars  map[string]*mnode // by name
+	file  string
+	dirty bool
+}
+
+// mnode is a node of a variable
+type mnode struct {
+	value   string
+	defined bool
+	keys    []string // subscripts of the children in M collation order
+	kids    map[string]*mnode
+}
+
+// NewMemory returns an empty Memory store
+func NewMemory() *Memory {
+	return &Memory{vars: make(map[string]*mnode)}
+}
+
+// Open returns a Memory store on a ZWR file: the file is created on Close if it does not exist.
+// "memory" (or "") gives a store without a file.
+func Open(file string) (*Memory, error) {
+	store := NewMemory()
+	if file == "" || file == "memory" {
+		return store, nil
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	store.file = file
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	_, err = Import(store, f)
+	store.dirty = false
+	return store, err
+}
+
+// Close writes the globals to the file of the store
+func (store *Memory) Close() error {
+	store.mu.Lock()
+	dirty := store.dirty && store.file != ""
+	store.mu.Unlock()
+	if !dirty {
+		return nil
+	}
+	tmp := store.file + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	for _, name := range store.Globals() {
+		_, err = Export(store, name, f, false)
+		if err != nil {
+			break
+		}
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	store.mu.Lock()
+	store.dirty = false
+	store.mu.Unlock()
+	return os.Rename(tmp, store.file)
+}
+
+// Globals returns the names of the globals in the store in M collation order
+func (store *Memory) Globals() []string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	names := make([]string, 0, len(store.vars))
+	for name := range store.vars {
+		if strings.HasPrefix(name, "^") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the canonical form of the reference: the subscripts should be literals
+func (store *Memory) Name(glvn string) string {
+	subs := QS(glvn)
+	if len(subs) < 2 {
+		return glvn
+	}
+	for i, sub := range subs[1:] {
+		switch {
+		case rnumber.MatchString(sub):
+			sub = canonize(sub)
+		case len(sub) > 1 && strings.HasPrefix(sub, `"`) && strings.HasSuffix(sub, `"`):
+			sub = MakeArg(MArgs([]string{"", sub})[1])
+		default:
+			return glvn
+		}
+		subs[i+1] = sub
+	}
+	return UnQS(subs)
+}
+
+// Exec cannot execute M code
+func (store *Memory) Exec(text string) error {
+	return errors.New("M code cannot be executed in a memory store")
+}
+
+// find returns the node of a reference (nil if it does not exist)
+func (store *Memory) find(subs []string) *mnode {
+	node := store.vars[subs[0]]
+	for _, sub := range subs[1:] {
+		if node == nil {
+			return nil
+		}
+		node = node.kids[sub]
+	}
+	return node
+}
+
+// Data returns $DATA
+func (store *Memory) Data(subs []string) (int, error) {
+	if err := check(subs, false); err != nil {
+		return -1, err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	node := store.find(subs)
+	if node == nil {
+		return 0, nil
+	}
+	d := 0
+	if node.defined {
+		d++
+	}
+	if len(node.keys) != 0 {
+		d += 10
+	}
+	return d, nil
+}
+
+// Get returns the value of a node
+func (store *Memory) Get(subs []string) (string, error) {
+	if err := check(subs, false); err != nil {
+		return "", err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	node := store.find(subs)
+	if node == nil || !node.defined {
+		return "", fmt.Errorf("`%s` is undefined", EUnQS(subs))
+	}
+	return node.value, nil
+}
+
+// Set sets the value of a node
+func (store *Memory) Set(subs []string, value string) error {
+	if err := check(subs, true); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	node := store.vars[subs[0]]
+	if node == nil {
+		node = new(mnode)
+		store.vars[subs[0]] = node
+	}
+	for _, sub := range subs[1:] {
+		kid := node.kids[sub]
+		if kid == nil {
+			kid = new(mnode)
+			if node.kids == nil {
+				node.kids = make(map[string]*mnode)
+			}
+			node.kids[sub] = kid
+			k := node.search(sub)
+			node.keys = append(node.keys, "")
+			copy(node.keys[k+1:], node.keys[k:])
+			node.keys[k] = sub
+		}
+		node = kid
+	}
+	node.value = value
+	node.defined = true
+	store.dirty = store.dirty || strings.HasPrefix(subs[0], "^")
+	return nil
+}
+
+// Kill removes a node (and with tree, its descendants): empty ancestors are removed too
+func (store *Memory) Kill(subs []string, tree bool) error {
+	if err := check(subs, false); err != nil {
+		return err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	path := []*mnode{store.vars[subs[0]]}
+	for _, sub := range subs[1:] {
+		if path[len(path)-1] == nil {
+			return nil
+		}
+		path = append(path, path[len(path)-1].kids[sub])
+	}
+	node := path[len(path)-1]
+	if node == nil {
+		return nil
+	}
+	store.dirty = store.dirty || strings.HasPrefix(subs[0], "^")
+	node.value = ""
+	node.defined = false
+	if tree {
+		node.keys = nil
+		node.kids = nil
+	}
+	for i := len(path) - 1; i >= 0; i-- {
+		node := path[i]
+		if node.defined || len(node.keys) != 0 {
+			break
+		}
+		if i == 0 {
+			delete(store.vars, subs[0])
+			break
+		}
+		parent := path[i-1]
+		sub := subs[i]
+		delete(parent.kids, sub)
+		k := parent.search(sub)
+		parent.keys = append(parent.keys[:k], parent.keys[k+1:]...)
+	}
+	return nil
+}
+
+// Order returns $ORDER: "" as the last subscript starts at the beginning (or the end)
+func (store *Memory) Order(subs []string, forward bool) (string, error) {
+	if len(subs) < 2 {
+		return "", errors.New("cannot determine next")
+	}
+	if err := check(subs[:len(subs)-1], false); err != nil {
+		return "", err
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	parent := store.find(subs[:len(subs)-1])
+	if parent == nil || len(parent.keys) == 0 {
+		return "", nil
+	}
+	last := subs[len(subs)-1]
+	switch {
+	case last == "" && forward:
+		return parent.keys[0], nil
+	case last == "":
+		return parent.keys[len(parent.keys)-1], nil
+	}
+	k := parent.search(last)
+	if forward {
+		if k < len(parent.keys) && parent.keys[k] == last {
+			k++
+		}
+		if k < len(parent.keys) {
+			return parent.keys[k], nil
+		}
+		return "", nil
+	}
+	if k > 0 {
+		return parent.keys[k-1], nil
+	}
+	return "", nil
+}
+
+// Query returns the subscripts of $QUERY: the next node with a value in M collation order
+func (store *Memory) Query(subs []string) ([]string, error) {
+	if len(subs) == 0 {
+		return nil, errors.New("invalid reference")
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	root := store.vars[subs[0]]
+	if root == nil {
+		return nil, nil
+	}
+	next := root.query(subs[1:])
+	if next == nil {
+		return nil, nil
+	}
+	return append([]string{subs[0]}, next...), nil
+}
+
+// query returns the relative path of the first descendant with a value after the path rest
+func (node *mnode) query(rest []string) []string {
+	k := 0
+	if len(rest) != 0 {
+		if rest[0] == "" {
+			rest = nil
+		} else {
+			k = node.search(rest[0])
+		}
+	}
+	for ; k < len(node.keys); k++ {
+		sub := node.keys[k]
+		kid := node.kids[sub]
+		if len(rest) != 0 && sub == rest[0] {
+			if next := kid.query(rest[1:]); next != nil {
+				return append([]string{sub}, next...)
+			}
+			continue
+		}
+		if kid.defined {
+			return []string{sub}
+		}
+		if next := kid.query(nil); next != nil {
+			return append([]string{sub}, next...)
+		}
+	}
+	return nil
+}
+
+// search returns the position of sub in the keys (or where it should be inserted)
+func (node *mnode) search(sub string) int {
+	return sort.Search(len(node.keys), func(i int) bool { return Collate(node.keys[i], sub) >= 0 })
+}
+
+// check validates a reference: with set, the subscripts cannot be empty
+func check(subs []string, set bool) error {
+	if len(subs) == 0 || strings.TrimPrefix(subs[0], "^") == "" {
+		return errors.New("invalid reference")
+	}
+	if !set {
+		return nil
+	}
+	for _, sub := range subs[1:] {
+		if sub == "" {
+			return fmt.Errorf("`%s` contains an empty subscript", EUnQS(subs))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package gstore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ZWRLiteral returns the M literal of a value as in ZWR notation:
+// control characters are written with `$C()`
+func ZWRLiteral(value string) string {
+	if Canonic(value) {
+		return value
+	}
+	if value == "" {
+		return `""`
+	}
+	parts := make([]string, 0)
+	text := ""
+	codes := make([]string, 0)
+	flush := func() {
+		if text != "" {
+			parts = append(parts, `"`+strings.ReplaceAll(text, `"`, `""`)+`"`)
+			text = ""
+		}
+		if len(codes) != 0 {
+			parts = append(parts, "$C("+strings.Join(codes, ",")+")")
+			codes = codes[:0]
+		}
+	}
+	for _, r := range value {
+		if r < 32 || r == 127 {
+			if text != "" {
+				flush()
+			}
+			codes = append(codes, strconv.Itoa(int(r)))
+			continue
+		}
+		if len(codes) != 0 {
+			flush()
+		}
+		text += string(r)
+	}
+	flush()
+	return strings.Join(parts, "_")
+}
+
+// ZWRLine returns a node in ZWR notation: `^BCAT(1,"isbn")="abc"`
+func ZWRLine(subs []string, value string) string {
+	ref := subs[0]
+	if len(subs) > 1 {
+		args := make([]string, len(subs)-1)
+		for i, sub := range subs[1:] {
+			args[i] = ZWRLiteral(sub)
+		}
+		ref += "(" + strings.Join(args, ",") + ")"
+	}
+	return ref + "=" + ZWRLiteral(value)
+}
+
+// ParseZWR is the inverse of ZWRLine
+func ParseZWR(line string) (subs []string, value string, err error) {
+	k := strings.IndexAny(line, "(=")
+	if k < 1 {
+		return nil, "", fmt.Errorf("`%s` is not in ZWR notation", line)
+	}
+	subs = []string{line[:k]}
+	rest := line[k:]
+	if rest[0] == '(' {
+		rest = rest[1:]
+		for {
+			var sub string
+			sub, rest, err = zwrValue(rest)
+			if err != nil {
+				return nil, "", fmt.Errorf("`%s` is not in ZWR notation: %s", line, err)
+			}
+			subs = append(subs, sub)
+			if strings.HasPrefix(rest, ",") {
+				rest = rest[1:]
+				continue
+			}
+			if strings.HasPrefix(rest, ")") {
+				rest = rest[1:]
+				break
+			}
+			return nil, "", fmt.Errorf("`%s` is not in ZWR notation", line)
+		}
+	}
+	if !strings.HasPrefix(rest, "=") {
+		return nil, "", fmt.Errorf("`%s` is not in ZWR notation", line)
+	}
+	value, rest, err = zwrValue(rest[1:])
+	if err == nil && rest != "" {
+		err = fmt.Errorf("unexpected `%s`", rest)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("`%s` is not in ZWR notation: %s", line, err)
+	}
+	return subs, value, nil
+}
+
+// zwrValue parses a literal: numbers, strings and `$C()` concatenated with `_`
+func zwrValue(text string) (value string, rest string, err error) {
+	rest = text
+	for {
+		switch {
+		case strings.HasPrefix(rest, `"`):
+			k := 1
+			for {
+				e := strings.IndexByte(rest[k:], '"')
+				if e < 0 {
+					return "", "", fmt.Errorf("unterminated string")
+				}
+				k += e + 1
+				if !strings.HasPrefix(rest[k:], `"`) {
+					break
+				}
+				k++
+			}
+			value += strings.ReplaceAll(rest[1:k-1], `""`, `"`)
+			rest = rest[k:]
+		case strings.HasPrefix(rest, "$"):
+			k := strings.IndexByte(rest, '(')
+			e := strings.IndexByte(rest, ')')
+			if k < 0 || e < k {
+				return "", "", fmt.Errorf("invalid function")
+			}
+			fn := strings.ToUpper(rest[1:k])
+			bytes := fn == "ZCH" || fn == "ZCHAR"
+			if !bytes && fn != "C" && fn != "CHAR" {
+				return "", "", fmt.Errorf("unknown function `$%s`", rest[1:k])
+			}
+			for _, code := range strings.Split(rest[k+1:e], ",") {
+				n, err := strconv.Atoi(strings.TrimSpace(code))
+				if err != nil || n < 0 {
+					return "", "", fmt.Errorf("invalid code `%s`", code)
+				}
+				if bytes {
+					value += string([]byte{byte(n)})
+				} else {
+					value += string(rune(n))
+				}
+			}
+			rest = rest[e+1:]
+		default:
+			k := strings.IndexAny(rest, ",)_")
+			if k < 0 {
+				k = len(rest)
+			}
+			number := rest[:k]
+			if !rnumber.MatchString(number) {
+				return "", "", fmt.Errorf("invalid literal `%s`", number)
+			}
+			value += canonize(number)
+			rest = rest[k:]
+		}
+		if !strings.HasPrefix(rest, "_") {
+			return value, rest, nil
+		}
+		rest = rest[1:]
+	}
+}
+
+// Export writes the nodes of glvn in ZWR format: with header, the file starts
+// with the 2 lines which are expected by `mupip load`.
+// The number of nodes is returned.
+func Export(store GlobalStore, glvn string, w io.Writer, header bool) (count int, err error) {
+	buf := bufio.NewWriter(w)
+	if header {
+		fmt.Fprintf(buf, "goyo EXTRACT\n%s ZWR\n", strings.ToUpper(time.Now().Format("02-Jan-2006  15:04:05")))
+	}
+	walk(store, glvn, "", func(subs []string, value string, e error) bool {
+		if subs == nil {
+			err = e
+			return false
+		}
+		count++
+		_, e = buf.WriteString(ZWRLine(subs, value) + "\n")
+		if e != nil {
+			err = e
+			return false
+		}
+		return true
+	})
+	if e := buf.Flush(); err == nil {
+		err = e
+	}
+	return count, err
+}
+
+// Import sets the nodes of a file in ZWR format: the header lines of `mupip extract` are skipped.
+// The number of nodes is returned.
+func Import(store GlobalStore, r io.Reader) (count int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineno := 0
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if lineno < 3 && !strings.Contains(line, "=") {
+			continue
+		}
+		subs, value, err := ParseZWR(line)
+		if err != nil {
+			return count, fmt.Errorf("line %d: %s", lineno, err)
+		}
+		err = store.Set(subs, value)
+		if err != nil {
+			return count, fmt.Errorf("line %d: %s", lineno, err)
+		}
+		count++
+	}
+	return count, scanner.Err()
+}
@@ -0,0 +1,92 @@
+package yottadb
+
+import (
+	"errors"
+
+	qgstore "brocade.be/goyo/lib/gstore"
+	"lang.yottadb.com/go/yottadb"
+)
+
+// Store is the GlobalStore on YottaDB
+type Store struct{}
+
+var _ qgstore.GlobalStore = Store{}
+
+func (Store) Data(subs []string) (int, error) {
+	if len(subs) == 0 {
+		return -1, errors.New("invalid reference")
+	}
+	d, err := yottadb.DataE(yottadb.NOTTP, nil, subs[0], subs[1:])
+	if err != nil {
+		return -1, err
+	}
+	return int(d), nil
+}
+
+func (Store) Get(subs []string) (string, error) {
+	if len(subs) == 0 {
+		return "", errors.New("invalid reference")
+	}
+	return yottadb.ValE(yottadb.NOTTP, nil, subs[0], subs[1:])
+}
+
+func (Store) Set(subs []string, value string) error {
+	if len(subs) == 0 {
+		return errors.New("invalid reference")
+	}
+	return yottadb.SetValE(yottadb.NOTTP, nil, value, subs[0], subs[1:])
+}
+
+func (Store) Kill(subs []string, tree bool) error {
+	if len(subs) == 0 {
+		return errors.New("cannot determine node")
+	}
+	kway := yottadb.YDB_DEL_TREE
+	if !tree {
+		kway = yottadb.YDB_DEL_NODE
+	}
+	return yottadb.DeleteE(yottadb.NOTTP, nil, kway, subs[0], subs[1:])
+}
+
+func (Store) Order(subs []string, forward bool) (string, error) {
+	if len(subs) < 2 {
+		return "", errors.New("cannot determine next")
+	}
+	var z string
+	var err error
+	if forward {
+		z, err = yottadb.SubNextE(yottadb.NOTTP, nil, subs[0], subs[1:])
+	} else {
+		z, err = yottadb.SubPrevE(yottadb.NOTTP, nil, subs[0], subs[1:])
+	}
+	if yottadb.ErrorCode(err) == yottadb.YDB_ERR_NODEEND {
+		return "", nil
+	}
+	return z, err
+}
+
+func (Store) Query(subs []string) ([]string, error) {
+	if len(subs) == 0 {
+		return nil, errors.New("invalid reference")
+	}
+	next, err := yottadb.NodeNextE(yottadb.NOTTP, nil, subs[0], subs[1:])
+	if yottadb.ErrorCode(err) == yottadb.YDB_ERR_NODEEND {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{subs[0]}, next...), nil
+}
+
+func (Store) Name(glvn string) string {
+	return N(glvn)
+}
+
+func (Store) Exec(text string) error {
+	return Execw(text)
+}
+
+func (Store) Close() error {
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"syscall"
 
+	qgstore "brocade.be/goyo/lib/gstore"
 	qutil "brocade.be/goyo/lib/util"
 	"lang.yottadb.com/go/yottadb"
 )
@@ -24,95 +25,14 @@ var Number4 = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
 var Number1 = regexp.MustCompile(`^[+-]?[0-9]+$`)
 var Number2 = regexp.MustCompile(`^[+-]?[0-9]+E[+]?[0-9]+$`)
 
+// QS splits a reference in the name and the subscripts (as M code)
 func QS(glvn string) (subs []string) {
-
-	glvn = strings.TrimSpace(glvn)
-	if glvn == "" {
-		return nil
-	}
-	k := strings.Index(glvn, "(")
-	if k == -1 {
-		subs = append(subs, glvn)
-		return
-	}
-	subs = append(subs, glvn[:k])
-	glvn = strings.TrimSuffix(glvn[k+1:], ")")
-	glvn = strings.TrimSpace(glvn)
-	glvn = strings.TrimSuffix(glvn, ",")
-
-	if glvn == "" {
-		return nil
-	}
-	level := 0
-	sub := ""
-	even := true
-	for _, r := range glvn {
-		if r < 32 || r > 127 {
-			sub += string(r)
-			continue
-		}
-		switch r {
-		case '"':
-			sub += string(r)
-			even = !even
-			continue
-		case '(':
-			sub += string(r)
-			if even {
-				level++
-			}
-			continue
-		case ')':
-			sub += string(r)
-			if even {
-				level--
-			}
-			continue
-		case ',':
-			if !even || level != 0 {
-				sub += string(r)
-				continue
-			}
-			subs = append(subs, sub)
-			level = 0
-			even = true
-			sub = ""
-			continue
-		default:
-			sub += string(r)
-			continue
-		}
-	}
-
-	if sub != "" {
-		if strings.Count(sub, `"`)%2 == 1 {
-			sub += `"`
-		}
-		if level > 0 {
-			sub += strings.Repeat(")", level)
-		}
-		subs = append(subs, sub)
-	}
-	for i, sub := range subs {
-		sub = strings.TrimSpace(sub)
-		if !strings.HasPrefix(sub, `"`) && strings.Contains(sub, "(") {
-			subsubs := QS(sub)
-			sub = UnQS(subsubs)
-		}
-		subs[i] = sub
-	}
-	return
+	return qgstore.QS(glvn)
 }
 
+// UnQS is the inverse of QS
 func UnQS(subs []string) (glvn string) {
-	switch len(subs) {
-	case 0:
-		return ""
-	case 1:
-		return subs[0]
-	default:
-		return subs[0] + `(` + strings.Join(subs[1:], ",") + `)`
-	}
+	return qgstore.UnQS(subs)
 }
 
 func N(glvn string) string {
@@ -124,20 +44,9 @@ func N(glvn string) string {
 	return r
 }
 
+// EUnQS builds a reference from the name and the values of the subscripts
 func EUnQS(subs []string) (glvn string) {
-	switch len(subs) {
-	case 0:
-		return ""
-	case 1:
-		return subs[0]
-	default:
-		args := make([]string, len(subs))
-		args[0] = subs[0]
-		for i := 1; i < len(subs); i++ {
-			args[i] = MakeArg(subs[i])
-		}
-		return UnQS(args)
-	}
+	return qgstore.EUnQS(subs)
 }
 
 func Glvn(ref string) (glvn string) {
@@ -297,11 +206,9 @@ func SetArg(arg string) (glvn string, value string, err error) {
 
 }
 
+// MakeArg makes an M literal of a value
 func MakeArg(s string) string {
-	if isNumber(s) {
-		return s
-	}
-	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	return qgstore.MakeArg(s)
 }
 
 // Next = $Next
@@ -340,53 +247,19 @@ func Prev(glvn string) (pglvn string, value string, err error) {
 	return EUnQS(subs), z, nil
 }
 
+// Right adds a subscript level
 func Right(glvn string) (rglvn string, err error) {
-	subs := MArgs(QS(glvn))
-	if len(subs) == 0 {
-		return glvn, errors.New("cannot determine right")
-	}
-	if len(subs) > 1 && subs[len(subs)-1] == "" {
-		return glvn, err
-	}
-	subs = append(subs, "")
-	return EUnQS(subs), nil
+	return qgstore.Right(glvn)
 }
 
+// Left removes a subscript level
 func Left(glvn string) (rglvn string, err error) {
-	subs := MArgs(QS(glvn))
-	if len(subs) < 2 {
-		return glvn, errors.New("cannot determine left")
-	}
-	subs = subs[:len(subs)-1]
-	return EUnQS(subs), nil
+	return qgstore.Left(glvn)
 }
 
+// MArgs removes the quotes of the string literals in the subscripts
 func MArgs(subs []string) []string {
-	if len(subs) == 0 {
-		return subs
-	}
-	argums := make([]string, len(subs))
-	for i := 0; i < len(subs); i++ {
-		sub := subs[i]
-		if i == 0 {
-			argums[i] = sub
-			continue
-		}
-		if !strings.HasPrefix(sub, `"`) {
-			argums[i] = sub
-			continue
-		}
-		if len(sub) < 2 {
-			argums[i] = sub
-			continue
-		}
-		if !strings.HasSuffix(sub, `"`) {
-			argums[i] = sub
-			continue
-		}
-		argums[i] = strings.ReplaceAll(sub[1:len(sub)-1], `""`, `"`)
-	}
-	return argums
+	return qgstore.MArgs(subs)
 }
 
 func Kill(glvn string, tree bool) (err error) {
@@ -542,192 +415,18 @@ func isliteral(term string) bool {
 	return false
 }
 
-func isNumber(term string) bool {
-	if Number1.MatchString(term) {
-		return true
-	}
-	if Number4.MatchString(term) {
-		return true
-	}
-	return false
-}
-
-type VarReport struct {
-	Gloref string
-	Value  string
-	Err    error
-}
+// VarReport is a node in ZWR notation
+type VarReport = qgstore.VarReport
 
-type SubReport struct {
-	Subs  []string
-	Value string
-	Err   error
-}
+// SubReport is a node as a list of subscripts
+type SubReport = qgstore.SubReport
 
+// ZWR sends the nodes of gloref in ZWR notation to report
 func ZWR(gloref string, report chan VarReport, needle string, forward bool) {
-	rex := new(regexp.Regexp)
-	var err error
-	if needle != "" {
-		rex, err = regexp.Compile(needle)
-		if err != nil {
-			rex = nil
-		}
-	}
-	gloref = N(gloref)
-	d, err := D(gloref)
-	if d == 0 {
-		err = fmt.Errorf("`%s` does not exist", gloref)
-	}
-	if err != nil {
-		report <- VarReport{
-			Gloref: "",
-			Value:  "",
-			Err:    err,
-		}
-		close(report)
-		return
-	}
-	if needle == "" && (d == 1 || d == 11) {
-		value, _ := G(gloref, true)
-		report <- VarReport{
-			Gloref: gloref,
-			Value:  value,
-			Err:    err,
-		}
-	}
-
-	last := strings.TrimRight(gloref, "(),")
-	for {
-		exec := "s %nExt=$Q(" + gloref + ")"
-		next, err := Calc(exec, "%nExt")
-
-		if !strings.HasPrefix(next, last) {
-			next = ""
-		}
-		if next != "" {
-			x := strings.SplitN(next, last, 2)[0]
-			if strings.IndexAny(x, "(),") == 0 {
-				next = ""
-			}
-
-		}
-		if err != nil || next == "" {
-			report <- VarReport{
-				Gloref: "",
-				Value:  "",
-				Err:    err,
-			}
-			close(report)
-			return
-		}
-		gloref = next
-		value, _ := G(gloref, true)
-		if needle != "" {
-			pair := gloref + "=" + value
-			found := strings.Contains(pair, needle)
-			if !found && rex != nil {
-				found = rex.FindStringIndex(pair) != nil
-			}
-			if !found {
-				continue
-			}
-		}
-
-		report <- VarReport{
-			Gloref: gloref,
-			Value:  value,
-			Err:    err,
-		}
-		if needle != "" {
-			close(report)
-			return
-		}
-	}
-
+	qgstore.ZWR(Store{}, gloref, report, needle, forward)
 }
 
+// CSV sends the nodes of gloref as a list of subscripts to report
 func CSV(gloref string, report chan SubReport, needle string, forward bool) {
-	rex := new(regexp.Regexp)
-	var err error
-	if needle != "" {
-		rex, err = regexp.Compile(needle)
-		if err != nil {
-			rex = nil
-		}
-	}
-	gloref = N(gloref)
-	d, err := D(gloref)
-	if d == 0 {
-		err = fmt.Errorf("`%s` does not exist", gloref)
-	}
-	if err != nil {
-		report <- SubReport{
-			Subs:  nil,
-			Value: "",
-			Err:   err,
-		}
-		close(report)
-		return
-	}
-	if needle == "" && (d == 1 || d == 11) {
-		subs := MArgs(QS(gloref))
-		value, _ := G(gloref, true)
-		report <- SubReport{
-			Subs:  subs,
-			Value: value,
-			Err:   err,
-		}
-	}
-
-	last := strings.TrimRight(gloref, "(),")
-	for {
-		exec := "s %nExt=$Q(" + gloref + ")"
-		next, err := Calc(exec, "%nExt")
-
-		if !strings.HasPrefix(next, last) {
-			next = ""
-		}
-		if next != "" {
-			x := strings.SplitN(next, last, 2)[0]
-			if strings.IndexAny(x, "(),") == 0 {
-				next = ""
-			}
-
-		}
-		if err != nil || next == "" {
-			subs := MArgs(QS(gloref))
-
-			report <- SubReport{
-				Subs:  subs,
-				Value: "",
-				Err:   err,
-			}
-			close(report)
-			return
-		}
-		gloref = next
-		value, _ := G(gloref, true)
-		if needle != "" {
-			pair := gloref + "=" + value
-			found := strings.Contains(pair, needle)
-			if !found && rex != nil {
-				found = rex.FindStringIndex(pair) != nil
-			}
-			if !found {
-				continue
-			}
-		}
-
-		subs := MArgs(QS(gloref))
-		report <- SubReport{
-			Subs:  subs,
-			Value: value,
-			Err:   err,
-		}
-		if needle != "" {
-			close(report)
-			return
-		}
-	}
-
+	qgstore.CSV(Store{}, gloref, report, needle, forward)
 }
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"log"
+	"net/http"
+
+	"brocade.be/base/registry"
+	"brocade.be/iiiftool/lib/server"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "IIIF image server",
+	Long: `Serve the IIIF archives over HTTP with the IIIF Image API 3.0
+	and the Presentation API manifests:
+
+	{prefix}/{digest}{file}/info.json
+	{prefix}/{digest}{file}/{region}/{size}/{rotation}/{quality}.{format}
+	{prefix}/{id}/manifest
+	{prefix}/{id}/canvas/{file}
+	{prefix}/{id}/sqlite
+
+	{id} is a IIIF digest or a IIIF identifier in the index database.
+	The images are read from the SQLite archives: JPEG 2000 files are decoded
+	with 'gm' (GraphicsMagick). Decoded images and tiles are kept in a cache.
+	The prefix is the registry value 'iiif-base-url'.`,
+	Args: cobra.NoArgs,
+	Example: `iiiftool serve
+iiiftool serve --addr=:8090 --cache=1024 --maxwidth=4000`,
+	RunE: serve,
+}
+
+// Faddr is the address of the IIIF server
+var Faddr string
+
+// Fbaseurl is the URL of the IIIF server in info.json
+var Fbaseurl string
+
+// Fcache is the size of the cache in MB
+var Fcache int
+
+// Fmaxwidth is the maximum width of an image
+var Fmaxwidth int
+
+// Fservequality is the JPEG quality of the served images
+var Fservequality int
+
+// Fservetile is the tile size in info.json
+var Fservetile int
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.PersistentFlags().StringVar(&Faddr, "addr", ":8080", "Address to listen on")
+	serveCmd.PersistentFlags().StringVar(&Fbaseurl, "baseurl", "", "URL of the server in info.json (default: derived from the request)")
+	serveCmd.PersistentFlags().IntVar(&Fcache, "cache", 512, "Size of the image and tile cache in MB")
+	serveCmd.PersistentFlags().IntVar(&Fmaxwidth, "maxwidth", 0, "Maximum width and height of an image (0: only a maximum area)")
+	serveCmd.PersistentFlags().IntVar(&Fservequality, "quality", 80, "JPEG quality")
+	serveCmd.PersistentFlags().IntVar(&Fservetile, "tile", 256, "Tile size in info.json")
+}
+
+func serve(cmd *cobra.Command, args []string) error {
+	prefix := registry.Registry["iiif-base-url"]
+	if prefix == "" {
+		prefix = "/iiif"
+	}
+	iiifserver := server.New(prefix, int64(Fcache)<<20)
+	iiifserver.BaseURL = Fbaseurl
+	iiifserver.Quality = Fservequality
+	iiifserver.Tile = Fservetile
+	iiifserver.MaxWidth = Fmaxwidth
+
+	log.Printf("iiiftool serving %s on %s", iiifserver.Prefix, Faddr)
+	err := http.ListenAndServe(Faddr, iiifserver)
+	if err != nil {
+		log.Fatalf("iiiftool ERROR: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cache is a LRU cache with a maximum size in bytes
+type cache struct {
+	mu    sync.Mutex
+	max   int64
+	size  int64
+	order *list.List
+	items map[string]*list.Element
+}
+
+type entry struct {
+	key   string
+	value interface{}
+	size  int64
+}
+
+func newCache(max int64) *cache {
+	return &cache{max: max, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get returns a value and marks it as recently used
+func (c *cache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*entry).value, true
+}
+
+// put adds a value and removes the least recently used values if the cache is too large.
+// Values larger than the cache are not stored.
+func (c *cache) put(key string, value interface{}, size int64) {
+	if size > c.max {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.size -= elem.Value.(*entry).size
+		c.order.Remove(elem)
+	}
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, size: size})
+	c.size += size
+	for c.size > c.max {
+		elem := c.order.Back()
+		e := elem.Value.(*entry)
+		c.order.Remove(elem)
+		delete(c.items, e.key)
+		c.size -= e.size
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Decode decodes an image from an archive: JPEG 2000 with `gm` (GraphicsMagick),
+// JPEG, PNG and GIF in Go
+func Decode(name string, data []byte) (*image.RGBA, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jp2", ".j2k", ".jpx":
+		cmd := exec.Command("gm", "convert", "jp2:-", "-depth", "8", "ppm:-")
+		cmd.Stdin = bytes.NewReader(data)
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %s: %v", name, err)
+		}
+		return readPPM(bytes.NewReader(out))
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %v", name, err)
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	return rgba, nil
+}
+
+// readPPM reads a binary PPM (P6) image with 8 bit samples
+func readPPM(r io.Reader) (*image.RGBA, error) {
+	br := bufio.NewReader(r)
+	header := make([]int, 0, 3)
+	magic := ""
+	for len(header) < 3 {
+		token, err := ppmToken(br)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PPM header: %v", err)
+		}
+		if magic == "" {
+			magic = token
+			if magic != "P6" {
+				return nil, fmt.Errorf("PPM magic `%s` is not supported", magic)
+			}
+			continue
+		}
+		n := 0
+		if _, err := fmt.Sscanf(token, "%d", &n); err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid PPM header: `%s`", token)
+		}
+		header = append(header, n)
+	}
+	width, height, maxval := header[0], header[1], header[2]
+	if maxval > 255 {
+		return nil, fmt.Errorf("PPM with 16 bit samples is not supported")
+	}
+	rgb := make([]byte, width*height*3)
+	if _, err := io.ReadFull(br, rgb); err != nil {
+		return nil, fmt.Errorf("PPM data is truncated: %v", err)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i, j := 0, 0; i < len(rgb); i, j = i+3, j+4 {
+		img.Pix[j] = uint8(int(rgb[i]) * 255 / maxval)
+		img.Pix[j+1] = uint8(int(rgb[i+1]) * 255 / maxval)
+		img.Pix[j+2] = uint8(int(rgb[i+2]) * 255 / maxval)
+		img.Pix[j+3] = 255
+	}
+	return img, nil
+}
+
+// ppmToken reads a token of a PPM header: the whitespace after the token is consumed
+func ppmToken(br *bufio.Reader) (string, error) {
+	token := make([]byte, 0, 8)
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case c == '#' && len(token) == 0:
+			if _, err := br.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if len(token) != 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, c)
+		}
+	}
+}
+
+// Transform applies a request to an image: region, size, mirroring, rotation and quality
+func Transform(src *image.RGBA, req ImageRequest) image.Image {
+	img := scale(src, req.Region, req.Width, req.Height)
+	if req.Mirror {
+		img = mirror(img)
+	}
+	for r := 0; r < req.Rotation; r += 90 {
+		img = rotate(img)
+	}
+	switch req.Quality {
+	case "gray":
+		return gray(img, false)
+	case "bitonal":
+		return gray(img, true)
+	}
+	return img
+}
+
+// scale scales a region of src to w x h: a pixel is the average of the pixels it covers
+func scale(src *image.RGBA, region image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	rw, rh := region.Dx(), region.Dy()
+	for y := 0; y < h; y++ {
+		y0 := region.Min.Y + y*rh/h
+		y1 := region.Min.Y + (y+1)*rh/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := region.Min.X + x*rw/w
+			x1 := region.Min.X + (x+1)*rw/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx, i = sx+1, i+4 {
+					sum[0] += int(src.Pix[i])
+					sum[1] += int(src.Pix[i+1])
+					sum[2] += int(src.Pix[i+2])
+					sum[3] += int(src.Pix[i+3])
+				}
+			}
+			n := (x1 - x0) * (y1 - y0)
+			j := dst.PixOffset(x, y)
+			for k := 0; k < 4; k++ {
+				dst.Pix[j+k] = uint8(sum[k] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// mirror flips an image horizontally
+func mirror(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := src.PixOffset(x, y)
+			copy(dst.Pix[dst.PixOffset(b.Max.X-1-(x-b.Min.X), y):], src.Pix[i:i+4])
+		}
+	}
+	return dst
+}
+
+// rotate rotates an image 90 degrees clockwise
+func rotate(src *image.RGBA) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			i := src.PixOffset(b.Min.X+x, b.Min.Y+y)
+			copy(dst.Pix[dst.PixOffset(b.Dy()-1-y, x):], src.Pix[i:i+4])
+		}
+	}
+	return dst
+}
+
+// gray converts an image to gray levels: with bitonal, to black and white
+func gray(src *image.RGBA, bitonal bool) *image.Gray {
+	b := src.Bounds()
+	dst := image.NewGray(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.GrayModel.Convert(src.RGBAAt(x, y)).(color.Gray)
+			if bitonal {
+				if c.Y < 128 {
+					c.Y = 0
+				} else {
+					c.Y = 255
+				}
+			}
+			dst.SetGray(x, y, c)
+		}
+	}
+	return dst
+}
+
+// Encode writes an image in a IIIF format: jpg, png or gif
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case "jpg":
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	case "png":
+		return png.Encode(w, img)
+	case "gif":
+		return gif.Encode(w, img, nil)
+	}
+	return fmt.Errorf("unsupported format `%s`", format)
+}
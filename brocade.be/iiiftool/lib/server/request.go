@@ -0,0 +1,311 @@
+package server
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// IIIF Image API 3.0: {identifier}/{region}/{size}/{rotation}/{quality}.{format}
+// See https://iiif.io/api/image/3.0/
+
+const imageContext = "http://iiif.io/api/image/3/context.json"
+const imageProfile = "http://iiif.io/api/image/3/level2.json"
+
+// MaxArea is the maximum number of pixels of an image (maxArea in info.json)
+const MaxArea = 100000000
+
+var qualities = map[string]bool{"default": true, "color": true, "gray": true, "bitonal": true}
+
+var formats = map[string]string{"jpg": "image/jpeg", "png": "image/png", "gif": "image/gif"}
+
+// Error is an error with a HTTP status code
+type Error struct {
+	Status int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func errorf(status int, format string, a ...interface{}) error {
+	return &Error{Status: status, Msg: fmt.Sprintf(format, a...)}
+}
+
+// ImageRequest is a parsed IIIF image request
+type ImageRequest struct {
+	Region   image.Rectangle // region in the full image
+	Width    int             // width of the scaled region
+	Height   int             // height of the scaled region
+	Mirror   bool            // mirror before rotation
+	Rotation int             // clockwise: 0, 90, 180 or 270
+	Quality  string          // color, gray or bitonal
+	Format   string          // jpg, png or gif
+}
+
+// Key returns a canonical form of the request: requests with the same key give the same image
+func (req ImageRequest) Key() string {
+	mirror := ""
+	if req.Mirror {
+		mirror = "!"
+	}
+	r := req.Region
+	return fmt.Sprintf("%d,%d,%d,%d/%d,%d/%s%d/%s.%s", r.Min.X, r.Min.Y, r.Dx(), r.Dy(), req.Width, req.Height, mirror, req.Rotation, req.Quality, req.Format)
+}
+
+// ParseImageRequest parses the parameters of an image request for an image of width x height.
+// maxWidth and maxHeight limit the size of the result (0: only MaxArea).
+func ParseImageRequest(width, height, maxWidth, maxHeight int, region, size, rotation, qualityformat string) (req ImageRequest, err error) {
+	req.Region, err = parseRegion(width, height, region)
+	if err != nil {
+		return req, err
+	}
+	if maxWidth > 0 && maxHeight <= 0 {
+		maxHeight = maxWidth
+	}
+	req.Width, req.Height, err = parseSize(req.Region.Dx(), req.Region.Dy(), maxWidth, maxHeight, size)
+	if err != nil {
+		return req, err
+	}
+	req.Mirror, req.Rotation, err = parseRotation(rotation)
+	if err != nil {
+		return req, err
+	}
+	k := strings.LastIndex(qualityformat, ".")
+	if k < 0 {
+		return req, errorf(http.StatusBadRequest, "format is missing in `%s`", qualityformat)
+	}
+	req.Quality, req.Format = qualityformat[:k], qualityformat[k+1:]
+	if !qualities[req.Quality] {
+		return req, errorf(http.StatusBadRequest, "invalid quality `%s`", req.Quality)
+	}
+	if req.Quality == "default" {
+		req.Quality = "color"
+	}
+	if formats[req.Format] == "" {
+		return req, errorf(http.StatusBadRequest, "unsupported format `%s`", req.Format)
+	}
+	return req, nil
+}
+
+// parseRegion: full, square, x,y,w,h or pct:x,y,w,h
+func parseRegion(width, height int, region string) (image.Rectangle, error) {
+	switch region {
+	case "full":
+		return image.Rect(0, 0, width, height), nil
+	case "square":
+		side := min(width, height)
+		x, y := (width-side)/2, (height-side)/2
+		return image.Rect(x, y, x+side, y+side), nil
+	}
+	pct := strings.HasPrefix(region, "pct:")
+	values, err := parseFloats(strings.TrimPrefix(region, "pct:"), 4, !pct)
+	if err != nil {
+		return image.Rectangle{}, errorf(http.StatusBadRequest, "invalid region `%s`", region)
+	}
+	if pct {
+		values[0] *= float64(width) / 100
+		values[1] *= float64(height) / 100
+		values[2] *= float64(width) / 100
+		values[3] *= float64(height) / 100
+	}
+	x, y := int(math.Round(values[0])), int(math.Round(values[1]))
+	w, h := int(math.Round(values[2])), int(math.Round(values[3]))
+	if w <= 0 || h <= 0 || x >= width || y >= height {
+		return image.Rectangle{}, errorf(http.StatusBadRequest, "region `%s` is outside the image", region)
+	}
+	return image.Rect(x, y, x+w, y+h).Intersect(image.Rect(0, 0, width, height)), nil
+}
+
+// parseSize: [^]max, [^]w,, [^],h, [^]pct:n, [^]w,h or [^]!w,h
+func parseSize(width, height, maxWidth, maxHeight int, size string) (w int, h int, err error) {
+	upscale := strings.HasPrefix(size, "^")
+	spec := strings.TrimPrefix(size, "^")
+	invalid := errorf(http.StatusBadRequest, "invalid size `%s`", size)
+	ratio := float64(height) / float64(width)
+	switch {
+	case spec == "max":
+		w, h = width, height
+		if upscale && maxWidth > 0 {
+			w, h = fit(width, height, maxWidth, maxHeight)
+		}
+		if maxWidth > 0 && (w > maxWidth || h > maxHeight) {
+			w, h = fit(width, height, maxWidth, maxHeight)
+		}
+		if area := float64(w) * float64(h); area > MaxArea {
+			scale := math.Sqrt(MaxArea / area)
+			w, h = atLeast(1, int(float64(w)*scale)), atLeast(1, int(float64(h)*scale))
+		}
+		return w, h, nil
+	case strings.HasPrefix(spec, "pct:"):
+		values, e := parseFloats(spec[4:], 1, false)
+		if e != nil || values[0] <= 0 {
+			return 0, 0, invalid
+		}
+		w = int(math.Round(float64(width) * values[0] / 100))
+		h = int(math.Round(float64(height) * values[0] / 100))
+	case strings.HasPrefix(spec, "!"):
+		values, e := parseFloats(spec[1:], 2, true)
+		if e != nil {
+			return 0, 0, invalid
+		}
+		w, h = int(values[0]), int(values[1])
+		if !upscale {
+			// not larger than the region
+			w, h = min(w, width), min(h, height)
+		}
+		w, h = fit(width, height, w, h)
+	case strings.HasPrefix(spec, ","):
+		values, e := parseFloats(spec[1:], 1, true)
+		if e != nil {
+			return 0, 0, invalid
+		}
+		h = int(values[0])
+		w = int(math.Round(float64(h) / ratio))
+	case strings.HasSuffix(spec, ","):
+		values, e := parseFloats(strings.TrimSuffix(spec, ","), 1, true)
+		if e != nil {
+			return 0, 0, invalid
+		}
+		w = int(values[0])
+		h = int(math.Round(float64(w) * ratio))
+	default:
+		values, e := parseFloats(spec, 2, true)
+		if e != nil {
+			return 0, 0, invalid
+		}
+		w, h = int(values[0]), int(values[1])
+	}
+	switch {
+	case w <= 0 || h <= 0:
+		return 0, 0, errorf(http.StatusBadRequest, "size `%s` is empty", size)
+	case !upscale && (w > width || h > height):
+		return 0, 0, errorf(http.StatusBadRequest, "size `%s` is larger than the region: use `^%s`", size, size)
+	case maxWidth > 0 && (w > maxWidth || h > maxHeight):
+		return 0, 0, errorf(http.StatusBadRequest, "size `%s` exceeds the maximum of %dx%d", size, maxWidth, maxHeight)
+	case float64(w)*float64(h) > MaxArea:
+		return 0, 0, errorf(http.StatusBadRequest, "size `%s` exceeds the maximum of %d pixels", size, MaxArea)
+	}
+	return w, h, nil
+}
+
+// fit returns the largest size with the aspect ratio of width x height within w x h
+func fit(width, height, w, h int) (int, int) {
+	scale := math.Min(float64(w)/float64(width), float64(h)/float64(height))
+	fw := int(math.Round(float64(width) * scale))
+	fh := int(math.Round(float64(height) * scale))
+	if fw < 1 {
+		fw = 1
+	}
+	if fh < 1 {
+		fh = 1
+	}
+	return fw, fh
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// atLeast returns the largest of n and b
+func atLeast(n, b int) int {
+	if b < n {
+		return n
+	}
+	return b
+}
+
+// parseRotation: [!]n: only multiples of 90 degrees are supported
+func parseRotation(rotation string) (mirror bool, degrees int, err error) {
+	mirror = strings.HasPrefix(rotation, "!")
+	values, err := parseFloats(strings.TrimPrefix(rotation, "!"), 1, false)
+	if err != nil || values[0] > 360 {
+		return false, 0, errorf(http.StatusBadRequest, "invalid rotation `%s`", rotation)
+	}
+	if math.Mod(values[0], 90) != 0 {
+		return false, 0, errorf(http.StatusNotImplemented, "rotation `%s` is not a multiple of 90", rotation)
+	}
+	return mirror, int(values[0]) % 360, nil
+}
+
+// parseFloats parses n comma separated non-negative numbers: with integer, only digits are allowed
+func parseFloats(s string, n int, integer bool) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("%d numbers expected", n)
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		if part == "" || strings.Trim(part, "0123456789.") != "" || (integer && strings.Contains(part, ".")) {
+			return nil, fmt.Errorf("`%s` is not a number", part)
+		}
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// Tile describes the tiles of an image in info.json
+type Tile struct {
+	Width        int   `json:"width"`
+	ScaleFactors []int `json:"scaleFactors"`
+}
+
+// InfoJSON is the image information document (info.json)
+type InfoJSON struct {
+	Context        string   `json:"@context"`
+	ID             string   `json:"id"`
+	Type           string   `json:"type"`
+	Protocol       string   `json:"protocol"`
+	Profile        string   `json:"profile"`
+	Width          int      `json:"width"`
+	Height         int      `json:"height"`
+	MaxWidth       int      `json:"maxWidth,omitempty"`
+	MaxHeight      int      `json:"maxHeight,omitempty"`
+	MaxArea        int      `json:"maxArea"`
+	Tiles          []Tile   `json:"tiles,omitempty"`
+	ExtraQualities []string `json:"extraQualities"`
+	ExtraFormats   []string `json:"extraFormats"`
+	ExtraFeatures  []string `json:"extraFeatures"`
+}
+
+// Info returns info.json for an image with tiles of tile x tile pixels
+func Info(id string, width, height, tile, maxWidth, maxHeight int) InfoJSON {
+	info := InfoJSON{
+		Context:        imageContext,
+		ID:             id,
+		Type:           "ImageService3",
+		Protocol:       "http://iiif.io/api/image",
+		Profile:        "level2",
+		Width:          width,
+		Height:         height,
+		MaxWidth:       maxWidth,
+		MaxHeight:      maxHeight,
+		MaxArea:        MaxArea,
+		ExtraQualities: []string{"gray", "bitonal"},
+		ExtraFormats:   []string{"gif"},
+		ExtraFeatures:  []string{"mirroring", "sizeUpscaling"},
+	}
+	if maxWidth > 0 && maxHeight <= 0 {
+		info.MaxHeight = maxWidth
+	}
+	if tile > 0 {
+		factors := []int{1}
+		for sf := 1; width/sf > tile || height/sf > tile; {
+			sf *= 2
+			factors = append(factors, sf)
+		}
+		info.Tiles = []Tile{{Width: tile, ScaleFactors: factors}}
+	}
+	return info
+}
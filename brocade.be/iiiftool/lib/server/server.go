@@ -0,0 +1,341 @@
+package server
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"image"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"brocade.be/base/fs"
+	"brocade.be/iiiftool/lib/iiif"
+	"brocade.be/iiiftool/lib/index"
+	"brocade.be/iiiftool/lib/sqlite"
+)
+
+const presentationContext = "http://iiif.io/api/presentation/3/context.json"
+
+// Server is a IIIF server on the SQLite archives:
+//
+//	{prefix}/{digest}{file}/info.json                                        image information
+//	{prefix}/{digest}{file}/{region}/{size}/{rotation}/{quality}.{format}    image
+//	{prefix}/{id}/manifest                                                   presentation manifest
+//	{prefix}/{id}/canvas/{file}                                              full image (as in the manifests)
+//	{prefix}/{id}/sqlite                                                     archive
+//
+// {digest}{file} is a harvest code: a42f98d253ea3dd019de07870862cbdc62d6077c00000001.jp2
+// {id} is a digest or a IIIF identifier in the index database: dg:ua:9
+type Server struct {
+	Prefix    string // path of the service: /iiif
+	BaseURL   string // URL of the service in info.json (default: derived from the request)
+	Tile      int    // width and height of the tiles in info.json
+	Quality   int    // JPEG quality
+	MaxWidth  int    // maximum width of an image (0: only MaxArea)
+	MaxHeight int    // maximum height of an image (0: MaxWidth)
+
+	cache   *cache
+	mu      sync.Mutex
+	loading map[string]*loading
+
+	lookup   func(id string) (string, error)
+	read     func(digest string, name string) ([]byte, error)
+	manifest func(digest string) (string, error)
+}
+
+// loading is an image which is being decoded
+type loading struct {
+	done chan struct{}
+	img  *image.RGBA
+	err  error
+}
+
+// New returns a server with a cache of cachesize bytes for the decoded images and the tiles
+func New(prefix string, cachesize int64) *Server {
+	return &Server{
+		Prefix:   "/" + strings.Trim(prefix, "/"),
+		Tile:     256,
+		Quality:  80,
+		cache:    newCache(cachesize),
+		loading:  make(map[string]*loading),
+		lookup:   index.LookupId,
+		read:     readSqlar,
+		manifest: readManifest,
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.fail(w, errorf(http.StatusMethodNotAllowed, "method %s is not allowed", r.Method))
+		return
+	}
+	base := strings.TrimSuffix(s.Prefix, "/") + "/"
+	path := r.URL.EscapedPath()
+	if !strings.HasPrefix(path, base) {
+		s.fail(w, errorf(http.StatusNotFound, "%s is not found", r.URL.Path))
+		return
+	}
+	parts := strings.Split(path[len(base):], "/")
+	for i, part := range parts {
+		unescaped, err := url.PathUnescape(part)
+		if err != nil {
+			s.fail(w, errorf(http.StatusBadRequest, "invalid path: %v", err))
+			return
+		}
+		parts[i] = unescaped
+	}
+
+	var err error
+	switch n := len(parts); {
+	case n == 1 && isImage(parts[0]):
+		http.Redirect(w, r, path+"/info.json", http.StatusSeeOther)
+	case n == 2 && parts[1] == "info.json":
+		err = s.serveInfo(w, r, parts[0])
+	case n == 2 && parts[1] == "manifest":
+		err = s.serveManifest(w, r, parts[0])
+	case n == 2 && parts[1] == "sqlite":
+		err = s.serveArchive(w, r, parts[0])
+	case n == 3 && parts[1] == "canvas":
+		var digest string
+		digest, err = s.digest(parts[0])
+		if err == nil {
+			name := parts[2]
+			if filepath.Ext(name) == "" {
+				name += ".jp2"
+			}
+			err = s.serveImage(w, r, digest+name, "full", "max", "0", "default.jpg")
+		}
+	case n == 5:
+		err = s.serveImage(w, r, parts[0], parts[1], parts[2], parts[3], parts[4])
+	default:
+		err = errorf(http.StatusNotFound, "%s is not found", r.URL.Path)
+	}
+	if err != nil {
+		s.fail(w, err)
+	}
+}
+
+// fail writes an error: errors without a status code are logged
+func (s *Server) fail(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var e *Error
+	if errors.As(err, &e) {
+		status = e.Status
+	} else {
+		log.Printf("iiiftool ERROR: %s", err)
+	}
+	http.Error(w, err.Error(), status)
+}
+
+func (s *Server) serveInfo(w http.ResponseWriter, r *http.Request, id string) error {
+	if !isImage(id) {
+		return errorf(http.StatusNotFound, "image `%s` is not found", id)
+	}
+	size, err := s.size(id[:40], id[40:])
+	if err != nil {
+		return err
+	}
+	info := Info(s.imageURL(r, id), size.X, size.Y, s.Tile, s.MaxWidth, s.MaxHeight)
+	blob, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Link", "<"+imageProfile+`>;rel="profile"`)
+	writeJSON(w, r, blob, imageContext)
+	return nil
+}
+
+func (s *Server) serveImage(w http.ResponseWriter, r *http.Request, id string, region string, size string, rotation string, qualityformat string) error {
+	if !isImage(id) {
+		return errorf(http.StatusNotFound, "image `%s` is not found", id)
+	}
+	digest, name := id[:40], id[40:]
+	full, err := s.size(digest, name)
+	if err != nil {
+		return err
+	}
+	req, err := ParseImageRequest(full.X, full.Y, s.MaxWidth, s.MaxHeight, region, size, rotation, qualityformat)
+	if err != nil {
+		return err
+	}
+	key := "tile:" + id + "/" + req.Key()
+	value, ok := s.cache.get(key)
+	if !ok {
+		src, err := s.image(digest, name)
+		if err != nil {
+			return err
+		}
+		buf := new(bytes.Buffer)
+		if err := Encode(buf, Transform(src, req), req.Format, s.Quality); err != nil {
+			return err
+		}
+		value = buf.Bytes()
+		s.cache.put(key, value, int64(buf.Len()))
+	}
+	w.Header().Set("Content-Type", formats[req.Format])
+	w.Header().Set("Link", "<"+imageProfile+`>;rel="profile"`)
+	w.Write(value.([]byte))
+	return nil
+}
+
+func (s *Server) serveManifest(w http.ResponseWriter, r *http.Request, id string) error {
+	digest, err := s.digest(id)
+	if err != nil {
+		return err
+	}
+	manifest, err := s.manifest(digest)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, r, []byte(manifest), presentationContext)
+	return nil
+}
+
+func (s *Server) serveArchive(w http.ResponseWriter, r *http.Request, id string) error {
+	digest, err := s.digest(id)
+	if err != nil {
+		return err
+	}
+	location := iiif.Digest2Location(digest)
+	if !fs.Exists(location) {
+		return errorf(http.StatusNotFound, "archive `%s` is not found", digest)
+	}
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	http.ServeFile(w, r, location)
+	return nil
+}
+
+// writeJSON writes a JSON document: as JSON-LD if the client asks for it
+func writeJSON(w http.ResponseWriter, r *http.Request, blob []byte, context string) {
+	if strings.Contains(r.Header.Get("Accept"), "application/ld+json") {
+		w.Header().Set("Content-Type", `application/ld+json;profile="`+context+`"`)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Write(blob)
+}
+
+// imageURL returns the id of an image in info.json
+func (s *Server) imageURL(r *http.Request, id string) string {
+	base := s.BaseURL
+	if base == "" {
+		scheme := "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		}
+		host := r.Host
+		if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+			host = forwarded
+		}
+		base = scheme + "://" + host + strings.TrimSuffix(s.Prefix, "/")
+	}
+	return strings.TrimSuffix(base, "/") + "/" + url.PathEscape(id)
+}
+
+// digest returns the digest of a digest or a IIIF identifier
+func (s *Server) digest(id string) (string, error) {
+	if isDigest(id) {
+		return id, nil
+	}
+	digest, err := s.lookup(id)
+	if err != nil {
+		return "", err
+	}
+	if digest == "" {
+		return "", errorf(http.StatusNotFound, "identifier `%s` is not found", id)
+	}
+	return digest, nil
+}
+
+// size returns the width and height of an image
+func (s *Server) size(digest string, name string) (image.Point, error) {
+	if value, ok := s.cache.get("size:" + digest + name); ok {
+		return value.(image.Point), nil
+	}
+	img, err := s.image(digest, name)
+	if err != nil {
+		return image.Point{}, err
+	}
+	return img.Bounds().Size(), nil
+}
+
+// image returns a decoded image: concurrent requests for the same image wait for
+// the first one
+func (s *Server) image(digest string, name string) (*image.RGBA, error) {
+	key := "image:" + digest + name
+	if value, ok := s.cache.get(key); ok {
+		return value.(*image.RGBA), nil
+	}
+	s.mu.Lock()
+	if l, ok := s.loading[key]; ok {
+		s.mu.Unlock()
+		<-l.done
+		return l.img, l.err
+	}
+	l := &loading{done: make(chan struct{})}
+	s.loading[key] = l
+	s.mu.Unlock()
+
+	data, err := s.read(digest, name)
+	if err == nil {
+		l.img, err = Decode(name, data)
+	}
+	l.err = err
+	if err == nil {
+		s.cache.put(key, l.img, int64(len(l.img.Pix)))
+		s.cache.put("size:"+digest+name, l.img.Bounds().Size(), 16)
+	}
+
+	s.mu.Lock()
+	delete(s.loading, key)
+	s.mu.Unlock()
+	close(l.done)
+	return l.img, l.err
+}
+
+// readSqlar reads a file from the archive of a digest
+func readSqlar(digest string, name string) ([]byte, error) {
+	if !fs.Exists(iiif.Digest2Location(digest)) {
+		return nil, errorf(http.StatusNotFound, "archive `%s` is not found", digest)
+	}
+	var sqlar sqlite.Sqlar
+	err := sqlite.Harvest(digest+name, &sqlar)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, errorf(http.StatusNotFound, "`%s` is not found in archive `%s`", name, digest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(sqlar.Reader)
+}
+
+// readManifest reads the manifest from the archive of a digest
+func readManifest(digest string) (string, error) {
+	if !fs.Exists(iiif.Digest2Location(digest)) {
+		return "", errorf(http.StatusNotFound, "archive `%s` is not found", digest)
+	}
+	return sqlite.Manifest(digest)
+}
+
+// isDigest checks for a SHA1 digest in lower case hexadecimal
+func isDigest(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// isImage checks for a harvest code: a digest followed by a file name
+func isImage(id string) bool {
+	return len(id) > 40 && isDigest(id[:40])
+}
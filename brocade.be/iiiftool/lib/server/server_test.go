@@ -0,0 +1,242 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"brocade.be/iiiftool/lib/util"
+)
+
+const testDigest = "5fd23dfc70d993af0da4e9b25c03766d45b66b32"
+
+// testImage is 400 x 300: red on the left half, blue on the right half
+func testImage() []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+	for y := 0; y < 300; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.RGBA{255, 0, 0, 255}
+			if x >= 200 {
+				c = color.RGBA{0, 0, 255, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	buf := new(bytes.Buffer)
+	png.Encode(buf, img)
+	return buf.Bytes()
+}
+
+func testServer() *Server {
+	s := New("/iiif", 1<<24)
+	s.BaseURL = "https://example.org/iiif"
+	data := testImage()
+	s.read = func(digest string, name string) ([]byte, error) {
+		if digest != testDigest || name != "00000001.png" {
+			return nil, errorf(http.StatusNotFound, "not found")
+		}
+		return data, nil
+	}
+	s.lookup = func(id string) (string, error) {
+		if id == "dg:ua:100" {
+			return testDigest, nil
+		}
+		return "", nil
+	}
+	s.manifest = func(digest string) (string, error) {
+		return `{"type":"Manifest"}`, nil
+	}
+	return s
+}
+
+func TestParseImageRequest(t *testing.T) {
+	tests := []struct {
+		params string
+		key    string
+	}{
+		{"full/max/0/default.jpg", "0,0,400,300/400,300/0/color.jpg"},
+		{"square/max/0/default.jpg", "50,0,300,300/300,300/0/color.jpg"},
+		{"100,50,200,100/max/90/gray.png", "100,50,200,100/200,100/90/gray.png"},
+		{"300,200,500,500/max/0/default.jpg", "300,200,100,100/100,100/0/color.jpg"},
+		{"pct:50,50,50,50/max/0/default.jpg", "200,150,200,150/200,150/0/color.jpg"},
+		{"full/200,/0/default.jpg", "0,0,400,300/200,150/0/color.jpg"},
+		{"full/,150/0/default.jpg", "0,0,400,300/200,150/0/color.jpg"},
+		{"full/pct:25/0/default.jpg", "0,0,400,300/100,75/0/color.jpg"},
+		{"full/100,100/0/default.jpg", "0,0,400,300/100,100/0/color.jpg"},
+		{"full/!100,100/0/default.jpg", "0,0,400,300/100,75/0/color.jpg"},
+		{"full/!1000,1000/0/default.jpg", "0,0,400,300/400,300/0/color.jpg"},
+		{"full/^!1000,1000/0/default.jpg", "0,0,400,300/1000,750/0/color.jpg"},
+		{"full/^800,/0/default.jpg", "0,0,400,300/800,600/0/color.jpg"},
+		{"full/max/!180/bitonal.gif", "0,0,400,300/400,300/!180/bitonal.gif"},
+		{"full/max/360/color.jpg", "0,0,400,300/400,300/0/color.jpg"},
+	}
+	for _, test := range tests {
+		p := strings.Split(test.params, "/")
+		req, err := ParseImageRequest(400, 300, 0, 0, p[0], p[1], p[2], p[3])
+		if err != nil {
+			t.Errorf("%s: %s", test.params, err)
+			continue
+		}
+		util.Check(req.Key(), test.key, t)
+	}
+
+	errs := []struct {
+		params string
+		status int
+	}{
+		{"0,0,0,10/max/0/default.jpg", http.StatusBadRequest},
+		{"400,0,10,10/max/0/default.jpg", http.StatusBadRequest},
+		{"a,b,c,d/max/0/default.jpg", http.StatusBadRequest},
+		{"full/800,/0/default.jpg", http.StatusBadRequest},
+		{"full/pct:200/0/default.jpg", http.StatusBadRequest},
+		{"full/0,/0/default.jpg", http.StatusBadRequest},
+		{"full/max/45/default.jpg", http.StatusNotImplemented},
+		{"full/max/400/default.jpg", http.StatusBadRequest},
+		{"full/max/0/sepia.jpg", http.StatusBadRequest},
+		{"full/max/0/default.webp", http.StatusBadRequest},
+		{"full/max/0/default", http.StatusBadRequest},
+		{"full/^100000,/0/default.jpg", http.StatusBadRequest},
+		{"full/^pct:5000/0/default.jpg", http.StatusBadRequest},
+		{"full/^!20000,20000/0/default.jpg", http.StatusBadRequest},
+	}
+	for _, test := range errs {
+		p := strings.Split(test.params, "/")
+		_, err := ParseImageRequest(400, 300, 0, 0, p[0], p[1], p[2], p[3])
+		var e *Error
+		if !errors.As(err, &e) || e.Status != test.status {
+			t.Errorf("%s: expected status %d, found %v", test.params, test.status, err)
+		}
+	}
+
+	req, err := ParseImageRequest(400, 300, 200, 0, "full", "max", "0", "default.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	util.Check(req.Key(), "0,0,400,300/200,150/0/color.jpg", t)
+	if _, err = ParseImageRequest(400, 300, 200, 0, "full", "300,", "0", "default.jpg"); err == nil {
+		t.Errorf("size above maxWidth should fail")
+	}
+	req, err = ParseImageRequest(40000, 30000, 0, 0, "full", "max", "0", "default.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Width*req.Height > MaxArea {
+		t.Errorf("max should not exceed MaxArea: %s", req.Key())
+	}
+	if info := Info("x", 40000, 30000, 256, 20000, 0); info.MaxArea != MaxArea || info.MaxWidth != 20000 || info.MaxHeight != 20000 {
+		t.Errorf("info.json should report the limits: %v", info)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	src, err := Decode("test.png", testImage())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := ParseImageRequest(400, 300, 0, 0, "full", "40,30", "90", "default.png")
+	img := Transform(src, req)
+	util.Check(fmt.Sprint(img.Bounds().Size()), "(30,40)", t)
+	// after a clockwise rotation, red is on top
+	util.Check(fmt.Sprint(img.At(15, 5)), "{255 0 0 255}", t)
+	util.Check(fmt.Sprint(img.At(15, 35)), "{0 0 255 255}", t)
+
+	req, _ = ParseImageRequest(400, 300, 0, 0, "full", "40,30", "!0", "gray.png")
+	img = Transform(src, req)
+	util.Check(fmt.Sprint(img.At(5, 5)), "{29}", t)
+	util.Check(fmt.Sprint(img.At(35, 5)), "{76}", t)
+
+	req, _ = ParseImageRequest(400, 300, 0, 0, "190,0,20,10", "2,1", "0", "bitonal.png")
+	img = Transform(src, req)
+	util.Check(fmt.Sprint(img.At(0, 0), img.At(1, 0)), "{0} {0}", t)
+}
+
+func TestReadPPM(t *testing.T) {
+	ppm := "P6\n# made by gm\n2 1\n255\n\xff\x00\x00\x00\x00\xff"
+	img, err := readPPM(strings.NewReader(ppm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	util.Check(fmt.Sprint(img.Bounds().Size(), img.At(0, 0), img.At(1, 0)), "(2,1) {255 0 0 255} {0 0 255 255}", t)
+	if _, err := readPPM(strings.NewReader("P6\n2 1\n255\n\xff")); err == nil {
+		t.Errorf("truncated PPM should fail")
+	}
+}
+
+func TestServer(t *testing.T) {
+	s := testServer()
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	id := testDigest + "00000001.png"
+
+	w := get("/iiif/" + id + "/info.json")
+	if w.Code != http.StatusOK {
+		t.Fatalf("info.json: %d %s", w.Code, w.Body.String())
+	}
+	var info InfoJSON
+	json.Unmarshal(w.Body.Bytes(), &info)
+	util.Check(fmt.Sprintf("%s %d %d %v", info.ID, info.Width, info.Height, info.Tiles), "https://example.org/iiif/"+id+" 400 300 [{256 [1 2]}]", t)
+	util.Check(w.Header().Get("Access-Control-Allow-Origin"), "*", t)
+
+	for i := 0; i < 2; i++ {
+		w = get("/iiif/" + id + "/full/100,/0/default.png")
+		if w.Code != http.StatusOK {
+			t.Fatalf("image: %d %s", w.Code, w.Body.String())
+		}
+		util.Check(w.Header().Get("Content-Type"), "image/png", t)
+		img, err := png.Decode(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		util.Check(fmt.Sprint(img.Bounds().Size()), "(100,75)", t)
+	}
+	if _, ok := s.cache.get("tile:" + id + "/0,0,400,300/100,75/0/color.png"); !ok {
+		t.Errorf("tile is not cached")
+	}
+
+	w = get("/iiif/" + id)
+	util.Check(fmt.Sprintf("%d %s", w.Code, w.Header().Get("Location")), "303 /iiif/"+id+"/info.json", t)
+	w = get("/iiif/dg:ua:100/manifest")
+	util.Check(fmt.Sprintf("%d %s", w.Code, w.Body.String()), `200 {"type":"Manifest"}`, t)
+	w = get("/iiif/dg:ua:100/canvas/00000001.png")
+	util.Check(fmt.Sprintf("%d %s", w.Code, w.Header().Get("Content-Type")), "200 image/jpeg", t)
+
+	for path, code := range map[string]int{
+		"/iiif/" + id + "/full/max/45/default.jpg":                      http.StatusNotImplemented,
+		"/iiif/" + id + "/full/max/0/default.tif":                       http.StatusBadRequest,
+		"/iiif/" + testDigest + "00000002.png/info.json":                http.StatusNotFound,
+		"/iiif/dg:ua:1/manifest":                                        http.StatusNotFound,
+		"/iiif/dg:ua:100/full/max/0/default.jpg":                        http.StatusNotFound,
+		"/other/" + id + "/info.json":                                   http.StatusNotFound,
+		"/iiif/" + id + "/full/max/0/default.jpg/extra/../../info.json": http.StatusNotFound,
+	} {
+		if w = get(path); w.Code != code {
+			t.Errorf("%s: expected %d, found %d", path, code, w.Code)
+		}
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := newCache(10)
+	c.put("a", 1, 4)
+	c.put("b", 2, 4)
+	c.get("a")
+	c.put("c", 3, 4)
+	_, a := c.get("a")
+	_, b := c.get("b")
+	_, cc := c.get("c")
+	util.Check(fmt.Sprint(a, b, cc, c.size), "true false true 8", t)
+	c.put("d", 4, 20)
+	_, d := c.get("d")
+	util.Check(fmt.Sprint(d), "false", t)
+}
@@ -269,7 +269,7 @@ func Harvest(harvestcode string, sqlar *Sqlar) error {
 	row := db.QueryRow("SELECT * FROM sqlar WHERE name =?", file)
 	err = ReadSqlarRow(row, sqlar)
 	if err != nil {
-		return fmt.Errorf("cannot read file contents from archive: %v", err)
+		return fmt.Errorf("cannot read file contents from archive: %w", err)
 	}
 
 	return nil
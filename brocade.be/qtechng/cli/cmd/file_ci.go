@@ -22,8 +22,10 @@ import (
 var fileCiCmd = &cobra.Command{
 	Use:   "ci",
 	Short: "Check in qtechng files",
-	Long:  `Stores local files in the qtechng repository` + Mfiles,
-	Args:  cobra.MinimumNArgs(0),
+	Long: `Stores local files in the qtechng repository.
+The files of a version are checked in as a whole:
+if one of them cannot be stored, none of them is.` + Mfiles,
+	Args: cobra.MinimumNArgs(0),
 	Example: `qtechng file ci application/bcawedit.m install.py cwd=../workspace
 qtechng file ci`,
 	RunE:   fileCi,
@@ -13,6 +13,7 @@ import (
 	qofile "brocade.be/qtechng/lib/file/ofile"
 	qobject "brocade.be/qtechng/lib/object"
 	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
 	qutil "brocade.be/qtechng/lib/util"
 	"github.com/spf13/cobra"
 )
@@ -20,14 +21,25 @@ import (
 var fileLintCmd = &cobra.Command{
 	Use:   "lint",
 	Short: "Lint a file",
-	Long:  `Command performs linting of one or more files, i.e. it checks their well-formedness`,
-	Args:  cobra.MinimumNArgs(0),
+	Long: `Command performs linting of one or more files, i.e. it checks their well-formedness
+
+With the '--format' flag, the result is rendered as:
+
+    - 'json': the default qtechng report
+    - 'sarif': a SARIF 2.1.0 log
+    - 'junit': JUnit XML, every file is a test case`,
+	Args: cobra.MinimumNArgs(0),
 	Example: `qtechng file lint cwd=../strings
 qtechng file lint --cwd=../strings --remote
 qtechng file lint mymfile.d
-qtechng file lint /stdlib/strings/mymfile.d --version=5.10`,
+qtechng file lint /stdlib/strings/mymfile.d --version=5.10
+qtechng file lint --cwd=../strings --format=junit --stdout=lint.xml`,
 	RunE:   fileLint,
-	PreRun: func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	PreRun: func(cmd *cobra.Command, args []string) {
+		if checkLintFormat(cmd, "file-lint-format") {
+			preSSH(cmd, nil)
+		}
+	},
 	Annotations: map[string]string{
 		"remote-allowed": "no",
 		"with-qtechtype": "BW",
@@ -42,6 +54,7 @@ func init() {
 	fileLintCmd.Flags().BoolVar(&Fforce, "force", false, "Lint even if the file is not in repository")
 	fileLintCmd.Flags().StringArrayVar(&Fqpattern, "qpattern", []string{}, "Posix glob pattern (multiple) on qpath")
 	fileLintCmd.Flags().StringVar(&Frefname, "refname", "", "Reference name instead of actual filename")
+	fileLintCmd.Flags().StringVar(&Flintformat, "format", "json", "Output format: json, sarif or junit")
 	fileCmd.AddCommand(fileLintCmd)
 }
 
@@ -54,8 +67,10 @@ func fileLint(cmd *cobra.Command, args []string) error {
 		return qerror.ErrorSlice(errlist)
 	}
 	files := make([]string, 0)
+	qpaths := make(map[string]string)
 	for _, plocfil := range plocfils {
 		files = append(files, plocfil.Place)
+		qpaths[plocfil.Place] = plocfil.QPath
 	}
 	if len(files) == 0 {
 		for _, arg := range args {
@@ -154,6 +169,22 @@ func fileLint(cmd *cobra.Command, args []string) error {
 	}
 	_, errorlist := qparallel.NMap(len(files), -1, lint)
 
+	if Flintformat == "sarif" || Flintformat == "junit" {
+		outcomes := make([]qsource.LintOutcome, len(files))
+		for n, fname := range files {
+			outcomes[n] = qsource.LintOutcome{
+				QPath: qpaths[fname],
+				File:  fname,
+			}
+			if errorlist[n] != nil {
+				outcomes[n].Errs = []error{errorlist[n]}
+			}
+			outcomes[n].Body, _ = os.ReadFile(fname)
+		}
+		Fmsg = lintFormat(outcomes)
+		return nil
+	}
+
 	Fmsg = qreport.Report(nil, errorlist, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
 	return nil
 }
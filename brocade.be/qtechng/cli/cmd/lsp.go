@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+
+	qlsp "brocade.be/qtechng/lib/lsp"
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Language server for qtechng files",
+	Long: `Command starts a Language Server Protocol server on stdin/stdout.
+Editors use this server for the qtechng file types (*.b, *.d, *.i, *.l, *.m, *.x, ...):
+
+    - diagnostics: the result of 'qtechng source lint' on the unsaved content
+    - go to definition: the definition of an object (m4_*, i4_*, l4_*, ...)
+    - hover: the synopsis and the parameters of a macro
+    - completion: object names
+    - formatting: the result of 'qtechng file format'
+
+Files are located in the repository with the '.qtechng' administration
+of their directory or with their place in the work directory.
+Files outside the repository are linted as objectfiles.`,
+	Args: cobra.NoArgs,
+	Example: `qtechng lsp
+qtechng lsp --warnings --version=0.00`,
+	RunE: lsp,
+	Annotations: map[string]string{
+		"remote-allowed": "no",
+		"with-qtechtype": "BWP",
+		"fill-version":   "yes",
+	},
+}
+
+func init() {
+	lspCmd.Flags().StringVar(&Fversion, "version", "", "Version to work with")
+	lspCmd.Flags().BoolVar(&Fwarnings, "warnings", false, "Include warnings in the diagnostics")
+	rootCmd.AddCommand(lspCmd)
+}
+
+func lsp(cmd *cobra.Command, args []string) error {
+	server := qlsp.New(Fversion, Fwarnings)
+	return server.Serve(os.Stdin, os.Stdout)
+}
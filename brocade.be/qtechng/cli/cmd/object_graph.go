@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	qobject "brocade.be/qtechng/lib/object"
+	qreport "brocade.be/qtechng/lib/report"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+	"github.com/spf13/cobra"
+)
+
+var objectGraphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the dependency graph of the objects",
+	Long: `This command exports the dependency graph of the objects in a version.
+The nodes are the objects and the sources, the edges are:
+
+    - 'defines': the source defines the object
+    - 'uses': the source or the object uses the object
+
+The graph can be restricted to:
+
+    - the objects of some types with the '--type' flag (e.g. 'm4', 'i4')
+    - a project with the '--project' flag: the sources of the project,
+      the objects defined in the project and their direct neighbours
+
+The output format is one of 'json' (default), 'dot' (Graphviz) and 'graphml'.`,
+	Args: cobra.NoArgs,
+	Example: `qtechng object graph --version=0.00 --format=dot --type=m4 > m4.dot
+qtechng object graph --project=/catalografie/application --format=graphml --stdout=cat.graphml`,
+	RunE:   objectGraph,
+	PreRun: func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+// Fgraphformat output format of the graph
+var Fgraphformat string
+
+// Fobjtype types of objects
+var Fobjtype []string
+
+func init() {
+	objectCmd.AddCommand(objectGraphCmd)
+	objectGraphCmd.Flags().StringVar(&Fgraphformat, "format", "json", "Output format: json, dot or graphml")
+	objectGraphCmd.Flags().StringArrayVar(&Fobjtype, "type", []string{}, "Type of the objects (e.g. m4, i4)")
+	objectGraphCmd.Flags().StringVar(&Fproject, "project", "", "Project to restrict the graph to")
+}
+
+func objectGraph(cmd *cobra.Command, args []string) error {
+	release, err := qserver.Release{}.New(Fversion, true)
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	project := ""
+	if Fproject != "" {
+		project = qutil.Canon(Fproject)
+	}
+	graph, err := qobject.GetGraph(release, project, Fobjtype)
+	if err != nil || Fgraphformat == "json" || Fgraphformat == "" {
+		Fmsg = qreport.Report(graph, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	switch Fgraphformat {
+	case "dot":
+		Fmsg = graph.DOT()
+	case "graphml":
+		Fmsg = graph.GraphML()
+	default:
+		err = fmt.Errorf("unknown format `%s`: should be json, dot or graphml", Fgraphformat)
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	}
+	return nil
+}
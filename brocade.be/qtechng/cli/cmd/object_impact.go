@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
+	"github.com/spf13/cobra"
+)
+
+var objectImpactCmd = &cobra.Command{
+	Use:   "impact",
+	Short: "Show the impact of a change of objects",
+	Long: `This command shows what is affected if the objects given as arguments change:
+
+    - the objects depending on them (directly or indirectly)
+    - the sources depending on them
+    - the M routines which have to be recompiled and reinstalled
+    - the number of these M routines per project
+
+Do not forget the appropriate prefix!`,
+	Args:    cobra.MinimumNArgs(1),
+	Example: `qtechng object impact m4_getCatIsbdTitles --version=0.00`,
+	RunE:    objectImpact,
+	PreRun:  func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+func init() {
+	objectCmd.AddCommand(objectImpactCmd)
+}
+
+func objectImpact(cmd *cobra.Command, args []string) error {
+	impact, err := qsource.ObjectImpact(Fversion, args...)
+	Fmsg = qreport.Report(impact, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
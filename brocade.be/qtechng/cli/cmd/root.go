@@ -95,6 +95,9 @@ var Fsmartcase bool
 // Fneedle needles to search for
 var Fneedle []string
 
+// Fexpr query expression on sources
+var Fexpr string
+
 // Fpattern patterns to select on
 var Fpattern []string
 
@@ -44,6 +44,7 @@ func init() {
 	sourceCmd.PersistentFlags().StringVar(&Fmtbefore, "mbefore", "", "Modified before")
 	sourceCmd.PersistentFlags().StringVar(&Fmtafter, "mafter", "", "Modified after")
 	sourceCmd.PersistentFlags().StringArrayVar(&Fneedle, "needle", []string{}, "Find substring")
+	sourceCmd.PersistentFlags().StringVar(&Fexpr, "expr", "", "Boolean query expression on sources")
 	sourceCmd.PersistentFlags().StringArrayVar(&Fqpattern, "qpattern", []string{}, "Posix glob pattern on qpaths")
 	sourceCmd.PersistentFlags().BoolVar(&Ffilesinproject, "neighbours", false, "Indicate if all files in project are selected")
 	sourceCmd.PersistentFlags().StringVar(&Fqdir, "qdir", "", "Qpath of a directory under a project")
@@ -75,4 +76,12 @@ Otherwise, on a 'B' machine, the value '0.00' is taken and on a 'W' workstation,
 the value of 'qtechng-version' is taken.
 
 The qpaths are calculated by the arguments.
-These lead to a list of qpaths which are filtered by a number of restrictions.`
+These lead to a list of qpaths which are filtered by a number of restrictions.
+
+The '--expr=...' flag adds a boolean expression on the sources:
+
+    nature:mfile AND mu:rphilips AND (contains:"^BCAT" OR object:m4_*) AND NOT qdir:/core/*
+
+Terms are 'field:value', combined with AND, OR, NOT and parentheses.
+Fields are: nature, cu, mu, ctbefore, ctafter, mtbefore, mtafter,
+contains, regexp, object, qpath, qdir and project.`
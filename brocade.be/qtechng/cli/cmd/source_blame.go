@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	qreport "brocade.be/qtechng/lib/report"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+	"github.com/spf13/cobra"
+)
+
+var sourceBlameCmd = &cobra.Command{
+	Use:   "blame qpath",
+	Short: "Show who changed the lines of a source",
+	Long: `This command shows, for every line of a source in the repository,
+the change which introduced the line: the commit, the author, the time and the batch id.
+Git version control should be enabled (registry value 'qtechng-git-enable').`,
+	Args:    cobra.ExactArgs(1),
+	Example: `qtechng source blame /catalografie/application/bcawedit.m --version=0.00`,
+	RunE:    sourceBlame,
+	PreRun:  func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+func init() {
+	sourceCmd.AddCommand(sourceBlameCmd)
+}
+
+func sourceBlame(cmd *cobra.Command, args []string) error {
+	qpath := qutil.Canon(args[0])
+	release, err := qserver.Release{}.New(Fversion, true)
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	lines, err := release.GitBlame(qpath)
+	Fmsg = qreport.Report(lines, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
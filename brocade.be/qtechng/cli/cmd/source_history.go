@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	qreport "brocade.be/qtechng/lib/report"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+	"github.com/spf13/cobra"
+)
+
+var sourceHistoryCmd = &cobra.Command{
+	Use:   "history qpath",
+	Short: "Show the history of a source",
+	Long: `This command shows the history of a source in the repository.
+Every check-in or delete of sources is recorded as a Git commit.
+
+For every change, the report contains:
+
+    - the commit
+    - the author
+    - the time of the change
+    - the batch id
+    - the sources changed together in the same batch
+
+The most recent change comes first.
+Git version control should be enabled (registry value 'qtechng-git-enable').`,
+	Args:    cobra.ExactArgs(1),
+	Example: `qtechng source history /catalografie/application/bcawedit.m --version=0.00`,
+	RunE:    sourceHistory,
+	PreRun:  func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+func init() {
+	sourceCmd.AddCommand(sourceHistoryCmd)
+}
+
+func sourceHistory(cmd *cobra.Command, args []string) error {
+	qpath := qutil.Canon(args[0])
+	release, err := qserver.Release{}.New(Fversion, true)
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	revisions, err := release.GitLog(qpath)
+	Fmsg = qreport.Report(revisions, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
@@ -15,13 +15,25 @@ import (
 )
 
 var sourceInstallCmd = &cobra.Command{
-	Use:     "install",
-	Short:   "Install sources in the repository",
-	Long:    `This command installs sources in the repository according to patterns, nature and contents`,
-	Args:    cobra.MinimumNArgs(0),
-	Example: `qtechng source install --qpattern=/application/*.m`,
-	RunE:    sourceInstall,
-	PreRun:  preSourceInstall,
+	Use:   "install",
+	Short: "Install sources in the repository",
+	Long: `This command installs sources in the repository according to patterns, nature and contents
+
+With the '--incremental' flag, the selected sources are considered to be changed:
+only the M routines, autofiles and widgets which are changed, or use an object
+defined in the changed sources, are installed.
+The install.py of a project is only executed if one of the sources of the project
+is changed.
+
+Check-ins use the incremental installation as well if the registry value
+'qtechng-install-incremental' is "1".
+
+With the '--plan' flag, the incremental installation is shown, but not executed.`,
+	Args: cobra.MinimumNArgs(0),
+	Example: `qtechng source install --qpattern=/application/*.m
+qtechng source install --incremental --qpattern=/catalografie/*.d --plan`,
+	RunE:   sourceInstall,
+	PreRun: preSourceInstall,
 	Annotations: map[string]string{
 		"remote-allowed": "no",
 		"with-qtechtype": "BWP",
@@ -29,9 +41,17 @@ var sourceInstallCmd = &cobra.Command{
 	},
 }
 
+// Fincremental installs only what is affected
+var Fincremental bool
+
+// Fplan shows the installation plan
+var Fplan bool
+
 func init() {
 	sourceInstallCmd.PersistentFlags().StringVar(&Frefname, "refname", "install", "Reference to the installation")
 	sourceInstallCmd.Flags().BoolVar(&Fwarnings, "warnings", false, "Include warnings")
+	sourceInstallCmd.Flags().BoolVar(&Fincremental, "incremental", false, "Install only what is affected by the sources")
+	sourceInstallCmd.Flags().BoolVar(&Fplan, "plan", false, "Show the incremental installation without executing it")
 	sourceCmd.AddCommand(sourceInstallCmd)
 }
 
@@ -60,10 +80,24 @@ func sourceInstall(cmd *cobra.Command, args []string) error {
 	query := &qsource.Query{
 		Release:  current,
 		Patterns: patterns,
+		Expr:     Fexpr,
 	}
 
 	sources := query.Run()
 
+	if Fincremental || Fplan {
+		qpaths := make([]string, len(sources))
+		for i, s := range sources {
+			qpaths[i] = s.String()
+		}
+		plan, err := qsource.PlanInstall(Frefname, current, qpaths, nil)
+		if err == nil && !Fplan {
+			err = plan.Execute(Fwarnings, nil, nil)
+		}
+		Fmsg = qreport.Report(plan, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+
 	err := qsource.Install(Frefname, sources, Fwarnings, nil, nil)
 
 	if err != nil {
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	qclient "brocade.be/qtechng/lib/client"
 	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
 	qutil "brocade.be/qtechng/lib/util"
 	"github.com/spf13/cobra"
 )
@@ -14,11 +17,28 @@ var sourceLintCmd = &cobra.Command{
 	Use:   "lint",
 	Short: "Lint sources in the repository",
 	Long: `This command lints sources in the repository according to patterns, nature and contents,
-, i.e. it checks their well-formedness`,
-	Args:    cobra.MinimumNArgs(0),
-	Example: `qtechng source lint --qpattern=/application/*.m`,
-	RunE:    sourceLint,
-	PreRun:  preSourceLint,
+, i.e. it checks their well-formedness
+
+The x4 constructions in the screens and formats of X files are checked against
+their schema: the verb, the number and the values of the parameters, the widget
+types they work in, their nesting and the labels of 'x4_if'.
+
+With the '--warnings' flag, M routines are also checked on their semantics:
+references to labels which do not exist (M021) or to routines outside the
+release (M022, disabled by default), NEW-ed variables which are not used (M023),
+unreachable code (M024) and the limits of the M engine in the registry value
+'m-engine': gtm, yottadb, cache, iris or ansi (M025).
+
+With the '--format' flag, the result is rendered as:
+
+    - 'json': the default qtechng report
+    - 'sarif': a SARIF 2.1.0 log
+    - 'junit': JUnit XML, every source is a test case`,
+	Args: cobra.MinimumNArgs(0),
+	Example: `qtechng source lint --qpattern=/application/*.m
+qtechng source lint --qpattern=/application/*.m --format=sarif --stdout=lint.sarif`,
+	RunE:   sourceLint,
+	PreRun: preSourceLint,
 	Annotations: map[string]string{
 		"remote-allowed": "no",
 		"with-qtechtype": "BWP",
@@ -29,10 +49,14 @@ var sourceLintCmd = &cobra.Command{
 var Fwarnings bool
 var Fonlybad bool
 
+// Flintformat output format of lint: json, sarif or junit
+var Flintformat string
+
 func init() {
 	sourceCmd.AddCommand(sourceLintCmd)
 	sourceLintCmd.Flags().BoolVar(&Fwarnings, "warnings", false, "Include warnings")
 	sourceLintCmd.Flags().BoolVar(&Fonlybad, "onlybad", false, "Report only failing sources")
+	sourceLintCmd.Flags().StringVar(&Flintformat, "format", "json", "Output format: json, sarif or junit")
 }
 
 func sourceLint(cmd *cobra.Command, args []string) error {
@@ -49,11 +73,51 @@ func sourceLint(cmd *cobra.Command, args []string) error {
 		result2 = append(result2, r)
 	}
 	qutil.EditList(Flist, Ftransported, qps)
+	if Flintformat == "sarif" || Flintformat == "junit" {
+		outcomes := make([]qsource.LintOutcome, len(result2))
+		for i, r := range result2 {
+			outcomes[i] = qsource.LintOutcome{
+				QPath: r.QPath,
+				File:  r.Path,
+				Errs:  qsource.ParseLintInfo(r.Info),
+			}
+			if r.Path != "" {
+				outcomes[i].Body, _ = os.ReadFile(r.Path)
+			}
+		}
+		Fmsg = lintFormat(outcomes)
+		return nil
+	}
 	Fmsg = qreport.Report(result2, nil, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
 	return nil
 }
 
+// lintFormat renders lint outcomes in the format of the '--format' flag: sarif or junit
+func lintFormat(outcomes []qsource.LintOutcome) string {
+	if Flintformat == "sarif" {
+		return string(qsource.LintSARIF(outcomes))
+	}
+	return string(qsource.LintJUnit(outcomes))
+}
+
+// checkLintFormat validates the --format flag of lint: for an unknown format,
+// the command reports an error instead of linting
+func checkLintFormat(cmd *cobra.Command, ref string) bool {
+	if Flintformat == "json" || Flintformat == "sarif" || Flintformat == "junit" {
+		return true
+	}
+	err := fmt.Errorf("unknown format `%s`: should be json, sarif or junit", Flintformat)
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", ref)
+		return nil
+	}
+	return false
+}
+
 func preSourceLint(cmd *cobra.Command, args []string) {
+	if !checkLintFormat(cmd, "source-lint-format") {
+		return
+	}
 	if !Ftransported {
 		var err error
 		Fcargo, err = fetchData(args, Ffilesinproject, nil, false)
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
+	"github.com/spf13/cobra"
+)
+
+var sourceLintrulesCmd = &cobra.Command{
+	Use:   "lintrules",
+	Short: "List the lint rules",
+	Long: `This command lists the rules applied by 'qtechng source lint'.
+Every rule has a stable ID, a severity and a message.
+
+Rules can be enabled or disabled per project in brocade.json
+with 'lintenable' and 'lintdisable' (wildcards are allowed):
+
+    {"lintdisable": ["M011"], "lintenable": ["M013"]}
+
+Disabled rules are only applied in projects which enable them.
+
+Findings are suppressed in the source with a comment:
+
+    - 'qtech:nolint M012' suppresses M012 on the same line
+    - 'qtech:nolint-file M012' suppresses M012 in the whole source
+    - without IDs, all rules are suppressed`,
+	Args:    cobra.NoArgs,
+	Example: `qtechng source lintrules`,
+	RunE:    sourceLintrules,
+	Annotations: map[string]string{
+		"remote-allowed": "no",
+		"with-qtechtype": "BWP",
+	},
+}
+
+func init() {
+	sourceCmd.AddCommand(sourceLintrulesCmd)
+}
+
+func sourceLintrules(cmd *cobra.Command, args []string) error {
+	type rule struct {
+		ID       string `json:"id"`
+		Severity string `json:"severity"`
+		Message  string `json:"message"`
+		Disabled bool   `json:"disabled"`
+	}
+	rules := make([]rule, 0)
+	for _, r := range qsource.LintRules() {
+		rules = append(rules, rule{r.ID, r.Severity, r.Message, r.Disabled})
+	}
+	Fmsg = qreport.Report(rules, nil, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
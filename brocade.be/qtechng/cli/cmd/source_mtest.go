@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
+	"github.com/spf13/cobra"
+)
+
+var sourceTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run M unit tests",
+	Long: `This command runs the unit tests of M routines in the repository.
+
+A test routine is an M file with a basename starting with 't' ('t*.m').
+Every label starting with 'test' and without formal parameters is a test,
+e.g. 'testSign'. A test uses the assertions of the support routine 'qtechtst':
+
+    - d eq^qtechtst(actual,expected,message)
+    - d true^qtechtst(condition,message)
+    - d fail^qtechtst(message)
+
+A test passes if all assertions succeed and no M error is raised.
+
+The arguments are projects (all test routines in the project are run) or
+qpaths of test routines.
+
+The test routines and the other M files of their projects are resolved and
+installed in a scratch UCI: the registry value 'm-test-db' is the directory of
+the M database, 'm-test-rou-dir' is the routine directory.
+
+With the '--format' flag, the result is rendered as:
+
+    - 'json': the default qtechng report
+    - 'junit': JUnit XML, every test routine is a test suite`,
+	Args: cobra.MinimumNArgs(1),
+	Example: `qtechng source test /catalografie/application
+qtechng source test /catalografie/application/tsign.m --format=junit --stdout=tests.xml`,
+	RunE:   sourceTest,
+	PreRun: func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+// Ftestformat output format of the tests: json or junit
+var Ftestformat string
+
+func init() {
+	sourceTestCmd.Flags().StringVar(&Ftestformat, "format", "json", "Output format: json or junit")
+	sourceCmd.AddCommand(sourceTestCmd)
+}
+
+func sourceTest(cmd *cobra.Command, args []string) error {
+	if Ftestformat != "json" && Ftestformat != "junit" {
+		err := fmt.Errorf("unknown format `%s`: should be json or junit", Ftestformat)
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	qpaths, err := qsource.MTestFind(Fversion, args)
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	results, err := qsource.MTestRun(Fversion, qpaths)
+	if Ftestformat == "junit" && err == nil {
+		Fmsg = string(qsource.MTestJUnit(results))
+		return nil
+	}
+	Fmsg = qreport.Report(results, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
+	"github.com/spf13/cobra"
+)
+
+var sourcePreviewCmd = &cobra.Command{
+	Use:   "preview",
+	Short: "Preview the screens and formats of an X file",
+	Long: `This command renders the screens and formats of an X file as a static HTML page.
+
+The text widgets, i4/m4/r4 constructions and lgcodes are resolved as for the installation.
+The lgcodes are shown in the language of the '--lg' flag: a code (E), a key (eng)
+or a BCP-47 tag (en).
+
+The x4 constructions are replaced by a mock:
+
+    - variables and parameters show their name between brackets: '[FDid]'
+    - 'x4_format' shows the format if it is in the same file
+    - 'x4_select' shows its first alternative
+    - 'x4_if', 'x4_exec' and the lookups become HTML comments
+
+The page does not contain the stylesheets of the application.`,
+	Args: cobra.ExactArgs(1),
+	Example: `qtechng source preview /catalografie/application/bcawedit.x
+qtechng source preview /catalografie/application/bcawedit.x --lg=N --stdout=bcawedit.html`,
+	RunE:   sourcePreview,
+	PreRun: func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+// Fpreviewlg language of the lgcodes in the preview
+var Fpreviewlg string
+
+func init() {
+	sourcePreviewCmd.Flags().StringVar(&Fpreviewlg, "lg", "E", "Language of the lgcodes")
+	sourceCmd.AddCommand(sourcePreviewCmd)
+}
+
+func sourcePreview(cmd *cobra.Command, args []string) error {
+	source, err := qsource.Source{}.New(Fversion, args[0], true)
+	var preview []byte
+	if err == nil {
+		preview, err = source.XFilePreview(Fpreviewlg)
+	}
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	Fmsg = string(preview)
+	return nil
+}
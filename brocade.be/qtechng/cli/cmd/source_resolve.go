@@ -1,24 +1,43 @@
 package cmd
 
 import (
+	"encoding/json"
 	"log"
 	"strings"
 
 	qclient "brocade.be/qtechng/lib/client"
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
 	"github.com/spf13/cobra"
 )
 
 //Frilm r4/i4/l4/m4 substitutie
 var Frilm string = ""
 
+// Fexplain shows the source map of the resolved sources
+var Fexplain bool
+
+// Fexplainformat output format of the source map
+var Fexplainformat string
+
 var sourceResolveCmd = &cobra.Command{
-	Use:     "resolve",
-	Short:   "Resolve a sourcefile",
-	Long:    `This command resolves the i4/l4/m4/r4 constructions in sources`,
-	Args:    cobra.MinimumNArgs(0),
-	Example: `qtechng source resolves --qpattern=/catalografie/application/bcawedit.m`,
-	RunE:    sourceResolve,
-	PreRun:  preSourceResolve,
+	Use:   "resolve",
+	Short: "Resolve a sourcefile",
+	Long: `This command resolves the i4/l4/m4/r4 constructions in sources
+
+With the '--explain' flag, the command shows the source map of the result:
+for every line of the result, the line in the source and the chains of
+m4/i4/l4 expansions which produced it. The guards of the macros are shown
+with their value in the environment of the source and the branch which is taken.
+
+The source map is rendered as annotated text ('--format=text')
+or as JSON ('--format=json').`,
+	Args: cobra.MinimumNArgs(0),
+	Example: `qtechng source resolve --qpattern=/catalografie/application/bcawedit.m
+qtechng source resolve --qpattern=/catalografie/application/bcawedit.m --explain
+qtechng source resolve --qpattern=/catalografie/application/bcawedit.m --explain --format=json`,
+	RunE:   sourceResolve,
+	PreRun: preSourceResolve,
 	Annotations: map[string]string{
 		"remote-allowed": "no",
 		"with-qtechtype": "BWP",
@@ -28,11 +47,32 @@ var sourceResolveCmd = &cobra.Command{
 
 func init() {
 	sourceResolveCmd.PersistentFlags().StringVar(&Frilm, "rilm", "", "specify the substitutions")
+	sourceResolveCmd.Flags().BoolVar(&Fexplain, "explain", false, "Show the source map of the result")
+	sourceResolveCmd.Flags().StringVar(&Fexplainformat, "format", "text", "Format of the source map: text or json")
 	sourceCmd.AddCommand(sourceResolveCmd)
 }
 
 func sourceResolve(cmd *cobra.Command, args []string) error {
-	Fmsg = string(Fcargo.Data)
+	if !Fexplain {
+		Fmsg = string(Fcargo.Data)
+		return nil
+	}
+	smaps := make([]*qsource.ResolveMap, 0)
+	if len(Fcargo.Data) != 0 {
+		err := json.Unmarshal(Fcargo.Data, &smaps)
+		if err != nil {
+			return err
+		}
+	}
+	if Fexplainformat == "json" || len(Fcargo.Error) != 0 {
+		Fmsg = qreport.Report(smaps, Fcargo.Error, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	texts := make([]string, len(smaps))
+	for i, smap := range smaps {
+		texts[i] = smap.Text()
+	}
+	Fmsg = strings.Join(texts, "\n")
 	return nil
 }
 
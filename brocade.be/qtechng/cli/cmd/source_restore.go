@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
+	"github.com/spf13/cobra"
+)
+
+var sourceRestoreCmd = &cobra.Command{
+	Use:   "restore qpath",
+	Short: "Restore a source from its history",
+	Long: `This command restores a source in the repository to its state at a given moment.
+The moment is specified with the '--at' flag:
+
+    - a batch id (as shown by 'qtechng source history')
+    - a Git commit
+    - a timestamp: the source is restored as it was at that time
+
+A batch id which matches more than one change is rejected: use the Git commit instead.
+
+The content is taken from the history of the version:
+the restore is recorded as a modification by the current user,
+with a batch id 'restore-<commit>-<timestamp>' which refers to the restored commit.
+The source is checked in again, so that the Brocade objects and their links are regenerated.
+If the source did not exist at that moment, it is deleted.
+
+With the '--dry' flag, nothing is changed:
+the differences between the current and the restored content are shown.
+
+Git version control should be enabled (registry value 'qtechng-git-enable').`,
+	Args: cobra.ExactArgs(1),
+	Example: `qtechng source restore /catalografie/application/bcawedit.m --at=2021-10-18T14:00 --version=0.00
+qtechng source restore /catalografie/application/bcawedit.m --at=install-2021-10-18T14.01.02.123456789.02.00-123456 --dry`,
+	RunE:   sourceRestore,
+	PreRun: func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+// Fat moment to restore to
+var Fat string
+
+func init() {
+	sourceRestoreCmd.Flags().StringVar(&Fat, "at", "", "Batch id, commit or timestamp to restore to")
+	sourceRestoreCmd.Flags().BoolVar(&Fdry, "dry", false, "Shows the differences without restoring")
+	sourceCmd.AddCommand(sourceRestoreCmd)
+}
+
+func sourceRestore(cmd *cobra.Command, args []string) error {
+	if Fat == "" {
+		Fmsg = qreport.Report(nil, errors.New("use the '--at' flag to specify the moment to restore to"), Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	restoration, err := qsource.Restore(Fversion, args[0], Fat, FUID, Fdry)
+	if err == nil && Fdry && restoration != nil && restoration.Diff != "" {
+		Fmsg = restoration.Diff
+		return nil
+	}
+	Fmsg = qreport.Report(restoration, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"strings"
+
+	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
+	"github.com/spf13/cobra"
+)
+
+var sourceXrefCmd = &cobra.Command{
+	Use:   "xref",
+	Short: "Cross-reference M routines and globals",
+	Long: `This command queries the cross-reference database of the M routines in a version.
+
+The database is built from the resolved M code. For every M file, it contains:
+
+    - the labels which are defined
+    - the call sites: DO, GOTO, JOB and extrinsic functions
+    - the references to globals: read, set or kill
+    - the lgcodes, macros, includes and text substitutions which are used
+
+The database is updated before every query: the entries of changed M files
+are rebuilt. Changes in macros are only seen with the '--reset' flag,
+which rebuilds the database completely.
+
+Without flags, the entries of the M files in the arguments (qpaths or routine
+names) are shown. The queries are:
+
+    - '--callers=label^routine': who calls the label ('^routine': any label in the routine)
+    - '--global=^G': who uses the global. With subscripts ('^G("isbn"'), the
+      references starting with it are shown. The '--mode' flag restricts to
+      'read', 'set' or 'kill'
+    - '--unused': labels which are never called (restricted with '--project').
+      Labels reached by indirection or with an offset are not detected`,
+	Args: cobra.ArbitraryArgs,
+	Example: `qtechng source xref /catalografie/application/bcawedit.m
+qtechng source xref --callers=%Sign^sign
+qtechng source xref --global='^BCAT("isbn"' --mode=set
+qtechng source xref --unused --project=/catalografie/application
+qtechng source xref --reset`,
+	RunE:   sourceXref,
+	PreRun: func(cmd *cobra.Command, args []string) { preSSH(cmd, nil) },
+	Annotations: map[string]string{
+		"remote-allowed":    "yes",
+		"always-remote-onW": "yes",
+		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
+	},
+}
+
+// Fxrefreset rebuilds the cross-reference database completely
+var Fxrefreset bool
+
+// Fcallers label^routine to find the callers of
+var Fcallers string
+
+// Fglobal global to find the references to
+var Fglobal string
+
+// Fglobalmode mode of the references to the global: read, set or kill
+var Fglobalmode string
+
+// Funused finds the labels which are never called
+var Funused bool
+
+func init() {
+	sourceXrefCmd.Flags().BoolVar(&Fxrefreset, "reset", false, "Rebuild the cross-reference database")
+	sourceXrefCmd.Flags().StringVar(&Fcallers, "callers", "", "Find the callers of label^routine")
+	sourceXrefCmd.Flags().StringVar(&Fglobal, "global", "", "Find the references to a global")
+	sourceXrefCmd.Flags().StringVar(&Fglobalmode, "mode", "", "Mode of the references to the global: read, set or kill")
+	sourceXrefCmd.Flags().BoolVar(&Funused, "unused", false, "Find the labels which are never called")
+	sourceXrefCmd.Flags().StringVar(&Fproject, "project", "", "Project to restrict the unused labels to")
+	sourceCmd.AddCommand(sourceXrefCmd)
+}
+
+func sourceXref(cmd *cobra.Command, args []string) error {
+	xref, err := qsource.BuildXref(Fversion, Fxrefreset)
+	if xref == nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	var result interface{}
+	switch {
+	case Fcallers != "":
+		result = xref.Callers(Fcallers)
+	case Fglobal != "":
+		result = xref.GlobalUsers(Fglobal, Fglobalmode)
+	case Funused:
+		result = xref.Unused(Fproject)
+	case len(args) != 0:
+		entries := make([]*qsource.XrefRoutine, 0)
+		for _, arg := range args {
+			for qpath, entry := range xref.Routines {
+				if qpath == arg || entry.Routine == strings.TrimPrefix(arg, "^") {
+					entries = append(entries, entry)
+				}
+			}
+		}
+		result = entries
+	default:
+		result = map[string]int{"routines": len(xref.Routines)}
+	}
+	Fmsg = qreport.Report(result, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -15,6 +17,7 @@ import (
 	qregistry "brocade.be/base/registry"
 	qreport "brocade.be/qtechng/lib/report"
 	qserver "brocade.be/qtechng/lib/server"
+	qtext "brocade.be/qtechng/lib/text"
 	qutil "brocade.be/qtechng/lib/util"
 	"github.com/spf13/cobra"
 )
@@ -25,17 +28,28 @@ var textExportCmd = &cobra.Command{
 	Long: `This command exports *lgcodes* (terms which can be translated).
 All lgcodes are checked and, if appropriate, are written to a CSV file.
 
-The arguments are the languages which should be exported.
+The arguments are the languages which should be exported: a key ("spa"),
+a code ("S") or a BCP-47 tag ("es") of a language (see the registry value
+'lgcode-languages').
 If no arguments are given, the languages "eng", "fre", "dut" are included.
 
 The flag '--emptyonly' selects only those lgcodes from which a translation is
 missing.
 
+With '--format=xliff' (XLIFF 2.0) or '--format=po' (gettext PO), the lgcodes
+in the L-files are exported for external translators. There is exactly one
+argument: the target language. The source language is given by '--lgsource'
+(default "dut"). The lgcodes are restricted to a project with '--project'.
+Nature, alias and the place of the lgcode (qpath and line) are added as notes
+(XLIFF) or comments (PO). The file is imported with 'qtechng text import'.
+
 The end result is a file which is copied to the 'download' subdirectory of
 'qtechng-work-dir'.
 `,
-	Args:    cobra.MaximumNArgs(0),
-	Example: `qtechng text export`,
+	Args: cobra.ArbitraryArgs,
+	Example: `qtechng text export
+qtechng text export fre --format=xliff --project=/catalografie/application
+qtechng text export en --format=po --lgsource=nl --emptyonly`,
 
 	RunE: textExport,
 	PreRun: func(cmd *cobra.Command, args []string) {
@@ -51,8 +65,14 @@ The end result is a file which is copied to the 'download' subdirectory of
 
 var Femptyonly bool
 
+// Fexportformat format of the export: csv, xliff or po
+var Fexportformat string
+
 func init() {
 	textExportCmd.Flags().BoolVar(&Femptyonly, "emptyonly", false, "Export only if a translation is empty")
+	textExportCmd.Flags().StringVar(&Fexportformat, "format", "csv", "Format of the export: csv, xliff or po")
+	textExportCmd.Flags().StringVar(&Fproject, "project", "", "Project with the lgcodes (xliff and po)")
+	textExportCmd.Flags().StringVar(&Flgsource, "lgsource", "", "Source language (xliff and po)")
 	textExportCmd.Flags().StringVar(&Flist, "list", "", "List with exported file")
 	textExportCmd.PersistentFlags().StringVar(&Fversion, "version", "", "Version to work with")
 	textCmd.AddCommand(textExportCmd)
@@ -80,9 +100,22 @@ func textExport(cmd *cobra.Command, args []string) error {
 		case "d", "ger", "ge":
 			lgs = append(lgs, "ger")
 		default:
+			if lg := qutil.LookupLanguage(arg); lg != nil {
+				arg = lg.Key
+			}
 			lgs = append(lgs, arg)
 		}
 	}
+	if Fexportformat == "xliff" || Fexportformat == "po" {
+		result, err := exchangeExport(Fversion, Fproject, Flgsource, lgs, Femptyonly, Fexportformat)
+		Fmsg = qreport.Report(result, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	if Fexportformat != "" && Fexportformat != "csv" {
+		err := fmt.Errorf("unknown format `%s`", Fexportformat)
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
 	if len(lgs) == 0 {
 		lgs = []string{"dut", "fre", "eng"}
 	}
@@ -108,6 +141,42 @@ func textExport(cmd *cobra.Command, args []string) error {
 
 }
 
+func exchangeExport(v string, project string, lgsource string, lgs []string, emptyonly bool, format string) (result map[string]string, err error) {
+	if len(lgs) != 1 {
+		return nil, errors.New("there should be exactly one target language")
+	}
+	if lgsource == "" {
+		lgsource = "dut"
+	}
+	catalog, err := qtext.ExportCatalog(v, project, lgsource, lgs[0], emptyonly)
+	if catalog == nil {
+		return nil, err
+	}
+	blob := catalog.PO()
+	ext := ".po"
+	if format == "xliff" {
+		ext = ".xlf"
+		var e error
+		blob, e = catalog.XLIFF()
+		if e != nil {
+			return nil, e
+		}
+	}
+	stamp := time.Now().Format(time.RFC3339)[:19]
+	stamp = strings.ReplaceAll(stamp, ":", "")
+	stamp = strings.ReplaceAll(stamp, "-", "")
+	filename := qutil.AbsPath("brocade-"+v+"-"+catalog.Target+"-"+stamp+ext, qregistry.Registry["scratch-dir"])
+	e := qfs.Store(filename, blob, "qtech")
+	if e != nil {
+		return nil, e
+	}
+	result = map[string]string{
+		"#filename": filename,
+		"#total":    strconv.Itoa(len(catalog.Units)),
+	}
+	return result, err
+}
+
 func lgsURL(v string, lgs []string, emptyonly bool) (url string, numbers map[string]int, err error) {
 	sort.Strings(lgs)
 
@@ -25,6 +25,7 @@ import (
 	qreport "brocade.be/qtechng/lib/report"
 	qserver "brocade.be/qtechng/lib/server"
 	qsource "brocade.be/qtechng/lib/source"
+	qtext "brocade.be/qtechng/lib/text"
 	qutil "brocade.be/qtechng/lib/util"
 	"github.com/spf13/cobra"
 )
@@ -52,9 +53,15 @@ Different error messages (per lgcode):
 
 The system makes a backup of the changed L-files: the return message indicates
 the location of the backup on the development server.
+
+Files with extension '.xlf' or '.xliff' (XLIFF 2.0) and '.po' (gettext PO) are
+created by 'qtechng text export --format=xliff|po'. The translations are stored
+in the originating L-files. If an L-file is changed since the export, its
+lgcodes are not imported: they are reported as CONFLICT.
 `,
-	Args:    cobra.ExactArgs(1),
-	Example: `qtechng text import mytranslations.csv`,
+	Args: cobra.ExactArgs(1),
+	Example: `qtechng text import mytranslations.csv
+qtechng text import brocade-5.10-fr.xlf`,
 
 	RunE:   textImport,
 	PreRun: preImport,
@@ -157,6 +164,17 @@ func textImport(cmd *cobra.Command, args []string) error {
 				}
 			}
 		}
+		switch strings.ToLower(filepath.Ext(csvfile)) {
+		case ".xlf", ".xliff", ".po":
+			numbers, err := handleExchange(version, csvfile)
+			result := make(map[string]string)
+			for x, nr := range numbers {
+				result[x] = strconv.Itoa(nr)
+			}
+			Fmsg = qreport.Report(result, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+			return nil
+		}
+
 		csvfile, numbers, backupdir, err := handleCSV(version, csvfile)
 
 		result := make(map[string]string)
@@ -179,6 +197,28 @@ func textImport(cmd *cobra.Command, args []string) error {
 
 }
 
+func handleExchange(version string, filename string) (numbers map[string]int, err error) {
+	blob, err := qfs.Fetch(filename)
+	if err != nil {
+		return nil, err
+	}
+	var catalog *qtext.Catalog
+	if strings.ToLower(filepath.Ext(filename)) == ".po" {
+		catalog, err = qtext.ParsePO(blob)
+	} else {
+		catalog, err = qtext.ParseXLIFF(blob)
+	}
+	if err != nil {
+		e := &qerror.QError{
+			Ref:  []string{"text.import.parse"},
+			File: filename,
+			Msg:  []string{"`" + filepath.Base(filename) + "` cannot be parsed: " + err.Error()},
+		}
+		return nil, e
+	}
+	return qtext.ImportCatalog(version, catalog, FUID)
+}
+
 func handleCSV(version string, csvfile string) (filename string, numbers map[string]int, backupdir string, err error) {
 
 	f, err := os.Open(csvfile)
@@ -245,7 +285,8 @@ func handleCSV(version string, csvfile string) (filename string, numbers map[str
 		}
 		m[code] = true
 		ok := false
-		for _, lg := range []string{"dut", "fre", "eng", "ger"} {
+		for _, language := range qutil.Languages() {
+			lg := language.Key
 			if lg == code {
 				lgs = append(lgs, lg)
 				ok = true
@@ -449,37 +490,10 @@ func rewriteCVS(v string, records [][]string, baselg string, lgs []string, crlf
 				for _, lg := range lgs {
 					j := lgmap[lg]
 					newtr := qutil.Simplify(record[j], false)
-					switch lg {
-					case "dut":
-						if lgcode.N != newtr {
-							lgcode.N = newtr
-							changed = true
-							oncechange = true
-						}
-					case "eng":
-						if lgcode.E != newtr {
-							lgcode.E = newtr
-							changed = true
-							oncechange = true
-						}
-					case "fre":
-						if lgcode.F != newtr {
-							lgcode.F = newtr
-							changed = true
-							oncechange = true
-						}
-					case "ger":
-						if lgcode.D != newtr {
-							lgcode.D = newtr
-							changed = true
-							oncechange = true
-						}
-					case "unv":
-						if lgcode.D != newtr {
-							lgcode.U = newtr
-							changed = true
-							oncechange = true
-						}
+					if lgcode.Translation(lg) != newtr {
+						lgcode.SetTranslation(lg, newtr)
+						changed = true
+						oncechange = true
 					}
 				}
 				if oncechange {
@@ -3,14 +3,22 @@ package cmd
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	qfs "brocade.be/base/fs"
 	qparallel "brocade.be/base/parallel"
 	qregistry "brocade.be/base/registry"
+	qerror "brocade.be/qtechng/lib/error"
+	qlfile "brocade.be/qtechng/lib/file/lfile"
+	qobject "brocade.be/qtechng/lib/object"
+	qproject "brocade.be/qtechng/lib/project"
 	qreport "brocade.be/qtechng/lib/report"
+	qsource "brocade.be/qtechng/lib/source"
 	qtext "brocade.be/qtechng/lib/text"
 	qutil "brocade.be/qtechng/lib/util"
 	"github.com/spf13/cobra"
@@ -32,16 +40,35 @@ The '--lgtarget' flag specifies the target language(s) according to BCP-47.
 Default value is "en-GB,fr-FR"
 
 If the '--isfile' flag is present, the argument is interpreted as a file with
-a JSON array. Every element of the array is translated.`,
+a JSON array. Every element of the array is translated.
+
+The translation services are in the registry value 'qtechng-translation-services'
+(a comma separated list) or in the '--services' flag:
+
+    - memory: the translation memory built from all lgcodes in the version.
+      Exact matches (case and whitespace are ignored) are preferred over
+      fuzzy matches. The minimal score of a fuzzy match is in the registry
+      value 'qtechng-translation-memory-threshold' (default 0.85)
+    - google: Google Cloud Translate
+    - deepl: DeepL
+
+If the '--missing' flag is present, the argument is a project: in all L-files
+of the project, the translations in the target languages which are absent are
+filled in. The services are tried in order until one of them succeeds.
+The L-files are not checked in: they are written to a directory for review
+(on a W machine, the 'download' subdirectory of 'qtechng-work-dir').`,
 	Args: cobra.MaximumNArgs(1),
 	Example: `qtechng text translate "Opgelet ! Er staan cijfers in de auteursnaam en dit is GEEN authority code"
-qtechng text translate translateme.json --isfile`,
+qtechng text translate translateme.json --isfile
+qtechng text translate "Opgelet !" --services=memory,deepl
+qtechng text translate /catalografie/application --missing --services=memory --lgtarget=en-GB,fr-FR`,
 	RunE:   textTranslate,
 	PreRun: textTranslateLocal,
 	Annotations: map[string]string{
 		"remote-allowed":    "yes",
 		"always-remote-onW": "yes",
 		"with-qtechtype":    "BW",
+		"fill-version":      "yes",
 	},
 }
 
@@ -49,10 +76,20 @@ var Flgsource = ""
 var Flgtarget = ""
 var Fisfile = false
 
+// Ftrservices translation services
+var Ftrservices = ""
+
+// Fmissing fill in the absent translations in L-files
+var Fmissing = false
+
 func init() {
 	textTranslateCmd.Flags().StringVar(&Flgsource, "lgsource", "", "Brontaal")
 	textTranslateCmd.Flags().StringVar(&Flgtarget, "lgtarget", "", "Bestemmingstaal")
 	textTranslateCmd.Flags().BoolVar(&Fisfile, "isfile", false, "is het argument een JSON bestand")
+	textTranslateCmd.Flags().StringVar(&Ftrservices, "services", "", "Comma separated list of translation services")
+	textTranslateCmd.Flags().BoolVar(&Fmissing, "missing", false, "Fill in the absent translations in the L-files of a project")
+	textTranslateCmd.Flags().StringVar(&Fversion, "version", "", "Version to work with")
+	textTranslateCmd.Flags().StringVar(&Flist, "list", "", "List with the translated L-files")
 	textCmd.AddCommand(textTranslateCmd)
 }
 
@@ -60,6 +97,10 @@ func textTranslateLocal(cmd *cobra.Command, args []string) {
 	if strings.ContainsRune(qregistry.Registry["qtechng-type"], 'B') {
 		return
 	}
+	if Fmissing {
+		preSSH(cmd, catchMissing)
+		return
+	}
 	if len(args) == 0 {
 		btext, err := io.ReadAll(os.Stdin)
 		if err != nil {
@@ -107,13 +148,33 @@ func textTranslateLocal(cmd *cobra.Command, args []string) {
 
 func textTranslate(cmd *cobra.Command, args []string) error {
 
-	services := qregistry.Registry["qtechng-translation-services"]
+	services := Ftrservices
+	if services == "" {
+		services = qregistry.Registry["qtechng-translation-services"]
+	}
 	if services == "" {
 		Fmsg = qreport.Report(nil, errors.New("no translation services defined"), Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
 		return nil
 	}
 	trsystems := strings.SplitN(services, ",", -1)
 
+	if Flgsource == "" {
+		Flgsource = "nl-NL"
+	}
+	if Flgtarget == "" {
+		Flgtarget = "en-GB,fr-FR"
+	}
+
+	if Fmissing {
+		if len(args) == 0 {
+			Fmsg = qreport.Report(nil, errors.New("missing project"), Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+			return nil
+		}
+		result, err := translateMissing(Fversion, args[0], trsystems, Flgsource, strings.SplitN(Flgtarget, ",", -1))
+		Fmsg = qreport.Report(result, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+
 	text := ""
 	if len(args) == 0 {
 		btext, err := io.ReadAll(os.Stdin)
@@ -127,13 +188,6 @@ func textTranslate(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if Flgsource == "" {
-		Flgsource = "nl-NL"
-	}
-	if Flgtarget == "" {
-		Flgtarget = "en-GB,fr-FR"
-	}
-
 	lgtargets := strings.SplitN(Flgtarget, ",", -1)
 
 	if Fisfile {
@@ -151,12 +205,14 @@ func textTranslate(cmd *cobra.Command, args []string) error {
 	}
 
 	type mission struct {
-		From        string `json:"lgsource"`
-		To          string `json:"lgtarget"`
-		Text        string `json:"text"`
-		System      string `json:"system"`
-		Translation string `json:"translation"`
-		Error       string `json:"error"`
+		From        string  `json:"lgsource"`
+		To          string  `json:"lgtarget"`
+		Text        string  `json:"text"`
+		System      string  `json:"system"`
+		Translation string  `json:"translation"`
+		Score       float64 `json:"score,omitempty"`
+		Match       string  `json:"match,omitempty"`
+		Error       string  `json:"error"`
 	}
 
 	missions := make([]mission, 0)
@@ -184,16 +240,17 @@ func textTranslate(cmd *cobra.Command, args []string) error {
 
 	fn := func(n int) (interface{}, error) {
 		m := missions[n]
-		system := m.System
-		tr := ""
-		var err error
-		switch system {
-		case "google":
-			tr, err = qtext.GoogleTranslate(m.Text, m.From, m.To)
-		case "deepl":
-			tr, err = qtext.DeepLTranslate(m.Text, m.From, m.To)
-		}
-		m.Translation = tr
+		translator, err := qtext.NewTranslator(m.System, Fversion)
+		if err != nil {
+			m.Error = err.Error()
+			return m, nil
+		}
+		tr, err := translator.Translate(m.Text, m.From, m.To)
+		m.Translation = tr.Text
+		if m.Translation != "" {
+			m.Score = tr.Score
+			m.Match = tr.Match
+		}
 		if err != nil {
 			m.Error = err.Error()
 		}
@@ -207,3 +264,202 @@ func textTranslate(cmd *cobra.Command, args []string) error {
 	return nil
 
 }
+
+// missingTranslation is an absent translation which is filled in
+type missingTranslation struct {
+	QPath       string  `json:"qpath"`
+	ID          string  `json:"lgcode"`
+	Language    string  `json:"language"`
+	Text        string  `json:"text"`
+	System      string  `json:"system"`
+	Translation string  `json:"translation"`
+	Score       float64 `json:"score"`
+	Match       string  `json:"match,omitempty"`
+}
+
+// missingResult is the result of 'text translate --missing'
+type missingResult struct {
+	Dir          string               `json:"#dir"`
+	Files        []string             `json:"files"`
+	Translations []missingTranslation `json:"translations"`
+	Untranslated int                  `json:"untranslated"`
+}
+
+// translateMissing fills in the absent translations in the L-files of a project.
+// The changed L-files are written to a temporary directory.
+func translateMissing(v string, project string, systems []string, from string, tos []string) (result *missingResult, err error) {
+	proj, err := qproject.Project{}.New(v, project, true)
+	if err != nil {
+		return nil, err
+	}
+	lgfrom := qutil.LookupLanguage(from)
+	if lgfrom == nil {
+		return nil, fmt.Errorf("`%s`: not a language", from)
+	}
+	lgtos := make([]*qutil.Language, 0)
+	for _, to := range tos {
+		to = strings.TrimSpace(to)
+		if to == "" {
+			continue
+		}
+		lgto := qutil.LookupLanguage(to)
+		if lgto == nil {
+			return nil, fmt.Errorf("`%s`: not a language", to)
+		}
+		lgtos = append(lgtos, lgto)
+	}
+	type service struct {
+		name       string
+		translator qtext.Translator
+	}
+	services := make([]service, 0)
+	for _, system := range systems {
+		system = strings.TrimSpace(system)
+		translator, err := qtext.NewTranslator(system, v)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, service{system, translator})
+	}
+
+	qpaths := proj.QPaths([]string{"*.l"}, true)
+	sort.Strings(qpaths)
+	dir, err := qfs.TempDir("", "translate-")
+	if err != nil {
+		return nil, err
+	}
+
+	fn := func(n int) (interface{}, error) {
+		qpath := qpaths[n]
+		source, err := qsource.Source{}.New(v, qpath, true)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := source.Fetch()
+		if err != nil {
+			return nil, err
+		}
+		lfile := new(qlfile.LFile)
+		lfile.SetEditFile(qpath)
+		lfile.SetRelease(v)
+		err = qobject.Loads(lfile, blob, true)
+		if err != nil {
+			return nil, err
+		}
+		lfile.SetObjects(lfile.Objects())
+		trs := make([]missingTranslation, 0)
+		untranslated := 0
+		for _, lgcode := range lfile.Lgcodes {
+			text := lgcode.Translation(lgfrom.Code)
+			if text == "" || lgcode.Alias != "" {
+				continue
+			}
+			for _, lgto := range lgtos {
+				if lgto.Code == lgfrom.Code || lgcode.Translation(lgto.Code) != "" {
+					continue
+				}
+				found := false
+				for _, service := range services {
+					tr, err := service.translator.Translate(text, lgfrom.Tag, lgto.Tag)
+					if err != nil || tr.Text == "" {
+						continue
+					}
+					lgcode.SetTranslation(lgto.Code, tr.Text)
+					trs = append(trs, missingTranslation{
+						QPath:       qpath,
+						ID:          lgcode.ID,
+						Language:    lgto.Key,
+						Text:        text,
+						System:      service.name,
+						Translation: tr.Text,
+						Score:       tr.Score,
+						Match:       tr.Match,
+					})
+					found = true
+					break
+				}
+				if !found {
+					untranslated++
+				}
+			}
+		}
+		if len(trs) != 0 {
+			target := filepath.Join(dir, filepath.FromSlash(qpath[1:]))
+			qfs.MkdirAll(filepath.Dir(target), "process")
+			err = qfs.Store(target, lfile.Format(), "process")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return missingResult{Translations: trs, Untranslated: untranslated}, nil
+	}
+	results, errs := qparallel.NMap(len(qpaths), -1, fn)
+
+	result = &missingResult{
+		Dir:          dir,
+		Files:        make([]string, 0),
+		Translations: make([]missingTranslation, 0),
+	}
+	errlist := make([]error, 0)
+	for i, r := range results {
+		if errs[i] != nil {
+			errlist = append(errlist, errs[i])
+			continue
+		}
+		mr := r.(missingResult)
+		if len(mr.Translations) != 0 {
+			result.Files = append(result.Files, qpaths[i])
+		}
+		result.Translations = append(result.Translations, mr.Translations...)
+		result.Untranslated += mr.Untranslated
+	}
+	if len(errlist) != 0 {
+		return result, qerror.ErrorSlice(errlist)
+	}
+	return result, nil
+}
+
+// catchMissing copies the L-files of 'text translate --missing' from the server
+// to the 'download' subdirectory of 'qtechng-work-dir'
+func catchMissing(result string) string {
+	if result == "" || !strings.ContainsRune(qregistry.Registry["qtechng-type"], 'W') || qregistry.Registry["qtechng-work-dir"] == "" {
+		return result
+	}
+	report := make(map[string]interface{})
+	if json.Unmarshal([]byte(result), &report) != nil {
+		return result
+	}
+	data, ok := report["DATA"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	dir, _ := data["#dir"].(string)
+	files, _ := data["files"].([]interface{})
+	if dir == "" || len(files) == 0 {
+		return result
+	}
+	base := filepath.Base(filepath.FromSlash(dir))
+	localdir := filepath.Join(qregistry.Registry["qtechng-work-dir"], "download", base)
+	editlist := make([]string, 0)
+	for _, f := range files {
+		qpath, _ := f.(string)
+		if qpath == "" {
+			continue
+		}
+		blob, err := ReadSSHAll(strings.TrimSuffix(filepath.ToSlash(dir), "/") + qpath)
+		if err != nil {
+			continue
+		}
+		target := filepath.Join(localdir, filepath.FromSlash(qpath[1:]))
+		qfs.MkdirAll(filepath.Dir(target), "qtech")
+		qfs.Store(target, blob, "qtech")
+		editlist = append(editlist, "/download/"+base+qpath)
+	}
+	qutil.EditList(Flist, false, editlist)
+	data["#dir"] = localdir
+	blob, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return result
+	}
+	return string(blob) + "\n"
+}
@@ -118,12 +118,17 @@ func buildSQuery(args []string, filesinproject bool, qdirs []string, mumps bool)
 		SmartCase:      Fsmartcase,
 		Contains:       Fneedle,
 		Mumps:          mumps,
+		Expr:           Fexpr,
 	}
 
 }
 
 func fetchData(args []string, filesinproject bool, qdirs []string, mumps bool) (pcargo *qclient.Cargo, err error) {
 
+	if _, err = qsource.ParseExpr(Fexpr); err != nil {
+		return new(qclient.Cargo), err
+	}
+
 	Fpayload = &qclient.Payload{
 		ID:     "Once",
 		UID:    FUID,
@@ -244,6 +249,7 @@ func addData(ppayload *qclient.Payload, pcargo *qclient.Cargo, withcontent bool,
 	}
 	buffer := new(bytes.Buffer)
 	transports := make([]qclient.Transport, len(paths))
+	smaps := make([]*qsource.ResolveMap, 0)
 
 	sorts := make(map[string]string)
 	cores := make(map[string]bool)
@@ -289,12 +295,23 @@ func addData(ppayload *qclient.Payload, pcargo *qclient.Cargo, withcontent bool,
 				qsource.Mend(batchid[2:], buffer)
 			}
 		}
-		if batchid != "" && strings.HasPrefix(batchid, "r:") {
+		if batchid != "" && strings.HasPrefix(batchid, "r:") && !Fexplain {
 			err := psource.Resolve(batchid, nil, nil, buffer, false)
 			pcargo.AddError(err)
 		}
+		if batchid != "" && strings.HasPrefix(batchid, "r:") && Fexplain {
+			smap, err := psource.Explain(batchid, false)
+			pcargo.AddError(err)
+			if smap != nil {
+				smaps = append(smaps, smap)
+			}
+		}
 
 	}
+	if len(smaps) != 0 {
+		blob, _ := json.Marshal(smaps)
+		buffer.Write(blob)
+	}
 	pcargo.Data = make([]byte, 0)
 	if batchid != "" {
 		pcargo.Data = buffer.Bytes()
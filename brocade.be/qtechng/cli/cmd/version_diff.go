@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	qreport "brocade.be/qtechng/lib/report"
+	qserver "brocade.be/qtechng/lib/server"
+	qsource "brocade.be/qtechng/lib/source"
+)
+
+var versionDiffCmd = &cobra.Command{
+	Use:   "diff cmpversion version",
+	Short: "Compare two versions",
+	Long: `This command compares two versions of the repository.
+The first argument is the reference version, the second argument the newer version.
+
+The report contains:
+
+    - per project: the added, removed and changed sources
+    - the added, removed and changed Brocade objects (i4/l4/m4/r4/...)
+      with their definitions before and after
+    - per changed object: the dependencies which are added or removed
+
+With the '--unified' flag, the differences are shown in unified diff format.`,
+	Args: cobra.ExactArgs(2),
+	Example: `qtechng version diff 5.10 5.20
+qtechng version diff 5.10 5.20 --unified`,
+	RunE: versionDiff,
+	Annotations: map[string]string{
+		"with-qtechtype": "B",
+	},
+}
+
+// Funified output in unified diff format
+var Funified bool
+
+func init() {
+	versionCmd.AddCommand(versionDiffCmd)
+	versionDiffCmd.Flags().BoolVar(&Funified, "unified", false, "Shows the differences in unified diff format")
+}
+
+func versionDiff(cmd *cobra.Command, args []string) error {
+	cmpr := qserver.Canon(args[0])
+	r := qserver.Canon(args[1])
+	diff, err := qsource.DiffRelease(cmpr, r)
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+	if Funified {
+		Fmsg = diff.Unified()
+		return nil
+	}
+	Fmsg = qreport.Report(diff, nil, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qreport "brocade.be/qtechng/lib/report"
+	qserver "brocade.be/qtechng/lib/server"
+	qsource "brocade.be/qtechng/lib/source"
+)
+
+var versionIndexCmd = &cobra.Command{
+	Use:   "index version",
+	Short: "Rebuild or check the index of a version",
+	Long: `This command reconstructs the index of a version
+in *{qtechng-repository-dir}/{version}/index*.
+The index narrows the candidates of searches on sources
+(contents, natures, creation and modification).
+
+With the '--check' flag, the index is verified against the sources:
+sources missing in the index, stale entries and orphaned entries are listed.
+Source files and their meta information is not touched.`,
+	Args: cobra.ExactArgs(1),
+	Example: `qtechng version index 0.00
+qtechng version index 0.00 --check`,
+	RunE: versionIndex,
+	Annotations: map[string]string{
+		"with-qtechtype": "BP",
+	},
+}
+
+// Fcheckindex only verifies the index
+var Fcheckindex bool
+
+func init() {
+	versionCmd.AddCommand(versionIndexCmd)
+	versionIndexCmd.Flags().BoolVar(&Fcheckindex, "check", false, "Checks the consistency of the index")
+}
+
+func versionIndex(cmd *cobra.Command, args []string) error {
+
+	r := qserver.Canon(args[0])
+	release, err := qserver.Release{}.New(r, true)
+	if err == nil {
+		ok, _ := release.Exists()
+		if !ok {
+			err = &qerror.QError{
+				Ref: []string{"index.notexist"},
+				Msg: []string{"version does not exist."},
+			}
+		}
+	}
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+
+	if Fcheckindex {
+		check, err := qsource.CheckIndex(release.String())
+		Fmsg = qreport.Report(check, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+
+	err = qsource.RebuildIndex(release.String())
+	msg := make(map[string]string)
+	msg["status"] = "Index FAILED"
+	if err == nil {
+		msg["status"] = "Index SUCCESS"
+	}
+	Fmsg = qreport.Report(msg, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qreport "brocade.be/qtechng/lib/report"
+	qserver "brocade.be/qtechng/lib/server"
+	qsource "brocade.be/qtechng/lib/source"
+)
+
+var versionRecoverCmd = &cobra.Command{
+	Use:   "recover version",
+	Short: "Roll back interrupted check-ins of a version",
+	Long: `A check-in keeps a journal in *{qtechng-repository-dir}/{version}/journal*
+until all its files are stored.
+This command rolls back the journals left behind by crashed processes:
+the files of an interrupted check-in are restored to their previous state.
+Journals of running processes are not touched.
+
+Every check-in recovers the journals of its version before it starts.`,
+	Args:    cobra.ExactArgs(1),
+	Example: `qtechng version recover 0.00`,
+	RunE:    versionRecover,
+	Annotations: map[string]string{
+		"with-qtechtype": "BP",
+	},
+}
+
+func init() {
+	versionCmd.AddCommand(versionRecoverCmd)
+}
+
+func versionRecover(cmd *cobra.Command, args []string) error {
+
+	r := qserver.Canon(args[0])
+	release, err := qserver.Release{}.New(r, true)
+	if err == nil {
+		ok, _ := release.Exists()
+		if !ok {
+			err = &qerror.QError{
+				Ref: []string{"recover.notexist"},
+				Msg: []string{"version does not exist."},
+			}
+		}
+	}
+	if err != nil {
+		Fmsg = qreport.Report(nil, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+		return nil
+	}
+
+	recovered, err := qsource.RecoverJournals(release.String())
+	msg := make(map[string][]string)
+	msg["recovered"] = recovered
+	Fmsg = qreport.Report(msg, err, Fjq, Fyaml, Funquote, Fjoiner, Fsilent, "", "")
+	return nil
+}
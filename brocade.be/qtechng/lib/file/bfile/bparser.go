@@ -161,6 +161,7 @@ var g = &grammar{
 							pos:        position{line: 66, col: 49, offset: 1248},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 66, col: 54, offset: 1253},
@@ -194,91 +195,109 @@ var g = &grammar{
 							pos:        position{line: 70, col: 14, offset: 1378},
 							val:        "oaiset",
 							ignoreCase: false,
+							want:       "\"oaiset\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 25, offset: 1389},
 							val:        "oai",
 							ignoreCase: false,
+							want:       "\"oai\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 33, offset: 1397},
 							val:        "mprocess",
 							ignoreCase: false,
+							want:       "\"mprocess\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 46, offset: 1410},
 							val:        "mailtrg",
 							ignoreCase: false,
+							want:       "\"mailtrg\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 58, offset: 1422},
 							val:        "usergroup",
 							ignoreCase: false,
+							want:       "\"usergroup\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 72, offset: 1436},
 							val:        "ujson",
 							ignoreCase: false,
+							want:       "\"ujson\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 82, offset: 1446},
 							val:        "lookup",
 							ignoreCase: false,
+							want:       "\"lookup\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 93, offset: 1457},
 							val:        "history",
 							ignoreCase: false,
+							want:       "\"history\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 105, offset: 1469},
 							val:        "meta",
 							ignoreCase: false,
+							want:       "\"meta\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 114, offset: 1478},
 							val:        "listattribute",
 							ignoreCase: false,
+							want:       "\"listattribute\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 132, offset: 1496},
 							val:        "listidentity",
 							ignoreCase: false,
+							want:       "\"listidentity\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 149, offset: 1513},
 							val:        "listdownloadtype",
 							ignoreCase: false,
+							want:       "\"listdownloadtype\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 170, offset: 1534},
 							val:        "cg",
 							ignoreCase: false,
+							want:       "\"cg\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 177, offset: 1541},
 							val:        "loi",
 							ignoreCase: false,
+							want:       "\"loi\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 185, offset: 1549},
 							val:        "search",
 							ignoreCase: false,
+							want:       "\"search\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 196, offset: 1560},
 							val:        "listsorttype",
 							ignoreCase: false,
+							want:       "\"listsorttype\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 213, offset: 1577},
 							val:        "nodeattribute",
 							ignoreCase: false,
+							want:       "\"nodeattribute\"",
 						},
 						&litMatcher{
 							pos:        position{line: 70, col: 231, offset: 1595},
 							val:        "listconversion",
 							ignoreCase: false,
+							want:       "\"listconversion\"",
 						},
 					},
 				},
@@ -331,6 +350,7 @@ var g = &grammar{
 							pos:        position{line: 83, col: 12, offset: 1815},
 							val:        "//",
 							ignoreCase: false,
+							want:       "\"//\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 83, col: 17, offset: 1820},
@@ -461,6 +481,7 @@ var g = &grammar{
 							pos:        position{line: 107, col: 14, offset: 2190},
 							val:        "$",
 							ignoreCase: false,
+							want:       "\"$\"",
 						},
 						&labeledExpr{
 							pos:   position{line: 107, col: 18, offset: 2194},
@@ -481,6 +502,7 @@ var g = &grammar{
 							pos:        position{line: 107, col: 32, offset: 2208},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 107, col: 36, offset: 2212},
@@ -559,6 +581,7 @@ var g = &grammar{
 							pos:        position{line: 122, col: 15, offset: 2546},
 							val:        "$$",
 							ignoreCase: false,
+							want:       "\"$$\"",
 						},
 						&labeledExpr{
 							pos:   position{line: 122, col: 20, offset: 2551},
@@ -579,6 +602,7 @@ var g = &grammar{
 							pos:        position{line: 122, col: 34, offset: 2565},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 122, col: 38, offset: 2569},
@@ -639,6 +663,7 @@ var g = &grammar{
 							pos:        position{line: 132, col: 11, offset: 2731},
 							val:        "⟦",
 							ignoreCase: false,
+							want:       "\"⟦\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 132, col: 15, offset: 2737},
@@ -654,6 +679,7 @@ var g = &grammar{
 							pos:        position{line: 132, col: 22, offset: 2748},
 							val:        "⟧",
 							ignoreCase: false,
+							want:       "\"⟧\"",
 						},
 					},
 				},
@@ -672,6 +698,7 @@ var g = &grammar{
 							pos:        position{line: 137, col: 11, offset: 2825},
 							val:        "«",
 							ignoreCase: false,
+							want:       "\"«\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 137, col: 15, offset: 2830},
@@ -687,6 +714,7 @@ var g = &grammar{
 							pos:        position{line: 137, col: 22, offset: 2839},
 							val:        "»",
 							ignoreCase: false,
+							want:       "\"»\"",
 						},
 					},
 				},
@@ -820,6 +848,7 @@ var g = &grammar{
 										pos:        position{line: 160, col: 59, offset: 3354},
 										val:        "/",
 										ignoreCase: false,
+										want:       "\"/\"",
 									},
 								},
 								&ruleRefExpr{
@@ -842,6 +871,7 @@ var g = &grammar{
 						pos:        position{line: 164, col: 17, offset: 3419},
 						val:        "//",
 						ignoreCase: false,
+						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 164, col: 22, offset: 3424},
@@ -919,21 +949,25 @@ var g = &grammar{
 						pos:        position{line: 175, col: 9, offset: 3605},
 						val:        "\r\n",
 						ignoreCase: false,
+						want:       "\"\\r\\n\"",
 					},
 					&litMatcher{
 						pos:        position{line: 175, col: 18, offset: 3614},
 						val:        "\n\r",
 						ignoreCase: false,
+						want:       "\"\\n\\r\"",
 					},
 					&litMatcher{
 						pos:        position{line: 175, col: 27, offset: 3623},
 						val:        "\r",
 						ignoreCase: false,
+						want:       "\"\\r\"",
 					},
 					&litMatcher{
 						pos:        position{line: 175, col: 34, offset: 3630},
 						val:        "\n",
 						ignoreCase: false,
+						want:       "\"\\n\"",
 					},
 				},
 			},
@@ -1264,18 +1298,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -1513,6 +1546,7 @@ type litMatcher struct {
 	pos        position
 	val        string
 	ignoreCase bool
+	want       string
 }
 
 type charClassMatcher struct {
@@ -2307,11 +2341,6 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
-	ignoreCase := ""
-	if lit.ignoreCase {
-		ignoreCase = "i"
-	}
-	val := string(strconv.AppendQuote([]byte{}, lit.val)) + ignoreCase // wrap 'lit.val' with double quotes
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
@@ -2319,13 +2348,13 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 			cur = unicode.ToLower(cur)
 		}
 		if cur != want {
-			p.failAt(false, start.position, val)
+			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
 		}
 		p.read()
 	}
-	p.failAt(true, start.position, val)
+	p.failAt(true, start.position, lit.want)
 	return p.sliceFrom(start), true
 }
 
@@ -1,6 +1,7 @@
 package dfile
 
 import (
+	"strings"
 	"testing"
 
 	qobject "brocade.be/qtechng/lib/object"
@@ -680,3 +681,57 @@ $cloi, $eloi
 	}
 
 }
+
+func TestParamType01(t *testing.T) {
+	preamble := "// About\n"
+	data := []byte(preamble + `macro typed($var:lvn, $n : numeric=1, *$label:label=«show^me», $text:lgcode, $x:colour=a):
+	'''
+    $synopsis: typed parameters
+    $var: variable
+    $n: number
+    $label: entry reference
+    $text: lgcode
+    $x: wrong type
+    $example: m4_typed(RAbuf,2,label=^xyz,text=catTitle)
+    '''
+	«s $var=$n d $label w l4_$text»`)
+	dfile := new(DFile)
+	err := qobject.Loads(dfile, data, true)
+	if err != nil {
+		t.Fatalf("Error: %s", err)
+	}
+	macro := dfile.Macros[0]
+	types := make([]string, 0)
+	for _, param := range macro.Params {
+		types = append(types, param.ID+":"+param.Type+"="+param.Default)
+	}
+	if strings.Join(types, " ") != "$var:lvn= $n:numeric=1 $label:label=show^me $text:lgcode= $x:colour=a" {
+		t.Errorf("Wrong params: %v", types)
+	}
+	if !strings.HasPrefix(macro.Format(), "macro typed($var:lvn, $n:numeric=1, *$label:label=show^me, *$text:lgcode, *$x:colour=a):") {
+		t.Errorf("Wrong format: %s", macro.Format())
+	}
+	errs := macro.Lint()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Unknown type `colour`") {
+		t.Errorf("Wrong lint: %v", errs)
+	}
+
+	args, _, err := macro.Args(`(RAbuf(1,"a"),1.5E3,text=l4_catTitle)`, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msgs := macro.CheckArgs(args); len(msgs) != 0 {
+		t.Errorf("Should be valid: %v", msgs)
+	}
+	args, _, _ = macro.Args(`("abc",x+1,label=a b,text=a b)`, "")
+	msgs := macro.CheckArgs(args)
+	expect := []string{
+		"Parameter `$var` of m4_typed: `\"abc\"` is not a local variable, with or without subscripts",
+		"Parameter `$n` of m4_typed: `x+1` is not a number",
+		"Parameter `$label` of m4_typed: `a b` is not an entry reference: label, label^routine or ^routine",
+		"Parameter `$text` of m4_typed: `a b` is not the name of an lgcode, with or without `l4_`",
+	}
+	if strings.Join(msgs, "\n") != strings.Join(expect, "\n") {
+		t.Errorf("Wrong checks:\n%s", strings.Join(msgs, "\n"))
+	}
+}
@@ -90,6 +90,7 @@ var g = &grammar{
 						pos:        position{line: 53, col: 16, offset: 998},
 						val:        "'''",
 						ignoreCase: false,
+						want:       "\"'''\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 53, col: 22, offset: 1004},
@@ -101,6 +102,7 @@ var g = &grammar{
 						pos:        position{line: 53, col: 25, offset: 1007},
 						val:        "'''",
 						ignoreCase: false,
+						want:       "\"'''\"",
 					},
 					&andCodeExpr{
 						pos: position{line: 53, col: 31, offset: 1013},
@@ -126,6 +128,7 @@ var g = &grammar{
 						pos:        position{line: 58, col: 17, offset: 1159},
 						val:        "\"\"\"",
 						ignoreCase: false,
+						want:       "\"\\\"\\\"\\\"\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 58, col: 26, offset: 1168},
@@ -137,6 +140,7 @@ var g = &grammar{
 						pos:        position{line: 58, col: 29, offset: 1171},
 						val:        "\"\"\"",
 						ignoreCase: false,
+						want:       "\"\\\"\\\"\\\"\"",
 					},
 					&andCodeExpr{
 						pos: position{line: 58, col: 38, offset: 1180},
@@ -238,6 +242,7 @@ var g = &grammar{
 										pos:        position{line: 63, col: 147, offset: 1459},
 										val:        "macro",
 										ignoreCase: false,
+										want:       "\"macro\"",
 									},
 									&ruleRefExpr{
 										pos:  position{line: 63, col: 157, offset: 1469},
@@ -263,6 +268,7 @@ var g = &grammar{
 							pos:        position{line: 123, col: 17, offset: 2822},
 							val:        "macro",
 							ignoreCase: false,
+							want:       "\"macro\"",
 						},
 						&oneOrMoreExpr{
 							pos: position{line: 123, col: 25, offset: 2830},
@@ -290,6 +296,7 @@ var g = &grammar{
 							pos:        position{line: 123, col: 46, offset: 2851},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 					},
 				},
@@ -308,6 +315,7 @@ var g = &grammar{
 							pos:        position{line: 131, col: 17, offset: 2969},
 							val:        "macro",
 							ignoreCase: false,
+							want:       "\"macro\"",
 						},
 						&oneOrMoreExpr{
 							pos: position{line: 131, col: 25, offset: 2977},
@@ -335,6 +343,7 @@ var g = &grammar{
 							pos:        position{line: 131, col: 46, offset: 2998},
 							val:        "(",
 							ignoreCase: false,
+							want:       "\"(\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 131, col: 50, offset: 3002},
@@ -347,6 +356,7 @@ var g = &grammar{
 							pos:        position{line: 131, col: 54, offset: 3006},
 							val:        ")",
 							ignoreCase: false,
+							want:       "\")\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 131, col: 58, offset: 3010},
@@ -359,6 +369,7 @@ var g = &grammar{
 							pos:        position{line: 131, col: 62, offset: 3014},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 					},
 				},
@@ -377,6 +388,7 @@ var g = &grammar{
 							pos:        position{line: 139, col: 17, offset: 3131},
 							val:        "macro",
 							ignoreCase: false,
+							want:       "\"macro\"",
 						},
 						&oneOrMoreExpr{
 							pos: position{line: 139, col: 25, offset: 3139},
@@ -404,6 +416,7 @@ var g = &grammar{
 							pos:        position{line: 139, col: 46, offset: 3160},
 							val:        "(",
 							ignoreCase: false,
+							want:       "\"(\"",
 						},
 						&labeledExpr{
 							pos:   position{line: 139, col: 50, offset: 3164},
@@ -417,6 +430,7 @@ var g = &grammar{
 							pos:        position{line: 139, col: 64, offset: 3178},
 							val:        ")",
 							ignoreCase: false,
+							want:       "\")\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 139, col: 68, offset: 3182},
@@ -429,6 +443,7 @@ var g = &grammar{
 							pos:        position{line: 139, col: 72, offset: 3186},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 					},
 				},
@@ -474,6 +489,7 @@ var g = &grammar{
 								pos:        position{line: 148, col: 36, offset: 3355},
 								val:        ")",
 								ignoreCase: false,
+								want:       "\")\"",
 							},
 						},
 					},
@@ -527,6 +543,7 @@ var g = &grammar{
 									pos:        position{line: 163, col: 54, offset: 3540},
 									val:        ",",
 									ignoreCase: false,
+									want:       "\",\"",
 								},
 								&andExpr{
 									pos: position{line: 163, col: 60, offset: 3546},
@@ -534,6 +551,7 @@ var g = &grammar{
 										pos:        position{line: 163, col: 61, offset: 3547},
 										val:        ")",
 										ignoreCase: false,
+										want:       "\")\"",
 									},
 								},
 							},
@@ -557,12 +575,14 @@ var g = &grammar{
 								pos:        position{line: 171, col: 16, offset: 3603},
 								val:        "*",
 								ignoreCase: false,
+								want:       "\"*\"",
 							},
 						},
 						&litMatcher{
 							pos:        position{line: 171, col: 21, offset: 3608},
 							val:        "$",
 							ignoreCase: false,
+							want:       "\"$\"",
 						},
 						&choiceExpr{
 							pos: position{line: 171, col: 27, offset: 3614},
@@ -601,51 +621,90 @@ var g = &grammar{
 								},
 							},
 						},
+						&zeroOrOneExpr{
+							pos: position{line: 171, col: 53, offset: 3640},
+							expr: &seqExpr{
+								pos: position{line: 171, col: 55, offset: 3642},
+								exprs: []interface{}{
+									&zeroOrMoreExpr{
+										pos: position{line: 171, col: 55, offset: 3642},
+										expr: &ruleRefExpr{
+											pos:  position{line: 171, col: 55, offset: 3642},
+											name: "WS",
+										},
+									},
+									&litMatcher{
+										pos:        position{line: 171, col: 59, offset: 3646},
+										val:        ":",
+										ignoreCase: false,
+										want:       "\":\"",
+									},
+									&zeroOrMoreExpr{
+										pos: position{line: 171, col: 63, offset: 3650},
+										expr: &ruleRefExpr{
+											pos:  position{line: 171, col: 63, offset: 3650},
+											name: "WS",
+										},
+									},
+									&oneOrMoreExpr{
+										pos: position{line: 171, col: 67, offset: 3654},
+										expr: &charClassMatcher{
+											pos:        position{line: 171, col: 67, offset: 3654},
+											val:        "[a-z]",
+											ranges:     []rune{'a', 'z'},
+											ignoreCase: false,
+											inverted:   false,
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
 			name: "ParamDefault",
-			pos:  position{line: 187, col: 1, offset: 3827},
+			pos:  position{line: 193, col: 1, offset: 3995},
 			expr: &actionExpr{
-				pos: position{line: 187, col: 17, offset: 3843},
+				pos: position{line: 193, col: 17, offset: 4011},
 				run: (*parser).callonParamDefault1,
 				expr: &seqExpr{
-					pos: position{line: 187, col: 17, offset: 3843},
+					pos: position{line: 193, col: 17, offset: 4011},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 187, col: 17, offset: 3843},
+							pos:   position{line: 193, col: 17, offset: 4011},
 							label: "param",
 							expr: &ruleRefExpr{
-								pos:  position{line: 187, col: 23, offset: 3849},
+								pos:  position{line: 193, col: 23, offset: 4017},
 								name: "ParamSimple",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 187, col: 35, offset: 3861},
+							pos: position{line: 193, col: 35, offset: 4029},
 							expr: &ruleRefExpr{
-								pos:  position{line: 187, col: 35, offset: 3861},
+								pos:  position{line: 193, col: 35, offset: 4029},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 187, col: 39, offset: 3865},
+							pos:        position{line: 193, col: 39, offset: 4033},
 							val:        "=",
 							ignoreCase: false,
+							want:       "\"=\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 187, col: 43, offset: 3869},
+							pos: position{line: 193, col: 43, offset: 4037},
 							expr: &ruleRefExpr{
-								pos:  position{line: 187, col: 43, offset: 3869},
+								pos:  position{line: 193, col: 43, offset: 4037},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 187, col: 47, offset: 3873},
+							pos:   position{line: 193, col: 47, offset: 4041},
 							label: "def",
 							expr: &ruleRefExpr{
-								pos:  position{line: 187, col: 51, offset: 3877},
+								pos:  position{line: 193, col: 51, offset: 4045},
 								name: "Default",
 							},
 						},
@@ -655,23 +714,23 @@ var g = &grammar{
 		},
 		{
 			name: "Default",
-			pos:  position{line: 232, col: 1, offset: 4669},
+			pos:  position{line: 238, col: 1, offset: 4837},
 			expr: &labeledExpr{
-				pos:   position{line: 232, col: 12, offset: 4680},
+				pos:   position{line: 238, col: 12, offset: 4848},
 				label: "def",
 				expr: &choiceExpr{
-					pos: position{line: 232, col: 18, offset: 4686},
+					pos: position{line: 238, col: 18, offset: 4854},
 					alternatives: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 232, col: 18, offset: 4686},
+							pos:  position{line: 238, col: 18, offset: 4854},
 							name: "Default1",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 232, col: 29, offset: 4697},
+							pos:  position{line: 238, col: 29, offset: 4865},
 							name: "Default2",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 232, col: 40, offset: 4708},
+							pos:  position{line: 238, col: 40, offset: 4876},
 							name: "Default3",
 						},
 					},
@@ -680,22 +739,23 @@ var g = &grammar{
 		},
 		{
 			name: "Default1",
-			pos:  position{line: 234, col: 1, offset: 4720},
+			pos:  position{line: 240, col: 1, offset: 4888},
 			expr: &actionExpr{
-				pos: position{line: 234, col: 13, offset: 4732},
+				pos: position{line: 240, col: 13, offset: 4900},
 				run: (*parser).callonDefault11,
 				expr: &seqExpr{
-					pos: position{line: 234, col: 13, offset: 4732},
+					pos: position{line: 240, col: 13, offset: 4900},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 234, col: 13, offset: 4732},
+							pos:        position{line: 240, col: 13, offset: 4900},
 							val:        "⟦",
 							ignoreCase: false,
+							want:       "\"⟦\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 234, col: 17, offset: 4738},
+							pos: position{line: 240, col: 17, offset: 4906},
 							expr: &charClassMatcher{
-								pos:        position{line: 234, col: 17, offset: 4738},
+								pos:        position{line: 240, col: 17, offset: 4906},
 								val:        "[^⟦⟧]",
 								chars:      []rune{'⟦', '⟧'},
 								ignoreCase: false,
@@ -703,9 +763,10 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 234, col: 24, offset: 4749},
+							pos:        position{line: 240, col: 24, offset: 4917},
 							val:        "⟧",
 							ignoreCase: false,
+							want:       "\"⟧\"",
 						},
 					},
 				},
@@ -713,22 +774,23 @@ var g = &grammar{
 		},
 		{
 			name: "Default2",
-			pos:  position{line: 238, col: 1, offset: 4812},
+			pos:  position{line: 244, col: 1, offset: 4980},
 			expr: &actionExpr{
-				pos: position{line: 238, col: 14, offset: 4825},
+				pos: position{line: 244, col: 14, offset: 4993},
 				run: (*parser).callonDefault21,
 				expr: &seqExpr{
-					pos: position{line: 238, col: 14, offset: 4825},
+					pos: position{line: 244, col: 14, offset: 4993},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 238, col: 14, offset: 4825},
+							pos:        position{line: 244, col: 14, offset: 4993},
 							val:        "«",
 							ignoreCase: false,
+							want:       "\"«\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 238, col: 18, offset: 4830},
+							pos: position{line: 244, col: 18, offset: 4998},
 							expr: &charClassMatcher{
-								pos:        position{line: 238, col: 18, offset: 4830},
+								pos:        position{line: 244, col: 18, offset: 4998},
 								val:        "[^«»]",
 								chars:      []rune{'«', '»'},
 								ignoreCase: false,
@@ -736,9 +798,10 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 238, col: 25, offset: 4839},
+							pos:        position{line: 244, col: 25, offset: 5007},
 							val:        "»",
 							ignoreCase: false,
+							want:       "\"»\"",
 						},
 					},
 				},
@@ -746,24 +809,24 @@ var g = &grammar{
 		},
 		{
 			name: "Default3",
-			pos:  position{line: 242, col: 1, offset: 4899},
+			pos:  position{line: 248, col: 1, offset: 5067},
 			expr: &choiceExpr{
-				pos: position{line: 242, col: 13, offset: 4911},
+				pos: position{line: 248, col: 13, offset: 5079},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 242, col: 13, offset: 4911},
+						pos:  position{line: 248, col: 13, offset: 5079},
 						name: "D0",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 242, col: 18, offset: 4916},
+						pos:  position{line: 248, col: 18, offset: 5084},
 						name: "D1",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 242, col: 23, offset: 4921},
+						pos:  position{line: 248, col: 23, offset: 5089},
 						name: "D2",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 242, col: 28, offset: 4926},
+						pos:  position{line: 248, col: 28, offset: 5094},
 						name: "D3",
 					},
 				},
@@ -771,24 +834,24 @@ var g = &grammar{
 		},
 		{
 			name: "D0",
-			pos:  position{line: 244, col: 1, offset: 4931},
+			pos:  position{line: 250, col: 1, offset: 5099},
 			expr: &actionExpr{
-				pos: position{line: 244, col: 7, offset: 4937},
+				pos: position{line: 250, col: 7, offset: 5105},
 				run: (*parser).callonD01,
 				expr: &seqExpr{
-					pos: position{line: 244, col: 7, offset: 4937},
+					pos: position{line: 250, col: 7, offset: 5105},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 244, col: 7, offset: 4937},
+							pos: position{line: 250, col: 7, offset: 5105},
 							expr: &ruleRefExpr{
-								pos:  position{line: 244, col: 7, offset: 4937},
+								pos:  position{line: 250, col: 7, offset: 5105},
 								name: "WS",
 							},
 						},
 						&andExpr{
-							pos: position{line: 244, col: 11, offset: 4941},
+							pos: position{line: 250, col: 11, offset: 5109},
 							expr: &charClassMatcher{
-								pos:        position{line: 244, col: 12, offset: 4942},
+								pos:        position{line: 250, col: 12, offset: 5110},
 								val:        "[,)]",
 								chars:      []rune{',', ')'},
 								ignoreCase: false,
@@ -801,17 +864,17 @@ var g = &grammar{
 		},
 		{
 			name: "D1",
-			pos:  position{line: 248, col: 1, offset: 4968},
+			pos:  position{line: 254, col: 1, offset: 5136},
 			expr: &actionExpr{
-				pos: position{line: 248, col: 7, offset: 4974},
+				pos: position{line: 254, col: 7, offset: 5142},
 				run: (*parser).callonD11,
 				expr: &seqExpr{
-					pos: position{line: 248, col: 7, offset: 4974},
+					pos: position{line: 254, col: 7, offset: 5142},
 					exprs: []interface{}{
 						&oneOrMoreExpr{
-							pos: position{line: 248, col: 7, offset: 4974},
+							pos: position{line: 254, col: 7, offset: 5142},
 							expr: &charClassMatcher{
-								pos:        position{line: 248, col: 7, offset: 4974},
+								pos:        position{line: 254, col: 7, offset: 5142},
 								val:        "[^,()\"]",
 								chars:      []rune{',', '(', ')', '"'},
 								ignoreCase: false,
@@ -819,12 +882,12 @@ var g = &grammar{
 							},
 						},
 						&choiceExpr{
-							pos: position{line: 248, col: 17, offset: 4984},
+							pos: position{line: 254, col: 17, offset: 5152},
 							alternatives: []interface{}{
 								&andExpr{
-									pos: position{line: 248, col: 17, offset: 4984},
+									pos: position{line: 254, col: 17, offset: 5152},
 									expr: &charClassMatcher{
-										pos:        position{line: 248, col: 18, offset: 4985},
+										pos:        position{line: 254, col: 18, offset: 5153},
 										val:        "[,)]",
 										chars:      []rune{',', ')'},
 										ignoreCase: false,
@@ -832,7 +895,7 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 248, col: 26, offset: 4993},
+									pos:  position{line: 254, col: 26, offset: 5161},
 									name: "Default3",
 								},
 							},
@@ -843,24 +906,24 @@ var g = &grammar{
 		},
 		{
 			name: "D2",
-			pos:  position{line: 252, col: 1, offset: 5055},
+			pos:  position{line: 258, col: 1, offset: 5223},
 			expr: &actionExpr{
-				pos: position{line: 252, col: 7, offset: 5061},
+				pos: position{line: 258, col: 7, offset: 5229},
 				run: (*parser).callonD21,
 				expr: &seqExpr{
-					pos: position{line: 252, col: 7, offset: 5061},
+					pos: position{line: 258, col: 7, offset: 5229},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 252, col: 7, offset: 5061},
+							pos:  position{line: 258, col: 7, offset: 5229},
 							name: "String",
 						},
 						&choiceExpr{
-							pos: position{line: 252, col: 15, offset: 5069},
+							pos: position{line: 258, col: 15, offset: 5237},
 							alternatives: []interface{}{
 								&andExpr{
-									pos: position{line: 252, col: 15, offset: 5069},
+									pos: position{line: 258, col: 15, offset: 5237},
 									expr: &charClassMatcher{
-										pos:        position{line: 252, col: 16, offset: 5070},
+										pos:        position{line: 258, col: 16, offset: 5238},
 										val:        "[,)]",
 										chars:      []rune{',', ')'},
 										ignoreCase: false,
@@ -868,7 +931,7 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 252, col: 24, offset: 5078},
+									pos:  position{line: 258, col: 24, offset: 5246},
 									name: "Default3",
 								},
 							},
@@ -879,34 +942,36 @@ var g = &grammar{
 		},
 		{
 			name: "D3",
-			pos:  position{line: 256, col: 1, offset: 5140},
+			pos:  position{line: 262, col: 1, offset: 5308},
 			expr: &actionExpr{
-				pos: position{line: 256, col: 7, offset: 5146},
+				pos: position{line: 262, col: 7, offset: 5314},
 				run: (*parser).callonD31,
 				expr: &seqExpr{
-					pos: position{line: 256, col: 7, offset: 5146},
+					pos: position{line: 262, col: 7, offset: 5314},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 256, col: 7, offset: 5146},
+							pos:        position{line: 262, col: 7, offset: 5314},
 							val:        "(",
 							ignoreCase: false,
+							want:       "\"(\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 256, col: 11, offset: 5150},
+							pos:  position{line: 262, col: 11, offset: 5318},
 							name: "Expr",
 						},
 						&litMatcher{
-							pos:        position{line: 256, col: 16, offset: 5155},
+							pos:        position{line: 262, col: 16, offset: 5323},
 							val:        ")",
 							ignoreCase: false,
+							want:       "\")\"",
 						},
 						&choiceExpr{
-							pos: position{line: 256, col: 21, offset: 5160},
+							pos: position{line: 262, col: 21, offset: 5328},
 							alternatives: []interface{}{
 								&andExpr{
-									pos: position{line: 256, col: 21, offset: 5160},
+									pos: position{line: 262, col: 21, offset: 5328},
 									expr: &charClassMatcher{
-										pos:        position{line: 256, col: 22, offset: 5161},
+										pos:        position{line: 262, col: 22, offset: 5329},
 										val:        "[,)]",
 										chars:      []rune{',', ')'},
 										ignoreCase: false,
@@ -914,7 +979,7 @@ var g = &grammar{
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 256, col: 30, offset: 5169},
+									pos:  position{line: 262, col: 30, offset: 5337},
 									name: "Default3",
 								},
 							},
@@ -925,22 +990,23 @@ var g = &grammar{
 		},
 		{
 			name: "String",
-			pos:  position{line: 260, col: 1, offset: 5231},
+			pos:  position{line: 266, col: 1, offset: 5399},
 			expr: &actionExpr{
-				pos: position{line: 260, col: 12, offset: 5242},
+				pos: position{line: 266, col: 12, offset: 5410},
 				run: (*parser).callonString1,
 				expr: &seqExpr{
-					pos: position{line: 260, col: 12, offset: 5242},
+					pos: position{line: 266, col: 12, offset: 5410},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 260, col: 12, offset: 5242},
+							pos:        position{line: 266, col: 12, offset: 5410},
 							val:        "\"",
 							ignoreCase: false,
+							want:       "\"\\\"\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 260, col: 16, offset: 5246},
+							pos: position{line: 266, col: 16, offset: 5414},
 							expr: &charClassMatcher{
-								pos:        position{line: 260, col: 16, offset: 5246},
+								pos:        position{line: 266, col: 16, offset: 5414},
 								val:        "[^\"]",
 								chars:      []rune{'"'},
 								ignoreCase: false,
@@ -948,9 +1014,10 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 260, col: 22, offset: 5252},
+							pos:        position{line: 266, col: 22, offset: 5420},
 							val:        "\"",
 							ignoreCase: false,
+							want:       "\"\\\"\"",
 						},
 					},
 				},
@@ -958,23 +1025,23 @@ var g = &grammar{
 		},
 		{
 			name: "Expr",
-			pos:  position{line: 264, col: 1, offset: 5289},
+			pos:  position{line: 270, col: 1, offset: 5457},
 			expr: &choiceExpr{
-				pos: position{line: 264, col: 9, offset: 5297},
+				pos: position{line: 270, col: 9, offset: 5465},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 264, col: 9, offset: 5297},
+						pos:  position{line: 270, col: 9, offset: 5465},
 						name: "E0",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 264, col: 14, offset: 5302},
+						pos:  position{line: 270, col: 14, offset: 5470},
 						name: "E1",
 					},
 					&actionExpr{
-						pos: position{line: 264, col: 19, offset: 5307},
+						pos: position{line: 270, col: 19, offset: 5475},
 						run: (*parser).callonExpr4,
 						expr: &ruleRefExpr{
-							pos:  position{line: 264, col: 19, offset: 5307},
+							pos:  position{line: 270, col: 19, offset: 5475},
 							name: "E2",
 						},
 					},
@@ -983,14 +1050,14 @@ var g = &grammar{
 		},
 		{
 			name: "E0",
-			pos:  position{line: 268, col: 1, offset: 5343},
+			pos:  position{line: 274, col: 1, offset: 5511},
 			expr: &seqExpr{
-				pos: position{line: 268, col: 7, offset: 5349},
+				pos: position{line: 274, col: 7, offset: 5517},
 				exprs: []interface{}{
 					&zeroOrMoreExpr{
-						pos: position{line: 268, col: 7, offset: 5349},
+						pos: position{line: 274, col: 7, offset: 5517},
 						expr: &charClassMatcher{
-							pos:        position{line: 268, col: 7, offset: 5349},
+							pos:        position{line: 274, col: 7, offset: 5517},
 							val:        "[^()\"]",
 							chars:      []rune{'(', ')', '"'},
 							ignoreCase: false,
@@ -998,11 +1065,12 @@ var g = &grammar{
 						},
 					},
 					&andExpr{
-						pos: position{line: 268, col: 15, offset: 5357},
+						pos: position{line: 274, col: 15, offset: 5525},
 						expr: &litMatcher{
-							pos:        position{line: 268, col: 16, offset: 5358},
+							pos:        position{line: 274, col: 16, offset: 5526},
 							val:        ")",
 							ignoreCase: false,
+							want:       "\")\"",
 						},
 					},
 				},
@@ -1010,14 +1078,14 @@ var g = &grammar{
 		},
 		{
 			name: "E1",
-			pos:  position{line: 270, col: 1, offset: 5364},
+			pos:  position{line: 276, col: 1, offset: 5532},
 			expr: &seqExpr{
-				pos: position{line: 270, col: 7, offset: 5370},
+				pos: position{line: 276, col: 7, offset: 5538},
 				exprs: []interface{}{
 					&zeroOrMoreExpr{
-						pos: position{line: 270, col: 7, offset: 5370},
+						pos: position{line: 276, col: 7, offset: 5538},
 						expr: &charClassMatcher{
-							pos:        position{line: 270, col: 7, offset: 5370},
+							pos:        position{line: 276, col: 7, offset: 5538},
 							val:        "[^()\"]",
 							chars:      []rune{'(', ')', '"'},
 							ignoreCase: false,
@@ -1025,22 +1093,23 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 270, col: 15, offset: 5378},
+						pos:  position{line: 276, col: 15, offset: 5546},
 						name: "String",
 					},
 					&choiceExpr{
-						pos: position{line: 270, col: 23, offset: 5386},
+						pos: position{line: 276, col: 23, offset: 5554},
 						alternatives: []interface{}{
 							&andExpr{
-								pos: position{line: 270, col: 23, offset: 5386},
+								pos: position{line: 276, col: 23, offset: 5554},
 								expr: &litMatcher{
-									pos:        position{line: 270, col: 24, offset: 5387},
+									pos:        position{line: 276, col: 24, offset: 5555},
 									val:        ")",
 									ignoreCase: false,
+									want:       "\")\"",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 270, col: 30, offset: 5393},
+								pos:  position{line: 276, col: 30, offset: 5561},
 								name: "Expr",
 							},
 						},
@@ -1050,14 +1119,14 @@ var g = &grammar{
 		},
 		{
 			name: "E2",
-			pos:  position{line: 272, col: 1, offset: 5401},
+			pos:  position{line: 278, col: 1, offset: 5569},
 			expr: &seqExpr{
-				pos: position{line: 272, col: 7, offset: 5407},
+				pos: position{line: 278, col: 7, offset: 5575},
 				exprs: []interface{}{
 					&zeroOrMoreExpr{
-						pos: position{line: 272, col: 7, offset: 5407},
+						pos: position{line: 278, col: 7, offset: 5575},
 						expr: &charClassMatcher{
-							pos:        position{line: 272, col: 7, offset: 5407},
+							pos:        position{line: 278, col: 7, offset: 5575},
 							val:        "[^()\"]",
 							chars:      []rune{'(', ')', '"'},
 							ignoreCase: false,
@@ -1065,32 +1134,35 @@ var g = &grammar{
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 272, col: 15, offset: 5415},
+						pos:        position{line: 278, col: 15, offset: 5583},
 						val:        "(",
 						ignoreCase: false,
+						want:       "\"(\"",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 272, col: 19, offset: 5419},
+						pos:  position{line: 278, col: 19, offset: 5587},
 						name: "Expr",
 					},
 					&litMatcher{
-						pos:        position{line: 272, col: 24, offset: 5424},
+						pos:        position{line: 278, col: 24, offset: 5592},
 						val:        ")",
 						ignoreCase: false,
+						want:       "\")\"",
 					},
 					&choiceExpr{
-						pos: position{line: 272, col: 29, offset: 5429},
+						pos: position{line: 278, col: 29, offset: 5597},
 						alternatives: []interface{}{
 							&andExpr{
-								pos: position{line: 272, col: 29, offset: 5429},
+								pos: position{line: 278, col: 29, offset: 5597},
 								expr: &litMatcher{
-									pos:        position{line: 272, col: 30, offset: 5430},
+									pos:        position{line: 278, col: 30, offset: 5598},
 									val:        ")",
 									ignoreCase: false,
+									want:       "\")\"",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 272, col: 36, offset: 5436},
+								pos:  position{line: 278, col: 36, offset: 5604},
 								name: "Expr",
 							},
 						},
@@ -1100,27 +1172,27 @@ var g = &grammar{
 		},
 		{
 			name: "MacroID",
-			pos:  position{line: 277, col: 1, offset: 5447},
+			pos:  position{line: 283, col: 1, offset: 5615},
 			expr: &actionExpr{
-				pos: position{line: 277, col: 12, offset: 5458},
+				pos: position{line: 283, col: 12, offset: 5626},
 				run: (*parser).callonMacroID1,
 				expr: &labeledExpr{
-					pos:   position{line: 277, col: 12, offset: 5458},
+					pos:   position{line: 283, col: 12, offset: 5626},
 					label: "id",
 					expr: &seqExpr{
-						pos: position{line: 277, col: 16, offset: 5462},
+						pos: position{line: 283, col: 16, offset: 5630},
 						exprs: []interface{}{
 							&charClassMatcher{
-								pos:        position{line: 277, col: 16, offset: 5462},
+								pos:        position{line: 283, col: 16, offset: 5630},
 								val:        "[a-zA-Z]",
 								ranges:     []rune{'a', 'z', 'A', 'Z'},
 								ignoreCase: false,
 								inverted:   false,
 							},
 							&zeroOrMoreExpr{
-								pos: position{line: 277, col: 24, offset: 5470},
+								pos: position{line: 283, col: 24, offset: 5638},
 								expr: &charClassMatcher{
-									pos:        position{line: 277, col: 24, offset: 5470},
+									pos:        position{line: 283, col: 24, offset: 5638},
 									val:        "[a-zA-Z0-9]",
 									ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 									ignoreCase: false,
@@ -1134,39 +1206,41 @@ var g = &grammar{
 		},
 		{
 			name: "Synopsis",
-			pos:  position{line: 283, col: 1, offset: 5557},
+			pos:  position{line: 289, col: 1, offset: 5725},
 			expr: &actionExpr{
-				pos: position{line: 283, col: 13, offset: 5569},
+				pos: position{line: 289, col: 13, offset: 5737},
 				run: (*parser).callonSynopsis1,
 				expr: &seqExpr{
-					pos: position{line: 283, col: 13, offset: 5569},
+					pos: position{line: 289, col: 13, offset: 5737},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 283, col: 13, offset: 5569},
+							pos: position{line: 289, col: 13, offset: 5737},
 							expr: &ruleRefExpr{
-								pos:  position{line: 283, col: 13, offset: 5569},
+								pos:  position{line: 289, col: 13, offset: 5737},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 283, col: 17, offset: 5573},
+							pos:        position{line: 289, col: 17, offset: 5741},
 							val:        "$synopsis",
 							ignoreCase: false,
+							want:       "\"$synopsis\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 283, col: 29, offset: 5585},
+							pos: position{line: 289, col: 29, offset: 5753},
 							expr: &ruleRefExpr{
-								pos:  position{line: 283, col: 29, offset: 5585},
+								pos:  position{line: 289, col: 29, offset: 5753},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 283, col: 33, offset: 5589},
+							pos:        position{line: 289, col: 33, offset: 5757},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 283, col: 37, offset: 5593},
+							pos:  position{line: 289, col: 37, offset: 5761},
 							name: "Info",
 						},
 					},
@@ -1175,28 +1249,28 @@ var g = &grammar{
 		},
 		{
 			name: "Vars",
-			pos:  position{line: 289, col: 1, offset: 5658},
+			pos:  position{line: 295, col: 1, offset: 5826},
 			expr: &actionExpr{
-				pos: position{line: 289, col: 9, offset: 5666},
+				pos: position{line: 295, col: 9, offset: 5834},
 				run: (*parser).callonVars1,
 				expr: &seqExpr{
-					pos: position{line: 289, col: 9, offset: 5666},
+					pos: position{line: 295, col: 9, offset: 5834},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 289, col: 9, offset: 5666},
+							pos:   position{line: 295, col: 9, offset: 5834},
 							label: "vars",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 289, col: 15, offset: 5672},
+								pos: position{line: 295, col: 15, offset: 5840},
 								expr: &ruleRefExpr{
-									pos:  position{line: 289, col: 15, offset: 5672},
+									pos:  position{line: 295, col: 15, offset: 5840},
 									name: "Var",
 								},
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 289, col: 21, offset: 5678},
+							pos: position{line: 295, col: 21, offset: 5846},
 							expr: &ruleRefExpr{
-								pos:  position{line: 289, col: 21, offset: 5678},
+								pos:  position{line: 295, col: 21, offset: 5846},
 								name: "WS",
 							},
 						},
@@ -1206,35 +1280,35 @@ var g = &grammar{
 		},
 		{
 			name: "Examples",
-			pos:  position{line: 298, col: 1, offset: 5847},
+			pos:  position{line: 304, col: 1, offset: 6015},
 			expr: &actionExpr{
-				pos: position{line: 298, col: 13, offset: 5859},
+				pos: position{line: 304, col: 13, offset: 6027},
 				run: (*parser).callonExamples1,
 				expr: &seqExpr{
-					pos: position{line: 298, col: 13, offset: 5859},
+					pos: position{line: 304, col: 13, offset: 6027},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 298, col: 13, offset: 5859},
+							pos: position{line: 304, col: 13, offset: 6027},
 							expr: &ruleRefExpr{
-								pos:  position{line: 298, col: 13, offset: 5859},
+								pos:  position{line: 304, col: 13, offset: 6027},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 298, col: 17, offset: 5863},
+							pos:   position{line: 304, col: 17, offset: 6031},
 							label: "examples",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 298, col: 27, offset: 5873},
+								pos: position{line: 304, col: 27, offset: 6041},
 								expr: &ruleRefExpr{
-									pos:  position{line: 298, col: 27, offset: 5873},
+									pos:  position{line: 304, col: 27, offset: 6041},
 									name: "Example",
 								},
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 298, col: 37, offset: 5883},
+							pos: position{line: 304, col: 37, offset: 6051},
 							expr: &ruleRefExpr{
-								pos:  position{line: 298, col: 37, offset: 5883},
+								pos:  position{line: 304, col: 37, offset: 6051},
 								name: "WS",
 							},
 						},
@@ -1244,72 +1318,76 @@ var g = &grammar{
 		},
 		{
 			name: "Var",
-			pos:  position{line: 315, col: 1, offset: 6222},
+			pos:  position{line: 321, col: 1, offset: 6390},
 			expr: &actionExpr{
-				pos: position{line: 315, col: 8, offset: 6229},
+				pos: position{line: 321, col: 8, offset: 6397},
 				run: (*parser).callonVar1,
 				expr: &seqExpr{
-					pos: position{line: 315, col: 8, offset: 6229},
+					pos: position{line: 321, col: 8, offset: 6397},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 315, col: 8, offset: 6229},
+							pos: position{line: 321, col: 8, offset: 6397},
 							expr: &ruleRefExpr{
-								pos:  position{line: 315, col: 8, offset: 6229},
+								pos:  position{line: 321, col: 8, offset: 6397},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 315, col: 12, offset: 6233},
+							pos:        position{line: 321, col: 12, offset: 6401},
 							val:        "$",
 							ignoreCase: false,
+							want:       "\"$\"",
 						},
 						&notExpr{
-							pos: position{line: 315, col: 16, offset: 6237},
+							pos: position{line: 321, col: 16, offset: 6405},
 							expr: &seqExpr{
-								pos: position{line: 315, col: 18, offset: 6239},
+								pos: position{line: 321, col: 18, offset: 6407},
 								exprs: []interface{}{
 									&litMatcher{
-										pos:        position{line: 315, col: 18, offset: 6239},
+										pos:        position{line: 321, col: 18, offset: 6407},
 										val:        "example",
 										ignoreCase: false,
+										want:       "\"example\"",
 									},
 									&zeroOrMoreExpr{
-										pos: position{line: 315, col: 28, offset: 6249},
+										pos: position{line: 321, col: 28, offset: 6417},
 										expr: &ruleRefExpr{
-											pos:  position{line: 315, col: 28, offset: 6249},
+											pos:  position{line: 321, col: 28, offset: 6417},
 											name: "WS",
 										},
 									},
 									&litMatcher{
-										pos:        position{line: 315, col: 32, offset: 6253},
+										pos:        position{line: 321, col: 32, offset: 6421},
 										val:        ":",
 										ignoreCase: false,
+										want:       "\":\"",
 									},
 								},
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 315, col: 37, offset: 6258},
+							pos:   position{line: 321, col: 37, offset: 6426},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 315, col: 42, offset: 6263},
+								pos:  position{line: 321, col: 42, offset: 6431},
 								name: "Name",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 315, col: 47, offset: 6268},
+							pos: position{line: 321, col: 47, offset: 6436},
 							expr: &ruleRefExpr{
-								pos:  position{line: 315, col: 47, offset: 6268},
+								pos:  position{line: 321, col: 47, offset: 6436},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 315, col: 51, offset: 6272},
+							pos:        position{line: 321, col: 51, offset: 6440},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 315, col: 55, offset: 6276},
+							pos:  position{line: 321, col: 55, offset: 6444},
 							name: "Info",
 						},
 					},
@@ -1318,46 +1396,48 @@ var g = &grammar{
 		},
 		{
 			name: "Example",
-			pos:  position{line: 319, col: 1, offset: 6374},
+			pos:  position{line: 325, col: 1, offset: 6542},
 			expr: &actionExpr{
-				pos: position{line: 319, col: 12, offset: 6385},
+				pos: position{line: 325, col: 12, offset: 6553},
 				run: (*parser).callonExample1,
 				expr: &seqExpr{
-					pos: position{line: 319, col: 12, offset: 6385},
+					pos: position{line: 325, col: 12, offset: 6553},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 319, col: 12, offset: 6385},
+							pos: position{line: 325, col: 12, offset: 6553},
 							expr: &ruleRefExpr{
-								pos:  position{line: 319, col: 12, offset: 6385},
+								pos:  position{line: 325, col: 12, offset: 6553},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 319, col: 16, offset: 6389},
+							pos:        position{line: 325, col: 16, offset: 6557},
 							val:        "$example",
 							ignoreCase: false,
+							want:       "\"$example\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 319, col: 27, offset: 6400},
+							pos: position{line: 325, col: 27, offset: 6568},
 							expr: &ruleRefExpr{
-								pos:  position{line: 319, col: 27, offset: 6400},
+								pos:  position{line: 325, col: 27, offset: 6568},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 319, col: 31, offset: 6404},
+							pos:        position{line: 325, col: 31, offset: 6572},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 319, col: 35, offset: 6408},
+							pos: position{line: 325, col: 35, offset: 6576},
 							expr: &ruleRefExpr{
-								pos:  position{line: 319, col: 35, offset: 6408},
+								pos:  position{line: 325, col: 35, offset: 6576},
 								name: "WS",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 319, col: 39, offset: 6412},
+							pos:  position{line: 325, col: 39, offset: 6580},
 							name: "Info",
 						},
 					},
@@ -1366,14 +1446,14 @@ var g = &grammar{
 		},
 		{
 			name: "Name",
-			pos:  position{line: 324, col: 1, offset: 6477},
+			pos:  position{line: 330, col: 1, offset: 6645},
 			expr: &actionExpr{
-				pos: position{line: 324, col: 9, offset: 6485},
+				pos: position{line: 330, col: 9, offset: 6653},
 				run: (*parser).callonName1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 324, col: 9, offset: 6485},
+					pos: position{line: 330, col: 9, offset: 6653},
 					expr: &charClassMatcher{
-						pos:        position{line: 324, col: 9, offset: 6485},
+						pos:        position{line: 330, col: 9, offset: 6653},
 						val:        "[0-9a-z]",
 						ranges:     []rune{'0', '9', 'a', 'z'},
 						ignoreCase: false,
@@ -1384,14 +1464,14 @@ var g = &grammar{
 		},
 		{
 			name: "Info",
-			pos:  position{line: 328, col: 1, offset: 6531},
+			pos:  position{line: 334, col: 1, offset: 6699},
 			expr: &actionExpr{
-				pos: position{line: 328, col: 9, offset: 6539},
+				pos: position{line: 334, col: 9, offset: 6707},
 				run: (*parser).callonInfo1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 328, col: 9, offset: 6539},
+					pos: position{line: 334, col: 9, offset: 6707},
 					expr: &ruleRefExpr{
-						pos:  position{line: 328, col: 9, offset: 6539},
+						pos:  position{line: 334, col: 9, offset: 6707},
 						name: "Line",
 					},
 				},
@@ -1399,42 +1479,44 @@ var g = &grammar{
 		},
 		{
 			name: "Line",
-			pos:  position{line: 333, col: 1, offset: 6580},
+			pos:  position{line: 339, col: 1, offset: 6748},
 			expr: &seqExpr{
-				pos: position{line: 333, col: 9, offset: 6588},
+				pos: position{line: 339, col: 9, offset: 6756},
 				exprs: []interface{}{
 					&seqExpr{
-						pos: position{line: 333, col: 10, offset: 6589},
+						pos: position{line: 339, col: 10, offset: 6757},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 333, col: 10, offset: 6589},
+								pos:        position{line: 339, col: 10, offset: 6757},
 								val:        "",
 								ignoreCase: false,
+								want:       "\"\"",
 							},
 							&notExpr{
-								pos: position{line: 333, col: 13, offset: 6592},
+								pos: position{line: 339, col: 13, offset: 6760},
 								expr: &choiceExpr{
-									pos: position{line: 333, col: 15, offset: 6594},
+									pos: position{line: 339, col: 15, offset: 6762},
 									alternatives: []interface{}{
 										&seqExpr{
-											pos: position{line: 333, col: 16, offset: 6595},
+											pos: position{line: 339, col: 16, offset: 6763},
 											exprs: []interface{}{
 												&zeroOrMoreExpr{
-													pos: position{line: 333, col: 16, offset: 6595},
+													pos: position{line: 339, col: 16, offset: 6763},
 													expr: &ruleRefExpr{
-														pos:  position{line: 333, col: 16, offset: 6595},
+														pos:  position{line: 339, col: 16, offset: 6763},
 														name: "WS",
 													},
 												},
 												&litMatcher{
-													pos:        position{line: 333, col: 20, offset: 6599},
+													pos:        position{line: 339, col: 20, offset: 6767},
 													val:        "$",
 													ignoreCase: false,
+													want:       "\"$\"",
 												},
 												&oneOrMoreExpr{
-													pos: position{line: 333, col: 24, offset: 6603},
+													pos: position{line: 339, col: 24, offset: 6771},
 													expr: &charClassMatcher{
-														pos:        position{line: 333, col: 24, offset: 6603},
+														pos:        position{line: 339, col: 24, offset: 6771},
 														val:        "[0-9a-z]",
 														ranges:     []rune{'0', '9', 'a', 'z'},
 														ignoreCase: false,
@@ -1444,21 +1526,21 @@ var g = &grammar{
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 333, col: 37, offset: 6616},
+											pos:  position{line: 339, col: 37, offset: 6784},
 											name: "EOF",
 										},
 										&seqExpr{
-											pos: position{line: 333, col: 44, offset: 6623},
+											pos: position{line: 339, col: 44, offset: 6791},
 											exprs: []interface{}{
 												&zeroOrMoreExpr{
-													pos: position{line: 333, col: 44, offset: 6623},
+													pos: position{line: 339, col: 44, offset: 6791},
 													expr: &ruleRefExpr{
-														pos:  position{line: 333, col: 44, offset: 6623},
+														pos:  position{line: 339, col: 44, offset: 6791},
 														name: "WS",
 													},
 												},
 												&ruleRefExpr{
-													pos:  position{line: 333, col: 48, offset: 6627},
+													pos:  position{line: 339, col: 48, offset: 6795},
 													name: "DELIM1",
 												},
 											},
@@ -1469,9 +1551,9 @@ var g = &grammar{
 						},
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 333, col: 58, offset: 6637},
+						pos: position{line: 339, col: 58, offset: 6805},
 						expr: &charClassMatcher{
-							pos:        position{line: 333, col: 58, offset: 6637},
+							pos:        position{line: 339, col: 58, offset: 6805},
 							val:        "[^\\n\\r]",
 							chars:      []rune{'\n', '\r'},
 							ignoreCase: false,
@@ -1479,7 +1561,7 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 333, col: 67, offset: 6646},
+						pos:  position{line: 339, col: 67, offset: 6814},
 						name: "EOL",
 					},
 				},
@@ -1487,27 +1569,27 @@ var g = &grammar{
 		},
 		{
 			name: "Actions",
-			pos:  position{line: 336, col: 1, offset: 6653},
+			pos:  position{line: 342, col: 1, offset: 6821},
 			expr: &actionExpr{
-				pos: position{line: 336, col: 12, offset: 6664},
+				pos: position{line: 342, col: 12, offset: 6832},
 				run: (*parser).callonActions1,
 				expr: &seqExpr{
-					pos: position{line: 336, col: 12, offset: 6664},
+					pos: position{line: 342, col: 12, offset: 6832},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 336, col: 12, offset: 6664},
+							pos: position{line: 342, col: 12, offset: 6832},
 							expr: &ruleRefExpr{
-								pos:  position{line: 336, col: 12, offset: 6664},
+								pos:  position{line: 342, col: 12, offset: 6832},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 336, col: 16, offset: 6668},
+							pos:   position{line: 342, col: 16, offset: 6836},
 							label: "actions",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 336, col: 25, offset: 6677},
+								pos: position{line: 342, col: 25, offset: 6845},
 								expr: &ruleRefExpr{
-									pos:  position{line: 336, col: 25, offset: 6677},
+									pos:  position{line: 342, col: 25, offset: 6845},
 									name: "Action",
 								},
 							},
@@ -1518,20 +1600,20 @@ var g = &grammar{
 		},
 		{
 			name: "Action",
-			pos:  position{line: 349, col: 1, offset: 6919},
+			pos:  position{line: 355, col: 1, offset: 7087},
 			expr: &choiceExpr{
-				pos: position{line: 349, col: 11, offset: 6929},
+				pos: position{line: 355, col: 11, offset: 7097},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 349, col: 11, offset: 6929},
+						pos:  position{line: 355, col: 11, offset: 7097},
 						name: "Action3",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 349, col: 21, offset: 6939},
+						pos:  position{line: 355, col: 21, offset: 7107},
 						name: "Action2",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 349, col: 31, offset: 6949},
+						pos:  position{line: 355, col: 31, offset: 7117},
 						name: "Action1",
 					},
 				},
@@ -1539,34 +1621,35 @@ var g = &grammar{
 		},
 		{
 			name: "Action1",
-			pos:  position{line: 352, col: 1, offset: 6959},
+			pos:  position{line: 358, col: 1, offset: 7127},
 			expr: &actionExpr{
-				pos: position{line: 352, col: 12, offset: 6970},
+				pos: position{line: 358, col: 12, offset: 7138},
 				run: (*parser).callonAction11,
 				expr: &seqExpr{
-					pos: position{line: 352, col: 12, offset: 6970},
+					pos: position{line: 358, col: 12, offset: 7138},
 					exprs: []interface{}{
 						&notExpr{
-							pos: position{line: 352, col: 12, offset: 6970},
+							pos: position{line: 358, col: 12, offset: 7138},
 							expr: &choiceExpr{
-								pos: position{line: 352, col: 14, offset: 6972},
+								pos: position{line: 358, col: 14, offset: 7140},
 								alternatives: []interface{}{
 									&litMatcher{
-										pos:        position{line: 352, col: 14, offset: 6972},
+										pos:        position{line: 358, col: 14, offset: 7140},
 										val:        "macro",
 										ignoreCase: false,
+										want:       "\"macro\"",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 352, col: 24, offset: 6982},
+										pos:  position{line: 358, col: 24, offset: 7150},
 										name: "EOF",
 									},
 								},
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 352, col: 29, offset: 6987},
+							pos: position{line: 358, col: 29, offset: 7155},
 							expr: &charClassMatcher{
-								pos:        position{line: 352, col: 29, offset: 6987},
+								pos:        position{line: 358, col: 29, offset: 7155},
 								val:        "[^\\n\\r]",
 								chars:      []rune{'\n', '\r'},
 								ignoreCase: false,
@@ -1574,28 +1657,29 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 352, col: 38, offset: 6996},
+							pos:  position{line: 358, col: 38, offset: 7164},
 							name: "EOL",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 352, col: 42, offset: 7000},
+							pos: position{line: 358, col: 42, offset: 7168},
 							expr: &ruleRefExpr{
-								pos:  position{line: 352, col: 42, offset: 7000},
+								pos:  position{line: 358, col: 42, offset: 7168},
 								name: "WS",
 							},
 						},
 						&andExpr{
-							pos: position{line: 352, col: 46, offset: 7004},
+							pos: position{line: 358, col: 46, offset: 7172},
 							expr: &choiceExpr{
-								pos: position{line: 352, col: 48, offset: 7006},
+								pos: position{line: 358, col: 48, offset: 7174},
 								alternatives: []interface{}{
 									&litMatcher{
-										pos:        position{line: 352, col: 48, offset: 7006},
+										pos:        position{line: 358, col: 48, offset: 7174},
 										val:        "macro",
 										ignoreCase: false,
+										want:       "\"macro\"",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 352, col: 58, offset: 7016},
+										pos:  position{line: 358, col: 58, offset: 7184},
 										name: "EOF",
 									},
 								},
@@ -1607,36 +1691,37 @@ var g = &grammar{
 		},
 		{
 			name: "Action2",
-			pos:  position{line: 368, col: 1, offset: 7356},
+			pos:  position{line: 374, col: 1, offset: 7524},
 			expr: &actionExpr{
-				pos: position{line: 368, col: 12, offset: 7367},
+				pos: position{line: 374, col: 12, offset: 7535},
 				run: (*parser).callonAction21,
 				expr: &seqExpr{
-					pos: position{line: 368, col: 12, offset: 7367},
+					pos: position{line: 374, col: 12, offset: 7535},
 					exprs: []interface{}{
 						&ruleRefExpr{
-							pos:  position{line: 368, col: 12, offset: 7367},
+							pos:  position{line: 374, col: 12, offset: 7535},
 							name: "Bracket",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 368, col: 21, offset: 7376},
+							pos: position{line: 374, col: 21, offset: 7544},
 							expr: &ruleRefExpr{
-								pos:  position{line: 368, col: 21, offset: 7376},
+								pos:  position{line: 374, col: 21, offset: 7544},
 								name: "WS",
 							},
 						},
 						&andExpr{
-							pos: position{line: 368, col: 25, offset: 7380},
+							pos: position{line: 374, col: 25, offset: 7548},
 							expr: &choiceExpr{
-								pos: position{line: 368, col: 27, offset: 7382},
+								pos: position{line: 374, col: 27, offset: 7550},
 								alternatives: []interface{}{
 									&litMatcher{
-										pos:        position{line: 368, col: 27, offset: 7382},
+										pos:        position{line: 374, col: 27, offset: 7550},
 										val:        "macro",
 										ignoreCase: false,
+										want:       "\"macro\"",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 368, col: 37, offset: 7392},
+										pos:  position{line: 374, col: 37, offset: 7560},
 										name: "EOF",
 									},
 								},
@@ -1648,66 +1733,68 @@ var g = &grammar{
 		},
 		{
 			name: "Action3",
-			pos:  position{line: 377, col: 1, offset: 7544},
+			pos:  position{line: 383, col: 1, offset: 7712},
 			expr: &actionExpr{
-				pos: position{line: 377, col: 12, offset: 7555},
+				pos: position{line: 383, col: 12, offset: 7723},
 				run: (*parser).callonAction31,
 				expr: &seqExpr{
-					pos: position{line: 377, col: 12, offset: 7555},
+					pos: position{line: 383, col: 12, offset: 7723},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 377, col: 12, offset: 7555},
+							pos:   position{line: 383, col: 12, offset: 7723},
 							label: "exe",
 							expr: &ruleRefExpr{
-								pos:  position{line: 377, col: 16, offset: 7559},
+								pos:  position{line: 383, col: 16, offset: 7727},
 								name: "Bracket",
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 377, col: 25, offset: 7568},
+							pos: position{line: 383, col: 25, offset: 7736},
 							expr: &ruleRefExpr{
-								pos:  position{line: 377, col: 25, offset: 7568},
+								pos:  position{line: 383, col: 25, offset: 7736},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 377, col: 29, offset: 7572},
+							pos:   position{line: 383, col: 29, offset: 7740},
 							label: "how",
 							expr: &choiceExpr{
-								pos: position{line: 377, col: 34, offset: 7577},
+								pos: position{line: 383, col: 34, offset: 7745},
 								alternatives: []interface{}{
 									&litMatcher{
-										pos:        position{line: 377, col: 34, offset: 7577},
+										pos:        position{line: 383, col: 34, offset: 7745},
 										val:        "if",
 										ignoreCase: false,
+										want:       "\"if\"",
 									},
 									&litMatcher{
-										pos:        position{line: 377, col: 41, offset: 7584},
+										pos:        position{line: 383, col: 41, offset: 7752},
 										val:        "unless",
 										ignoreCase: false,
+										want:       "\"unless\"",
 									},
 								},
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 377, col: 51, offset: 7594},
+							pos: position{line: 383, col: 51, offset: 7762},
 							expr: &ruleRefExpr{
-								pos:  position{line: 377, col: 51, offset: 7594},
+								pos:  position{line: 383, col: 51, offset: 7762},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 377, col: 55, offset: 7598},
+							pos:   position{line: 383, col: 55, offset: 7766},
 							label: "guard",
 							expr: &ruleRefExpr{
-								pos:  position{line: 377, col: 61, offset: 7604},
+								pos:  position{line: 383, col: 61, offset: 7772},
 								name: "GClause",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 377, col: 69, offset: 7612},
+							pos: position{line: 383, col: 69, offset: 7780},
 							expr: &ruleRefExpr{
-								pos:  position{line: 377, col: 69, offset: 7612},
+								pos:  position{line: 383, col: 69, offset: 7780},
 								name: "WS",
 							},
 						},
@@ -1717,25 +1804,26 @@ var g = &grammar{
 		},
 		{
 			name: "Bracket",
-			pos:  position{line: 386, col: 1, offset: 7795},
+			pos:  position{line: 392, col: 1, offset: 7963},
 			expr: &actionExpr{
-				pos: position{line: 386, col: 12, offset: 7806},
+				pos: position{line: 392, col: 12, offset: 7974},
 				run: (*parser).callonBracket1,
 				expr: &choiceExpr{
-					pos: position{line: 386, col: 13, offset: 7807},
+					pos: position{line: 392, col: 13, offset: 7975},
 					alternatives: []interface{}{
 						&seqExpr{
-							pos: position{line: 386, col: 14, offset: 7808},
+							pos: position{line: 392, col: 14, offset: 7976},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 386, col: 14, offset: 7808},
+									pos:        position{line: 392, col: 14, offset: 7976},
 									val:        "«",
 									ignoreCase: false,
+									want:       "\"«\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 386, col: 18, offset: 7813},
+									pos: position{line: 392, col: 18, offset: 7981},
 									expr: &charClassMatcher{
-										pos:        position{line: 386, col: 18, offset: 7813},
+										pos:        position{line: 392, col: 18, offset: 7981},
 										val:        "[^«»]",
 										chars:      []rune{'«', '»'},
 										ignoreCase: false,
@@ -1743,24 +1831,26 @@ var g = &grammar{
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 386, col: 25, offset: 7822},
+									pos:        position{line: 392, col: 25, offset: 7990},
 									val:        "»",
 									ignoreCase: false,
+									want:       "\"»\"",
 								},
 							},
 						},
 						&seqExpr{
-							pos: position{line: 386, col: 33, offset: 7831},
+							pos: position{line: 392, col: 33, offset: 7999},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 386, col: 33, offset: 7831},
+									pos:        position{line: 392, col: 33, offset: 7999},
 									val:        "⟦",
 									ignoreCase: false,
+									want:       "\"⟦\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 386, col: 37, offset: 7837},
+									pos: position{line: 392, col: 37, offset: 8005},
 									expr: &charClassMatcher{
-										pos:        position{line: 386, col: 37, offset: 7837},
+										pos:        position{line: 392, col: 37, offset: 8005},
 										val:        "[^⟦⟧]",
 										chars:      []rune{'⟦', '⟧'},
 										ignoreCase: false,
@@ -1768,9 +1858,10 @@ var g = &grammar{
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 386, col: 44, offset: 7848},
+									pos:        position{line: 392, col: 44, offset: 8016},
 									val:        "⟧",
 									ignoreCase: false,
+									want:       "\"⟧\"",
 								},
 							},
 						},
@@ -1780,16 +1871,16 @@ var g = &grammar{
 		},
 		{
 			name: "GClause",
-			pos:  position{line: 393, col: 1, offset: 7925},
+			pos:  position{line: 399, col: 1, offset: 8093},
 			expr: &choiceExpr{
-				pos: position{line: 393, col: 12, offset: 7936},
+				pos: position{line: 399, col: 12, offset: 8104},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 393, col: 12, offset: 7936},
+						pos:  position{line: 399, col: 12, offset: 8104},
 						name: "GClause1",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 393, col: 23, offset: 7947},
+						pos:  position{line: 399, col: 23, offset: 8115},
 						name: "GClause2",
 					},
 				},
@@ -1797,44 +1888,46 @@ var g = &grammar{
 		},
 		{
 			name: "GClause1",
-			pos:  position{line: 395, col: 1, offset: 7957},
+			pos:  position{line: 401, col: 1, offset: 8125},
 			expr: &actionExpr{
-				pos: position{line: 395, col: 13, offset: 7969},
+				pos: position{line: 401, col: 13, offset: 8137},
 				run: (*parser).callonGClause11,
 				expr: &seqExpr{
-					pos: position{line: 395, col: 13, offset: 7969},
+					pos: position{line: 401, col: 13, offset: 8137},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 395, col: 13, offset: 7969},
+							pos:        position{line: 401, col: 13, offset: 8137},
 							val:        "«",
 							ignoreCase: false,
+							want:       "\"«\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 395, col: 17, offset: 7974},
+							pos: position{line: 401, col: 17, offset: 8142},
 							expr: &ruleRefExpr{
-								pos:  position{line: 395, col: 17, offset: 7974},
+								pos:  position{line: 401, col: 17, offset: 8142},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 395, col: 21, offset: 7978},
+							pos:   position{line: 401, col: 21, offset: 8146},
 							label: "guard",
 							expr: &ruleRefExpr{
-								pos:  position{line: 395, col: 27, offset: 7984},
+								pos:  position{line: 401, col: 27, offset: 8152},
 								name: "Guard",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 395, col: 33, offset: 7990},
+							pos: position{line: 401, col: 33, offset: 8158},
 							expr: &ruleRefExpr{
-								pos:  position{line: 395, col: 33, offset: 7990},
+								pos:  position{line: 401, col: 33, offset: 8158},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 395, col: 37, offset: 7994},
+							pos:        position{line: 401, col: 37, offset: 8162},
 							val:        "»",
 							ignoreCase: false,
+							want:       "\"»\"",
 						},
 					},
 				},
@@ -1842,44 +1935,46 @@ var g = &grammar{
 		},
 		{
 			name: "GClause2",
-			pos:  position{line: 399, col: 1, offset: 8041},
+			pos:  position{line: 405, col: 1, offset: 8209},
 			expr: &actionExpr{
-				pos: position{line: 399, col: 13, offset: 8053},
+				pos: position{line: 405, col: 13, offset: 8221},
 				run: (*parser).callonGClause21,
 				expr: &seqExpr{
-					pos: position{line: 399, col: 13, offset: 8053},
+					pos: position{line: 405, col: 13, offset: 8221},
 					exprs: []interface{}{
 						&litMatcher{
-							pos:        position{line: 399, col: 13, offset: 8053},
+							pos:        position{line: 405, col: 13, offset: 8221},
 							val:        "⟦",
 							ignoreCase: false,
+							want:       "\"⟦\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 399, col: 17, offset: 8059},
+							pos: position{line: 405, col: 17, offset: 8227},
 							expr: &ruleRefExpr{
-								pos:  position{line: 399, col: 17, offset: 8059},
+								pos:  position{line: 405, col: 17, offset: 8227},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 399, col: 21, offset: 8063},
+							pos:   position{line: 405, col: 21, offset: 8231},
 							label: "guard",
 							expr: &ruleRefExpr{
-								pos:  position{line: 399, col: 27, offset: 8069},
+								pos:  position{line: 405, col: 27, offset: 8237},
 								name: "Guard",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 399, col: 33, offset: 8075},
+							pos: position{line: 405, col: 33, offset: 8243},
 							expr: &ruleRefExpr{
-								pos:  position{line: 399, col: 33, offset: 8075},
+								pos:  position{line: 405, col: 33, offset: 8243},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 399, col: 37, offset: 8079},
+							pos:        position{line: 405, col: 37, offset: 8247},
 							val:        "⟧",
 							ignoreCase: false,
+							want:       "\"⟧\"",
 						},
 					},
 				},
@@ -1887,25 +1982,25 @@ var g = &grammar{
 		},
 		{
 			name: "Guard",
-			pos:  position{line: 405, col: 1, offset: 8129},
+			pos:  position{line: 411, col: 1, offset: 8297},
 			expr: &actionExpr{
-				pos: position{line: 405, col: 10, offset: 8138},
+				pos: position{line: 411, col: 10, offset: 8306},
 				run: (*parser).callonGuard1,
 				expr: &seqExpr{
-					pos: position{line: 405, col: 10, offset: 8138},
+					pos: position{line: 411, col: 10, offset: 8306},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 405, col: 10, offset: 8138},
+							pos: position{line: 411, col: 10, offset: 8306},
 							expr: &ruleRefExpr{
-								pos:  position{line: 405, col: 10, offset: 8138},
+								pos:  position{line: 411, col: 10, offset: 8306},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 405, col: 14, offset: 8142},
+							pos:   position{line: 411, col: 14, offset: 8310},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 405, col: 19, offset: 8147},
+								pos:  position{line: 411, col: 19, offset: 8315},
 								name: "GExpr",
 							},
 						},
@@ -1915,48 +2010,49 @@ var g = &grammar{
 		},
 		{
 			name: "GExpr",
-			pos:  position{line: 409, col: 1, offset: 8190},
+			pos:  position{line: 415, col: 1, offset: 8358},
 			expr: &choiceExpr{
-				pos: position{line: 409, col: 10, offset: 8199},
+				pos: position{line: 415, col: 10, offset: 8367},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 409, col: 10, offset: 8199},
+						pos: position{line: 415, col: 10, offset: 8367},
 						run: (*parser).callonGExpr2,
 						expr: &seqExpr{
-							pos: position{line: 409, col: 10, offset: 8199},
+							pos: position{line: 415, col: 10, offset: 8367},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 409, col: 10, offset: 8199},
+									pos:   position{line: 415, col: 10, offset: 8367},
 									label: "t1",
 									expr: &ruleRefExpr{
-										pos:  position{line: 409, col: 13, offset: 8202},
+										pos:  position{line: 415, col: 13, offset: 8370},
 										name: "GTerm",
 									},
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 409, col: 19, offset: 8208},
+									pos: position{line: 415, col: 19, offset: 8376},
 									expr: &ruleRefExpr{
-										pos:  position{line: 409, col: 19, offset: 8208},
+										pos:  position{line: 415, col: 19, offset: 8376},
 										name: "WS",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 409, col: 23, offset: 8212},
+									pos:        position{line: 415, col: 23, offset: 8380},
 									val:        "or",
 									ignoreCase: false,
+									want:       "\"or\"",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 409, col: 28, offset: 8217},
+									pos: position{line: 415, col: 28, offset: 8385},
 									expr: &ruleRefExpr{
-										pos:  position{line: 409, col: 28, offset: 8217},
+										pos:  position{line: 415, col: 28, offset: 8385},
 										name: "WS",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 409, col: 32, offset: 8221},
+									pos:   position{line: 415, col: 32, offset: 8389},
 									label: "t2",
 									expr: &ruleRefExpr{
-										pos:  position{line: 409, col: 35, offset: 8224},
+										pos:  position{line: 415, col: 35, offset: 8392},
 										name: "GTerm",
 									},
 								},
@@ -1964,13 +2060,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 413, col: 7, offset: 8320},
+						pos: position{line: 419, col: 7, offset: 8488},
 						run: (*parser).callonGExpr13,
 						expr: &labeledExpr{
-							pos:   position{line: 413, col: 7, offset: 8320},
+							pos:   position{line: 419, col: 7, offset: 8488},
 							label: "t3",
 							expr: &ruleRefExpr{
-								pos:  position{line: 413, col: 10, offset: 8323},
+								pos:  position{line: 419, col: 10, offset: 8491},
 								name: "GTerm",
 							},
 						},
@@ -1980,48 +2076,49 @@ var g = &grammar{
 		},
 		{
 			name: "GTerm",
-			pos:  position{line: 418, col: 1, offset: 8373},
+			pos:  position{line: 424, col: 1, offset: 8541},
 			expr: &choiceExpr{
-				pos: position{line: 418, col: 10, offset: 8382},
+				pos: position{line: 424, col: 10, offset: 8550},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 418, col: 10, offset: 8382},
+						pos: position{line: 424, col: 10, offset: 8550},
 						run: (*parser).callonGTerm2,
 						expr: &seqExpr{
-							pos: position{line: 418, col: 10, offset: 8382},
+							pos: position{line: 424, col: 10, offset: 8550},
 							exprs: []interface{}{
 								&labeledExpr{
-									pos:   position{line: 418, col: 10, offset: 8382},
+									pos:   position{line: 424, col: 10, offset: 8550},
 									label: "f1",
 									expr: &ruleRefExpr{
-										pos:  position{line: 418, col: 13, offset: 8385},
+										pos:  position{line: 424, col: 13, offset: 8553},
 										name: "GFactor",
 									},
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 418, col: 21, offset: 8393},
+									pos: position{line: 424, col: 21, offset: 8561},
 									expr: &ruleRefExpr{
-										pos:  position{line: 418, col: 21, offset: 8393},
+										pos:  position{line: 424, col: 21, offset: 8561},
 										name: "WS",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 418, col: 25, offset: 8397},
+									pos:        position{line: 424, col: 25, offset: 8565},
 									val:        "and",
 									ignoreCase: false,
+									want:       "\"and\"",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 418, col: 31, offset: 8403},
+									pos: position{line: 424, col: 31, offset: 8571},
 									expr: &ruleRefExpr{
-										pos:  position{line: 418, col: 31, offset: 8403},
+										pos:  position{line: 424, col: 31, offset: 8571},
 										name: "WS",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 418, col: 35, offset: 8407},
+									pos:   position{line: 424, col: 35, offset: 8575},
 									label: "f2",
 									expr: &ruleRefExpr{
-										pos:  position{line: 418, col: 38, offset: 8410},
+										pos:  position{line: 424, col: 38, offset: 8578},
 										name: "GFactor",
 									},
 								},
@@ -2029,13 +2126,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 421, col: 7, offset: 8504},
+						pos: position{line: 427, col: 7, offset: 8672},
 						run: (*parser).callonGTerm13,
 						expr: &labeledExpr{
-							pos:   position{line: 421, col: 7, offset: 8504},
+							pos:   position{line: 427, col: 7, offset: 8672},
 							label: "f3",
 							expr: &ruleRefExpr{
-								pos:  position{line: 421, col: 10, offset: 8507},
+								pos:  position{line: 427, col: 10, offset: 8675},
 								name: "GFactor",
 							},
 						},
@@ -2045,88 +2142,91 @@ var g = &grammar{
 		},
 		{
 			name: "GFactor",
-			pos:  position{line: 427, col: 1, offset: 8560},
+			pos:  position{line: 433, col: 1, offset: 8728},
 			expr: &choiceExpr{
-				pos: position{line: 427, col: 12, offset: 8571},
+				pos: position{line: 433, col: 12, offset: 8739},
 				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 427, col: 12, offset: 8571},
+						pos: position{line: 433, col: 12, offset: 8739},
 						run: (*parser).callonGFactor2,
 						expr: &seqExpr{
-							pos: position{line: 427, col: 12, offset: 8571},
+							pos: position{line: 433, col: 12, offset: 8739},
 							exprs: []interface{}{
 								&zeroOrMoreExpr{
-									pos: position{line: 427, col: 12, offset: 8571},
+									pos: position{line: 433, col: 12, offset: 8739},
 									expr: &ruleRefExpr{
-										pos:  position{line: 427, col: 12, offset: 8571},
+										pos:  position{line: 433, col: 12, offset: 8739},
 										name: "WS",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 427, col: 16, offset: 8575},
+									pos:        position{line: 433, col: 16, offset: 8743},
 									val:        "(",
 									ignoreCase: false,
+									want:       "\"(\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 427, col: 20, offset: 8579},
+									pos: position{line: 433, col: 20, offset: 8747},
 									expr: &ruleRefExpr{
-										pos:  position{line: 427, col: 20, offset: 8579},
+										pos:  position{line: 433, col: 20, offset: 8747},
 										name: "WS",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 427, col: 24, offset: 8583},
+									pos:   position{line: 433, col: 24, offset: 8751},
 									label: "f1",
 									expr: &ruleRefExpr{
-										pos:  position{line: 427, col: 27, offset: 8586},
+										pos:  position{line: 433, col: 27, offset: 8754},
 										name: "GExpr",
 									},
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 427, col: 33, offset: 8592},
+									pos: position{line: 433, col: 33, offset: 8760},
 									expr: &ruleRefExpr{
-										pos:  position{line: 427, col: 33, offset: 8592},
+										pos:  position{line: 433, col: 33, offset: 8760},
 										name: "WS",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 427, col: 37, offset: 8596},
+									pos:        position{line: 433, col: 37, offset: 8764},
 									val:        ")",
 									ignoreCase: false,
+									want:       "\")\"",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 430, col: 7, offset: 8644},
+						pos: position{line: 436, col: 7, offset: 8812},
 						run: (*parser).callonGFactor14,
 						expr: &seqExpr{
-							pos: position{line: 430, col: 7, offset: 8644},
+							pos: position{line: 436, col: 7, offset: 8812},
 							exprs: []interface{}{
 								&zeroOrMoreExpr{
-									pos: position{line: 430, col: 7, offset: 8644},
+									pos: position{line: 436, col: 7, offset: 8812},
 									expr: &ruleRefExpr{
-										pos:  position{line: 430, col: 7, offset: 8644},
+										pos:  position{line: 436, col: 7, offset: 8812},
 										name: "WS",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 430, col: 11, offset: 8648},
+									pos:        position{line: 436, col: 11, offset: 8816},
 									val:        "not",
 									ignoreCase: false,
+									want:       "\"not\"",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 430, col: 17, offset: 8654},
+									pos: position{line: 436, col: 17, offset: 8822},
 									expr: &ruleRefExpr{
-										pos:  position{line: 430, col: 17, offset: 8654},
+										pos:  position{line: 436, col: 17, offset: 8822},
 										name: "WS",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 430, col: 21, offset: 8658},
+									pos:   position{line: 436, col: 21, offset: 8826},
 									label: "f2",
 									expr: &ruleRefExpr{
-										pos:  position{line: 430, col: 24, offset: 8661},
+										pos:  position{line: 436, col: 24, offset: 8829},
 										name: "GFactor",
 									},
 								},
@@ -2134,13 +2234,13 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 433, col: 7, offset: 8733},
+						pos: position{line: 439, col: 7, offset: 8901},
 						run: (*parser).callonGFactor23,
 						expr: &labeledExpr{
-							pos:   position{line: 433, col: 7, offset: 8733},
+							pos:   position{line: 439, col: 7, offset: 8901},
 							label: "f3",
 							expr: &ruleRefExpr{
-								pos:  position{line: 433, col: 10, offset: 8736},
+								pos:  position{line: 439, col: 10, offset: 8904},
 								name: "GConstant",
 							},
 						},
@@ -2150,36 +2250,36 @@ var g = &grammar{
 		},
 		{
 			name: "GConstant",
-			pos:  position{line: 437, col: 1, offset: 8789},
+			pos:  position{line: 443, col: 1, offset: 8957},
 			expr: &actionExpr{
-				pos: position{line: 437, col: 14, offset: 8802},
+				pos: position{line: 443, col: 14, offset: 8970},
 				run: (*parser).callonGConstant1,
 				expr: &seqExpr{
-					pos: position{line: 437, col: 14, offset: 8802},
+					pos: position{line: 443, col: 14, offset: 8970},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 437, col: 14, offset: 8802},
+							pos: position{line: 443, col: 14, offset: 8970},
 							expr: &ruleRefExpr{
-								pos:  position{line: 437, col: 14, offset: 8802},
+								pos:  position{line: 443, col: 14, offset: 8970},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 437, col: 18, offset: 8806},
+							pos:   position{line: 443, col: 18, offset: 8974},
 							label: "three",
 							expr: &choiceExpr{
-								pos: position{line: 437, col: 25, offset: 8813},
+								pos: position{line: 443, col: 25, offset: 8981},
 								alternatives: []interface{}{
 									&ruleRefExpr{
-										pos:  position{line: 437, col: 25, offset: 8813},
+										pos:  position{line: 443, col: 25, offset: 8981},
 										name: "GTrue",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 437, col: 33, offset: 8821},
+										pos:  position{line: 443, col: 33, offset: 8989},
 										name: "GFalse",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 437, col: 42, offset: 8830},
+										pos:  position{line: 443, col: 42, offset: 8998},
 										name: "GTripel",
 									},
 								},
@@ -2191,74 +2291,76 @@ var g = &grammar{
 		},
 		{
 			name: "GTrue",
-			pos:  position{line: 441, col: 1, offset: 8877},
+			pos:  position{line: 447, col: 1, offset: 9045},
 			expr: &actionExpr{
-				pos: position{line: 441, col: 10, offset: 8886},
+				pos: position{line: 447, col: 10, offset: 9054},
 				run: (*parser).callonGTrue1,
 				expr: &litMatcher{
-					pos:        position{line: 441, col: 10, offset: 8886},
+					pos:        position{line: 447, col: 10, offset: 9054},
 					val:        "true",
 					ignoreCase: false,
+					want:       "\"true\"",
 				},
 			},
 		},
 		{
 			name: "GFalse",
-			pos:  position{line: 447, col: 1, offset: 8946},
+			pos:  position{line: 453, col: 1, offset: 9114},
 			expr: &actionExpr{
-				pos: position{line: 447, col: 11, offset: 8956},
+				pos: position{line: 453, col: 11, offset: 9124},
 				run: (*parser).callonGFalse1,
 				expr: &litMatcher{
-					pos:        position{line: 447, col: 11, offset: 8956},
+					pos:        position{line: 453, col: 11, offset: 9124},
 					val:        "false",
 					ignoreCase: false,
+					want:       "\"false\"",
 				},
 			},
 		},
 		{
 			name: "GTripel",
-			pos:  position{line: 454, col: 1, offset: 9019},
+			pos:  position{line: 460, col: 1, offset: 9187},
 			expr: &actionExpr{
-				pos: position{line: 454, col: 12, offset: 9030},
+				pos: position{line: 460, col: 12, offset: 9198},
 				run: (*parser).callonGTripel1,
 				expr: &seqExpr{
-					pos: position{line: 454, col: 12, offset: 9030},
+					pos: position{line: 460, col: 12, offset: 9198},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 454, col: 12, offset: 9030},
+							pos:   position{line: 460, col: 12, offset: 9198},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 454, col: 18, offset: 9036},
+								pos:  position{line: 460, col: 18, offset: 9204},
 								name: "GOperand1",
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 454, col: 28, offset: 9046},
+							pos: position{line: 460, col: 28, offset: 9214},
 							expr: &ruleRefExpr{
-								pos:  position{line: 454, col: 28, offset: 9046},
+								pos:  position{line: 460, col: 28, offset: 9214},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 454, col: 32, offset: 9050},
+							pos:   position{line: 460, col: 32, offset: 9218},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 454, col: 35, offset: 9053},
+								pos:  position{line: 460, col: 35, offset: 9221},
 								name: "GOperator",
 							},
 						},
 						&oneOrMoreExpr{
-							pos: position{line: 454, col: 45, offset: 9063},
+							pos: position{line: 460, col: 45, offset: 9231},
 							expr: &ruleRefExpr{
-								pos:  position{line: 454, col: 45, offset: 9063},
+								pos:  position{line: 460, col: 45, offset: 9231},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 454, col: 49, offset: 9067},
+							pos:   position{line: 460, col: 49, offset: 9235},
 							label: "second",
 							expr: &ruleRefExpr{
-								pos:  position{line: 454, col: 56, offset: 9074},
+								pos:  position{line: 460, col: 56, offset: 9242},
 								name: "GOperand2",
 							},
 						},
@@ -2268,25 +2370,26 @@ var g = &grammar{
 		},
 		{
 			name: "GOperand1",
-			pos:  position{line: 463, col: 1, offset: 9192},
+			pos:  position{line: 469, col: 1, offset: 9360},
 			expr: &actionExpr{
-				pos: position{line: 463, col: 14, offset: 9205},
+				pos: position{line: 469, col: 14, offset: 9373},
 				run: (*parser).callonGOperand11,
 				expr: &choiceExpr{
-					pos: position{line: 463, col: 15, offset: 9206},
+					pos: position{line: 469, col: 15, offset: 9374},
 					alternatives: []interface{}{
 						&seqExpr{
-							pos: position{line: 463, col: 15, offset: 9206},
+							pos: position{line: 469, col: 15, offset: 9374},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 463, col: 15, offset: 9206},
+									pos:        position{line: 469, col: 15, offset: 9374},
 									val:        "$",
 									ignoreCase: false,
+									want:       "\"$\"",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 463, col: 19, offset: 9210},
+									pos: position{line: 469, col: 19, offset: 9378},
 									expr: &charClassMatcher{
-										pos:        position{line: 463, col: 19, offset: 9210},
+										pos:        position{line: 469, col: 19, offset: 9378},
 										val:        "[A-Za-z0-9]",
 										ranges:     []rune{'A', 'Z', 'a', 'z', '0', '9'},
 										ignoreCase: false,
@@ -2296,64 +2399,76 @@ var g = &grammar{
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 34, offset: 9225},
+							pos:        position{line: 469, col: 34, offset: 9393},
 							val:        "%project",
 							ignoreCase: false,
+							want:       "\"%project\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 47, offset: 9238},
+							pos:        position{line: 469, col: 47, offset: 9406},
 							val:        "%qrelpath",
 							ignoreCase: false,
+							want:       "\"%qrelpath\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 61, offset: 9252},
+							pos:        position{line: 469, col: 61, offset: 9420},
 							val:        "%qpath",
 							ignoreCase: false,
+							want:       "\"%qpath\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 72, offset: 9263},
+							pos:        position{line: 469, col: 72, offset: 9431},
 							val:        "%qdir",
 							ignoreCase: false,
+							want:       "\"%qdir\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 82, offset: 9273},
+							pos:        position{line: 469, col: 82, offset: 9441},
 							val:        "%ext",
 							ignoreCase: false,
+							want:       "\"%ext\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 91, offset: 9282},
+							pos:        position{line: 469, col: 91, offset: 9450},
 							val:        "%basename",
 							ignoreCase: false,
+							want:       "\"%basename\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 105, offset: 9296},
+							pos:        position{line: 469, col: 105, offset: 9464},
 							val:        "%version",
 							ignoreCase: false,
+							want:       "\"%version\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 118, offset: 9309},
+							pos:        position{line: 469, col: 118, offset: 9477},
 							val:        "%mostype",
 							ignoreCase: false,
+							want:       "\"%mostype\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 131, offset: 9322},
+							pos:        position{line: 469, col: 131, offset: 9490},
 							val:        "%mclib",
 							ignoreCase: false,
+							want:       "\"%mclib\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 142, offset: 9333},
+							pos:        position{line: 469, col: 142, offset: 9501},
 							val:        "%systemname",
 							ignoreCase: false,
+							want:       "\"%systemname\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 158, offset: 9349},
+							pos:        position{line: 469, col: 158, offset: 9517},
 							val:        "%os",
 							ignoreCase: false,
+							want:       "\"%os\"",
 						},
 						&litMatcher{
-							pos:        position{line: 463, col: 166, offset: 9357},
+							pos:        position{line: 469, col: 166, offset: 9525},
 							val:        "%systemgroup",
 							ignoreCase: false,
+							want:       "\"%systemgroup\"",
 						},
 					},
 				},
@@ -2361,88 +2476,102 @@ var g = &grammar{
 		},
 		{
 			name: "GOperator",
-			pos:  position{line: 467, col: 1, offset: 9409},
+			pos:  position{line: 473, col: 1, offset: 9577},
 			expr: &actionExpr{
-				pos: position{line: 467, col: 14, offset: 9422},
+				pos: position{line: 473, col: 14, offset: 9590},
 				run: (*parser).callonGOperator1,
 				expr: &seqExpr{
-					pos: position{line: 467, col: 14, offset: 9422},
+					pos: position{line: 473, col: 14, offset: 9590},
 					exprs: []interface{}{
 						&zeroOrOneExpr{
-							pos: position{line: 467, col: 14, offset: 9422},
+							pos: position{line: 473, col: 14, offset: 9590},
 							expr: &litMatcher{
-								pos:        position{line: 467, col: 14, offset: 9422},
+								pos:        position{line: 473, col: 14, offset: 9590},
 								val:        "not-",
 								ignoreCase: false,
+								want:       "\"not-\"",
 							},
 						},
 						&choiceExpr{
-							pos: position{line: 467, col: 23, offset: 9431},
+							pos: position{line: 473, col: 23, offset: 9599},
 							alternatives: []interface{}{
 								&litMatcher{
-									pos:        position{line: 467, col: 23, offset: 9431},
+									pos:        position{line: 473, col: 23, offset: 9599},
 									val:        "sortsAfter",
 									ignoreCase: false,
+									want:       "\"sortsAfter\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 38, offset: 9446},
+									pos:        position{line: 473, col: 38, offset: 9614},
 									val:        "sortsBefore",
 									ignoreCase: false,
+									want:       "\"sortsBefore\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 54, offset: 9462},
+									pos:        position{line: 473, col: 54, offset: 9630},
 									val:        "contains",
 									ignoreCase: false,
+									want:       "\"contains\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 67, offset: 9475},
+									pos:        position{line: 473, col: 67, offset: 9643},
 									val:        "isEqualTo",
 									ignoreCase: false,
+									want:       "\"isEqualTo\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 81, offset: 9489},
+									pos:        position{line: 473, col: 81, offset: 9657},
 									val:        "fileMatches",
 									ignoreCase: false,
+									want:       "\"fileMatches\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 97, offset: 9505},
+									pos:        position{line: 473, col: 97, offset: 9673},
 									val:        "regexpMatches",
 									ignoreCase: false,
+									want:       "\"regexpMatches\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 115, offset: 9523},
+									pos:        position{line: 473, col: 115, offset: 9691},
 									val:        "isInstanceOf",
 									ignoreCase: false,
+									want:       "\"isInstanceOf\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 132, offset: 9540},
+									pos:        position{line: 473, col: 132, offset: 9708},
 									val:        "isIn",
 									ignoreCase: false,
+									want:       "\"isIn\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 141, offset: 9549},
+									pos:        position{line: 473, col: 141, offset: 9717},
 									val:        "isEqualTrueAs",
 									ignoreCase: false,
+									want:       "\"isEqualTrueAs\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 159, offset: 9567},
+									pos:        position{line: 473, col: 159, offset: 9735},
 									val:        "isPrefixOf",
 									ignoreCase: false,
+									want:       "\"isPrefixOf\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 174, offset: 9582},
+									pos:        position{line: 473, col: 174, offset: 9750},
 									val:        "isSuffixOf",
 									ignoreCase: false,
+									want:       "\"isSuffixOf\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 189, offset: 9597},
+									pos:        position{line: 473, col: 189, offset: 9765},
 									val:        "startsWith",
 									ignoreCase: false,
+									want:       "\"startsWith\"",
 								},
 								&litMatcher{
-									pos:        position{line: 467, col: 204, offset: 9612},
+									pos:        position{line: 473, col: 204, offset: 9780},
 									val:        "endsWith",
 									ignoreCase: false,
+									want:       "\"endsWith\"",
 								},
 							},
 						},
@@ -2452,24 +2581,25 @@ var g = &grammar{
 		},
 		{
 			name: "GOperand2",
-			pos:  position{line: 471, col: 1, offset: 9660},
+			pos:  position{line: 477, col: 1, offset: 9828},
 			expr: &actionExpr{
-				pos: position{line: 471, col: 14, offset: 9673},
+				pos: position{line: 477, col: 14, offset: 9841},
 				run: (*parser).callonGOperand21,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 471, col: 14, offset: 9673},
+					pos: position{line: 477, col: 14, offset: 9841},
 					expr: &seqExpr{
-						pos: position{line: 471, col: 15, offset: 9674},
+						pos: position{line: 477, col: 15, offset: 9842},
 						exprs: []interface{}{
 							&litMatcher{
-								pos:        position{line: 471, col: 15, offset: 9674},
+								pos:        position{line: 477, col: 15, offset: 9842},
 								val:        "\"",
 								ignoreCase: false,
+								want:       "\"\\\"\"",
 							},
 							&zeroOrMoreExpr{
-								pos: position{line: 471, col: 20, offset: 9679},
+								pos: position{line: 477, col: 20, offset: 9847},
 								expr: &charClassMatcher{
-									pos:        position{line: 471, col: 20, offset: 9679},
+									pos:        position{line: 477, col: 20, offset: 9847},
 									val:        "[^\"]",
 									chars:      []rune{'"'},
 									ignoreCase: false,
@@ -2477,9 +2607,10 @@ var g = &grammar{
 								},
 							},
 							&litMatcher{
-								pos:        position{line: 471, col: 26, offset: 9685},
+								pos:        position{line: 477, col: 26, offset: 9853},
 								val:        "\"",
 								ignoreCase: false,
+								want:       "\"\\\"\"",
 							},
 						},
 					},
@@ -2488,19 +2619,20 @@ var g = &grammar{
 		},
 		{
 			name: "CommentLine",
-			pos:  position{line: 484, col: 1, offset: 9810},
+			pos:  position{line: 490, col: 1, offset: 9978},
 			expr: &seqExpr{
-				pos: position{line: 484, col: 17, offset: 9826},
+				pos: position{line: 490, col: 17, offset: 9994},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 484, col: 17, offset: 9826},
+						pos:        position{line: 490, col: 17, offset: 9994},
 						val:        "//",
 						ignoreCase: false,
+						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 484, col: 22, offset: 9831},
+						pos: position{line: 490, col: 22, offset: 9999},
 						expr: &charClassMatcher{
-							pos:        position{line: 484, col: 22, offset: 9831},
+							pos:        position{line: 490, col: 22, offset: 9999},
 							val:        "[^\\n\\r]",
 							chars:      []rune{'\n', '\r'},
 							ignoreCase: false,
@@ -2512,26 +2644,26 @@ var g = &grammar{
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 486, col: 1, offset: 9842},
+			pos:  position{line: 492, col: 1, offset: 10010},
 			expr: &actionExpr{
-				pos: position{line: 486, col: 12, offset: 9853},
+				pos: position{line: 492, col: 12, offset: 10021},
 				run: (*parser).callonComment1,
 				expr: &labeledExpr{
-					pos:   position{line: 486, col: 12, offset: 9853},
+					pos:   position{line: 492, col: 12, offset: 10021},
 					label: "comment",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 486, col: 20, offset: 9861},
+						pos: position{line: 492, col: 20, offset: 10029},
 						expr: &choiceExpr{
-							pos: position{line: 486, col: 21, offset: 9862},
+							pos: position{line: 492, col: 21, offset: 10030},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 486, col: 21, offset: 9862},
+									pos:  position{line: 492, col: 21, offset: 10030},
 									name: "CommentLine",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 486, col: 35, offset: 9876},
+									pos: position{line: 492, col: 35, offset: 10044},
 									expr: &ruleRefExpr{
-										pos:  position{line: 486, col: 35, offset: 9876},
+										pos:  position{line: 492, col: 35, offset: 10044},
 										name: "WS",
 									},
 								},
@@ -2543,51 +2675,53 @@ var g = &grammar{
 		},
 		{
 			name: "DELIM1",
-			pos:  position{line: 491, col: 1, offset: 9916},
+			pos:  position{line: 497, col: 1, offset: 10084},
 			expr: &seqExpr{
-				pos: position{line: 491, col: 11, offset: 9926},
+				pos: position{line: 497, col: 11, offset: 10094},
 				exprs: []interface{}{
 					&zeroOrMoreExpr{
-						pos: position{line: 491, col: 11, offset: 9926},
+						pos: position{line: 497, col: 11, offset: 10094},
 						expr: &ruleRefExpr{
-							pos:  position{line: 491, col: 11, offset: 9926},
+							pos:  position{line: 497, col: 11, offset: 10094},
 							name: "WS",
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 491, col: 15, offset: 9930},
+						pos:        position{line: 497, col: 15, offset: 10098},
 						val:        "'''",
 						ignoreCase: false,
+						want:       "\"'''\"",
 					},
 				},
 			},
 		},
 		{
 			name: "DELIM2",
-			pos:  position{line: 493, col: 1, offset: 9937},
+			pos:  position{line: 499, col: 1, offset: 10105},
 			expr: &seqExpr{
-				pos: position{line: 493, col: 11, offset: 9947},
+				pos: position{line: 499, col: 11, offset: 10115},
 				exprs: []interface{}{
 					&zeroOrMoreExpr{
-						pos: position{line: 493, col: 11, offset: 9947},
+						pos: position{line: 499, col: 11, offset: 10115},
 						expr: &ruleRefExpr{
-							pos:  position{line: 493, col: 11, offset: 9947},
+							pos:  position{line: 499, col: 11, offset: 10115},
 							name: "WS",
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 493, col: 15, offset: 9951},
+						pos:        position{line: 499, col: 15, offset: 10119},
 						val:        "\"\"\"",
 						ignoreCase: false,
+						want:       "\"\\\"\\\"\\\"\"",
 					},
 				},
 			},
 		},
 		{
 			name: "WS",
-			pos:  position{line: 495, col: 1, offset: 9958},
+			pos:  position{line: 501, col: 1, offset: 10126},
 			expr: &charClassMatcher{
-				pos:        position{line: 495, col: 7, offset: 9964},
+				pos:        position{line: 501, col: 7, offset: 10132},
 				val:        "[ \\n\\t\\r]",
 				chars:      []rune{' ', '\n', '\t', '\r'},
 				ignoreCase: false,
@@ -2596,40 +2730,44 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 497, col: 1, offset: 9975},
+			pos:  position{line: 503, col: 1, offset: 10143},
 			expr: &choiceExpr{
-				pos: position{line: 497, col: 9, offset: 9983},
+				pos: position{line: 503, col: 9, offset: 10151},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 497, col: 9, offset: 9983},
+						pos:        position{line: 503, col: 9, offset: 10151},
 						val:        "\r\n",
 						ignoreCase: false,
+						want:       "\"\\r\\n\"",
 					},
 					&litMatcher{
-						pos:        position{line: 497, col: 18, offset: 9992},
+						pos:        position{line: 503, col: 18, offset: 10160},
 						val:        "\n\r",
 						ignoreCase: false,
+						want:       "\"\\n\\r\"",
 					},
 					&litMatcher{
-						pos:        position{line: 497, col: 27, offset: 10001},
+						pos:        position{line: 503, col: 27, offset: 10169},
 						val:        "\r",
 						ignoreCase: false,
+						want:       "\"\\r\"",
 					},
 					&litMatcher{
-						pos:        position{line: 497, col: 34, offset: 10008},
+						pos:        position{line: 503, col: 34, offset: 10176},
 						val:        "\n",
 						ignoreCase: false,
+						want:       "\"\\n\"",
 					},
 				},
 			},
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 499, col: 1, offset: 10015},
+			pos:  position{line: 505, col: 1, offset: 10183},
 			expr: &notExpr{
-				pos: position{line: 499, col: 8, offset: 10022},
+				pos: position{line: 505, col: 8, offset: 10190},
 				expr: &anyMatcher{
-					line: 499, col: 9, offset: 10023,
+					line: 505, col: 9, offset: 10191,
 				},
 			},
 		},
@@ -2848,8 +2986,14 @@ func (c *current) onParamSimple1() (interface{}, error) {
 		name = name[1:]
 		named = true
 	}
+	ty := ""
+	if k := strings.Index(name, ":"); k != -1 {
+		ty = strings.TrimSpace(name[k+1:])
+		name = strings.TrimSpace(name[:k])
+	}
 	param := Param{
 		ID:    name,
+		Type:  ty,
 		Named: named,
 	}
 	return param, nil
@@ -3389,18 +3533,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -3638,6 +3781,7 @@ type litMatcher struct {
 	pos        position
 	val        string
 	ignoreCase bool
+	want       string
 }
 
 type charClassMatcher struct {
@@ -4432,11 +4576,6 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
-	ignoreCase := ""
-	if lit.ignoreCase {
-		ignoreCase = "i"
-	}
-	val := string(strconv.AppendQuote([]byte{}, lit.val)) + ignoreCase // wrap 'lit.val' with double quotes
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
@@ -4444,13 +4583,13 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 			cur = unicode.ToLower(cur)
 		}
 		if cur != want {
-			p.failAt(false, start.position, val)
+			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
 		}
 		p.read()
 	}
-	p.failAt(true, start.position, val)
+	p.failAt(true, start.position, lit.want)
 	return p.sliceFrom(start), true
 }
 
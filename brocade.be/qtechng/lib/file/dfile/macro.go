@@ -3,6 +3,7 @@ package dfile
 import (
 	"bytes"
 	"encoding/json"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,11 +14,72 @@ import (
 
 // Param staat voor een parameter
 type Param struct {
-	ID      string `json:"name"`    // Naam
-	Ref     string `json:"ref"`     // Reference
-	Default string `json:"default"` // default value
-	Doc     string `json:"doc"`     // Documentation
-	Named   bool   `json:"named"`   // Named?
+	ID      string `json:"name"`           // Naam
+	Ref     string `json:"ref"`            // Reference
+	Default string `json:"default"`        // default value
+	Doc     string `json:"doc"`            // Documentation
+	Named   bool   `json:"named"`          // Named?
+	Type    string `json:"type,omitempty"` // Type: see ParamTypes
+}
+
+// ParamTypes are the types of macro parameters: `$x:numeric` in the signature of a macro
+var ParamTypes = map[string]string{
+	"string":  "any text",
+	"numeric": "a number",
+	"lvn":     "a local variable, with or without subscripts",
+	"glvn":    "a local or global variable, with or without subscripts",
+	"label":   "an entry reference: label, label^routine or ^routine",
+	"lgcode":  "the name of an lgcode, with or without `l4_`",
+}
+
+var rparamtypes = map[string]*regexp.Regexp{
+	"numeric": regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`),
+	"lvn":     regexp.MustCompile(`^(@.+|%?[A-Za-z][A-Za-z0-9]*(\(.+\))?)$`),
+	"glvn":    regexp.MustCompile(`^(@.+|\^?%?[A-Za-z][A-Za-z0-9]*(\(.+\))?|\^\(.+\)|\^\|[^|]+\|%?[A-Za-z][A-Za-z0-9]*(\(.+\))?)$`),
+	"label":   regexp.MustCompile(`^(@.+|(%?[A-Za-z0-9]+(\+[0-9]+)?)?(\^%?[A-Za-z][A-Za-z0-9]*)?)$`),
+	"lgcode":  regexp.MustCompile(`^(l4_)?[A-Za-z0-9][A-Za-z0-9_.]*$`),
+}
+
+// CheckValue checks a value against the type of the parameter: the result is empty if the value is valid.
+// Empty values and values with other objects (m4_, l4_, ...) are not checked,
+// except for lgcodes.
+func (param Param) CheckValue(value string) string {
+	value = strings.TrimSpace(value)
+	if param.Type == "" || param.Type == "string" || value == "" {
+		return ""
+	}
+	rx := rparamtypes[param.Type]
+	if rx == nil {
+		return ""
+	}
+	objects := value
+	if param.Type == "lgcode" {
+		objects = strings.TrimPrefix(value, "l4_")
+	}
+	if strings.Contains(objects, "4_") {
+		return ""
+	}
+	if param.Type == "label" && value == "^" {
+		return "`" + value + "` is not " + ParamTypes[param.Type]
+	}
+	if !rx.MatchString(value) {
+		return "`" + value + "` is not " + ParamTypes[param.Type]
+	}
+	return ""
+}
+
+// CheckArgs checks the arguments of a call (see Args) against the types of the parameters
+func (macro *Macro) CheckArgs(args map[string]string) (msgs []string) {
+	for _, param := range macro.Params {
+		value, ok := args[param.ID]
+		if !ok || (param.Ref != "" && value == args[param.Ref]) {
+			continue
+		}
+		if msg := param.CheckValue(value); msg != "" {
+			msgs = append(msgs, "Parameter `"+param.ID+"` of "+macro.String()+": "+msg)
+		}
+	}
+	return msgs
 }
 
 // Action for the macro
@@ -219,6 +281,44 @@ func (macro *Macro) Lint() (errslice qerror.ErrorSlice) {
 		}
 	}
 
+	// types
+	for _, param := range macro.Params {
+		if param.Type == "" {
+			continue
+		}
+		if ParamTypes[param.Type] == "" {
+			types := make([]string, 0, len(ParamTypes))
+			for ty := range ParamTypes {
+				types = append(types, ty)
+			}
+			sort.Strings(types)
+			e := &qerror.QError{
+				Ref:    []string{"macro.lint.paramtype"},
+				QPath:  fname,
+				Lineno: lineno,
+				Object: name,
+				Type:   "Error",
+				Msg:    []string{"Unknown type `" + param.Type + "` for `" + param.ID + "`: should be one of " + strings.Join(types, ", ")},
+			}
+			errslice = append(errslice, e)
+			continue
+		}
+		if param.Ref != "" {
+			continue
+		}
+		if msg := param.CheckValue(param.Default); msg != "" {
+			e := &qerror.QError{
+				Ref:    []string{"macro.lint.paramdefault"},
+				QPath:  fname,
+				Lineno: lineno,
+				Object: name,
+				Type:   "Error",
+				Msg:    []string{"Default of `" + param.ID + "` in `" + macro.String() + "`: " + msg},
+			}
+			errslice = append(errslice, e)
+		}
+	}
+
 	// reference
 	first := ""
 	for i, param := range macro.Params {
@@ -272,6 +372,9 @@ func (macro *Macro) Format() string {
 			prm = "*"
 		}
 		prm += param.ID
+		if param.Type != "" {
+			prm += ":" + param.Type
+		}
 		def := param.Default
 		def2 := def
 		if len(def2) > 1 && strings.HasPrefix(def2, `"`) && strings.HasSuffix(def2, `"`) {
@@ -346,16 +449,20 @@ func (macro *Macro) Format() string {
 	return strings.Join(result, "\n")
 }
 
+// Holds tells if the guard of an action is satisfied in an environment
+func (action Action) Holds(env map[string]string) bool {
+	truth := Eval(action.Guard, env)
+	if action.Unless {
+		truth = !truth
+	}
+	return truth
+}
+
 // Replacer berekent de tekst die moet worden gebruikt bij de macro
 func (macro *Macro) Replacer(env map[string]string, original string) string {
 	act := Action{}
 	for _, action := range macro.Actions {
-		guard := action.Guard
-		truth := Eval(guard, env)
-		if action.Unless {
-			truth = !truth
-		}
-		if truth {
+		if action.Holds(env) {
 			act = action
 			break
 		}
@@ -82,6 +82,7 @@ var g = &grammar{
 							pos:        position{line: 33, col: 12, offset: 521},
 							val:        "include",
 							ignoreCase: false,
+							want:       "\"include\"",
 						},
 						&oneOrMoreExpr{
 							pos: position{line: 33, col: 22, offset: 531},
@@ -109,6 +110,7 @@ var g = &grammar{
 							pos:        position{line: 33, col: 45, offset: 554},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 33, col: 49, offset: 558},
@@ -241,6 +243,7 @@ var g = &grammar{
 									pos:        position{line: 62, col: 11, offset: 1293},
 									val:        "",
 									ignoreCase: false,
+									want:       "\"\"",
 								},
 								&notExpr{
 									pos: position{line: 62, col: 14, offset: 1296},
@@ -261,6 +264,7 @@ var g = &grammar{
 												pos:        position{line: 62, col: 23, offset: 1305},
 												val:        "include",
 												ignoreCase: false,
+												want:       "\"include\"",
 											},
 											&charClassMatcher{
 												pos:        position{line: 62, col: 33, offset: 1315},
@@ -308,6 +312,7 @@ var g = &grammar{
 									pos:        position{line: 66, col: 11, offset: 1379},
 									val:        "",
 									ignoreCase: false,
+									want:       "\"\"",
 								},
 								&notExpr{
 									pos: position{line: 66, col: 14, offset: 1382},
@@ -328,6 +333,7 @@ var g = &grammar{
 												pos:        position{line: 66, col: 23, offset: 1391},
 												val:        "include",
 												ignoreCase: false,
+												want:       "\"include\"",
 											},
 											&charClassMatcher{
 												pos:        position{line: 66, col: 33, offset: 1401},
@@ -396,6 +402,7 @@ var g = &grammar{
 						pos:        position{line: 74, col: 17, offset: 1531},
 						val:        "//",
 						ignoreCase: false,
+						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 74, col: 22, offset: 1536},
@@ -462,21 +469,25 @@ var g = &grammar{
 						pos:        position{line: 82, col: 9, offset: 1645},
 						val:        "\r\n",
 						ignoreCase: false,
+						want:       "\"\\r\\n\"",
 					},
 					&litMatcher{
 						pos:        position{line: 82, col: 18, offset: 1654},
 						val:        "\n\r",
 						ignoreCase: false,
+						want:       "\"\\n\\r\"",
 					},
 					&litMatcher{
 						pos:        position{line: 82, col: 27, offset: 1663},
 						val:        "\r",
 						ignoreCase: false,
+						want:       "\"\\r\"",
 					},
 					&litMatcher{
 						pos:        position{line: 82, col: 34, offset: 1670},
 						val:        "\n",
 						ignoreCase: false,
+						want:       "\"\\n\"",
 					},
 				},
 			},
@@ -488,6 +499,7 @@ var g = &grammar{
 				pos:        position{line: 85, col: 7, offset: 1684},
 				val:        "[⟦«]",
 				ignoreCase: false,
+				want:       "\"[⟦«]\"",
 			},
 		},
 		{
@@ -497,6 +509,7 @@ var g = &grammar{
 				pos:        position{line: 86, col: 8, offset: 1701},
 				val:        "⟦",
 				ignoreCase: false,
+				want:       "\"⟦\"",
 			},
 		},
 		{
@@ -506,6 +519,7 @@ var g = &grammar{
 				pos:        position{line: 87, col: 8, offset: 1714},
 				val:        "⟧",
 				ignoreCase: false,
+				want:       "\"⟧\"",
 			},
 		},
 		{
@@ -515,6 +529,7 @@ var g = &grammar{
 				pos:        position{line: 88, col: 8, offset: 1727},
 				val:        "«",
 				ignoreCase: false,
+				want:       "\"«\"",
 			},
 		},
 		{
@@ -524,6 +539,7 @@ var g = &grammar{
 				pos:        position{line: 89, col: 8, offset: 1739},
 				val:        "»",
 				ignoreCase: false,
+				want:       "\"»\"",
 			},
 		},
 		{
@@ -764,18 +780,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -1013,6 +1028,7 @@ type litMatcher struct {
 	pos        position
 	val        string
 	ignoreCase bool
+	want       string
 }
 
 type charClassMatcher struct {
@@ -1807,11 +1823,6 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
-	ignoreCase := ""
-	if lit.ignoreCase {
-		ignoreCase = "i"
-	}
-	val := string(strconv.AppendQuote([]byte{}, lit.val)) + ignoreCase // wrap 'lit.val' with double quotes
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
@@ -1819,13 +1830,13 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 			cur = unicode.ToLower(cur)
 		}
 		if cur != want {
-			p.failAt(false, start.position, val)
+			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
 		}
 		p.read()
 	}
-	p.failAt(true, start.position, val)
+	p.failAt(true, start.position, lit.want)
 	return p.sliceFrom(start), true
 }
 
@@ -175,7 +175,6 @@ func (lf *LFile) Sort() {
 
 func (lf *LFile) Format() (output *bytes.Buffer) {
 	output = bytes.NewBuffer([]byte(lf.Comment()))
-	output.Write([]byte(lf.Comment()))
 	for _, lgcode := range lf.Lgcodes {
 		output.WriteString("\n\n")
 		output.WriteString(lgcode.Format())
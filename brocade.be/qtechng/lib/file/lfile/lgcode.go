@@ -29,6 +29,8 @@ type Lgcode struct {
 	Line     string `json:"-"`        // Lijnnummer
 	Version  string `json:"-"`        // Version
 	Text     string `json:"text"`     // Text
+
+	Translations map[string]string `json:"-"` // Other languages (see qutil.Languages), keyed by language key
 }
 
 // *Lgcode moet de Object interface ondersteunen.
@@ -89,14 +91,104 @@ func (lgcode *Lgcode) SetLineno(lineno string) {
 	lgcode.Line = lineno
 }
 
-// MarshalJSON of lgcode
+// MarshalJSON of lgcode: the translations in other languages are stored with their key
 func (lgcode *Lgcode) MarshalJSON() ([]byte, error) {
-	return json.Marshal(*lgcode)
+	type plain Lgcode
+	blob, err := json.Marshal((*plain)(lgcode))
+	if err != nil || len(lgcode.Translations) == 0 {
+		return blob, err
+	}
+	m := make(map[string]interface{})
+	json.Unmarshal(blob, &m)
+	for key, text := range lgcode.Translations {
+		if _, ok := m[key]; !ok && text != "" {
+			m[key] = text
+		}
+	}
+	return json.Marshal(m)
 }
 
 // Unmarshal of lgcode
 func (lgcode *Lgcode) Unmarshal(blob []byte) error {
-	return json.Unmarshal(blob, lgcode)
+	type plain Lgcode
+	err := json.Unmarshal(blob, (*plain)(lgcode))
+	if err != nil {
+		return err
+	}
+	m := make(map[string]interface{})
+	json.Unmarshal(blob, &m)
+	for _, lg := range qutil.Languages() {
+		if qutil.IsBuiltinLanguage(lg.Code) {
+			continue
+		}
+		if text, ok := m[lg.Key].(string); ok {
+			lgcode.SetTranslation(lg.Code, text)
+		}
+	}
+	return nil
+}
+
+// Translation returns the text of the lgcode in a language.
+// The language is given by code, key or BCP-47 tag (see qutil.LookupLanguage).
+func (lgcode *Lgcode) Translation(lg string) string {
+	language := qutil.LookupLanguage(lg)
+	if language == nil {
+		return ""
+	}
+	switch language.Code {
+	case "N":
+		return lgcode.N
+	case "E":
+		return lgcode.E
+	case "F":
+		return lgcode.F
+	case "D":
+		return lgcode.D
+	case "U":
+		return lgcode.U
+	}
+	return lgcode.Translations[language.Key]
+}
+
+// SetTranslation sets the text of the lgcode in a language.
+// It returns false if the language is not known.
+func (lgcode *Lgcode) SetTranslation(lg string, text string) bool {
+	language := qutil.LookupLanguage(lg)
+	if language == nil {
+		return false
+	}
+	switch language.Code {
+	case "N":
+		lgcode.N = text
+	case "E":
+		lgcode.E = text
+	case "F":
+		lgcode.F = text
+	case "D":
+		lgcode.D = text
+	case "U":
+		lgcode.U = text
+	default:
+		if text == "" && lgcode.Translations[language.Key] == "" {
+			return true
+		}
+		if lgcode.Translations == nil {
+			lgcode.Translations = make(map[string]string)
+		}
+		lgcode.Translations[language.Key] = text
+	}
+	return true
+}
+
+// extraTexts concatenates the texts in the languages which are not built-in
+func (lgcode *Lgcode) extraTexts() string {
+	x := ""
+	for _, lg := range qutil.Languages() {
+		if !qutil.IsBuiltinLanguage(lg.Code) {
+			x += lgcode.Translations[lg.Key]
+		}
+	}
+	return x
 }
 
 // Loads from blob
@@ -214,6 +306,18 @@ func (lgcode *Lgcode) Mumps(batchid string) (mumps qmumps.MUMPS) {
 	}
 	mumps = append(mumps, m)
 
+	for _, lg := range qutil.Languages() {
+		if qutil.IsBuiltinLanguage(lg.Code) {
+			continue
+		}
+		m = qmumps.M{
+			Subs:   []string{"ZA", "data", lg.Key},
+			Value:  qutil.Simplify(lgco.Translation(lg.Code), true),
+			Action: "set",
+		}
+		mumps = append(mumps, m)
+	}
+
 	m = qmumps.M{
 		Subs:   []string{"ZA", "data", "nature"},
 		Value:  lgcode.Nature,
@@ -249,11 +353,12 @@ func (lgcode *Lgcode) Mumps(batchid string) (mumps qmumps.MUMPS) {
 
 func replaceit(lgcode *Lgcode) {
 	r := lgcode.Release()
-	lgcode.N = replaceInString(lgcode.N, "N", r)
-	lgcode.E = replaceInString(lgcode.E, "E", r)
-	lgcode.D = replaceInString(lgcode.D, "D", r)
-	lgcode.F = replaceInString(lgcode.F, "F", r)
-	lgcode.U = replaceInString(lgcode.U, "U", r)
+	for _, lg := range qutil.Languages() {
+		text := lgcode.Translation(lg.Code)
+		if text != "" {
+			lgcode.SetTranslation(lg.Code, replaceInString(text, lg.Code, r))
+		}
+	}
 }
 
 func replaceInString(s string, lg string, r string) string {
@@ -290,19 +395,7 @@ func replaceInString(s string, lg string, r string) string {
 		}
 		lgco = (&lgco).AliasResolve()
 
-		text := ""
-		switch lg {
-		case "N":
-			text = lgco.N
-		case "E":
-			text = lgco.E
-		case "D":
-			text = lgco.D
-		case "F":
-			text = lgco.F
-		case "U":
-			text = lgco.U
-		}
+		text := lgco.Translation(lg)
 		text = replaceInString(text, lg, r)
 		parts[i] = text + l4rest
 	}
@@ -333,6 +426,11 @@ func (lgcode *Lgcode) Deps() []byte {
 		}
 	}
 	x += "\n" + lgcode.N + "\n" + lgcode.E + "\n" + lgcode.D + "\n" + lgcode.F + "\n" + lgcode.U
+	for _, lg := range qutil.Languages() {
+		if text := lgcode.Translations[lg.Key]; text != "" {
+			x += "\n" + text
+		}
+	}
 	return []byte(x)
 }
 
@@ -350,7 +448,7 @@ func (lgcode *Lgcode) Lint() (errslice qerror.ErrorSlice) {
 	}
 
 	id := lgcode.ID
-	x := lgcode.N + lgcode.E + lgcode.D + lgcode.F + lgcode.U + lgcode.Alias + lgcode.Nature
+	x := lgcode.N + lgcode.E + lgcode.D + lgcode.F + lgcode.U + lgcode.extraTexts() + lgcode.Alias + lgcode.Nature
 	if testempty && !isscope && strings.TrimSpace(x) == "" && lgcode.Nature != "empty" {
 		err := &qerror.QError{
 			Ref:    []string{"lgcode.lint.empty"},
@@ -394,7 +492,7 @@ func (lgcode *Lgcode) Lint() (errslice qerror.ErrorSlice) {
 	lregalias := regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
 	alias := lgcode.Alias
 	if alias != "" {
-		y := lgcode.N + lgcode.E + lgcode.D + lgcode.F + lgcode.U + lgcode.Encoding
+		y := lgcode.N + lgcode.E + lgcode.D + lgcode.F + lgcode.U + lgcode.extraTexts() + lgcode.Encoding
 		if strings.TrimSpace(y) != "" {
 			err := &qerror.QError{
 				Ref:    []string{"lgcode.lint.alias.nonempty"},
@@ -453,25 +551,12 @@ func (lgcode *Lgcode) Format() string {
 		lgdefault = ""
 	}
 	ok := false
-	if lgcode.N != "" || lgdefault == "N" {
-		lines = append(lines, "    N: "+qutil.Embrace(lgcode.N))
-		ok = true
-	}
-	if lgcode.E != "" || lgdefault == "E" {
-		lines = append(lines, "    E: "+qutil.Embrace(lgcode.E))
-		ok = true
-	}
-	if lgcode.F != "" || lgdefault == "F" {
-		lines = append(lines, "    F: "+qutil.Embrace(lgcode.F))
-		ok = true
-	}
-	if lgcode.D != "" || lgdefault == "D" {
-		lines = append(lines, "    D: "+qutil.Embrace(lgcode.D))
-		ok = true
-	}
-	if lgcode.U != "" || lgdefault == "U" {
-		lines = append(lines, "    U: "+qutil.Embrace(lgcode.U))
-		ok = true
+	for _, lg := range qutil.Languages() {
+		text := lgcode.Translation(lg.Code)
+		if text != "" || lgdefault == lg.Code {
+			lines = append(lines, "    "+lg.Code+": "+qutil.Embrace(text))
+			ok = true
+		}
 	}
 	if lgcode.Alias != "" {
 		lines = append(lines, "    Alias: "+lgcode.Alias)
@@ -500,27 +585,13 @@ func (lgcode *Lgcode) Replacer(env map[string]string, original string) string {
 	}
 	parts := strings.SplitN(original, "_", 3)
 	algo := parts[1]
-	if strings.IndexAny(algo, "NEFDU") != 0 {
+	if strings.IndexAny(algo, qutil.LanguageCodes()) != 0 {
 		return original
 	}
 	suffix := algo[1:]
-	lg := rune(algo[0])
 	if suffix != "" && suffix != "php" && suffix != "py" && suffix != "js" {
 		return original
 	}
-	data := ""
-	switch lg {
-	case 'N':
-		data = lgcode.N
-	case 'E':
-		data = lgcode.E
-	case 'D':
-		data = lgcode.D
-	case 'F':
-		data = lgcode.F
-	case 'U':
-		data = lgcode.U
-
-	}
+	data := lgcode.Translation(algo[:1])
 	return qutil.ApplyAlgo(data, suffix)
 }
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	qmumps "brocade.be/base/mumps"
+	qregistry "brocade.be/base/registry"
 	qutil "brocade.be/qtechng/lib/util"
 	//qsource "brocade.be/qtechng/lib/source"
 )
@@ -171,3 +172,48 @@ func TestLgcode06(t *testing.T) {
 	}
 
 }
+
+func TestLgcode07(t *testing.T) {
+	r := "9.99"
+	config := qregistry.Registry["lgcode-languages"]
+	qregistry.Registry["lgcode-languages"] = `{"es": {"code": "S", "key": "spa"}}`
+	defer func() { qregistry.Registry["lgcode-languages"] = config }()
+
+	data := []byte(`lgcode lg1:
+		N: Hallo
+		S: «Hola»
+`)
+	lgcode := &Lgcode{
+		ID:      "testcide",
+		Source:  "/test/a/b/c.l",
+		Version: r,
+	}
+	err := lgcode.Loads(data)
+	if err != nil {
+		t.Errorf("Error: %s", err)
+		return
+	}
+	if lgcode.Translation("es-ES") != "Hola" || lgcode.Translation("S") != "Hola" {
+		t.Errorf("Spanish: %v", lgcode.Translations)
+	}
+	mumps := lgcode.Mumps("batch")
+	if len(mumps) != 16 {
+		t.Errorf("Error: %s", mumps)
+	}
+	if !strings.Contains(lgcode.Format(), "S: Hola") {
+		t.Errorf("Format: %s", lgcode.Format())
+	}
+	blob, _ := lgcode.MarshalJSON()
+	other := new(Lgcode)
+	other.Unmarshal(blob)
+	if !strings.Contains(string(blob), `"spa":"Hola"`) || other.Translation("spa") != "Hola" {
+		t.Errorf("JSON: %s", blob)
+	}
+	if lgcode.Replacer(nil, "l4_S_lg1") != "Hola" {
+		t.Errorf("Replacer: %s", lgcode.Replacer(nil, "l4_S_lg1"))
+	}
+	lgcode = new(Lgcode)
+	if lgcode.Loads([]byte("lgcode lg1:\n\tQ: Unknown\n")) == nil {
+		t.Errorf("Q should not be a language")
+	}
+}
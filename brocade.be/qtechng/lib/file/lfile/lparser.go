@@ -82,6 +82,7 @@ var g = &grammar{
 							pos:        position{line: 31, col: 11, offset: 464},
 							val:        "lgcode",
 							ignoreCase: false,
+							want:       "\"lgcode\"",
 						},
 						&oneOrMoreExpr{
 							pos: position{line: 31, col: 20, offset: 473},
@@ -109,6 +110,7 @@ var g = &grammar{
 							pos:        position{line: 31, col: 42, offset: 495},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 31, col: 46, offset: 499},
@@ -141,30 +143,30 @@ var g = &grammar{
 		},
 		{
 			name: "LgcodeID",
-			pos:  position{line: 58, col: 1, offset: 1079},
+			pos:  position{line: 57, col: 1, offset: 1097},
 			expr: &actionExpr{
-				pos: position{line: 58, col: 13, offset: 1091},
+				pos: position{line: 57, col: 13, offset: 1109},
 				run: (*parser).callonLgcodeID1,
 				expr: &labeledExpr{
-					pos:   position{line: 58, col: 13, offset: 1091},
+					pos:   position{line: 57, col: 13, offset: 1109},
 					label: "id",
 					expr: &choiceExpr{
-						pos: position{line: 58, col: 17, offset: 1095},
+						pos: position{line: 57, col: 17, offset: 1113},
 						alternatives: []interface{}{
 							&ruleRefExpr{
-								pos:  position{line: 58, col: 17, offset: 1095},
+								pos:  position{line: 57, col: 17, offset: 1113},
 								name: "ScopeID",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 58, col: 27, offset: 1105},
+								pos:  position{line: 57, col: 27, offset: 1123},
 								name: "TextID",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 58, col: 36, offset: 1114},
+								pos:  position{line: 57, col: 36, offset: 1132},
 								name: "NsID",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 58, col: 43, offset: 1121},
+								pos:  position{line: 57, col: 43, offset: 1139},
 								name: "SimpleID",
 							},
 						},
@@ -174,21 +176,21 @@ var g = &grammar{
 		},
 		{
 			name: "ScopeID",
-			pos:  position{line: 61, col: 1, offset: 1201},
+			pos:  position{line: 60, col: 1, offset: 1219},
 			expr: &seqExpr{
-				pos: position{line: 61, col: 13, offset: 1213},
+				pos: position{line: 60, col: 13, offset: 1231},
 				exprs: []interface{}{
 					&charClassMatcher{
-						pos:        position{line: 61, col: 13, offset: 1213},
+						pos:        position{line: 60, col: 13, offset: 1231},
 						val:        "[a-zA-Z]",
 						ranges:     []rune{'a', 'z', 'A', 'Z'},
 						ignoreCase: false,
 						inverted:   false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 61, col: 22, offset: 1222},
+						pos: position{line: 60, col: 22, offset: 1240},
 						expr: &charClassMatcher{
-							pos:        position{line: 61, col: 22, offset: 1222},
+							pos:        position{line: 60, col: 22, offset: 1240},
 							val:        "[a-zA-Z0-9]",
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 							ignoreCase: false,
@@ -196,14 +198,15 @@ var g = &grammar{
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 61, col: 35, offset: 1235},
+						pos:        position{line: 60, col: 35, offset: 1253},
 						val:        ".",
 						ignoreCase: false,
+						want:       "\".\"",
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 61, col: 39, offset: 1239},
+						pos: position{line: 60, col: 39, offset: 1257},
 						expr: &charClassMatcher{
-							pos:        position{line: 61, col: 39, offset: 1239},
+							pos:        position{line: 60, col: 39, offset: 1257},
 							val:        "[a-zA-Z0-9]",
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 							ignoreCase: false,
@@ -211,30 +214,31 @@ var g = &grammar{
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 61, col: 52, offset: 1252},
+						pos:        position{line: 60, col: 52, offset: 1270},
 						val:        ".scope",
 						ignoreCase: false,
+						want:       "\".scope\"",
 					},
 				},
 			},
 		},
 		{
 			name: "TextID",
-			pos:  position{line: 62, col: 1, offset: 1261},
+			pos:  position{line: 61, col: 1, offset: 1279},
 			expr: &seqExpr{
-				pos: position{line: 62, col: 13, offset: 1273},
+				pos: position{line: 61, col: 13, offset: 1291},
 				exprs: []interface{}{
 					&charClassMatcher{
-						pos:        position{line: 62, col: 13, offset: 1273},
+						pos:        position{line: 61, col: 13, offset: 1291},
 						val:        "[a-zA-Z]",
 						ranges:     []rune{'a', 'z', 'A', 'Z'},
 						ignoreCase: false,
 						inverted:   false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 62, col: 22, offset: 1282},
+						pos: position{line: 61, col: 22, offset: 1300},
 						expr: &charClassMatcher{
-							pos:        position{line: 62, col: 22, offset: 1282},
+							pos:        position{line: 61, col: 22, offset: 1300},
 							val:        "[a-zA-Z0-9]",
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 							ignoreCase: false,
@@ -242,14 +246,15 @@ var g = &grammar{
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 62, col: 35, offset: 1295},
+						pos:        position{line: 61, col: 35, offset: 1313},
 						val:        ".",
 						ignoreCase: false,
+						want:       "\".\"",
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 62, col: 39, offset: 1299},
+						pos: position{line: 61, col: 39, offset: 1317},
 						expr: &charClassMatcher{
-							pos:        position{line: 62, col: 39, offset: 1299},
+							pos:        position{line: 61, col: 39, offset: 1317},
 							val:        "[a-zA-Z0-9]",
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 							ignoreCase: false,
@@ -261,21 +266,21 @@ var g = &grammar{
 		},
 		{
 			name: "NsID",
-			pos:  position{line: 63, col: 1, offset: 1312},
+			pos:  position{line: 62, col: 1, offset: 1330},
 			expr: &seqExpr{
-				pos: position{line: 63, col: 13, offset: 1324},
+				pos: position{line: 62, col: 13, offset: 1342},
 				exprs: []interface{}{
 					&charClassMatcher{
-						pos:        position{line: 63, col: 13, offset: 1324},
+						pos:        position{line: 62, col: 13, offset: 1342},
 						val:        "[a-zA-Z]",
 						ranges:     []rune{'a', 'z', 'A', 'Z'},
 						ignoreCase: false,
 						inverted:   false,
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 63, col: 22, offset: 1333},
+						pos: position{line: 62, col: 22, offset: 1351},
 						expr: &charClassMatcher{
-							pos:        position{line: 63, col: 22, offset: 1333},
+							pos:        position{line: 62, col: 22, offset: 1351},
 							val:        "[a-zA-Z0-9]",
 							ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
 							ignoreCase: false,
@@ -283,20 +288,21 @@ var g = &grammar{
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 63, col: 35, offset: 1346},
+						pos:        position{line: 62, col: 35, offset: 1364},
 						val:        ".",
 						ignoreCase: false,
+						want:       "\".\"",
 					},
 				},
 			},
 		},
 		{
 			name: "SimpleID",
-			pos:  position{line: 64, col: 1, offset: 1350},
+			pos:  position{line: 63, col: 1, offset: 1368},
 			expr: &oneOrMoreExpr{
-				pos: position{line: 64, col: 13, offset: 1362},
+				pos: position{line: 63, col: 13, offset: 1380},
 				expr: &charClassMatcher{
-					pos:        position{line: 64, col: 13, offset: 1362},
+					pos:        position{line: 63, col: 13, offset: 1380},
 					val:        "[a-zA-Z0-9_ -]",
 					chars:      []rune{'_', ' ', '-'},
 					ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
@@ -307,59 +313,60 @@ var g = &grammar{
 		},
 		{
 			name: "Translation",
-			pos:  position{line: 68, col: 1, offset: 1381},
+			pos:  position{line: 67, col: 1, offset: 1399},
 			expr: &actionExpr{
-				pos: position{line: 68, col: 16, offset: 1396},
+				pos: position{line: 67, col: 16, offset: 1414},
 				run: (*parser).callonTranslation1,
 				expr: &seqExpr{
-					pos: position{line: 68, col: 16, offset: 1396},
+					pos: position{line: 67, col: 16, offset: 1414},
 					exprs: []interface{}{
 						&zeroOrMoreExpr{
-							pos: position{line: 68, col: 16, offset: 1396},
+							pos: position{line: 67, col: 16, offset: 1414},
 							expr: &ruleRefExpr{
-								pos:  position{line: 68, col: 16, offset: 1396},
+								pos:  position{line: 67, col: 16, offset: 1414},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 68, col: 20, offset: 1400},
+							pos:   position{line: 67, col: 20, offset: 1418},
 							label: "prefix",
 							expr: &ruleRefExpr{
-								pos:  position{line: 68, col: 27, offset: 1407},
+								pos:  position{line: 67, col: 27, offset: 1425},
 								name: "Prefix",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 68, col: 35, offset: 1415},
+							pos: position{line: 67, col: 35, offset: 1433},
 							expr: &ruleRefExpr{
-								pos:  position{line: 68, col: 35, offset: 1415},
+								pos:  position{line: 67, col: 35, offset: 1433},
 								name: "WS",
 							},
 						},
 						&litMatcher{
-							pos:        position{line: 68, col: 39, offset: 1419},
+							pos:        position{line: 67, col: 39, offset: 1437},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 68, col: 43, offset: 1423},
+							pos: position{line: 67, col: 43, offset: 1441},
 							expr: &ruleRefExpr{
-								pos:  position{line: 68, col: 43, offset: 1423},
+								pos:  position{line: 67, col: 43, offset: 1441},
 								name: "WS",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 68, col: 47, offset: 1427},
+							pos:   position{line: 67, col: 47, offset: 1445},
 							label: "text",
 							expr: &ruleRefExpr{
-								pos:  position{line: 68, col: 52, offset: 1432},
+								pos:  position{line: 67, col: 52, offset: 1450},
 								name: "Text",
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 68, col: 57, offset: 1437},
+							pos: position{line: 67, col: 57, offset: 1455},
 							expr: &ruleRefExpr{
-								pos:  position{line: 68, col: 57, offset: 1437},
+								pos:  position{line: 67, col: 57, offset: 1455},
 								name: "WS",
 							},
 						},
@@ -369,35 +376,38 @@ var g = &grammar{
 		},
 		{
 			name: "Prefix",
-			pos:  position{line: 73, col: 1, offset: 1502},
+			pos:  position{line: 72, col: 1, offset: 1520},
 			expr: &actionExpr{
-				pos: position{line: 73, col: 11, offset: 1512},
+				pos: position{line: 72, col: 11, offset: 1530},
 				run: (*parser).callonPrefix1,
 				expr: &labeledExpr{
-					pos:   position{line: 73, col: 11, offset: 1512},
+					pos:   position{line: 72, col: 11, offset: 1530},
 					label: "prefix",
 					expr: &choiceExpr{
-						pos: position{line: 73, col: 19, offset: 1520},
+						pos: position{line: 72, col: 19, offset: 1538},
 						alternatives: []interface{}{
 							&litMatcher{
-								pos:        position{line: 73, col: 19, offset: 1520},
+								pos:        position{line: 72, col: 19, offset: 1538},
 								val:        "Alias",
 								ignoreCase: false,
+								want:       "\"Alias\"",
 							},
 							&litMatcher{
-								pos:        position{line: 73, col: 29, offset: 1530},
+								pos:        position{line: 72, col: 29, offset: 1548},
 								val:        "Nature",
 								ignoreCase: false,
+								want:       "\"Nature\"",
 							},
 							&litMatcher{
-								pos:        position{line: 73, col: 40, offset: 1541},
+								pos:        position{line: 72, col: 40, offset: 1559},
 								val:        "Encoding",
 								ignoreCase: false,
+								want:       "\"Encoding\"",
 							},
 							&charClassMatcher{
-								pos:        position{line: 73, col: 53, offset: 1554},
-								val:        "[NEFDU]",
-								chars:      []rune{'N', 'E', 'F', 'D', 'U'},
+								pos:        position{line: 72, col: 53, offset: 1572},
+								val:        "[A-Z]",
+								ranges:     []rune{'A', 'Z'},
 								ignoreCase: false,
 								inverted:   false,
 							},
@@ -408,64 +418,64 @@ var g = &grammar{
 		},
 		{
 			name: "Text",
-			pos:  position{line: 77, col: 1, offset: 1596},
+			pos:  position{line: 76, col: 1, offset: 1612},
 			expr: &actionExpr{
-				pos: position{line: 77, col: 9, offset: 1604},
+				pos: position{line: 76, col: 9, offset: 1620},
 				run: (*parser).callonText1,
 				expr: &seqExpr{
-					pos: position{line: 77, col: 9, offset: 1604},
+					pos: position{line: 76, col: 9, offset: 1620},
 					exprs: []interface{}{
 						&labeledExpr{
-							pos:   position{line: 77, col: 9, offset: 1604},
+							pos:   position{line: 76, col: 9, offset: 1620},
 							label: "text",
 							expr: &choiceExpr{
-								pos: position{line: 77, col: 15, offset: 1610},
+								pos: position{line: 76, col: 15, offset: 1626},
 								alternatives: []interface{}{
 									&seqExpr{
-										pos: position{line: 77, col: 16, offset: 1611},
+										pos: position{line: 76, col: 16, offset: 1627},
 										exprs: []interface{}{
 											&ruleRefExpr{
-												pos:  position{line: 77, col: 16, offset: 1611},
+												pos:  position{line: 76, col: 16, offset: 1627},
 												name: "TS1",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 77, col: 20, offset: 1615},
+												pos:  position{line: 76, col: 20, offset: 1631},
 												name: "CHARS1",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 77, col: 27, offset: 1622},
+												pos:  position{line: 76, col: 27, offset: 1638},
 												name: "TE1",
 											},
 										},
 									},
 									&seqExpr{
-										pos: position{line: 77, col: 35, offset: 1630},
+										pos: position{line: 76, col: 35, offset: 1646},
 										exprs: []interface{}{
 											&ruleRefExpr{
-												pos:  position{line: 77, col: 35, offset: 1630},
+												pos:  position{line: 76, col: 35, offset: 1646},
 												name: "TS2",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 77, col: 39, offset: 1634},
+												pos:  position{line: 76, col: 39, offset: 1650},
 												name: "CHARS2",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 77, col: 46, offset: 1641},
+												pos:  position{line: 76, col: 46, offset: 1657},
 												name: "TE2",
 											},
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 77, col: 53, offset: 1648},
+										pos:  position{line: 76, col: 53, offset: 1664},
 										name: "CHARS3",
 									},
 								},
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 77, col: 62, offset: 1657},
+							pos: position{line: 76, col: 62, offset: 1673},
 							expr: &ruleRefExpr{
-								pos:  position{line: 77, col: 62, offset: 1657},
+								pos:  position{line: 76, col: 62, offset: 1673},
 								name: "WS",
 							},
 						},
@@ -475,33 +485,35 @@ var g = &grammar{
 		},
 		{
 			name: "Line",
-			pos:  position{line: 82, col: 1, offset: 1706},
+			pos:  position{line: 81, col: 1, offset: 1722},
 			expr: &actionExpr{
-				pos: position{line: 82, col: 9, offset: 1714},
+				pos: position{line: 81, col: 9, offset: 1730},
 				run: (*parser).callonLine1,
 				expr: &seqExpr{
-					pos: position{line: 82, col: 9, offset: 1714},
+					pos: position{line: 81, col: 9, offset: 1730},
 					exprs: []interface{}{
 						&seqExpr{
-							pos: position{line: 82, col: 10, offset: 1715},
+							pos: position{line: 81, col: 10, offset: 1731},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 82, col: 10, offset: 1715},
+									pos:        position{line: 81, col: 10, offset: 1731},
 									val:        "",
 									ignoreCase: false,
+									want:       "\"\"",
 								},
 								&notExpr{
-									pos: position{line: 82, col: 13, offset: 1718},
+									pos: position{line: 81, col: 13, offset: 1734},
 									expr: &choiceExpr{
-										pos: position{line: 82, col: 15, offset: 1720},
+										pos: position{line: 81, col: 15, offset: 1736},
 										alternatives: []interface{}{
 											&litMatcher{
-												pos:        position{line: 82, col: 15, offset: 1720},
+												pos:        position{line: 81, col: 15, offset: 1736},
 												val:        "lgcode",
 												ignoreCase: false,
+												want:       "\"lgcode\"",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 82, col: 26, offset: 1731},
+												pos:  position{line: 81, col: 26, offset: 1747},
 												name: "EOF",
 											},
 										},
@@ -510,9 +522,9 @@ var g = &grammar{
 							},
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 82, col: 32, offset: 1737},
+							pos: position{line: 81, col: 32, offset: 1753},
 							expr: &charClassMatcher{
-								pos:        position{line: 82, col: 32, offset: 1737},
+								pos:        position{line: 81, col: 32, offset: 1753},
 								val:        "[^\\n\\r]",
 								chars:      []rune{'\n', '\r'},
 								ignoreCase: false,
@@ -520,9 +532,9 @@ var g = &grammar{
 							},
 						},
 						&zeroOrOneExpr{
-							pos: position{line: 82, col: 41, offset: 1746},
+							pos: position{line: 81, col: 41, offset: 1762},
 							expr: &ruleRefExpr{
-								pos:  position{line: 82, col: 41, offset: 1746},
+								pos:  position{line: 81, col: 41, offset: 1762},
 								name: "EOL",
 							},
 						},
@@ -532,19 +544,20 @@ var g = &grammar{
 		},
 		{
 			name: "CommentLine",
-			pos:  position{line: 86, col: 1, offset: 1785},
+			pos:  position{line: 85, col: 1, offset: 1801},
 			expr: &seqExpr{
-				pos: position{line: 86, col: 17, offset: 1801},
+				pos: position{line: 85, col: 17, offset: 1817},
 				exprs: []interface{}{
 					&litMatcher{
-						pos:        position{line: 86, col: 17, offset: 1801},
+						pos:        position{line: 85, col: 17, offset: 1817},
 						val:        "//",
 						ignoreCase: false,
+						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 86, col: 22, offset: 1806},
+						pos: position{line: 85, col: 22, offset: 1822},
 						expr: &charClassMatcher{
-							pos:        position{line: 86, col: 22, offset: 1806},
+							pos:        position{line: 85, col: 22, offset: 1822},
 							val:        "[^\\n\\r]",
 							chars:      []rune{'\n', '\r'},
 							ignoreCase: false,
@@ -556,26 +569,26 @@ var g = &grammar{
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 88, col: 1, offset: 1817},
+			pos:  position{line: 87, col: 1, offset: 1833},
 			expr: &actionExpr{
-				pos: position{line: 88, col: 12, offset: 1828},
+				pos: position{line: 87, col: 12, offset: 1844},
 				run: (*parser).callonComment1,
 				expr: &labeledExpr{
-					pos:   position{line: 88, col: 12, offset: 1828},
+					pos:   position{line: 87, col: 12, offset: 1844},
 					label: "comment",
 					expr: &oneOrMoreExpr{
-						pos: position{line: 88, col: 20, offset: 1836},
+						pos: position{line: 87, col: 20, offset: 1852},
 						expr: &choiceExpr{
-							pos: position{line: 88, col: 21, offset: 1837},
+							pos: position{line: 87, col: 21, offset: 1853},
 							alternatives: []interface{}{
 								&ruleRefExpr{
-									pos:  position{line: 88, col: 21, offset: 1837},
+									pos:  position{line: 87, col: 21, offset: 1853},
 									name: "CommentLine",
 								},
 								&oneOrMoreExpr{
-									pos: position{line: 88, col: 35, offset: 1851},
+									pos: position{line: 87, col: 35, offset: 1867},
 									expr: &ruleRefExpr{
-										pos:  position{line: 88, col: 35, offset: 1851},
+										pos:  position{line: 87, col: 35, offset: 1867},
 										name: "WS",
 									},
 								},
@@ -587,9 +600,9 @@ var g = &grammar{
 		},
 		{
 			name: "WS",
-			pos:  position{line: 92, col: 1, offset: 1890},
+			pos:  position{line: 91, col: 1, offset: 1906},
 			expr: &charClassMatcher{
-				pos:        position{line: 92, col: 7, offset: 1896},
+				pos:        position{line: 91, col: 7, offset: 1912},
 				val:        "[ \\n\\t\\r]",
 				chars:      []rune{' ', '\n', '\t', '\r'},
 				ignoreCase: false,
@@ -598,88 +611,97 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 94, col: 1, offset: 1907},
+			pos:  position{line: 93, col: 1, offset: 1923},
 			expr: &choiceExpr{
-				pos: position{line: 94, col: 9, offset: 1915},
+				pos: position{line: 93, col: 9, offset: 1931},
 				alternatives: []interface{}{
 					&litMatcher{
-						pos:        position{line: 94, col: 9, offset: 1915},
+						pos:        position{line: 93, col: 9, offset: 1931},
 						val:        "\r\n",
 						ignoreCase: false,
+						want:       "\"\\r\\n\"",
 					},
 					&litMatcher{
-						pos:        position{line: 94, col: 18, offset: 1924},
+						pos:        position{line: 93, col: 18, offset: 1940},
 						val:        "\n\r",
 						ignoreCase: false,
+						want:       "\"\\n\\r\"",
 					},
 					&litMatcher{
-						pos:        position{line: 94, col: 27, offset: 1933},
+						pos:        position{line: 93, col: 27, offset: 1949},
 						val:        "\r",
 						ignoreCase: false,
+						want:       "\"\\r\"",
 					},
 					&litMatcher{
-						pos:        position{line: 94, col: 34, offset: 1940},
+						pos:        position{line: 93, col: 34, offset: 1956},
 						val:        "\n",
 						ignoreCase: false,
+						want:       "\"\\n\"",
 					},
 				},
 			},
 		},
 		{
 			name: "TS",
-			pos:  position{line: 97, col: 1, offset: 1948},
+			pos:  position{line: 96, col: 1, offset: 1964},
 			expr: &litMatcher{
-				pos:        position{line: 97, col: 7, offset: 1954},
+				pos:        position{line: 96, col: 7, offset: 1970},
 				val:        "[⟦«]",
 				ignoreCase: false,
+				want:       "\"[⟦«]\"",
 			},
 		},
 		{
 			name: "TS1",
-			pos:  position{line: 98, col: 1, offset: 1964},
+			pos:  position{line: 97, col: 1, offset: 1980},
 			expr: &litMatcher{
-				pos:        position{line: 98, col: 8, offset: 1971},
+				pos:        position{line: 97, col: 8, offset: 1987},
 				val:        "⟦",
 				ignoreCase: false,
+				want:       "\"⟦\"",
 			},
 		},
 		{
 			name: "TE1",
-			pos:  position{line: 99, col: 1, offset: 1977},
+			pos:  position{line: 98, col: 1, offset: 1993},
 			expr: &litMatcher{
-				pos:        position{line: 99, col: 8, offset: 1984},
+				pos:        position{line: 98, col: 8, offset: 2000},
 				val:        "⟧",
 				ignoreCase: false,
+				want:       "\"⟧\"",
 			},
 		},
 		{
 			name: "TS2",
-			pos:  position{line: 100, col: 1, offset: 1990},
+			pos:  position{line: 99, col: 1, offset: 2006},
 			expr: &litMatcher{
-				pos:        position{line: 100, col: 8, offset: 1997},
+				pos:        position{line: 99, col: 8, offset: 2013},
 				val:        "«",
 				ignoreCase: false,
+				want:       "\"«\"",
 			},
 		},
 		{
 			name: "TE2",
-			pos:  position{line: 101, col: 1, offset: 2002},
+			pos:  position{line: 100, col: 1, offset: 2018},
 			expr: &litMatcher{
-				pos:        position{line: 101, col: 8, offset: 2009},
+				pos:        position{line: 100, col: 8, offset: 2025},
 				val:        "»",
 				ignoreCase: false,
+				want:       "\"»\"",
 			},
 		},
 		{
 			name: "CHARS1",
-			pos:  position{line: 103, col: 1, offset: 2015},
+			pos:  position{line: 102, col: 1, offset: 2031},
 			expr: &actionExpr{
-				pos: position{line: 103, col: 11, offset: 2025},
+				pos: position{line: 102, col: 11, offset: 2041},
 				run: (*parser).callonCHARS11,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 103, col: 11, offset: 2025},
+					pos: position{line: 102, col: 11, offset: 2041},
 					expr: &charClassMatcher{
-						pos:        position{line: 103, col: 11, offset: 2025},
+						pos:        position{line: 102, col: 11, offset: 2041},
 						val:        "[^⟦⟧]",
 						chars:      []rune{'⟦', '⟧'},
 						ignoreCase: false,
@@ -690,14 +712,14 @@ var g = &grammar{
 		},
 		{
 			name: "CHARS2",
-			pos:  position{line: 107, col: 1, offset: 2069},
+			pos:  position{line: 106, col: 1, offset: 2085},
 			expr: &actionExpr{
-				pos: position{line: 107, col: 11, offset: 2079},
+				pos: position{line: 106, col: 11, offset: 2095},
 				run: (*parser).callonCHARS21,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 107, col: 11, offset: 2079},
+					pos: position{line: 106, col: 11, offset: 2095},
 					expr: &charClassMatcher{
-						pos:        position{line: 107, col: 11, offset: 2079},
+						pos:        position{line: 106, col: 11, offset: 2095},
 						val:        "[^«»]",
 						chars:      []rune{'«', '»'},
 						ignoreCase: false,
@@ -708,14 +730,14 @@ var g = &grammar{
 		},
 		{
 			name: "CHARS3",
-			pos:  position{line: 111, col: 1, offset: 2121},
+			pos:  position{line: 110, col: 1, offset: 2137},
 			expr: &actionExpr{
-				pos: position{line: 111, col: 11, offset: 2131},
+				pos: position{line: 110, col: 11, offset: 2147},
 				run: (*parser).callonCHARS31,
 				expr: &zeroOrMoreExpr{
-					pos: position{line: 111, col: 11, offset: 2131},
+					pos: position{line: 110, col: 11, offset: 2147},
 					expr: &charClassMatcher{
-						pos:        position{line: 111, col: 11, offset: 2131},
+						pos:        position{line: 110, col: 11, offset: 2147},
 						val:        "[^\\n\\r]",
 						chars:      []rune{'\n', '\r'},
 						ignoreCase: false,
@@ -726,11 +748,11 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 116, col: 1, offset: 2174},
+			pos:  position{line: 115, col: 1, offset: 2190},
 			expr: &notExpr{
-				pos: position{line: 116, col: 8, offset: 2181},
+				pos: position{line: 115, col: 8, offset: 2197},
 				expr: &anyMatcher{
-					line: 116, col: 9, offset: 2182,
+					line: 115, col: 9, offset: 2198,
 				},
 			},
 		},
@@ -777,22 +799,16 @@ func (c *current) onLgcode1(id, translations interface{}) (interface{}, error) {
 		prefix := tr[0]
 		text := tr[1]
 		switch prefix {
-		case "N":
-			lgcode.N = text
-		case "E":
-			lgcode.E = text
-		case "D":
-			lgcode.D = text
-		case "F":
-			lgcode.F = text
-		case "U":
-			lgcode.U = text
 		case "Alias":
 			lgcode.Alias = text
 		case "Encoding":
 			lgcode.Alias = text
 		case "Nature":
 			lgcode.Nature = text
+		default:
+			if !lgcode.SetTranslation(prefix, text) {
+				return &lgcode, errors.New("unknown language `" + prefix + "` in lgcode `" + lgcode.ID + "`")
+			}
 		}
 	}
 	return &lgcode, nil
@@ -955,18 +971,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -1204,6 +1219,7 @@ type litMatcher struct {
 	pos        position
 	val        string
 	ignoreCase bool
+	want       string
 }
 
 type charClassMatcher struct {
@@ -1998,11 +2014,6 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
-	ignoreCase := ""
-	if lit.ignoreCase {
-		ignoreCase = "i"
-	}
-	val := string(strconv.AppendQuote([]byte{}, lit.val)) + ignoreCase // wrap 'lit.val' with double quotes
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
@@ -2010,13 +2021,13 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 			cur = unicode.ToLower(cur)
 		}
 		if cur != want {
-			p.failAt(false, start.position, val)
+			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
 		}
 		p.read()
 	}
-	p.failAt(true, start.position, val)
+	p.failAt(true, start.position, lit.want)
 	return p.sliceFrom(start), true
 }
 
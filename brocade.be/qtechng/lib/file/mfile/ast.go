@@ -0,0 +1,690 @@
+package mfile
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Routine is the syntax tree of an M file
+type Routine struct {
+	Name   string
+	Labels []*Label
+	Lines  []*Line // lines of the generated routine: without blank lines and `//` comments
+}
+
+// Label is a label in an M routine
+type Label struct {
+	Name    string
+	Kind    string   // "def", "sub", "fn" or ""
+	Formals []string // nil if the label has no formal list
+	Lineno  int
+}
+
+// Line is a line of M code
+type Line struct {
+	Lineno   int
+	Label    *Label // label on the line, if any
+	Level    int    // number of dots
+	Code     string // the line without comment
+	Commands []*Command
+}
+
+// Command is an M command with its postconditional and arguments
+type Command struct {
+	Name     string // full name in upper case (e.g. "SET"), the macro or "" if unknown
+	Word     string // command word as written
+	Postcond string
+	Args     []string
+	Column   int // 1-based column of the command word
+}
+
+// EntryRef is a reference to a label and/or a routine
+type EntryRef struct {
+	Label     string // empty: the top of the routine
+	Offset    int
+	Routine   string // empty: the current routine
+	Extrinsic bool   // $$label^routine
+	Command   string // command containing the reference
+	Lineno    int
+}
+
+// String returns the reference in M notation
+func (ref EntryRef) String() string {
+	s := ref.Label
+	if ref.Offset != 0 {
+		s += "+" + strconv.Itoa(ref.Offset)
+	}
+	if ref.Routine != "" {
+		s += "^" + ref.Routine
+	}
+	if ref.Extrinsic {
+		s = "$$" + s
+	}
+	return s
+}
+
+var mCommands = map[string]string{
+	"B": "BREAK", "BREAK": "BREAK",
+	"C": "CLOSE", "CLOSE": "CLOSE",
+	"D": "DO", "DO": "DO",
+	"E": "ELSE", "ELSE": "ELSE",
+	"F": "FOR", "FOR": "FOR",
+	"G": "GOTO", "GOTO": "GOTO",
+	"H": "HALT", "HALT": "HALT", "HANG": "HANG",
+	"I": "IF", "IF": "IF",
+	"J": "JOB", "JOB": "JOB",
+	"K": "KILL", "KILL": "KILL",
+	"L": "LOCK", "LOCK": "LOCK",
+	"M": "MERGE", "MERGE": "MERGE",
+	"N": "NEW", "NEW": "NEW",
+	"O": "OPEN", "OPEN": "OPEN",
+	"Q": "QUIT", "QUIT": "QUIT",
+	"R": "READ", "READ": "READ",
+	"S": "SET", "SET": "SET",
+	"TC": "TCOMMIT", "TCOMMIT": "TCOMMIT",
+	"TRE": "TRESTART", "TRESTART": "TRESTART",
+	"TRO": "TROLLBACK", "TROLLBACK": "TROLLBACK",
+	"TS": "TSTART", "TSTART": "TSTART",
+	"U": "USE", "USE": "USE",
+	"V": "VIEW", "VIEW": "VIEW",
+	"W": "WRITE", "WRITE": "WRITE",
+	"X": "XECUTE", "XECUTE": "XECUTE",
+}
+
+// Parse builds the syntax tree of an M file in qtechng format.
+// The parser is tolerant: constructions it does not understand are kept as they are.
+func Parse(name string, body []byte) *Routine {
+	routine := &Routine{
+		Name:   name,
+		Labels: make([]*Label, 0),
+		Lines:  make([]*Line, 0),
+	}
+	for i, text := range strings.Split(string(body), "\n") {
+		text = strings.TrimRight(text, "\r")
+		code, comment := SplitComment(text)
+		if strings.HasPrefix(comment, "//") {
+			comment = ""
+		}
+		if strings.TrimSpace(code) == "" && comment == "" {
+			continue
+		}
+		line := &Line{Lineno: i + 1}
+		label, rest := parseLabel(code)
+		if label != nil {
+			label.Lineno = line.Lineno
+			line.Label = label
+			routine.Labels = append(routine.Labels, label)
+		}
+		rest = strings.TrimLeft(rest, " \t")
+		for strings.HasPrefix(rest, ".") {
+			line.Level++
+			rest = strings.TrimLeft(rest[1:], " \t")
+		}
+		line.Code = strings.TrimRight(code, " \t")
+		line.Commands = parseCommands(rest, len(code)-len(rest))
+		routine.Lines = append(routine.Lines, line)
+	}
+	return routine
+}
+
+// Label returns the label with a given name
+func (routine *Routine) Label(name string) *Label {
+	for _, label := range routine.Labels {
+		if label.Name == name {
+			return label
+		}
+	}
+	return nil
+}
+
+// Target returns the line a reference `label+offset` points to.
+// Offsets from the top of the routine are not resolved: qtechng adds lines to the top.
+func (routine *Routine) Target(label string, offset int) *Line {
+	start := -1
+	for i, line := range routine.Lines {
+		if line.Label != nil && line.Label.Name == label {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		return nil
+	}
+	k := start + offset
+	if k < 0 || k >= len(routine.Lines) {
+		return nil
+	}
+	return routine.Lines[k]
+}
+
+// SplitComment splits a line in code and comment (`;` or `//`)
+func SplitComment(line string) (code string, comment string) {
+	closer := rune(0)
+	prev := rune(0)
+	for k, r := range line {
+		switch {
+		case closer != 0:
+			if r == closer {
+				closer = 0
+			}
+		case r == '"':
+			closer = '"'
+		case r == '«':
+			closer = '»'
+		case r == '⟦':
+			closer = '⟧'
+		case r == ';':
+			return line[:k], line[k:]
+		case r == '/' && prev == '/':
+			return line[:k-1], line[k-1:]
+		}
+		prev = r
+	}
+	return line, ""
+}
+
+func isNameChar(c byte, first bool) bool {
+	switch {
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	case c == '%':
+		return first
+	case c >= '0' && c <= '9':
+		return !first
+	}
+	return false
+}
+
+// scanName returns the end of an M name starting at i (0 if there is no name).
+// Macros like `m4_name` are recognised as one name.
+func scanName(s string, i int) (end int, macro bool) {
+	j := i
+	for j < len(s) && isNameChar(s[j], j == i) {
+		j++
+	}
+	if j == i {
+		return i, false
+	}
+	if j-i == 2 && s[i+1] == '4' && j < len(s) && s[j] == '_' {
+		j++
+		for j < len(s) && (isNameChar(s[j], false) || s[j] == '_') {
+			j++
+		}
+		return j, true
+	}
+	return j, false
+}
+
+// parseLabel finds the label at the start of a line
+func parseLabel(code string) (label *Label, rest string) {
+	if code == "" || code[0] == ' ' || code[0] == '\t' || code[0] == '.' {
+		return nil, code
+	}
+	kind := ""
+	s := code
+	for _, k := range []string{"def", "sub", "fn"} {
+		if strings.HasPrefix(s, k) && len(s) > len(k) && (s[len(k)] == ' ' || s[len(k)] == '\t') {
+			kind = k
+			s = strings.TrimLeft(s[len(k):], " \t")
+			break
+		}
+	}
+	j := 0
+	for j < len(s) && (isNameChar(s[j], j == 0) || (s[j] >= '0' && s[j] <= '9')) {
+		j++
+	}
+	if j == 0 {
+		return nil, code
+	}
+	label = &Label{Name: s[:j], Kind: kind}
+	s = s[j:]
+	if strings.HasPrefix(s, "(") {
+		k := strings.Index(s, ")")
+		if k == -1 {
+			k = len(s) - 1
+		}
+		label.Formals = make([]string, 0)
+		for _, formal := range strings.Split(s[1:k+1], ",") {
+			formal = strings.TrimSpace(strings.TrimSuffix(formal, ")"))
+			if formal != "" {
+				label.Formals = append(label.Formals, formal)
+			}
+		}
+		s = s[k+1:]
+	}
+	if kind != "" {
+		s = strings.TrimLeft(s, " \t")
+		s = strings.TrimPrefix(s, ":")
+	}
+	return label, s
+}
+
+// scanArgument returns the end of an argument, postconditional or expression starting at i:
+// the first space outside strings and parentheses
+func scanArgument(s string, i int) int {
+	depth := 0
+	closer := rune(0)
+	for k, r := range s[i:] {
+		switch {
+		case closer != 0:
+			if r == closer {
+				closer = 0
+			}
+		case r == '"':
+			closer = '"'
+		case r == '«':
+			closer = '»'
+		case r == '⟦':
+			closer = '⟧'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case (r == ' ' || r == '\t') && depth <= 0:
+			return i + k
+		}
+	}
+	return len(s)
+}
+
+// SplitArgs splits an argument list on the commas outside strings and parentheses
+func SplitArgs(s string) []string {
+	args := make([]string, 0)
+	if s == "" {
+		return args
+	}
+	depth := 0
+	closer := rune(0)
+	start := 0
+	for k, r := range s {
+		switch {
+		case closer != 0:
+			if r == closer {
+				closer = 0
+			}
+		case r == '"':
+			closer = '"'
+		case r == '«':
+			closer = '»'
+		case r == '⟦':
+			closer = '⟧'
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			args = append(args, s[start:k])
+			start = k + 1
+		}
+	}
+	return append(args, s[start:])
+}
+
+// parseCommands splits the command part of a line in commands.
+// offset is the position of s in the line.
+func parseCommands(s string, offset int) []*Command {
+	commands := make([]*Command, 0)
+	i := 0
+	for i < len(s) {
+		if s[i] == ' ' || s[i] == '\t' {
+			i++
+			continue
+		}
+		cmd := &Command{Column: offset + i + 1}
+		j, macro := scanName(s, i)
+		if j == i {
+			j = scanArgument(s, i)
+			cmd.Word = s[i:j]
+			commands = append(commands, cmd)
+			i = j
+			continue
+		}
+		cmd.Word = s[i:j]
+		i = j
+		if macro {
+			cmd.Name = cmd.Word
+			j = scanArgument(s, i)
+			if j > i {
+				cmd.Args = []string{s[i:j]}
+			}
+			commands = append(commands, cmd)
+			i = j
+			continue
+		}
+		cmd.Name = mCommands[strings.ToUpper(cmd.Word)]
+		if cmd.Name == "" && (cmd.Word[0] == 'z' || cmd.Word[0] == 'Z') {
+			cmd.Name = strings.ToUpper(cmd.Word)
+		}
+		if i < len(s) && s[i] == ':' {
+			j = scanArgument(s, i+1)
+			cmd.Postcond = s[i+1 : j]
+			i = j
+		}
+		if i+1 < len(s) && (s[i] == ' ' || s[i] == '\t') && s[i+1] != ' ' && s[i+1] != '\t' {
+			j = scanArgument(s, i+1)
+			cmd.Args = SplitArgs(s[i+1 : j])
+			i = j
+		}
+		if cmd.Name == "HALT" && len(cmd.Args) != 0 {
+			cmd.Name = "HANG"
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+// ParseEntryRef reads the entryref at the start of an argument of DO, GOTO or JOB.
+// The rest of the argument (actual parameters, postconditional) is returned as well.
+// Indirect references and macros give a nil reference.
+func ParseEntryRef(arg string) (ref *EntryRef, rest string) {
+	j, macro := scanName(arg, 0)
+	if macro || strings.HasPrefix(arg, "@") {
+		return nil, arg
+	}
+	ref = &EntryRef{Label: arg[:j]}
+	s := arg[j:]
+	if strings.HasPrefix(s, "+") {
+		k := 1
+		for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+			k++
+		}
+		if k == 1 {
+			return nil, arg
+		}
+		ref.Offset, _ = strconv.Atoi(s[1:k])
+		s = s[k:]
+	}
+	if strings.HasPrefix(s, "^") {
+		k, macro := scanName(s, 1)
+		if k == 1 || macro {
+			return nil, arg
+		}
+		ref.Routine = s[1:k]
+		s = s[k:]
+	}
+	if ref.Label == "" && ref.Routine == "" {
+		return nil, arg
+	}
+	return ref, s
+}
+
+// extrinsics finds the extrinsic functions `$$label^routine` in an expression
+func extrinsics(expr string) []EntryRef {
+	refs := make([]EntryRef, 0)
+	closer := byte(0)
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case closer != 0:
+			if c == closer {
+				closer = 0
+			}
+			continue
+		case c == '"':
+			closer = '"'
+			continue
+		case c != '$' || i+1 == len(expr) || expr[i+1] != '$':
+			continue
+		}
+		ref, rest := ParseEntryRef(expr[i+2:])
+		if ref != nil {
+			ref.Extrinsic = true
+			refs = append(refs, *ref)
+		}
+		i = len(expr) - len(rest) - 1
+	}
+	return refs
+}
+
+// Refs returns the references to labels and routines in the command
+func (cmd *Command) Refs() []EntryRef {
+	refs := extrinsics(cmd.Postcond)
+	for _, arg := range cmd.Args {
+		if cmd.Name == "DO" || cmd.Name == "GOTO" || cmd.Name == "JOB" {
+			ref, rest := ParseEntryRef(arg)
+			if ref != nil {
+				refs = append(refs, *ref)
+			}
+			arg = rest
+		}
+		refs = append(refs, extrinsics(arg)...)
+	}
+	for i := range refs {
+		refs[i].Command = cmd.Name
+	}
+	return refs
+}
+
+// Refs returns the references to labels and routines in the line
+func (line *Line) Refs() []EntryRef {
+	refs := make([]EntryRef, 0)
+	for _, cmd := range line.Commands {
+		for _, ref := range cmd.Refs() {
+			ref.Lineno = line.Lineno
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// Names returns the local variables used in the command.
+// Labels and routines in DO, GOTO and JOB are not variables.
+func (cmd *Command) Names() []string {
+	names := Names(cmd.Postcond)
+	for _, arg := range cmd.Args {
+		if cmd.Name == "DO" || cmd.Name == "GOTO" || cmd.Name == "JOB" {
+			_, arg = ParseEntryRef(arg)
+		}
+		names = append(names, Names(arg)...)
+	}
+	return names
+}
+
+// Names returns the local variable names in an M expression.
+// Strings, intrinsic functions, globals, extrinsic functions, macros and patterns are skipped.
+func Names(expr string) []string {
+	names := make([]string, 0)
+	s := expr
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"':
+			k := strings.IndexByte(s[i+1:], '"')
+			if k == -1 {
+				return names
+			}
+			i += k + 2
+		case strings.HasPrefix(s[i:], "«"):
+			k := strings.Index(s[i:], "»")
+			if k == -1 {
+				return names
+			}
+			i += k + len("»")
+		case strings.HasPrefix(s[i:], "⟦"):
+			k := strings.Index(s[i:], "⟧")
+			if k == -1 {
+				return names
+			}
+			i += k + len("⟧")
+		case c == '$' && i+1 < len(s) && s[i+1] == '$':
+			_, rest := ParseEntryRef(s[i+2:])
+			if len(rest) == len(s)-i-2 {
+				i += 2
+				continue
+			}
+			i = len(s) - len(rest)
+		case c == '$' || c == '^':
+			i++
+			if c == '^' && i < len(s) && s[i] == '$' {
+				i++
+			}
+			j, _ := scanName(s, i)
+			i = j
+		case c == '?' && i+1 < len(s) && s[i+1] != '@':
+			i++
+			for i < len(s) {
+				if s[i] == '"' {
+					k := strings.IndexByte(s[i+1:], '"')
+					if k == -1 {
+						return names
+					}
+					i += k + 2
+					continue
+				}
+				if s[i] == '.' || (s[i] >= '0' && s[i] <= '9') || (s[i] >= 'A' && s[i] <= 'Z') || (s[i] >= 'a' && s[i] <= 'z') {
+					i++
+					continue
+				}
+				break
+			}
+		case c >= '0' && c <= '9', c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			i++
+			for i < len(s) && (s[i] == '.' || (s[i] >= '0' && s[i] <= '9')) {
+				i++
+			}
+			if i+1 < len(s) && (s[i] == 'E' || s[i] == 'e') && (s[i+1] == '-' || s[i+1] == '+' || (s[i+1] >= '0' && s[i+1] <= '9')) {
+				i += 2
+				for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+					i++
+				}
+			}
+		case isNameChar(c, true):
+			j, macro := scanName(s, i)
+			if !macro {
+				names = append(names, s[i:j])
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return names
+}
+
+// GlobalRef is a reference to a global variable
+type GlobalRef struct {
+	Name      string // e.g. ^BCAT
+	Reference string // name with subscripts, e.g. ^BCAT("isbn",x)
+	Mode      string // "read", "set" or "kill"
+}
+
+// globals finds the global references in an expression: naked references,
+// extended references and structured system variables are skipped.
+func globals(expr string, mode string) []GlobalRef {
+	refs := make([]GlobalRef, 0)
+	closer := byte(0)
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case closer != 0:
+			if c == closer {
+				closer = 0
+			}
+			continue
+		case c == '"':
+			closer = '"'
+			continue
+		case c != '^':
+			continue
+		}
+		if i > 0 && (isNameChar(expr[i-1], false) || expr[i-1] == '$' || expr[i-1] == '%') {
+			// entryref: label^routine, +1^routine, $$^routine
+			continue
+		}
+		j, macro := scanName(expr, i+1)
+		if j == i+1 || macro {
+			continue
+		}
+		k := j
+		if k < len(expr) && expr[k] == '(' {
+			k = scanParens(expr, k)
+		}
+		refs = append(refs, GlobalRef{
+			Name:      expr[i:j],
+			Reference: expr[i:k],
+			Mode:      mode,
+		})
+	}
+	return refs
+}
+
+// scanParens returns the end of the parenthesized part starting at i
+func scanParens(s string, i int) int {
+	depth := 0
+	instring := false
+	for k := i; k < len(s); k++ {
+		switch {
+		case s[k] == '"':
+			instring = !instring
+		case instring:
+		case s[k] == '(':
+			depth++
+		case s[k] == ')':
+			depth--
+			if depth == 0 {
+				return k + 1
+			}
+		}
+	}
+	return len(s)
+}
+
+// splitAssign splits a SET or MERGE argument on the first `=` outside strings and parentheses
+func splitAssign(arg string) (left string, right string) {
+	depth := 0
+	instring := false
+	for k := 0; k < len(arg); k++ {
+		switch {
+		case arg[k] == '"':
+			instring = !instring
+		case instring:
+		case arg[k] == '(':
+			depth++
+		case arg[k] == ')':
+			depth--
+		case arg[k] == '=' && depth == 0:
+			return arg[:k], arg[k+1:]
+		}
+	}
+	return "", arg
+}
+
+// targets marks the first global reference in every target as set or kill:
+// other global references in the target (e.g. in subscripts) are read
+func targets(list string, mode string) []GlobalRef {
+	if strings.HasPrefix(list, "(") && strings.HasSuffix(list, ")") {
+		list = list[1 : len(list)-1]
+	}
+	refs := make([]GlobalRef, 0)
+	for _, target := range SplitArgs(list) {
+		grefs := globals(target, "read")
+		if len(grefs) != 0 && (strings.HasPrefix(target, "^") || strings.HasPrefix(target, "$")) {
+			grefs[0].Mode = mode
+		}
+		refs = append(refs, grefs...)
+	}
+	return refs
+}
+
+// Globals returns the global references in the command: the targets of SET and MERGE are set,
+// the arguments of KILL are killed, all other references are read. LOCK is not a data access.
+func (cmd *Command) Globals() []GlobalRef {
+	refs := globals(cmd.Postcond, "read")
+	for _, arg := range cmd.Args {
+		switch cmd.Name {
+		case "LOCK":
+		case "SET", "MERGE":
+			left, right := splitAssign(arg)
+			refs = append(refs, targets(left, "set")...)
+			refs = append(refs, globals(right, "read")...)
+		case "KILL":
+			refs = append(refs, targets(arg, "kill")...)
+		case "DO", "GOTO", "JOB":
+			_, rest := ParseEntryRef(arg)
+			refs = append(refs, globals(rest, "read")...)
+		default:
+			refs = append(refs, globals(arg, "read")...)
+		}
+	}
+	return refs
+}
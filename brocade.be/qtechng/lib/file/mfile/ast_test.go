@@ -0,0 +1,100 @@
+package mfile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse1(t *testing.T) {
+	body := []byte(`// About: test
+
+def %Sign(x):  // sign of a number
+    n y,z
+    s:x>0 y=1 i x<0 s y=-1 q:x'=0 y
+    q 0
+
+sub check(list) ;
+    d %Sign^sign(.list),show:$d(list)  f i=1:1:10 w $$%Sign(i),!
+    . q
+    g end+1^other
+main w "a;b" ; comment
+`)
+	routine := Parse("sign", body)
+	if len(routine.Labels) != 3 || routine.Labels[0].Name != "%Sign" || routine.Labels[0].Kind != "def" || routine.Labels[2].Name != "main" {
+		t.Fatalf("Wrong labels: %v", routine.Labels)
+	}
+	if strings.Join(routine.Labels[1].Formals, ",") != "list" || routine.Labels[1].Lineno != 8 {
+		t.Errorf("Wrong label: %v", routine.Labels[1])
+	}
+	if len(routine.Lines) != 9 {
+		t.Fatalf("Wrong lines: %d", len(routine.Lines))
+	}
+	line := routine.Lines[2]
+	if line.Lineno != 5 || len(line.Commands) != 4 {
+		t.Fatalf("Wrong line: %v", line)
+	}
+	if cmd := line.Commands[0]; cmd.Name != "SET" || cmd.Postcond != "x>0" || strings.Join(cmd.Args, ",") != "y=1" || cmd.Column != 5 {
+		t.Errorf("Wrong command: %v", cmd)
+	}
+	if cmd := line.Commands[3]; cmd.Name != "QUIT" || cmd.Postcond != "x'=0" || strings.Join(cmd.Args, ",") != "y" {
+		t.Errorf("Wrong command: %v", cmd)
+	}
+	line = routine.Lines[5]
+	if line.Level != 0 || len(line.Commands) != 3 || line.Commands[1].Name != "FOR" || len(line.Commands[1].Args) != 1 {
+		t.Fatalf("Wrong line: %v", line.Commands)
+	}
+	refs := line.Refs()
+	if len(refs) != 3 || refs[0].String() != "%Sign^sign" || refs[1].String() != "show" || refs[2].String() != "$$%Sign" {
+		t.Errorf("Wrong refs: %v", refs)
+	}
+	if names := line.Commands[0].Names(); strings.Join(names, ",") != "list,list" {
+		t.Errorf("Wrong names: %v", names)
+	}
+	if routine.Lines[6].Level != 1 || routine.Lines[6].Commands[0].Name != "QUIT" {
+		t.Errorf("Wrong dotted line: %v", routine.Lines[6])
+	}
+	if refs := routine.Lines[7].Refs(); len(refs) != 1 || refs[0].Offset != 1 || refs[0].Routine != "other" {
+		t.Errorf("Wrong refs: %v", refs)
+	}
+	if target := routine.Target("%Sign", 3); target == nil || target.Lineno != 6 {
+		t.Errorf("Wrong target: %v", target)
+	}
+	if code, comment := SplitComment(`main w "a;b" ; comment`); code != `main w "a;b" ` || comment != "; comment" {
+		t.Errorf("Wrong split: [%s] [%s]", code, comment)
+	}
+}
+
+func TestNames1(t *testing.T) {
+	tests := map[string]string{
+		`x+$L(y)_^G(z,"a")`:         "x,y,z",
+		`$$f^r(.a,b)+$$g(c)`:        "a,b,c",
+		`x?1N.E`:                    "x",
+		`1.5E3+.5*n`:                "n",
+		`m4_macro(a)+^$J(j)`:        "a,j",
+		`"s x=1"_w`:                 "w",
+		`$D(%a)&(«text with x»=tx)`: "%a,tx",
+	}
+	for expr, expected := range tests {
+		if names := strings.Join(Names(expr), ","); names != expected {
+			t.Errorf("Names of `%s`: `%s`, should be `%s`", expr, names, expected)
+		}
+	}
+}
+
+func TestGlobals1(t *testing.T) {
+	routine := Parse("glo", []byte(` s:$d(^A(1)) ^B(^C(2))=^D,$p(^E("x"),"^",2)=1,(x,^F)=$$f^r(^G)
+ k ^H(1),y m ^I=^J d show^glo(^K) l +^L(1) w $t(+1^glo),^|"db"|X,^$J,^(3)
+`))
+	modes := make([]string, 0)
+	for _, line := range routine.Lines {
+		for _, cmd := range line.Commands {
+			for _, ref := range cmd.Globals() {
+				modes = append(modes, ref.Reference+":"+ref.Mode)
+			}
+		}
+	}
+	expected := `^A(1):read ^B(^C(2)):set ^C(2):read ^D:read ^E("x"):set ^F:set ^G:read ^H(1):kill ^I:set ^J:read ^K:read`
+	if strings.Join(modes, " ") != expected {
+		t.Errorf("Wrong globals:\n%s\n%s", strings.Join(modes, " "), expected)
+	}
+}
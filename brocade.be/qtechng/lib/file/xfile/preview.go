@@ -0,0 +1,133 @@
+package xfile
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// previewStyle marks the mocks of the x4 constructions in a preview
+const previewStyle = `body { font-family: sans-serif; margin: 1em; }
+section.qtechng-widget { border: 1px dashed #999; margin: 1em 0; padding: 0.5em; }
+h1.qtechng-widget-title { font-size: 0.9em; color: #555; margin: 0 0 0.5em 0; }
+div.qtechng-format { outline: 1px dotted #39c; }`
+
+// Preview renders the widgets of an X file as a static HTML page.
+// Screens and formats are rendered in the order of the file, text widgets are skipped.
+// lang is the BCP-47 tag of the language of the lgcodes.
+func Preview(title string, lang string, widgets []*Widget) []byte {
+	formats := make(map[string]*Widget)
+	for _, widget := range widgets {
+		if widget.Type() == "format" {
+			formats[strings.TrimSpace(strings.TrimPrefix(widget.ID, "format"))] = widget
+		}
+	}
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("<!DOCTYPE html>\n")
+	buffer.WriteString(`<html lang="` + html.EscapeString(lang) + "\">\n<head>\n<meta charset=\"utf-8\">\n")
+	buffer.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	buffer.WriteString("<style>\n" + previewStyle + "\n</style>\n</head>\n<body>\n")
+	for _, widget := range widgets {
+		if widget.Type() == "text" {
+			continue
+		}
+		id := strings.ReplaceAll(widget.ID, " ", "-")
+		buffer.WriteString(`<section class="qtechng-widget" id="` + html.EscapeString(id) + "\">\n")
+		buffer.WriteString(`<h1 class="qtechng-widget-title">` + html.EscapeString(widget.ID) + "</h1>\n")
+		buffer.WriteString(widget.Preview(formats))
+		buffer.WriteString("\n</section>\n")
+	}
+	buffer.WriteString("</body>\n</html>\n")
+	return buffer.Bytes()
+}
+
+// Preview renders the body of a widget as static HTML: the x4 constructions are replaced
+// by mocks. Variables and parameters show their name, `x4_format` shows the format
+// in formats, `x4_select` shows its first alternative and
+// the other constructions become HTML comments.
+func (widget *Widget) Preview(formats map[string]*Widget) string {
+	return widget.preview(formats, map[string]bool{widget.ID: true})
+}
+
+func (widget *Widget) preview(formats map[string]*Widget, seen map[string]bool) string {
+	if strings.HasPrefix(widget.ID, "format $") || strings.HasPrefix(widget.ID, "format @") {
+		return previewComment(widget.ID + ": M code")
+	}
+	ty := widget.Type()
+	body := widget.Body
+	result := ""
+	labels := make([]string, 0)
+	offset := 0
+	for _, use := range X4Scan(ty, body, "") {
+		result += body[offset:use.Start]
+		offset = use.End
+		if len(use.Issues) != 0 {
+			result += body[use.Start:use.End]
+			continue
+		}
+		switch use.Verb {
+		case "format":
+			name := strings.TrimSpace(use.Args[0])
+			format := formats[name]
+			if format == nil || seen[format.ID] {
+				result += previewComment("x4_format(" + name + ")")
+				continue
+			}
+			seen[format.ID] = true
+			result += `<div class="qtechng-format">` + format.preview(formats, seen) + "</div>"
+			delete(seen, format.ID)
+		case "if":
+			label := strings.TrimSpace(use.Args[0])
+			labels = append(labels, label)
+			result += previewComment("x4_if " + label + ": " + use.Args[1])
+		case "select":
+			result += previewMock(ty, use.Args[0])
+		case "exec", "lookupinitscreen", "lookupinitformat":
+			result += previewComment("x4_" + use.Verb + "(" + strings.Join(use.Args, ",") + ")")
+		default:
+			result += previewValue(use)
+		}
+	}
+	result += body[offset:]
+	if len(labels) == 0 {
+		return result
+	}
+	lines := strings.Split(result, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		for _, label := range labels {
+			if x4label(label, trimmed) {
+				lines[i] = line[:len(line)-len(trimmed)] + previewComment("end "+label) + strings.TrimPrefix(trimmed, label)
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// previewMock replaces the x4 constructions in a parameter by their value
+func previewMock(ty string, text string) string {
+	result := ""
+	offset := 0
+	for _, use := range X4Scan(ty, text, "select") {
+		result += text[offset:use.Start] + previewValue(use)
+		offset = use.End
+	}
+	return result + text[offset:]
+}
+
+// previewValue is the mock of a variable or parameter: its name between brackets
+func previewValue(use X4Use) string {
+	name := ""
+	if len(use.Args) != 0 {
+		name = strings.TrimSpace(use.Args[0])
+	}
+	if name == "" {
+		return ""
+	}
+	return "[" + html.EscapeString(name) + "]"
+}
+
+func previewComment(text string) string {
+	return "<!-- " + strings.ReplaceAll(text, "--", "- -") + " -->"
+}
@@ -0,0 +1,227 @@
+package xfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// The x4 constructions in screens and formats are described by a schema:
+// Widget.Lint checks a widget against it, Widget.Preview uses it to render a mock.
+
+// X4Param is a parameter of an x4 construction
+type X4Param struct {
+	Name     string   `json:"name"`
+	Required bool     `json:"required,omitempty"` // the parameter cannot be empty
+	Values   []string `json:"values,omitempty"`   // allowed values: the part before the first `_` is checked
+	Label    bool     `json:"label,omitempty"`    // the parameter is a label further in the widget (e.g. `.END_1`)
+}
+
+// X4Verb is the schema of an x4 construction
+type X4Verb struct {
+	Verb   string    `json:"verb"`
+	Params []X4Param `json:"params"`
+	Min    int       `json:"min"`              // minimum number of parameters
+	NotIn  []string  `json:"notin,omitempty"`  // widget types in which the construction does not work
+	Hulls  []string  `json:"hulls,omitempty"`  // constructions in which it can be embedded
+	Embeds bool      `json:"embeds,omitempty"` // parameters can contain other x4 constructions
+}
+
+var x4encodings = []string{"raw", "html", "js", "url", "num", "date", "xml"}
+
+var x4hulls = []string{"exec", "if", "select", "lookupinitscreen", "lookupinitformat"}
+
+// X4Verbs is the schema of all x4 constructions
+var X4Verbs = map[string]X4Verb{
+	"varruntime": {
+		Verb:   "varruntime",
+		Params: []X4Param{{Name: "variable"}, {Name: "encoding", Values: x4encodings}},
+		Hulls:  x4hulls,
+	},
+	"varcoderuntime": {
+		Verb:   "varcoderuntime",
+		Params: []X4Param{{Name: "variable"}},
+		Hulls:  x4hulls,
+	},
+	"varcode": {
+		Verb:   "varcode",
+		Params: []X4Param{{Name: "code"}},
+		Hulls:  x4hulls,
+	},
+	"parconstant": {
+		Verb:   "parconstant",
+		Params: []X4Param{{Name: "parameter"}, {Name: "encoding", Values: x4encodings}},
+		NotIn:  []string{"screen"},
+		Hulls:  x4hulls,
+	},
+	"parcode": {
+		Verb:   "parcode",
+		Params: []X4Param{{Name: "parameter"}},
+		NotIn:  []string{"screen"},
+		Hulls:  x4hulls,
+	},
+	"vararray": {
+		Verb:   "vararray",
+		Params: []X4Param{{Name: "array"}},
+	},
+	"format": {
+		Verb:   "format",
+		Params: []X4Param{{Name: "format", Required: true}},
+		Min:    1,
+		NotIn:  []string{"format"},
+	},
+	"exec": {
+		Verb:   "exec",
+		Params: []X4Param{{Name: "code", Required: true}},
+		Min:    1,
+		Embeds: true,
+	},
+	"if": {
+		Verb:   "if",
+		Params: []X4Param{{Name: "label", Required: true, Label: true}, {Name: "condition", Required: true}},
+		Min:    2,
+		Embeds: true,
+	},
+	"select": {
+		Verb:   "select",
+		Params: []X4Param{{Name: "then"}, {Name: "else"}, {Name: "condition", Required: true}},
+		Min:    3,
+		Embeds: true,
+	},
+	"lookupinitscreen": {
+		Verb:   "lookupinitscreen",
+		Params: []X4Param{{Name: "lookup", Required: true}, {Name: "action", Required: true}, {Name: "value"}, {Name: "runtime"}, {Name: "options"}},
+		Min:    2,
+		Embeds: true,
+	},
+	"lookupinitformat": {
+		Verb:   "lookupinitformat",
+		Params: []X4Param{{Name: "lookup", Required: true}, {Name: "action", Required: true}, {Name: "value"}, {Name: "runtime"}, {Name: "options"}},
+		Min:    2,
+		NotIn:  []string{"screen"},
+	},
+}
+
+var rx4 = regexp.MustCompile(`x4_([a-zA-Z]+)\(`)
+
+// X4Use is an x4 construction in a text
+type X4Use struct {
+	Verb   string   // the verb without `x4_`
+	Args   []string // the parameters, `«` and `»` are removed
+	Start  int      // offset of `x4_` in the text
+	End    int      // offset after the closing `)`
+	Issues []string // violations of the schema
+}
+
+// X4Scan finds the x4 constructions in the text of a widget of type ty.
+// If hull is not empty, the text is a parameter of x4_hull.
+// Embedded constructions are checked but not returned.
+func X4Scan(ty string, text string, hull string) (uses []X4Use) {
+	offset := 0
+	for {
+		here := rx4.FindStringSubmatchIndex(text[offset:])
+		if here == nil {
+			return
+		}
+		start := offset + here[0]
+		verb := text[offset+here[2] : offset+here[3]]
+		open := offset + here[1] - 1
+		offset = open + 1
+		schema, known := X4Verbs[verb]
+		if !known {
+			if start != 0 && strings.ContainsAny(text[start-1:start], "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789%$") {
+				continue
+			}
+			uses = append(uses, X4Use{
+				Verb:   verb,
+				Start:  start,
+				End:    offset,
+				Issues: []string{fmt.Sprintf("`x4_%s` is not an x4 construction", verb)},
+			})
+			continue
+		}
+		use := X4Use{Verb: verb, Start: start, End: offset}
+		args, until, msg := qutil.BuildArgs(text[open:])
+		if msg != "" {
+			use.Issues = append(use.Issues, fmt.Sprintf("x4_%s: %s", verb, msg))
+			uses = append(uses, use)
+			continue
+		}
+		for i, arg := range args {
+			args[i] = strings.ReplaceAll(strings.ReplaceAll(arg, "«", ""), "»", "")
+		}
+		use.Args = args
+		use.End = open + len(until)
+		offset = use.End
+		use.Issues = schema.check(ty, args, hull, text[use.End:])
+		uses = append(uses, use)
+	}
+}
+
+// check validates the parameters of a construction: rest is the text after the construction
+func (schema X4Verb) check(ty string, args []string, hull string, rest string) (issues []string) {
+	verb := "x4_" + schema.Verb
+	if hull != "" && !x4in(hull, schema.Hulls) {
+		issues = append(issues, fmt.Sprintf("%s cannot be embedded in `x4_%s`", verb, hull))
+	}
+	if x4in(ty, schema.NotIn) {
+		issues = append(issues, fmt.Sprintf("%s does not work in %ss", verb, ty))
+	}
+	if len(args) < schema.Min {
+		issues = append(issues, fmt.Sprintf("%s works with at least %d parameters", verb, schema.Min))
+	}
+	if len(args) > len(schema.Params) {
+		issues = append(issues, fmt.Sprintf("%s works with no more than %d parameters", verb, len(schema.Params)))
+		return issues
+	}
+	for i, arg := range args {
+		param := schema.Params[i]
+		if param.Required && strings.TrimSpace(arg) == "" {
+			issues = append(issues, fmt.Sprintf("%s: parameter `%s` should not be empty", verb, param.Name))
+			continue
+		}
+		if len(param.Values) != 0 && !x4in(strings.SplitN(arg, "_", 2)[0], param.Values) {
+			issues = append(issues, fmt.Sprintf("%s: parameter `%s` should be one of %s, not `%s`", verb, param.Name, strings.Join(param.Values, ", "), arg))
+		}
+		if param.Label && arg != "" && !x4label(strings.TrimSpace(arg), rest) {
+			issues = append(issues, fmt.Sprintf("%s: label `%s` not found", verb, strings.TrimSpace(arg)))
+		}
+		if !strings.Contains(arg, "x4_") {
+			continue
+		}
+		if !schema.Embeds {
+			issues = append(issues, fmt.Sprintf("%s: parameter `%s` cannot contain x4 constructions", verb, param.Name))
+			continue
+		}
+		for _, use := range X4Scan(ty, arg, schema.Verb) {
+			issues = append(issues, use.Issues...)
+		}
+	}
+	return issues
+}
+
+// x4label checks if a line in text starts with the label
+func x4label(label string, text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimLeft(line, " \t")
+		if !strings.HasPrefix(line, label) {
+			continue
+		}
+		after := strings.TrimPrefix(line, label)
+		if after == "" || strings.TrimLeft(after, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890_") == after {
+			return true
+		}
+	}
+	return false
+}
+
+func x4in(s string, list []string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -124,8 +124,26 @@ func (widget *Widget) Deps() []byte {
 	return buffer.Bytes()
 }
 
-// Lint macro
+// Lint checks the x4 constructions against the schema in X4Verbs
 func (widget *Widget) Lint() (errslice qerror.ErrorSlice) {
+	ty := widget.Type()
+	if ty == "text" || strings.HasPrefix(widget.ID, "format $") || strings.HasPrefix(widget.ID, "format @") {
+		return nil
+	}
+	lineno, _ := strconv.Atoi(widget.Lineno())
+	for _, use := range X4Scan(ty, widget.Body, "") {
+		for _, issue := range use.Issues {
+			err := &qerror.QError{
+				Ref:    []string{"widget.lint.x4"},
+				File:   widget.EditFile(),
+				Lineno: lineno + 1 + strings.Count(widget.Body[:use.Start], "\n"),
+				Object: widget.String(),
+				Type:   "Error",
+				Msg:    []string{issue},
+			}
+			errslice = append(errslice, err)
+		}
+	}
 	if len(errslice) == 0 {
 		return nil
 	}
@@ -1,8 +1,11 @@
 package xfile
 
 import (
+	"strconv"
 	"strings"
 	"testing"
+
+	qerror "brocade.be/qtechng/lib/error"
 )
 
 func TestX401(t *testing.T) {
@@ -280,6 +283,61 @@ x4_lookupinitscreen(docfile,set,FDdata_ill_x4_parconstant(1)_dloi,FDupload)`
 	}
 }
 
+func TestLint1(t *testing.T) {
+	body := `<td>x4_varruntime(FDid,raw)</td><td>x4_varruntime(FDid,bin)</td>
+x4_if(.END_1,FDid'="")
+x4_parconstant(1) x4_nonsense(1) FDx4_abc(2)
+x4_select(a,b,c,d)
+x4_exec(w x4_format(list))
+x4_if(.END_9,1)
+.END_1`
+	widget := makewidget("screen", body)
+	errs := widget.Lint()
+	expect := []string{
+		"86:x4_varruntime: parameter `encoding` should be one of raw, html, js, url, num, date, xml, not `bin`",
+		"88:x4_parconstant does not work in screens",
+		"88:`x4_nonsense` is not an x4 construction",
+		"89:x4_select works with no more than 3 parameters",
+		"90:x4_format cannot be embedded in `x4_exec`",
+		"91:x4_if: label `.END_9` not found",
+	}
+	if len(errs) != len(expect) {
+		t.Fatalf("Wrong errors: %v", errs)
+	}
+	for i, err := range errs {
+		e := err.(*qerror.QError)
+		if found := strconv.Itoa(e.Lineno) + ":" + e.Msg[0]; found != expect[i] {
+			t.Errorf("Error %d:\nExpected: [%s]\nFound   : [%s]", i, expect[i], found)
+		}
+	}
+	if errs := makewidget("format", `x4_select(x4_parconstant(1,raw),x4_varcode(no),x4_parconstant(3)=1)`).Lint(); errs != nil {
+		t.Errorf("Should be without errors: %v", errs)
+	}
+}
+
+func TestPreview1(t *testing.T) {
+	widget := makewidget("screen", `<table>
+x4_if(.END_1,FDid'="")
+<tr><td>Title</td><td><input name="FDid" value="x4_varruntime(FDid)"></td></tr>
+.END_1
+<tr>x4_format(row)</tr>
+x4_exec(d show^me)
+</table>`)
+	formats := map[string]*Widget{
+		"row": makewidget("format", `<td class="x4_select(x4_parconstant(2),,1)">x4_varcode(code)</td>`),
+	}
+	expect := `<table>
+<!-- x4_if .END_1: FDid'="" -->
+<tr><td>Title</td><td><input name="FDid" value="[FDid]"></td></tr>
+<!-- end .END_1 -->
+<tr><div class="qtechng-format"><td class="[2]">[code]</td></div></tr>
+<!-- x4_exec(d show^me) -->
+</table>`
+	if found := widget.Preview(formats); found != expect {
+		t.Errorf("Preview:\nExpected: [%s]\nFound   : [%s]", expect, found)
+	}
+}
+
 func makewidget(ty string, body string) *Widget {
 	widget := Widget{
 		ID:      ty + " " + "myWidget",
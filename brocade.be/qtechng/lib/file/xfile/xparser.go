@@ -90,6 +90,7 @@ var g = &grammar{
 						pos:        position{line: 53, col: 16, offset: 1005},
 						val:        "'''",
 						ignoreCase: false,
+						want:       "\"'''\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 53, col: 22, offset: 1011},
@@ -101,6 +102,7 @@ var g = &grammar{
 						pos:        position{line: 53, col: 25, offset: 1014},
 						val:        "'''",
 						ignoreCase: false,
+						want:       "\"'''\"",
 					},
 					&andCodeExpr{
 						pos: position{line: 53, col: 31, offset: 1020},
@@ -126,6 +128,7 @@ var g = &grammar{
 						pos:        position{line: 58, col: 17, offset: 1166},
 						val:        "\"\"\"",
 						ignoreCase: false,
+						want:       "\"\\\"\\\"\\\"\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 58, col: 26, offset: 1175},
@@ -137,6 +140,7 @@ var g = &grammar{
 						pos:        position{line: 58, col: 29, offset: 1178},
 						val:        "\"\"\"",
 						ignoreCase: false,
+						want:       "\"\\\"\\\"\\\"\"",
 					},
 					&andCodeExpr{
 						pos: position{line: 58, col: 38, offset: 1187},
@@ -217,6 +221,7 @@ var g = &grammar{
 							pos:        position{line: 69, col: 55, offset: 1482},
 							val:        ":",
 							ignoreCase: false,
+							want:       "\":\"",
 						},
 						&zeroOrMoreExpr{
 							pos: position{line: 69, col: 59, offset: 1486},
@@ -261,16 +266,19 @@ var g = &grammar{
 							pos:        position{line: 77, col: 16, offset: 1658},
 							val:        "screen",
 							ignoreCase: false,
+							want:       "\"screen\"",
 						},
 						&litMatcher{
 							pos:        position{line: 77, col: 27, offset: 1669},
 							val:        "format",
 							ignoreCase: false,
+							want:       "\"format\"",
 						},
 						&litMatcher{
 							pos:        position{line: 77, col: 38, offset: 1680},
 							val:        "text",
 							ignoreCase: false,
+							want:       "\"text\"",
 						},
 					},
 				},
@@ -422,6 +430,7 @@ var g = &grammar{
 						pos:        position{line: 107, col: 17, offset: 2359},
 						val:        "//",
 						ignoreCase: false,
+						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
 						pos: position{line: 107, col: 22, offset: 2364},
@@ -484,6 +493,7 @@ var g = &grammar{
 						pos:        position{line: 114, col: 15, offset: 2463},
 						val:        "'''",
 						ignoreCase: false,
+						want:       "\"'''\"",
 					},
 				},
 			},
@@ -505,6 +515,7 @@ var g = &grammar{
 						pos:        position{line: 116, col: 15, offset: 2484},
 						val:        "\"\"\"",
 						ignoreCase: false,
+						want:       "\"\\\"\\\"\\\"\"",
 					},
 				},
 			},
@@ -541,21 +552,25 @@ var g = &grammar{
 						pos:        position{line: 121, col: 9, offset: 2529},
 						val:        "\r\n",
 						ignoreCase: false,
+						want:       "\"\\r\\n\"",
 					},
 					&litMatcher{
 						pos:        position{line: 121, col: 18, offset: 2538},
 						val:        "\n\r",
 						ignoreCase: false,
+						want:       "\"\\n\\r\"",
 					},
 					&litMatcher{
 						pos:        position{line: 121, col: 27, offset: 2547},
 						val:        "\r",
 						ignoreCase: false,
+						want:       "\"\\r\"",
 					},
 					&litMatcher{
 						pos:        position{line: 121, col: 34, offset: 2554},
 						val:        "\n",
 						ignoreCase: false,
+						want:       "\"\\n\"",
 					},
 				},
 			},
@@ -789,18 +804,17 @@ func Entrypoint(ruleName string) Option {
 //
 // Example usage:
 //
-//     input := "input"
-//     stats := Stats{}
-//     _, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
-//     if err != nil {
-//         log.Panicln(err)
-//     }
-//     fmt.Println(string(b))
-//
+//	input := "input"
+//	stats := Stats{}
+//	_, err := Parse("input-file", []byte(input), Statistics(&stats, "no match"))
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	b, err := json.MarshalIndent(stats.ChoiceAltCnt, "", "  ")
+//	if err != nil {
+//	    log.Panicln(err)
+//	}
+//	fmt.Println(string(b))
 func Statistics(stats *Stats, choiceNoMatch string) Option {
 	return func(p *parser) Option {
 		oldStats := p.Stats
@@ -1038,6 +1052,7 @@ type litMatcher struct {
 	pos        position
 	val        string
 	ignoreCase bool
+	want       string
 }
 
 type charClassMatcher struct {
@@ -1832,11 +1847,6 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 		defer p.out(p.in("parseLitMatcher"))
 	}
 
-	ignoreCase := ""
-	if lit.ignoreCase {
-		ignoreCase = "i"
-	}
-	val := string(strconv.AppendQuote([]byte{}, lit.val)) + ignoreCase // wrap 'lit.val' with double quotes
 	start := p.pt
 	for _, want := range lit.val {
 		cur := p.pt.rn
@@ -1844,13 +1854,13 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (interface{}, bool) {
 			cur = unicode.ToLower(cur)
 		}
 		if cur != want {
-			p.failAt(false, start.position, val)
+			p.failAt(false, start.position, lit.want)
 			p.restore(start)
 			return nil, false
 		}
 		p.read()
 	}
-	p.failAt(true, start.position, val)
+	p.failAt(true, start.position, lit.want)
 	return p.sliceFrom(start), true
 }
 
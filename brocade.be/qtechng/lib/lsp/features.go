@@ -0,0 +1,374 @@
+package lsp
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	qregistry "brocade.be/base/registry"
+	qclient "brocade.be/qtechng/lib/client"
+	qerror "brocade.be/qtechng/lib/error"
+	qbfile "brocade.be/qtechng/lib/file/bfile"
+	qdfile "brocade.be/qtechng/lib/file/dfile"
+	qifile "brocade.be/qtechng/lib/file/ifile"
+	qlfile "brocade.be/qtechng/lib/file/lfile"
+	qmfile "brocade.be/qtechng/lib/file/mfile"
+	qofile "brocade.be/qtechng/lib/file/ofile"
+	qxfile "brocade.be/qtechng/lib/file/xfile"
+	qobject "brocade.be/qtechng/lib/object"
+	qserver "brocade.be/qtechng/lib/server"
+	qsource "brocade.be/qtechng/lib/source"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// maxCompletions limits the number of proposals for completion
+const maxCompletions = 200
+
+var objectRegexp = regexp.MustCompile(`[a-z]4_[A-Za-z0-9_]+`)
+var prefixRegexp = regexp.MustCompile(`[a-z]4_[A-Za-z0-9_]*$`)
+
+// formatters are the Format functions per extension
+var formatters = map[string]func(fname string, blob []byte, output *bytes.Buffer) error{
+	".b": qbfile.Format,
+	".d": qdfile.Format,
+	".i": qifile.Format,
+	".l": qlfile.Format,
+	".m": qmfile.Format,
+	".x": qxfile.Format,
+}
+
+// objectFile returns an empty objectfile for a filename
+func objectFile(fname string) qobject.OFile {
+	var objfile qobject.OFile
+	switch filepath.Ext(fname) {
+	case ".b":
+		objfile = new(qofile.BFile)
+	case ".d":
+		objfile = new(qofile.DFile)
+	case ".i":
+		objfile = new(qofile.IFile)
+	case ".l":
+		objfile = new(qofile.LFile)
+	case ".x":
+		objfile = new(qofile.XFile)
+	default:
+		return nil
+	}
+	objfile.SetEditFile(fname)
+	return objfile
+}
+
+// locate finds the version and the qpath of a file.
+// The qpath is taken from the `.qtechng` administration of the directory,
+// from the location in the repository or from the location in the work directory.
+func (server *Server) locate(fname string) (r string, qpath string) {
+	if fname == "" {
+		return "", ""
+	}
+	dir := &qclient.Dir{Dir: filepath.Dir(fname)}
+	if locfil := dir.Get(filepath.Base(fname)); locfil != nil && locfil.QPath != "" {
+		r = locfil.Release
+		if server.Release != "" {
+			r = server.Release
+		}
+		return r, locfil.QPath
+	}
+	if server.Release == "" {
+		return "", ""
+	}
+	bases := make([]string, 0, 2)
+	if release, err := (qserver.Release{}).New(server.Release, true); err == nil {
+		if base, e := release.FS().RealPath("/"); e == nil {
+			bases = append(bases, base)
+		}
+	}
+	if work := qregistry.Registry["qtechng-work-dir"]; work != "" {
+		bases = append(bases, work)
+	}
+	for _, base := range bases {
+		rel, err := filepath.Rel(base, fname)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		return server.Release, "/" + filepath.ToSlash(rel)
+	}
+	return "", ""
+}
+
+// source returns the repository source of a document
+func (server *Server) source(uri string) *qsource.Source {
+	r, qpath := server.locate(uriToPath(uri))
+	if r == "" || qpath == "" {
+		return nil
+	}
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil
+	}
+	if ok, _ := release.Exists(); !ok {
+		return nil
+	}
+	source, err := qsource.Source{}.New(r, qpath, true)
+	if err != nil {
+		return nil
+	}
+	return source
+}
+
+// diagnostics lints a document.
+// A document in the repository is checked with the lint rules of the source,
+// other documents are parsed as objectfiles.
+func (server *Server) diagnostics(uri string, text string) []Diagnostic {
+	fname := uriToPath(uri)
+	var errs []error
+	if source := server.source(uri); source != nil {
+		info, err := source.WithContent([]byte(text)).Lint("", server.Warnings)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if info != nil {
+			errs = append(errs, info)
+		}
+	} else {
+		errs = lintLocal(fname, []byte(text))
+	}
+	diagnostics := make([]Diagnostic, 0)
+	for _, issue := range (qsource.LintOutcome{Errs: errs}).Issues() {
+		severity := severityError
+		switch issue.Severity {
+		case "warning":
+			severity = severityWarning
+		case "info":
+			severity = severityInfo
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    lineRange(text, issue.Lineno-1),
+			Severity: severity,
+			Code:     issue.RuleID,
+			Source:   "qtechng",
+			Message:  issue.Msg,
+		})
+	}
+	return diagnostics
+}
+
+// lintLocal checks a file which is not in the repository
+func lintLocal(fname string, blob []byte) []error {
+	_, result, e := qutil.NoUTF8(bytes.NewReader(blob))
+	if e != nil || len(result) > 0 {
+		lineno := -1
+		if len(result) > 0 {
+			lineno = result[0][0]
+		}
+		return []error{&qerror.QError{
+			Ref:    []string{"lsp.lint.utf8"},
+			File:   fname,
+			Lineno: lineno,
+			Type:   "Error",
+			Msg:    []string{"UTF-8 error in file"},
+		}}
+	}
+	objfile := objectFile(fname)
+	if objfile == nil {
+		return nil
+	}
+	err := qobject.Loads(objfile, blob, true)
+	if err == nil {
+		err = qobject.LintObjects(objfile)
+	}
+	if err == nil {
+		return nil
+	}
+	return []error{err}
+}
+
+// wordAt returns the object name on a position
+func wordAt(text string, pos Position) string {
+	all := lines(text)
+	if pos.Line < 0 || pos.Line >= len(all) {
+		return ""
+	}
+	line := all[pos.Line]
+	offset := byteOffset(line, pos.Character)
+	for _, loc := range objectRegexp.FindAllStringIndex(line, -1) {
+		if loc[0] <= offset && offset <= loc[1] {
+			return line[loc[0]:loc[1]]
+		}
+	}
+	return ""
+}
+
+// prefixAt returns the beginning of an object name before a position
+func prefixAt(text string, pos Position) string {
+	all := lines(text)
+	if pos.Line < 0 || pos.Line >= len(all) {
+		return ""
+	}
+	line := all[pos.Line]
+	return prefixRegexp.FindString(line[:byteOffset(line, pos.Character)])
+}
+
+// release returns the version to use for a document
+func (server *Server) release(uri string) string {
+	if server.Release != "" {
+		return server.Release
+	}
+	r, _ := server.locate(uriToPath(uri))
+	return r
+}
+
+// definition finds the location of the definition of an object
+func (server *Server) definition(uri string, pos Position) []Location {
+	text, ok := server.document(uri)
+	if !ok {
+		return nil
+	}
+	name := wordAt(text, pos)
+	r := server.release(uri)
+	if name == "" || r == "" {
+		return nil
+	}
+	qpath := qobject.GetEditFile(r, name)
+	if qpath == "" {
+		return nil
+	}
+	fname := ""
+	if work := qregistry.Registry["qtechng-work-dir"]; work != "" {
+		fname = filepath.Join(work, filepath.FromSlash(qpath))
+		if _, err := os.Stat(fname); err != nil {
+			fname = ""
+		}
+	}
+	if fname == "" {
+		source, err := qsource.Source{}.New(r, qpath, true)
+		if err != nil {
+			return nil
+		}
+		fname = source.Path()
+	}
+	blob, err := os.ReadFile(fname)
+	if err != nil {
+		return nil
+	}
+	lineno := 0
+	if objfile := objectFile(fname); objfile != nil && qobject.Loads(objfile, blob, false) == nil {
+		for _, obj := range objfile.Objects() {
+			if obj.String() == name || obj.Name() == name {
+				lineno, _ = strconv.Atoi(obj.Lineno())
+				break
+			}
+		}
+	}
+	return []Location{{URI: pathToURI(fname), Range: lineRange(string(blob), lineno-1)}}
+}
+
+// hover describes the object on a position.
+// Macros are described with their synopsis, parameters and examples.
+func (server *Server) hover(uri string, pos Position) *Hover {
+	text, ok := server.document(uri)
+	if !ok {
+		return nil
+	}
+	name := wordAt(text, pos)
+	r := server.release(uri)
+	if name == "" || r == "" {
+		return nil
+	}
+	buffer := new(bytes.Buffer)
+	if strings.HasPrefix(name, "m4_") {
+		macro := new(qofile.Macro)
+		macro.SetName(strings.TrimPrefix(name, "m4_"))
+		macro.SetRelease(r)
+		if qobject.Fetch(macro) != nil {
+			return nil
+		}
+		buffer.WriteString("**" + name + "**")
+		if macro.Source != "" {
+			buffer.WriteString(" (`" + macro.Source + "`)")
+		}
+		buffer.WriteString("\n\n")
+		if synopsis := strings.TrimSpace(macro.Synopsis); synopsis != "" {
+			buffer.WriteString(synopsis + "\n\n")
+		}
+		for _, param := range macro.Params {
+			buffer.WriteString("- `" + param.ID + "`")
+			if param.Type != "" {
+				buffer.WriteString(" (" + param.Type + ")")
+			}
+			if param.Default != "" {
+				buffer.WriteString(" = `" + param.Default + "`")
+			}
+			if doc := strings.TrimSpace(param.Doc); doc != "" {
+				buffer.WriteString(": " + doc)
+			}
+			buffer.WriteString("\n")
+		}
+		if len(macro.Examples) != 0 {
+			buffer.WriteString("\nExamples:\n\n```\n" + strings.Join(macro.Examples, "\n") + "\n```\n")
+		}
+	} else {
+		obj := qobject.InfoObjectList(r, []string{name})[name]
+		if obj == nil {
+			return nil
+		}
+		buffer.WriteString("**" + name + "**")
+		if obj.EditFile() != "" {
+			buffer.WriteString(" (`" + obj.EditFile() + "`)")
+		}
+		buffer.WriteString("\n")
+	}
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: buffer.String()}}
+}
+
+// completion proposes object names for the name before a position
+func (server *Server) completion(uri string, pos Position) []CompletionItem {
+	items := make([]CompletionItem, 0)
+	text, ok := server.document(uri)
+	if !ok {
+		return items
+	}
+	prefix := prefixAt(text, pos)
+	r := server.release(uri)
+	if prefix == "" || r == "" {
+		return items
+	}
+	names := qobject.FindObjects(r, []string{prefix + "*"})
+	sort.Strings(names)
+	for _, name := range names {
+		if len(items) == maxCompletions {
+			break
+		}
+		kind := 21 // Constant
+		if strings.HasPrefix(name, "m4_") {
+			kind = 3 // Function
+		}
+		items = append(items, CompletionItem{Label: name, Kind: kind})
+	}
+	return items
+}
+
+// formatting formats a document as a whole
+func (server *Server) formatting(uri string) ([]TextEdit, error) {
+	text, ok := server.document(uri)
+	if !ok {
+		return nil, nil
+	}
+	fname := uriToPath(uri)
+	format := formatters[filepath.Ext(fname)]
+	if format == nil {
+		return nil, nil
+	}
+	buffer := new(bytes.Buffer)
+	err := format(fname, []byte(text), buffer)
+	if err != nil {
+		return nil, err
+	}
+	if buffer.String() == text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: documentRange(text), NewText: buffer.String()}}, nil
+}
@@ -0,0 +1,99 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func frame(t *testing.T, buffer *bytes.Buffer, msg map[string]interface{}) {
+	msg["jsonrpc"] = "2.0"
+	err := writeMessage(buffer, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServe01(t *testing.T) {
+	dir := t.TempDir()
+	fname := filepath.Join(dir, "bad.d")
+	uri := pathToURI(fname)
+	text := "\"\"\"\nAbout: test\n\"\"\"\n\nmacro getX($a):\n    '''\n    $synopsis: X\n    $a: A\n    $example: m4_getX(1)\n    '''\n    «d %X^gbx($a)»\n\nmacro getX($a):\n    '''\n    $synopsis: X\n    $a: A\n    $example: m4_getX(1)\n    '''\n    «d %X^gbx($a)»\n"
+	os.WriteFile(fname, []byte(text), 0644)
+
+	in := new(bytes.Buffer)
+	frame(t, in, map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{}})
+	frame(t, in, map[string]interface{}{"method": "initialized", "params": map[string]interface{}{}})
+	frame(t, in, map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "qtechng", "version": 1, "text": text},
+	}})
+	frame(t, in, map[string]interface{}{"id": 2, "method": "textDocument/formatting", "params": map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}})
+	frame(t, in, map[string]interface{}{"id": 3, "method": "unknown/method"})
+	frame(t, in, map[string]interface{}{"id": 4, "method": "shutdown"})
+	frame(t, in, map[string]interface{}{"method": "exit"})
+
+	out := new(bytes.Buffer)
+	err := New("", false).Serve(in, out)
+	if err != nil {
+		t.Errorf("Serve: %v", err)
+		return
+	}
+
+	answers := make(map[string]map[string]interface{})
+	reader := bufio.NewReader(out)
+	for {
+		msg, err := readMessage(reader)
+		if err != nil {
+			break
+		}
+		key := msg.Method
+		if msg.ID != nil {
+			key = string(*msg.ID)
+		}
+		m := make(map[string]interface{})
+		blob, _ := json.Marshal(msg)
+		json.Unmarshal(blob, &m)
+		answers[key] = m
+	}
+	if len(answers) != 5 {
+		t.Errorf("Should be 5 answers: %v", answers)
+		return
+	}
+
+	diagnostics := answers["textDocument/publishDiagnostics"]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	if len(diagnostics) != 1 {
+		t.Errorf("Should be 1 diagnostic: %v", diagnostics)
+		return
+	}
+	diagnostic := diagnostics[0].(map[string]interface{})
+	line := diagnostic["range"].(map[string]interface{})["start"].(map[string]interface{})["line"]
+	if line != float64(11) || !strings.Contains(diagnostic["message"].(string), "getX") {
+		t.Errorf("Wrong diagnostic: %v", diagnostic)
+	}
+}
+
+func TestWordAt01(t *testing.T) {
+	text := "\tS x=m4_getCatGenStatus(a) ; é m4_x\n\tS y=m4_ab"
+	if word := wordAt(text, Position{Line: 0, Character: 8}); word != "m4_getCatGenStatus" {
+		t.Errorf("Word should be `m4_getCatGenStatus`: `%s`", word)
+	}
+	if word := wordAt(text, Position{Line: 0, Character: 33}); word != "m4_x" {
+		t.Errorf("Word should be `m4_x`: `%s`", word)
+	}
+	if word := wordAt(text, Position{Line: 0, Character: 1}); word != "" {
+		t.Errorf("Word should be empty: `%s`", word)
+	}
+	if prefix := prefixAt(text, Position{Line: 1, Character: 10}); prefix != "m4_ab" {
+		t.Errorf("Prefix should be `m4_ab`: `%s`", prefix)
+	}
+	r := documentRange(text)
+	if r.End.Line != 1 || r.End.Character != 10 {
+		t.Errorf("Wrong document range: %v", r)
+	}
+}
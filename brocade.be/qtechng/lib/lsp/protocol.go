@@ -0,0 +1,249 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	qerror "brocade.be/qtechng/lib/error"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	parseError     = -32700
+	invalidParams  = -32602
+	methodNotFound = -32601
+	requestFailed  = -32803
+)
+
+// Diagnostic severities
+const (
+	severityError   = 1
+	severityWarning = 2
+	severityInfo    = 3
+)
+
+// message is a JSON-RPC 2.0 request or notification
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+// response is a JSON-RPC 2.0 response
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+// failure is a JSON-RPC 2.0 error response
+type failure struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   rpcError         `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// notification is a JSON-RPC 2.0 notification from the server
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// Position in a document: zero-based line and UTF-16 offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range in a document
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// Diagnostic is a finding in a document
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// TextEdit replaces a range in a document
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// CompletionItem is a proposal for completion
+type CompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// MarkupContent is formatted text
+type MarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+// Hover is the information on a position
+type Hover struct {
+	Contents MarkupContent `json:"contents"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     Position               `json:"position"`
+}
+
+type didOpenParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument   textDocumentIdentifier `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didSaveParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Text         *string                `json:"text"`
+}
+
+type documentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// readMessage reads a message with a `Content-Length` header
+func readMessage(reader *bufio.Reader) (msg *message, err error) {
+	header, err := textproto.NewReader(reader).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(header.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, &qerror.QError{
+			Ref: []string{"lsp.read.length"},
+			Msg: []string{"Missing or invalid `Content-Length` header"},
+		}
+	}
+	body := make([]byte, length)
+	_, err = io.ReadFull(reader, body)
+	if err != nil {
+		return nil, err
+	}
+	msg = new(message)
+	err = json.Unmarshal(body, msg)
+	if err != nil {
+		return nil, &rpcError{Code: parseError, Message: err.Error()}
+	}
+	return msg, nil
+}
+
+// writeMessage writes a message with a `Content-Length` header
+func writeMessage(writer io.Writer, msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(writer, "Content-Length: "+strconv.Itoa(len(body))+"\r\n\r\n")
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(body)
+	return err
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// lines splits a document in lines without line endings
+func lines(text string) []string {
+	result := strings.Split(text, "\n")
+	for i, line := range result {
+		result[i] = strings.TrimSuffix(line, "\r")
+	}
+	return result
+}
+
+// utf16Len is the length of a string in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return n
+}
+
+// byteOffset converts a UTF-16 offset in a line to a byte offset
+func byteOffset(line string, character int) int {
+	n := 0
+	for i, r := range line {
+		if n >= character {
+			return i
+		}
+		if r == utf8.RuneError {
+			n++
+			continue
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return len(line)
+}
+
+// lineRange is the range of a complete line
+func lineRange(text string, lineno int) Range {
+	all := lines(text)
+	if lineno < 0 || lineno >= len(all) {
+		lineno = 0
+	}
+	end := 0
+	if lineno < len(all) {
+		end = utf16Len(all[lineno])
+	}
+	return Range{
+		Start: Position{Line: lineno},
+		End:   Position{Line: lineno, Character: end},
+	}
+}
+
+// documentRange is the range of a complete document
+func documentRange(text string) Range {
+	all := lines(text)
+	last := len(all) - 1
+	return Range{
+		End: Position{Line: last, Character: utf16Len(all[last])},
+	}
+}
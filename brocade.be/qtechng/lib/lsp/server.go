@@ -0,0 +1,246 @@
+// Package lsp implements a Language Server Protocol server for the qtechng file types.
+// The server communicates over JSON-RPC 2.0 with `Content-Length` framing.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	qerror "brocade.be/qtechng/lib/error"
+)
+
+// Server is a language server.
+// Documents are synchronised completely on every change.
+type Server struct {
+	Release  string // version of the repository
+	Warnings bool   // diagnostics include warnings
+	out      io.Writer
+	wmu      sync.Mutex
+	docs     map[string]string
+	shutdown bool
+}
+
+// New creates a language server
+func New(r string, warnings bool) *Server {
+	return &Server{
+		Release:  r,
+		Warnings: warnings,
+		docs:     make(map[string]string),
+	}
+}
+
+// Serve handles the messages on in and writes the answers on out.
+// Serve returns after the `exit` notification or at the end of the input.
+func (server *Server) Serve(in io.Reader, out io.Writer) error {
+	server.out = out
+	reader := bufio.NewReader(in)
+	for {
+		msg, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if e, ok := err.(*rpcError); ok {
+			server.write(failure{JSONRPC: "2.0", Error: *e})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			if !server.shutdown {
+				return &qerror.QError{
+					Ref: []string{"lsp.exit"},
+					Msg: []string{"Exit without shutdown"},
+				}
+			}
+			return nil
+		}
+		result, err := server.handle(msg)
+		if msg.ID == nil {
+			continue
+		}
+		if err != nil {
+			e, ok := err.(*rpcError)
+			if !ok {
+				e = &rpcError{Code: requestFailed, Message: err.Error()}
+			}
+			server.write(failure{JSONRPC: "2.0", ID: msg.ID, Error: *e})
+			continue
+		}
+		server.write(response{JSONRPC: "2.0", ID: msg.ID, Result: result})
+	}
+}
+
+// write sends a message to the client
+func (server *Server) write(msg interface{}) {
+	server.wmu.Lock()
+	defer server.wmu.Unlock()
+	writeMessage(server.out, msg)
+}
+
+// notify sends a notification to the client
+func (server *Server) notify(method string, params interface{}) {
+	server.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle dispatches a message
+func (server *Server) handle(msg *message) (result interface{}, err error) {
+	decode := func(params interface{}) error {
+		if e := json.Unmarshal(msg.Params, params); e != nil {
+			return &rpcError{Code: invalidParams, Message: e.Error()}
+		}
+		return nil
+	}
+	switch msg.Method {
+	case "initialize":
+		return server.initialize(), nil
+	case "shutdown":
+		server.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		server.docs[params.TextDocument.URI] = params.TextDocument.Text
+		server.publish(params.TextDocument.URI)
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n != 0 {
+			server.docs[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		server.publish(params.TextDocument.URI)
+	case "textDocument/didSave":
+		params := didSaveParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Text != nil {
+			server.docs[params.TextDocument.URI] = *params.Text
+		}
+		server.publish(params.TextDocument.URI)
+	case "textDocument/didClose":
+		params := documentParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		delete(server.docs, params.TextDocument.URI)
+		server.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         params.TextDocument.URI,
+			"diagnostics": []Diagnostic{},
+		})
+	case "textDocument/definition":
+		params := textDocumentPositionParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.definition(params.TextDocument.URI, params.Position), nil
+	case "textDocument/hover":
+		params := textDocumentPositionParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.hover(params.TextDocument.URI, params.Position), nil
+	case "textDocument/completion":
+		params := textDocumentPositionParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.completion(params.TextDocument.URI, params.Position), nil
+	case "textDocument/formatting":
+		params := documentParams{}
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		return server.formatting(params.TextDocument.URI)
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+	default:
+		if msg.ID != nil {
+			return nil, &rpcError{Code: methodNotFound, Message: "Method `" + msg.Method + "` is not supported"}
+		}
+	}
+	return nil, nil
+}
+
+// initialize returns the capabilities of the server
+func (server *Server) initialize() interface{} {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    1,
+				"save":      map[string]interface{}{"includeText": true},
+			},
+			"definitionProvider":         true,
+			"hoverProvider":              true,
+			"documentFormattingProvider": true,
+			"completionProvider": map[string]interface{}{
+				"triggerCharacters": []string{"_"},
+			},
+		},
+		"serverInfo": map[string]interface{}{
+			"name": "qtechng",
+		},
+	}
+}
+
+// publish sends the diagnostics of a document
+func (server *Server) publish(uri string) {
+	diagnostics := server.diagnostics(uri, server.docs[uri])
+	server.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// document returns the content of a document:
+// the open document or the file on disk
+func (server *Server) document(uri string) (text string, ok bool) {
+	text, ok = server.docs[uri]
+	if ok {
+		return text, true
+	}
+	fname := uriToPath(uri)
+	if fname == "" {
+		return "", false
+	}
+	blob, err := os.ReadFile(fname)
+	if err != nil {
+		return "", false
+	}
+	return string(blob), true
+}
+
+// uriToPath converts a `file:` URI to a filename
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	fname := u.Path
+	if runtime.GOOS == "windows" {
+		fname = strings.TrimPrefix(fname, "/")
+	}
+	return filepath.FromSlash(fname)
+}
+
+// pathToURI converts a filename to a `file:` URI
+func pathToURI(fname string) string {
+	fname, _ = filepath.Abs(fname)
+	fname = filepath.ToSlash(fname)
+	if runtime.GOOS == "windows" {
+		fname = "/" + fname
+	}
+	u := &url.URL{Scheme: "file", Path: fname}
+	return u.String()
+}
@@ -0,0 +1,232 @@
+package object
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	qfs "brocade.be/base/fs"
+	qparallel "brocade.be/base/parallel"
+	qserver "brocade.be/qtechng/lib/server"
+)
+
+// Graph is the dependency graph of the objects and sources in a release
+type Graph struct {
+	Release string       `json:"version"`
+	Nodes   []*GraphNode `json:"nodes"`
+	Edges   []*GraphEdge `json:"edges"`
+}
+
+// GraphNode is an object or a source
+type GraphNode struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"`     // "object" or "source"
+	Type     string `json:"type"`     // object type: "m4", "i4", ...
+	EditFile string `json:"editfile"` // source which defines the object
+}
+
+// GraphEdge links a source or an object with an object it depends on
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"` // "defines" or "uses"
+}
+
+// GetGraph builds the dependency graph of a release from the object links.
+// With a project, only the sources of the project, the objects defined in the project
+// and their direct neighbours are kept.
+// With types, only the objects of these types (e.g. "m4", "i4") are kept.
+func GetGraph(version *qserver.Release, project string, types []string) (graph *Graph, err error) {
+	fs := version.FS("/object")
+	dir, _ := fs.RealPath("/")
+
+	// objects
+	objfiles, _ := qfs.Find(dir, []string{"obj.json"}, true, true, false)
+	fno := func(n int) (interface{}, error) {
+		blob, err := qfs.Fetch(objfiles[n])
+		if err != nil {
+			return nil, nil
+		}
+		def := make(map[string]interface{})
+		if json.Unmarshal(blob, &def) != nil {
+			return nil, nil
+		}
+		id, _ := def["id"].(string)
+		if id == "" {
+			return nil, nil
+		}
+		ty := filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(objfiles[n]))))
+		if !strings.HasPrefix(id, ty+"_") {
+			id = ty + "_" + id
+		}
+		editfile, _ := def["source"].(string)
+		return &GraphNode{ID: id, Kind: "object", Type: ty, EditFile: editfile}, nil
+	}
+	resultlist, _ := qparallel.NMap(len(objfiles), -1, fno)
+	nodes := make(map[string]*GraphNode)
+	for _, res := range resultlist {
+		node, _ := res.(*GraphNode)
+		if node != nil {
+			nodes[node.ID] = node
+		}
+	}
+
+	// links
+	depfiles, _ := qfs.Find(dir, []string{"*.dep"}, true, true, false)
+	fnd := func(n int) (interface{}, error) {
+		blob, err := qfs.Fetch(depfiles[n])
+		if err != nil {
+			return nil, nil
+		}
+		dep := make(map[string]string)
+		if json.Unmarshal(blob, &dep) != nil || dep["source"] == "" || dep["object"] == "" {
+			return nil, nil
+		}
+		return &GraphEdge{From: dep["source"], To: dep["object"]}, nil
+	}
+	resultlist, _ = qparallel.NMap(len(depfiles), -1, fnd)
+	edges := make([]*GraphEdge, 0, len(resultlist))
+	for _, res := range resultlist {
+		edge, _ := res.(*GraphEdge)
+		if edge == nil {
+			continue
+		}
+		edge.Kind = "uses"
+		if nodes[edge.To] != nil && nodes[edge.To].EditFile == edge.From {
+			edge.Kind = "defines"
+		}
+		edges = append(edges, edge)
+	}
+
+	// filters
+	node := func(id string) *GraphNode {
+		if nodes[id] != nil {
+			return nodes[id]
+		}
+		if strings.HasPrefix(id, "/") {
+			return &GraphNode{ID: id, Kind: "source"}
+		}
+		ty := strings.SplitN(id, "_", 2)[0]
+		return &GraphNode{ID: id, Kind: "object", Type: ty}
+	}
+	keeptype := func(n *GraphNode) bool {
+		if n.Kind != "object" || len(types) == 0 {
+			return true
+		}
+		for _, ty := range types {
+			if n.Type == strings.TrimSuffix(ty, "_") {
+				return true
+			}
+		}
+		return false
+	}
+	inproject := func(n *GraphNode) bool {
+		if project == "" || project == "/" {
+			return true
+		}
+		qpath := n.ID
+		if n.Kind == "object" {
+			qpath = n.EditFile
+		}
+		return qpath == project || strings.HasPrefix(qpath, project+"/")
+	}
+
+	graph = &Graph{
+		Release: version.String(),
+		Nodes:   make([]*GraphNode, 0),
+		Edges:   make([]*GraphEdge, 0),
+	}
+	kept := make(map[string]*GraphNode)
+	for _, edge := range edges {
+		from := node(edge.From)
+		to := node(edge.To)
+		if !keeptype(from) || !keeptype(to) {
+			continue
+		}
+		if !inproject(from) && !inproject(to) {
+			continue
+		}
+		kept[from.ID] = from
+		kept[to.ID] = to
+		graph.Edges = append(graph.Edges, edge)
+	}
+	for _, n := range nodes {
+		if kept[n.ID] == nil && keeptype(n) && inproject(n) {
+			kept[n.ID] = n
+		}
+	}
+	for _, n := range kept {
+		graph.Nodes = append(graph.Nodes, n)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph, nil
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (graph *Graph) DOT() string {
+	buffer := new(bytes.Buffer)
+	fmt.Fprintf(buffer, "digraph %q {\n", "qtechng-"+graph.Release)
+	fmt.Fprintln(buffer, "    rankdir=LR;")
+	for _, node := range graph.Nodes {
+		shape := "ellipse"
+		if node.Kind == "source" {
+			shape = "box"
+		}
+		fmt.Fprintf(buffer, "    %q [shape=%s];\n", node.ID, shape)
+	}
+	for _, edge := range graph.Edges {
+		style := "solid"
+		if edge.Kind == "defines" {
+			style = "dashed"
+		}
+		fmt.Fprintf(buffer, "    %q -> %q [label=%q, style=%s];\n", edge.From, edge.To, edge.Kind, style)
+	}
+	fmt.Fprintln(buffer, "}")
+	return buffer.String()
+}
+
+// GraphML renders the graph in GraphML
+func (graph *Graph) GraphML() string {
+	escape := func(s string) string {
+		buf := new(bytes.Buffer)
+		xml.EscapeText(buf, []byte(s))
+		return buf.String()
+	}
+	buffer := new(bytes.Buffer)
+	fmt.Fprintln(buffer, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(buffer, `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`)
+	fmt.Fprintln(buffer, `  <key id="kind" for="node" attr.name="kind" attr.type="string"/>`)
+	fmt.Fprintln(buffer, `  <key id="type" for="node" attr.name="type" attr.type="string"/>`)
+	fmt.Fprintln(buffer, `  <key id="editfile" for="node" attr.name="editfile" attr.type="string"/>`)
+	fmt.Fprintln(buffer, `  <key id="relation" for="edge" attr.name="kind" attr.type="string"/>`)
+	fmt.Fprintf(buffer, "  <graph id=\"%s\" edgedefault=\"directed\">\n", escape("qtechng-"+graph.Release))
+	for _, node := range graph.Nodes {
+		fmt.Fprintf(buffer, "    <node id=\"%s\">\n", escape(node.ID))
+		fmt.Fprintf(buffer, "      <data key=\"kind\">%s</data>\n", node.Kind)
+		if node.Type != "" {
+			fmt.Fprintf(buffer, "      <data key=\"type\">%s</data>\n", escape(node.Type))
+		}
+		if node.EditFile != "" {
+			fmt.Fprintf(buffer, "      <data key=\"editfile\">%s</data>\n", escape(node.EditFile))
+		}
+		fmt.Fprintln(buffer, "    </node>")
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(buffer, "    <edge source=\"%s\" target=\"%s\">\n", escape(edge.From), escape(edge.To))
+		fmt.Fprintf(buffer, "      <data key=\"relation\">%s</data>\n", edge.Kind)
+		fmt.Fprintln(buffer, "    </edge>")
+	}
+	fmt.Fprintln(buffer, "  </graph>")
+	fmt.Fprintln(buffer, "</graphml>")
+	return buffer.String()
+}
@@ -27,6 +27,8 @@ type Config struct {
 	ObjectsNotReplaced map[string][]string `json:"objectsnotreplaced"`
 	NotUnique          []string            `json:"notunique"`
 	NoLint             []string            `json:"nolint"`
+	LintEnable         []string            `json:"lintenable"`
+	LintDisable        []string            `json:"lintdisable"`
 	Comment            string              `json:"comment"`
 	Id                 string              `json:"$id"`
 	Python3Lint        []string            `json:"py3lint"`
@@ -48,6 +50,8 @@ func IsValidConfig(blob []byte) bool {
 		"comment":            true,
 		"core":               true,
 		"groups":             true,
+		"lintdisable":        true,
+		"lintenable":         true,
 		"mumps":              true,
 		"names":              true,
 		"nolint":             true,
@@ -1,42 +1,68 @@
 package server
 
 import (
+	"bytes"
 	"fmt"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	qfs "brocade.be/base/fs"
 	qregistry "brocade.be/base/registry"
+	qerror "brocade.be/qtechng/lib/error"
+	qutil "brocade.be/qtechng/lib/util"
 )
 
-func (release Release) InitGit() {
+// GitRevision is a commit in the history of a release
+type GitRevision struct {
+	Commit string   `json:"commit"`
+	Author string   `json:"author"`
+	Time   string   `json:"time"`
+	Batch  string   `json:"batchid"`
+	QPaths []string `json:"qpaths"`
+}
+
+// GitBlameLine is a line of a source with the commit which introduced it
+type GitBlameLine struct {
+	Lineno int    `json:"lineno"`
+	Commit string `json:"commit"`
+	Author string `json:"author"`
+	Time   string `json:"time"`
+	Batch  string `json:"batchid"`
+	Text   string `json:"text"`
+}
+
+// GitEnabled tells if the sources of the release are under version control
+func (release Release) GitEnabled() bool {
 	qtechType := qregistry.Registry["qtechng-type"]
 	if !strings.ContainsRune(qtechType, 'B') {
-		return
-
+		return false
 	}
+	return qregistry.Registry["qtechng-git-enable"] == "1"
+}
 
-	if qregistry.Registry["qtechng-git-enable"] != "1" {
+// InitGit puts the sources of the release under version control.
+// The existing sources are committed as `Init`.
+func (release Release) InitGit() {
+	if !release.GitEnabled() {
 		return
 	}
-	if release.String() != "0.00" {
+	sourcedir, _ := release.FS("").RealPath("/source")
+	if qfs.Exists(filepath.Join(sourcedir, ".git")) {
 		return
 	}
 
-	// initialises mercurial repository
-	cmd := exec.Command("git", "init", "--quiet")
-	sourcedir, _ := release.FS("").RealPath("/source")
-	cmd.Dir = sourcedir
-	cmd.Run()
-
-	cmd = exec.Command("git", "add", "--all")
-	cmd.Dir = sourcedir
-	cmd.Run()
+	// initialises git repository
+	release.git("init", "--quiet")
+	release.git("add", "--all")
+	release.git("-c", "user.name=qtechng", "-c", "user.email=qtechng@qtechng", "commit", "--quiet", "--allow-empty", "--message", "Init")
 
-	cmd = exec.Command("git", "commit", "--quiet", "--message", "Init")
-	cmd.Dir = sourcedir
-	cmd.Run()
+	if release.String() != "0.00" {
+		return
+	}
 
 	if qregistry.Registry["qtechng-backup-url"] == "" {
 		return
@@ -57,3 +83,244 @@ func (release Release) InitGit() {
 	qfs.Store(configfile, fmt.Sprintf(format, url), "qtech")
 
 }
+
+// GitCommit records the changes to qpaths as one commit.
+// The message contains the batch id and the qpaths.
+// Without author, the commit is attributed to the qtechng user.
+// Only the qpaths are committed: files staged by other batches are left alone.
+func (release Release) GitCommit(batchid string, author string, qpaths []string) (err error) {
+	if !release.GitEnabled() || len(qpaths) == 0 {
+		return nil
+	}
+	release.InitGit()
+
+	qpaths = qutil.Uniqify(qpaths)
+	sort.Strings(qpaths)
+	paths := make([]string, len(qpaths))
+	for i, qpath := range qpaths {
+		paths[i] = gitPath(qpath)
+	}
+	_, err = release.git(append([]string{"add", "--all", "--"}, paths...)...)
+	if err != nil {
+		return release.gitError("add", err)
+	}
+	_, err = release.git(append([]string{"diff", "--cached", "--quiet", "--"}, paths...)...)
+	if err == nil {
+		return nil
+	}
+
+	if author == "" {
+		author = qregistry.Registry["qtechng-user"]
+	}
+	if author == "" {
+		author = "usystem"
+	}
+	message := batchid + "\n\nBatch: " + batchid + "\n"
+	for _, qpath := range qpaths {
+		message += "QPath: " + qpath + "\n"
+	}
+	args := []string{"-c", "user.name=" + author, "-c", "user.email=" + author + "@qtechng",
+		"commit", "--quiet", "--author", author + " <" + author + "@qtechng>", "--message", message, "--only", "--"}
+	_, err = release.git(append(args, paths...)...)
+	if err != nil {
+		return release.gitError("commit", err)
+	}
+	return nil
+}
+
+// GitLog returns the history of a qpath, most recent first.
+// Without qpath, the history of the release is returned.
+func (release Release) GitLog(qpath string) (revisions []GitRevision, err error) {
+	args := []string{"log", gitFormat}
+	if qpath != "" {
+		args = append(args, "--follow", "--", gitPath(qpath))
+	}
+	return release.gitLog(args...)
+}
+
+// GitFind finds the commit in the history of the release corresponding to at:
+// a batch id, a (prefix of a) commit or a timestamp.
+// With a timestamp, the last commit before or at that moment is returned.
+// If at matches more than one commit, an error is returned: use the commit instead.
+func (release Release) GitFind(at string) (revision *GitRevision, err error) {
+	revisions, err := release.GitLog("")
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]string, 0)
+	for i, rev := range revisions {
+		if rev.Batch == at || (len(at) > 6 && strings.HasPrefix(rev.Commit, at)) {
+			if revision == nil {
+				revision = &revisions[i]
+			}
+			matches = append(matches, rev.Commit)
+		}
+	}
+	if len(matches) > 1 {
+		err = &qerror.QError{
+			Ref:     []string{"release.git.find.ambiguous"},
+			Version: release.String(),
+			Msg:     []string{"`" + at + "` matches more than one change: use one of the commits " + strings.Join(matches, ", ")},
+		}
+		return nil, err
+	}
+	if revision != nil {
+		return revision, nil
+	}
+	moment, e := time.ParseInLocation("2006-01-02T15:04:05", qutil.Time(at), time.Local)
+	if e == nil {
+		for i, rev := range revisions {
+			t, e := time.Parse(time.RFC3339, rev.Time)
+			if e == nil && !t.After(moment) {
+				return &revisions[i], nil
+			}
+		}
+	}
+	err = &qerror.QError{
+		Ref:     []string{"release.git.find"},
+		Version: release.String(),
+		Msg:     []string{"Cannot find a change with batch id, commit or before timestamp `" + at + "`"},
+	}
+	return nil, err
+}
+
+// GitShow returns the content of a qpath at a commit and the last revision which changed it.
+// If the qpath did not exist at that commit, content is nil.
+func (release Release) GitShow(commit string, qpath string) (content []byte, revision *GitRevision, err error) {
+	revisions, err := release.gitLog("log", "-1", gitFormat, commit, "--", gitPath(qpath))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(revisions) != 0 {
+		revision = &revisions[0]
+	}
+	object := commit + ":" + gitPath(qpath)
+	_, e := release.git("cat-file", "-e", object)
+	if e != nil {
+		return nil, revision, nil
+	}
+	content, err = release.git("cat-file", "blob", object)
+	if err != nil {
+		return nil, revision, release.gitError("show", err)
+	}
+	return content, revision, nil
+}
+
+const gitFormat = "--format=%H%x1f%an%x1f%aI%x1f%B%x1e"
+
+func (release Release) gitLog(args ...string) (revisions []GitRevision, err error) {
+	out, err := release.git(args...)
+	if err != nil {
+		return nil, release.gitError("log", err)
+	}
+	revisions = make([]GitRevision, 0)
+	for _, record := range strings.Split(string(out), "\x1e") {
+		record = strings.TrimSpace(record)
+		if record == "" {
+			continue
+		}
+		fields := strings.SplitN(record, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		revision := GitRevision{
+			Commit: fields[0],
+			Author: fields[1],
+			Time:   fields[2],
+			QPaths: make([]string, 0),
+		}
+		for _, line := range strings.Split(fields[3], "\n") {
+			switch {
+			case strings.HasPrefix(line, "Batch: "):
+				revision.Batch = strings.TrimSpace(strings.TrimPrefix(line, "Batch: "))
+			case strings.HasPrefix(line, "QPath: "):
+				revision.QPaths = append(revision.QPaths, strings.TrimSpace(strings.TrimPrefix(line, "QPath: ")))
+			}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// GitBlame returns, for every line of a qpath, the commit which introduced it
+func (release Release) GitBlame(qpath string) (lines []GitBlameLine, err error) {
+	out, err := release.git("blame", "--line-porcelain", "--", gitPath(qpath))
+	if err != nil {
+		return nil, release.gitError("blame", err)
+	}
+	lines = make([]GitBlameLine, 0)
+	line := GitBlameLine{}
+	header := true
+	for _, l := range strings.SplitAfter(string(out), "\n") {
+		if l == "" {
+			continue
+		}
+		if header {
+			fields := strings.Fields(l)
+			line = GitBlameLine{}
+			if len(fields) > 2 {
+				line.Commit = fields[0]
+				line.Lineno, _ = strconv.Atoi(fields[2])
+			}
+			header = false
+			continue
+		}
+		if strings.HasPrefix(l, "\t") {
+			line.Text = strings.TrimSuffix(l[1:], "\n")
+			lines = append(lines, line)
+			header = true
+			continue
+		}
+		key, value, _ := strings.Cut(strings.TrimSuffix(l, "\n"), " ")
+		switch key {
+		case "author":
+			line.Author = value
+		case "author-time":
+			sec, _ := strconv.ParseInt(value, 10, 64)
+			line.Time = time.Unix(sec, 0).Format(time.RFC3339)
+		case "summary":
+			line.Batch = value
+		}
+	}
+	return lines, nil
+}
+
+// gitPath is the path of a qpath relative to the source directory
+func gitPath(qpath string) string {
+	return "data/" + strings.TrimPrefix(qutil.Canon(qpath), "/")
+}
+
+// git runs a git command in the source directory of the release.
+// Commands which find the index locked by another process, are retried.
+func (release Release) git(args ...string) (out []byte, err error) {
+	sourcedir, _ := release.FS("").RealPath("/source")
+	for i := 0; i < 20; i++ {
+		var stdout bytes.Buffer
+		var stderr bytes.Buffer
+		cmd := exec.Command("git", args...)
+		cmd.Dir = sourcedir
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		err = cmd.Run()
+		if err == nil {
+			return stdout.Bytes(), nil
+		}
+		serr := strings.TrimSpace(stderr.String())
+		if serr != "" {
+			err = fmt.Errorf("%s: %s", err.Error(), serr)
+		}
+		if !strings.Contains(serr, "index.lock") {
+			return stdout.Bytes(), err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil, err
+}
+
+func (release Release) gitError(action string, err error) error {
+	return &qerror.QError{
+		Ref:     []string{"release.git." + action},
+		Version: release.String(),
+		Msg:     []string{"Git " + action + " failed: " + err.Error()},
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"testing"
+
+	qregistry "brocade.be/base/registry"
+)
+
+func TestGit01(t *testing.T) {
+	qregistry.Registry["qtechng-git-enable"] = "1"
+	defer delete(qregistry.Registry, "qtechng-git-enable")
+
+	release, _ := Release{}.New("9.93", false)
+	release.FS("/").RemoveAll("/")
+	err := release.Init()
+	if err != nil {
+		t.Errorf("Creation failed `%s`", err)
+		return
+	}
+	fs := release.FS()
+	fs.Store("/a/b/f1.m", "line1\nline2\n", "")
+	fs.Store("/a/b/f2.m", "other\n", "")
+	err = release.GitCommit("batch1", "rphilips", []string{"/a/b/f1.m", "/a/b/f2.m"})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	fs.Store("/a/b/f1.m", "line1\nline2 changed\n", "")
+	err = release.GitCommit("batch2", "mjeuris", []string{"/a/b/f1.m"})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	// nothing changed: no commit
+	err = release.GitCommit("batch3", "mjeuris", []string{"/a/b/f2.m"})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	revisions, err := release.GitLog("/a/b/f1.m")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if len(revisions) != 2 {
+		t.Errorf("Should have 2 revisions: %v", revisions)
+		return
+	}
+	if revisions[0].Batch != "batch2" || revisions[0].Author != "mjeuris" || len(revisions[0].QPaths) != 1 {
+		t.Errorf("Bad revision: %v", revisions[0])
+	}
+	if revisions[1].Batch != "batch1" || revisions[1].Author != "rphilips" || len(revisions[1].QPaths) != 2 {
+		t.Errorf("Bad revision: %v", revisions[1])
+	}
+
+	lines, err := release.GitBlame("/a/b/f1.m")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if len(lines) != 2 {
+		t.Errorf("Should have 2 lines: %v", lines)
+		return
+	}
+	if lines[0].Author != "rphilips" || lines[0].Batch != "batch1" || lines[0].Text != "line1" || lines[0].Lineno != 1 {
+		t.Errorf("Bad line 1: %v", lines[0])
+	}
+	if lines[1].Author != "mjeuris" || lines[1].Batch != "batch2" || lines[1].Text != "line2 changed" || lines[1].Lineno != 2 {
+		t.Errorf("Bad line 2: %v", lines[1])
+	}
+
+	// files staged by another batch are not committed
+	fs.Store("/a/b/f3.m", "staged\n", "")
+	release.git("add", "--", gitPath("/a/b/f3.m"))
+	fs.Store("/a/b/f2.m", "other changed\n", "")
+	err = release.GitCommit("batch4", "mjeuris", []string{"/a/b/f2.m"})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	revisions, _ = release.GitLog("")
+	if len(revisions) == 0 || revisions[0].Batch != "batch4" || len(revisions[0].QPaths) != 1 {
+		t.Errorf("Bad revision: %v", revisions)
+	}
+	if revisions, _ = release.GitLog("/a/b/f3.m"); len(revisions) != 0 {
+		t.Errorf("`/a/b/f3.m` should not be committed: %v", revisions)
+	}
+	release.FS("/").RemoveAll("/")
+}
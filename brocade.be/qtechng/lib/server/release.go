@@ -123,7 +123,7 @@ func (release Release) Init() (err error) {
 		return err
 	}
 	fs := release.FS("/")
-	for _, dir := range []string{"/", "/source/data", "/meta", "/unique", "/tmp", "/object/t4", "/object/m4", "/object/l4", "/object/i4", "/object/r4", "/admin", "/log"} {
+	for _, dir := range []string{"/", "/source/data", "/meta", "/unique", "/tmp", "/object/t4", "/object/m4", "/object/l4", "/object/i4", "/object/r4", "/admin", "/log", "/index", "/journal"} {
 		fs.MkdirAll(dir, 0o770)
 	}
 
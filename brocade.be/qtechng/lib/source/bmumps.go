@@ -26,6 +26,11 @@ func (bfile *Source) BFileToMumps(batchid string, buf *bytes.Buffer) error {
 	if err != nil {
 		return err
 	}
+	objectmap := make(map[string]qobject.Object)
+	err = bfile.CheckMacroCalls(content, objectmap)
+	if err != nil {
+		return err
+	}
 	content = qutil.Decomment(content).Bytes()
 	content = qutil.About(content)
 	bf := new(qofile.BFile)
@@ -40,7 +45,6 @@ func (bfile *Source) BFileToMumps(batchid string, buf *bytes.Buffer) error {
 	env := bfile.Env()
 
 	notreplace := bfile.NotReplace()
-	objectmap := make(map[string]qobject.Object)
 	bufmac := new(bytes.Buffer)
 	_, err = ResolveText(env, content, "rilm", notreplace, objectmap, textmap, bufmac, "", bfile.String())
 	if err != nil {
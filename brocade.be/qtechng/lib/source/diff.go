@@ -0,0 +1,280 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+
+	qfs "brocade.be/base/fs"
+	qparallel "brocade.be/base/parallel"
+	qerror "brocade.be/qtechng/lib/error"
+	qobject "brocade.be/qtechng/lib/object"
+	qproject "brocade.be/qtechng/lib/project"
+	qserver "brocade.be/qtechng/lib/server"
+)
+
+// ReleaseDiff models the differences between two releases
+type ReleaseDiff struct {
+	Release    string                  `json:"version"`
+	CmpRelease string                  `json:"cmpversion"`
+	Projects   map[string]*ProjectDiff `json:"projects"`
+	Objects    []*ObjectDiff           `json:"objects"`
+}
+
+// ProjectDiff models the changed sources in a project
+type ProjectDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// ObjectDiff models a changed object
+type ObjectDiff struct {
+	Object      string   `json:"object"`
+	Status      string   `json:"status"`
+	EditFile    string   `json:"source"`
+	Before      string   `json:"before"`
+	After       string   `json:"after"`
+	DepsAdded   []string `json:"depsadded"`
+	DepsRemoved []string `json:"depsremoved"`
+}
+
+// DiffRelease compares release `r` with the older release `cmpr`
+func DiffRelease(cmpr string, r string) (diff *ReleaseDiff, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	cmprelease, err := qserver.Release{}.New(cmpr, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range []*qserver.Release{release, cmprelease} {
+		if ok, _ := rel.Exists(); !ok {
+			err = &qerror.QError{
+				Ref:     []string{"source.diffrelease.notexists"},
+				Version: rel.String(),
+				Msg:     []string{"Version `" + rel.String() + "` does not exists"},
+			}
+			return nil, err
+		}
+	}
+	r = release.String()
+	cmpr = cmprelease.String()
+	diff = &ReleaseDiff{
+		Release:    r,
+		CmpRelease: cmpr,
+		Projects:   make(map[string]*ProjectDiff),
+		Objects:    make([]*ObjectDiff, 0),
+	}
+
+	// sources
+	project := func(r string, qpath string) *ProjectDiff {
+		p := "/"
+		proj := qproject.GetProject(r, qpath, true)
+		if proj != nil {
+			p = proj.String()
+		}
+		if diff.Projects[p] == nil {
+			diff.Projects[p] = &ProjectDiff{
+				Added:   make([]string, 0),
+				Removed: make([]string, 0),
+				Changed: make([]string, 0),
+			}
+		}
+		return diff.Projects[p]
+	}
+
+	query := &Query{
+		Release:    r,
+		CmpRelease: cmpr,
+	}
+	for _, source := range query.Run() {
+		qpath := source.String()
+		pdiff := project(r, qpath)
+		if ok, _ := cmprelease.FS().Exists(qpath); ok {
+			pdiff.Changed = append(pdiff.Changed, qpath)
+			continue
+		}
+		pdiff.Added = append(pdiff.Added, qpath)
+	}
+	fs := release.FS()
+	for _, qpath := range cmprelease.QPaths() {
+		if ok, _ := fs.Exists(qpath); !ok {
+			pdiff := project(cmpr, qpath)
+			pdiff.Removed = append(pdiff.Removed, qpath)
+		}
+	}
+	for _, pdiff := range diff.Projects {
+		sort.Strings(pdiff.Added)
+		sort.Strings(pdiff.Removed)
+		sort.Strings(pdiff.Changed)
+	}
+
+	// objects
+	before := releaseObjects(cmprelease)
+	after := releaseObjects(release)
+	names := make([]string, 0)
+	for name := range after {
+		if before[name] == nil || before[name].Text != after[name].Text {
+			names = append(names, name)
+		}
+	}
+	for name := range before {
+		if after[name] == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	cmpdeps, err := qobject.GetDependenciesDeep(cmprelease, names...)
+	if err != nil {
+		return diff, err
+	}
+	deps, err := qobject.GetDependenciesDeep(release, names...)
+	if err != nil {
+		return diff, err
+	}
+	for _, name := range names {
+		odiff := &ObjectDiff{
+			Object:      name,
+			DepsAdded:   make([]string, 0),
+			DepsRemoved: make([]string, 0),
+		}
+		b := before[name]
+		a := after[name]
+		switch {
+		case b == nil:
+			odiff.Status = "added"
+			odiff.EditFile = a.Source
+			odiff.After = a.Text
+		case a == nil:
+			odiff.Status = "removed"
+			odiff.EditFile = b.Source
+			odiff.Before = b.Text
+		default:
+			odiff.Status = "changed"
+			odiff.EditFile = a.Source
+			odiff.Before = b.Text
+			odiff.After = a.Text
+		}
+		mbefore := make(map[string]bool)
+		for _, d := range cmpdeps[name] {
+			mbefore[d] = true
+		}
+		mafter := make(map[string]bool)
+		for _, d := range deps[name] {
+			mafter[d] = true
+			if !mbefore[d] {
+				odiff.DepsAdded = append(odiff.DepsAdded, d)
+			}
+		}
+		for d := range mbefore {
+			if !mafter[d] {
+				odiff.DepsRemoved = append(odiff.DepsRemoved, d)
+			}
+		}
+		sort.Strings(odiff.DepsAdded)
+		sort.Strings(odiff.DepsRemoved)
+		diff.Objects = append(diff.Objects, odiff)
+	}
+	return diff, nil
+}
+
+// Unified gives the differences in unified diff format
+func (diff *ReleaseDiff) Unified() string {
+	buffer := new(bytes.Buffer)
+	projects := make([]string, 0, len(diff.Projects))
+	for p := range diff.Projects {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+
+	fetch := func(r string, qpath string) string {
+		source, err := Source{}.New(r, qpath, true)
+		if err != nil {
+			return ""
+		}
+		blob, _ := source.Fetch()
+		return string(blob)
+	}
+	unified := func(name1 string, name2 string, text1 string, text2 string) {
+		edits := myers.ComputeEdits(span.URIFromPath(name1), text1, text2)
+		fmt.Fprint(buffer, gotextdiff.ToUnified(name1, name2, text1, edits))
+	}
+
+	for _, p := range projects {
+		pdiff := diff.Projects[p]
+		for _, qpath := range pdiff.Changed {
+			unified(path.Join(diff.CmpRelease, qpath), path.Join(diff.Release, qpath), fetch(diff.CmpRelease, qpath), fetch(diff.Release, qpath))
+		}
+		for _, qpath := range pdiff.Added {
+			unified("/dev/null", path.Join(diff.Release, qpath), "", fetch(diff.Release, qpath))
+		}
+		for _, qpath := range pdiff.Removed {
+			unified(path.Join(diff.CmpRelease, qpath), "/dev/null", fetch(diff.CmpRelease, qpath), "")
+		}
+	}
+	for _, odiff := range diff.Objects {
+		before := odiff.Before
+		if before != "" && !strings.HasSuffix(before, "\n") {
+			before += "\n"
+		}
+		after := odiff.After
+		if after != "" && !strings.HasSuffix(after, "\n") {
+			after += "\n"
+		}
+		unified(diff.CmpRelease+":"+odiff.Object, diff.Release+":"+odiff.Object, before, after)
+	}
+	return buffer.String()
+}
+
+type objectDefinition struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Text   string `json:"text"`
+}
+
+// releaseObjects retrieves the definitions of all objects in a release
+func releaseObjects(release *qserver.Release) map[string]*objectDefinition {
+	fs := release.FS("/object")
+	dir, _ := fs.RealPath("/")
+	files, _ := qfs.Find(dir, []string{"obj.json"}, true, true, false)
+	fn := func(n int) (interface{}, error) {
+		blob, err := qfs.Fetch(files[n])
+		if err != nil {
+			return nil, err
+		}
+		def := new(objectDefinition)
+		err = json.Unmarshal(blob, def)
+		if err != nil || def.ID == "" {
+			return nil, err
+		}
+		if def.Text == "" {
+			def.Text = string(blob)
+		}
+		ty := filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(files[n]))))
+		if !strings.HasPrefix(def.ID, ty+"_") {
+			def.ID = ty + "_" + def.ID
+		}
+		return def, nil
+	}
+	resultlist, _ := qparallel.NMap(len(files), -1, fn)
+	objects := make(map[string]*objectDefinition)
+	for _, res := range resultlist {
+		if res == nil {
+			continue
+		}
+		def := res.(*objectDefinition)
+		objects[def.ID] = def
+	}
+	return objects
+}
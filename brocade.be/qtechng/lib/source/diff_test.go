@@ -0,0 +1,51 @@
+package source
+
+import (
+	"strings"
+	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+func TestDiffRelease01(t *testing.T) {
+	makeqRelease("9.98")
+	makeqRelease("9.99")
+
+	source, _ := Source{}.New("9.99", "/a/b/f1.txt", false)
+	blob, _ := source.Fetch()
+	meta := qmeta.Meta{Digest: qutil.Digest(blob)}
+	_, _, _, err := source.Store(meta, "Hello World f1.txt\nGood Night Moon", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	source, _ = Source{}.New("9.99", "/a/b/f4.txt", false)
+	_, _, _, err = source.Store(qmeta.Meta{}, "Hello World f4.txt", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	source, _ = Source{}.New("9.99", "/a1/b1/f3.pdf", false)
+	source.Waste()
+
+	diff, err := DiffRelease("9.98", "9.99")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	pdiff := diff.Projects["/a/b"]
+	if pdiff == nil || strings.Join(pdiff.Changed, " ") != "/a/b/f1.txt" || strings.Join(pdiff.Added, " ") != "/a/b/f4.txt" {
+		t.Errorf("Project `/a/b`: %v", pdiff)
+		return
+	}
+	pdiff = diff.Projects["/a1/b1"]
+	if pdiff == nil || strings.Join(pdiff.Removed, " ") != "/a1/b1/f3.pdf" {
+		t.Errorf("Project `/a1/b1`: %v", pdiff)
+		return
+	}
+	unified := diff.Unified()
+	if !strings.Contains(unified, "+Good Night Moon") || !strings.Contains(unified, "-Bye Moon") {
+		t.Errorf("Unified diff:\n%s", unified)
+	}
+}
@@ -0,0 +1,334 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qofile "brocade.be/qtechng/lib/file/ofile"
+	qobject "brocade.be/qtechng/lib/object"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// ResolveMap is the source map of a resolved source:
+// for every line of the result, the line in the source and the expansions which produced it.
+type ResolveMap struct {
+	Release string         `json:"version"`
+	QPath   string         `json:"qpath"`
+	Lines   []ResolveLine  `json:"lines"`
+	Guards  []ResolveGuard `json:"guards"`
+}
+
+// ResolveLine is a line of a resolved source
+type ResolveLine struct {
+	Lineno     int             `json:"lineno"`      // line in the result
+	Input      int             `json:"inputlineno"` // line in the source
+	Text       string          `json:"text"`
+	Expansions [][]ResolveStep `json:"expansions"` // chains of expansions
+}
+
+// ResolveStep is an expansion of an object (m4_, i4_, l4_, t4_).
+// Lineno is the line of the object in the text which contains it:
+// the source for the first step, the expansion of the previous step otherwise.
+type ResolveStep struct {
+	Object string `json:"object"`
+	Lineno int    `json:"lineno"`
+}
+
+// ResolveGuard shows the evaluation of the guards of a macro
+type ResolveGuard struct {
+	Macro    string          `json:"macro"`
+	Input    int             `json:"inputlineno"` // line in the source
+	Chain    []ResolveStep   `json:"chain"`
+	Branches []ResolveBranch `json:"branches"`
+	Taken    int             `json:"taken"` // number of the first branch which holds (0 if none)
+}
+
+// ResolveBranch is an action of a macro with its guard
+type ResolveBranch struct {
+	Guard  string `json:"guard"`
+	Unless bool   `json:"unless"`
+	Holds  bool   `json:"holds"`
+}
+
+// resolveTrace registers the expansions during a resolve
+type resolveTrace struct {
+	buffer   *bytes.Buffer
+	chain    []ResolveStep
+	tails    []resolveTail
+	segments []resolveSegment
+	guards   []ResolveGuard
+}
+
+// resolveTail is the text after the arguments of a macro call:
+// it is resolved together with the macro but it belongs to the caller.
+type resolveTail struct {
+	start  int // offset in the expansion, -1 if there is no tail
+	lineno int // line of the tail in the text of the caller
+}
+
+// resolveSegment is a part of the result with the same origin
+type resolveSegment struct {
+	offset int
+	input  int
+	chain  []ResolveStep
+}
+
+// mark starts a new segment in the result.
+// lineno is the line in the text which is resolved.
+func (trace *resolveTrace) mark(lineno int) {
+	if trace == nil {
+		return
+	}
+	trace.add(lineno, trace.chain)
+}
+
+// markAt starts a new segment for the text at offset pos in body
+func (trace *resolveTrace) markAt(lineno int, body []byte, pos int) {
+	if trace == nil {
+		return
+	}
+	top := len(trace.tails) - 1
+	if top < 0 || trace.tails[top].start < 0 || pos < trace.tails[top].start {
+		trace.add(lineno, trace.chain)
+		return
+	}
+	tail := trace.tails[top]
+	trace.add(tail.lineno+bytes.Count(body[tail.start:pos], []byte("\n")), trace.chain[:top])
+}
+
+// write writes a piece of text which is not resolved.
+// A piece which contains the start of a tail is split.
+func (trace *resolveTrace) write(buffer *bytes.Buffer, piece []byte, body []byte, pos int, lineno int) {
+	if trace == nil {
+		buffer.Write(piece)
+		return
+	}
+	top := len(trace.tails) - 1
+	if top >= 0 {
+		k := trace.tails[top].start - pos
+		if k > 0 && k < len(piece) {
+			trace.markAt(lineno, body, pos)
+			buffer.Write(piece[:k])
+			lineno += bytes.Count(piece[:k], []byte("\n"))
+			piece = piece[k:]
+			pos += k
+		}
+	}
+	trace.markAt(lineno, body, pos)
+	buffer.Write(piece)
+}
+
+func (trace *resolveTrace) add(lineno int, chain []ResolveStep) {
+	segment := resolveSegment{
+		offset: trace.buffer.Len(),
+		input:  lineno,
+		chain:  append([]ResolveStep(nil), chain...),
+	}
+	if len(segment.chain) != 0 {
+		segment.input = segment.chain[0].Lineno
+	}
+	n := len(trace.segments)
+	if n != 0 && trace.segments[n-1].offset == segment.offset {
+		trace.segments[n-1] = segment
+		return
+	}
+	trace.segments = append(trace.segments, segment)
+}
+
+// push starts the expansion of an object on a line of the text which is resolved
+func (trace *resolveTrace) push(object string, lineno int) {
+	if trace == nil {
+		return
+	}
+	trace.chain = append(trace.chain, ResolveStep{Object: object, Lineno: lineno})
+	trace.tails = append(trace.tails, resolveTail{start: -1})
+	trace.mark(lineno)
+}
+
+// pop ends the expansion of an object
+func (trace *resolveTrace) pop() {
+	if trace == nil {
+		return
+	}
+	trace.chain = trace.chain[:len(trace.chain)-1]
+	trace.tails = trace.tails[:len(trace.tails)-1]
+}
+
+// tail registers the start of the tail in the expansion of a macro.
+// skip is the number of lines in the arguments of the macro call.
+func (trace *resolveTrace) tail(start int, skip int) {
+	if trace == nil {
+		return
+	}
+	top := len(trace.tails) - 1
+	trace.tails[top] = resolveTail{start: start, lineno: trace.chain[top].Lineno + skip}
+}
+
+// guard registers the evaluation of the guards of a macro
+func (trace *resolveTrace) guard(macro *qofile.Macro, env map[string]string) {
+	if trace == nil {
+		return
+	}
+	guard := ResolveGuard{
+		Macro:    macro.String(),
+		Chain:    append([]ResolveStep(nil), trace.chain...),
+		Branches: make([]ResolveBranch, len(macro.Actions)),
+	}
+	if len(guard.Chain) != 0 {
+		guard.Input = guard.Chain[0].Lineno
+	}
+	for i, action := range macro.Actions {
+		holds := action.Holds(env)
+		guard.Branches[i] = ResolveBranch{
+			Guard:  strings.Join(action.Guard, " "),
+			Unless: action.Unless,
+			Holds:  holds,
+		}
+		if holds && guard.Taken == 0 {
+			guard.Taken = i + 1
+		}
+	}
+	trace.guards = append(trace.guards, guard)
+}
+
+// sourceMap computes the source map of the result
+func (trace *resolveTrace) sourceMap() []ResolveLine {
+	result := trace.buffer.Bytes()
+	segments := trace.segments
+	bound := func(i int) int {
+		if i+1 < len(segments) {
+			return segments[i+1].offset
+		}
+		return len(result)
+	}
+	lines := make([]ResolveLine, 0)
+	seg := 0
+	for start, lineno := 0, 1; start < len(result) || lineno == 1; lineno++ {
+		end := len(result)
+		if k := bytes.IndexByte(result[start:], '\n'); k != -1 {
+			end = start + k + 1
+		}
+		for seg+1 < len(segments) && segments[seg+1].offset <= start {
+			seg++
+		}
+		line := ResolveLine{
+			Lineno:     lineno,
+			Text:       strings.TrimRight(string(result[start:end]), "\r\n"),
+			Expansions: make([][]ResolveStep, 0),
+		}
+		if seg < len(segments) {
+			segment := segments[seg]
+			line.Input = segment.input
+			if len(segment.chain) == 0 {
+				line.Input += bytes.Count(result[segment.offset:start], []byte("\n"))
+			}
+		}
+		found := make(map[string]bool)
+		for i := seg; i < len(segments) && segments[i].offset < end; i++ {
+			segment := segments[i]
+			if len(segment.chain) == 0 || bound(i) <= start || bound(i) == segment.offset {
+				continue
+			}
+			key := fmt.Sprint(segment.chain)
+			if found[key] {
+				continue
+			}
+			found[key] = true
+			line.Expansions = append(line.Expansions, segment.chain)
+		}
+		lines = append(lines, line)
+		start = end
+	}
+	return lines
+}
+
+// Explain resolves the r4/i4/m4/l4 constructions in a source (as Resolve does:
+// the macro calls are checked first) and returns the source map of the result.
+func (source *Source) Explain(what string, decomment bool) (smap *ResolveMap, err error) {
+	body, err := source.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	trace := &resolveTrace{buffer: new(bytes.Buffer)}
+	smap = &ResolveMap{
+		Release: source.Release().String(),
+		QPath:   source.String(),
+		Guards:  make([]ResolveGuard, 0),
+	}
+	nature := source.Natures()
+	if !bytes.Contains(body, []byte("4_")) || !nature["text"] || nature["objectfile"] {
+		trace.mark(1)
+		trace.buffer.Write(body)
+		smap.Lines = trace.sourceMap()
+		return smap, nil
+	}
+	objectmap := make(map[string]qobject.Object)
+	textmap := make(map[string]string)
+	err = source.CheckMacroCalls(body, objectmap)
+	if err != nil {
+		return nil, err
+	}
+	if decomment {
+		body = qutil.Decomment(body).Bytes()
+	}
+	_, err = resolveText(source.Env(), body, what, source.NotReplace(), objectmap, textmap, trace.buffer, "", source.String(), trace)
+	if err != nil {
+		e := &qerror.QError{
+			Ref:     []string{"source.explain"},
+			Version: smap.Release,
+			QPath:   smap.QPath,
+			Msg:     []string{"Cannot resolve"},
+		}
+		return nil, qerror.QErrorTune(err, e)
+	}
+	smap.Lines = trace.sourceMap()
+	if trace.guards != nil {
+		smap.Guards = trace.guards
+	}
+	return smap, nil
+}
+
+// Text renders a source map as annotated text:
+// every line of the result is preceded by its line number and the line number in the source.
+// The expansions are shown below the line, followed by the evaluated guards.
+func (smap *ResolveMap) Text() string {
+	buffer := new(bytes.Buffer)
+	chain := func(steps []ResolveStep) string {
+		parts := make([]string, len(steps))
+		for i, step := range steps {
+			parts[i] = step.Object + ":" + strconv.Itoa(step.Lineno)
+		}
+		return strings.Join(parts, " > ")
+	}
+	fmt.Fprintf(buffer, "# %s [%s]\n", smap.QPath, smap.Release)
+	for _, line := range smap.Lines {
+		fmt.Fprintf(buffer, "%5d <%5d | %s\n", line.Lineno, line.Input, line.Text)
+		for _, steps := range line.Expansions {
+			fmt.Fprintf(buffer, "%13s | ^ %s\n", "", chain(steps))
+		}
+	}
+	for _, guard := range smap.Guards {
+		fmt.Fprintf(buffer, "# guard %s (line %d: %s)\n", guard.Macro, guard.Input, chain(guard.Chain))
+		for i, branch := range guard.Branches {
+			mark := " "
+			if i+1 == guard.Taken {
+				mark = "*"
+			}
+			condition := branch.Guard
+			if condition == "" {
+				condition = "true"
+			}
+			if branch.Unless {
+				condition = "unless " + condition
+			}
+			fmt.Fprintf(buffer, "#   %s %d. %s => %t\n", mark, i+1, condition, branch.Holds)
+		}
+		if guard.Taken == 0 {
+			fmt.Fprintln(buffer, "#   no branch holds: the macro is empty")
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,100 @@
+package source
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+)
+
+func TestExplain01(t *testing.T) {
+	r := "9.89"
+	proj := "/a/b/c"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+
+	dfile := string(dfile1()) + `
+
+macro getSign($x):
+    '''
+    $synopsis: Sign
+    $x: number
+    $example: m4_getSign(1)
+    '''
+	«s x=1» if «$x isEqualTo "1"»
+	«s x=-1»
+`
+	fdata := func(p string) ([]byte, error) {
+		switch p {
+		case proj + "/acat.d":
+			return []byte(dfile), nil
+		case proj + "/bad.txt":
+			return []byte("m4_getSign(1,2)\n"), nil
+		default:
+			return []byte("Hello\nm4_getSign(2) m4_getSign(1)\nWorld m4_setCatGenStatus(1,2,3)\nEnd\n"), nil
+		}
+	}
+	fmeta := func(p string) qmeta.Meta { return qmeta.Meta{} }
+	qpaths := []string{proj + "/acat.d", proj + "/my.txt", proj + "/bad.txt"}
+	_, errs := StoreList("install", r, qpaths, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Errorf(errs.Error())
+		return
+	}
+	source, err := Source{}.New(r, proj+"/my.txt", true)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	smap, err := source.Explain("r:rilm", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if len(smap.Lines) != 4 {
+		t.Errorf("Should be 4 lines: %v", smap.Lines)
+		return
+	}
+	if smap.Lines[1].Text != "s x=-1 s x=1" || smap.Lines[1].Input != 2 {
+		t.Errorf("Wrong line 2: %v", smap.Lines[1])
+	}
+	if len(smap.Lines[1].Expansions) != 1 || smap.Lines[1].Expansions[0][0].Object != "m4_getSign" {
+		t.Errorf("Wrong expansions of line 2: %v", smap.Lines[1].Expansions)
+	}
+	line := smap.Lines[2]
+	if !strings.HasPrefix(line.Text, "World m4_CO d %SetSs^gbcat(1,2,3") || line.Input != 3 {
+		t.Errorf("Wrong line 3: %v", line)
+	}
+	if smap.Lines[3].Text != "End" || smap.Lines[3].Input != 4 || len(smap.Lines[3].Expansions) != 0 {
+		t.Errorf("Wrong line 4: %v", smap.Lines[3])
+	}
+	if len(smap.Guards) != 3 {
+		t.Errorf("Should be 3 guards: %v", smap.Guards)
+		return
+	}
+	if smap.Guards[0].Macro != "m4_getSign" || smap.Guards[0].Taken != 2 || smap.Guards[1].Taken != 1 {
+		t.Errorf("Wrong guards: %v", smap.Guards)
+	}
+	text := smap.Text()
+	if !strings.Contains(text, "    2 <    2 | s x=-1 s x=1") || !strings.Contains(text, "^ m4_getSign:2") {
+		t.Errorf("Wrong text:\n%s", text)
+	}
+
+	// same result as resolve
+	buffer := new(bytes.Buffer)
+	source.Resolve("r:rilm", nil, nil, buffer, false)
+	texts := make([]string, len(smap.Lines))
+	for i, line := range smap.Lines {
+		texts[i] = line.Text
+	}
+	if strings.Join(texts, "\n")+"\n" != buffer.String() {
+		t.Errorf("Explain should resolve as resolve does:\n%s\n%s", strings.Join(texts, "\n"), buffer.String())
+	}
+
+	// macro calls are checked
+	bad, _ := Source{}.New(r, proj+"/bad.txt", true)
+	if _, err = bad.Explain("r:rilm", false); err == nil {
+		t.Errorf("Wrong macro call should fail")
+	}
+}
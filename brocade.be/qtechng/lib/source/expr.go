@@ -0,0 +1,376 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	qfnmatch "brocade.be/base/fnmatch"
+	qerror "brocade.be/qtechng/lib/error"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// Expr is a boolean expression on sources, e.g.
+//
+//	nature:mfile AND mu:rphilips AND (contains:"^BCAT" OR object:m4_*) AND NOT qdir:/core/*
+//
+// Terms are `field:value`, combined with AND, OR, NOT and parentheses.
+// Juxtaposed terms are combined with AND.
+// Fields:
+//
+//	nature    nature of the source ("mfile", "text", "mumps", ...)
+//	cu, mu    creator, last modifier
+//	ctbefore, ctafter, mtbefore, mtafter   timestamps
+//	contains  substring of the contents
+//	regexp    regular expression on the contents
+//	object    Brocade object used in the source (glob pattern)
+//	qpath     glob pattern on the qpath
+//	qdir      glob pattern on the qdir (without wildcards: the qdir and its subdirectories)
+//	project   glob pattern on the project
+type Expr struct {
+	Op    string // "AND", "OR", "NOT" or "" for a term
+	Field string
+	Value string
+	Args  []*Expr
+	rex   *regexp.Regexp
+}
+
+var exprFields = map[string]bool{
+	"nature":   true,
+	"cu":       true,
+	"mu":       true,
+	"ctbefore": true,
+	"ctafter":  true,
+	"mtbefore": true,
+	"mtafter":  true,
+	"contains": true,
+	"regexp":   true,
+	"object":   true,
+	"qpath":    true,
+	"qdir":     true,
+	"project":  true,
+}
+
+type exprParser struct {
+	text   string
+	tokens []string
+	pos    int
+}
+
+// ParseExpr parses a query expression
+func ParseExpr(text string) (expr *Expr, err error) {
+	tokens, err := exprTokens(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &exprParser{text: text, tokens: tokens}
+	expr, err = p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, p.error("unexpected `" + p.tokens[p.pos] + "`")
+	}
+	return expr, nil
+}
+
+// String of an expression: canonical form
+func (expr *Expr) String() string {
+	switch expr.Op {
+	case "NOT":
+		return "NOT " + expr.Args[0].String()
+	case "AND", "OR":
+		parts := make([]string, len(expr.Args))
+		for i, arg := range expr.Args {
+			parts[i] = arg.String()
+			if arg.Op == "AND" || arg.Op == "OR" {
+				parts[i] = "(" + parts[i] + ")"
+			}
+		}
+		return strings.Join(parts, " "+expr.Op+" ")
+	}
+	value := expr.Value
+	if value == "" || strings.ContainsAny(value, " \t\"()\\") {
+		value = fmt.Sprintf("%q", value)
+	}
+	return expr.Field + ":" + value
+}
+
+func (p *exprParser) error(msg string) error {
+	return &qerror.QError{
+		Ref: []string{"source.expr.parse"},
+		Msg: []string{fmt.Sprintf("Query expression `%s`: %s", p.text, msg)},
+	}
+}
+
+func (p *exprParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) or() (*Expr, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	args := []*Expr{left}
+	for strings.ToUpper(p.peek()) == "OR" {
+		p.pos++
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	if len(args) == 1 {
+		return left, nil
+	}
+	return &Expr{Op: "OR", Args: args}, nil
+}
+
+func (p *exprParser) and() (*Expr, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+	args := []*Expr{left}
+	for {
+		token := p.peek()
+		if token == "" || token == ")" || strings.ToUpper(token) == "OR" {
+			break
+		}
+		if strings.ToUpper(token) == "AND" {
+			p.pos++
+		}
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, right)
+	}
+	if len(args) == 1 {
+		return left, nil
+	}
+	return &Expr{Op: "AND", Args: args}, nil
+}
+
+func (p *exprParser) not() (*Expr, error) {
+	if strings.ToUpper(p.peek()) == "NOT" {
+		p.pos++
+		arg, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Op: "NOT", Args: []*Expr{arg}}, nil
+	}
+	return p.term()
+}
+
+func (p *exprParser) term() (*Expr, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, p.error("unexpected end")
+	case token == "(":
+		p.pos++
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, p.error("missing `)`")
+		}
+		p.pos++
+		return expr, nil
+	case token == ")":
+		return nil, p.error("unexpected `)`")
+	}
+	p.pos++
+	k := strings.Index(token, ":")
+	if k < 1 {
+		return nil, p.error("`" + token + "` is not of the form field:value")
+	}
+	field := strings.ToLower(token[:k])
+	if !exprFields[field] {
+		return nil, p.error("unknown field `" + field + "`")
+	}
+	value := token[k+1:]
+	if strings.HasPrefix(value, "\"") {
+		value = unquoteExpr(value)
+	}
+	expr := &Expr{Field: field, Value: value}
+	switch field {
+	case "regexp":
+		rex, err := regexp.Compile(value)
+		if err != nil {
+			return nil, p.error("invalid regular expression `" + value + "`")
+		}
+		expr.rex = rex
+	case "ctbefore", "ctafter", "mtbefore", "mtafter":
+		expr.Value = qutil.Time(value)
+	case "object":
+		expr.Value, _ = qutil.DeNEDFU(value)
+	}
+	return expr, nil
+}
+
+// exprTokens splits in tokens: parentheses, words and field:"quoted values"
+func exprTokens(text string) (tokens []string, err error) {
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		default:
+			start := i
+			quoted := false
+			for i < len(runes) {
+				r = runes[i]
+				if quoted {
+					if r == '\\' && i+1 < len(runes) {
+						i += 2
+						continue
+					}
+					if r == '"' {
+						quoted = false
+					}
+					i++
+					continue
+				}
+				if r == '"' {
+					quoted = true
+					i++
+					continue
+				}
+				if unicode.IsSpace(r) || r == '(' || r == ')' {
+					break
+				}
+				i++
+			}
+			if quoted {
+				return nil, &qerror.QError{
+					Ref: []string{"source.expr.quote"},
+					Msg: []string{fmt.Sprintf("Query expression `%s`: unterminated string", text)},
+				}
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		}
+	}
+	return tokens, nil
+}
+
+func unquoteExpr(value string) string {
+	value = strings.TrimPrefix(value, "\"")
+	value = strings.TrimSuffix(value, "\"")
+	var b strings.Builder
+	escaped := false
+	for _, r := range value {
+		if escaped {
+			b.WriteRune(r)
+			escaped = false
+			continue
+		}
+		if r == '\\' {
+			escaped = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Test a source against an expression
+func (expr *Expr) Test(source *Source, tolower bool) bool {
+	switch expr.Op {
+	case "NOT":
+		return !expr.Args[0].Test(source, tolower)
+	case "AND":
+		for _, arg := range expr.Args {
+			if !arg.Test(source, tolower) {
+				return false
+			}
+		}
+		return true
+	case "OR":
+		for _, arg := range expr.Args {
+			if arg.Test(source, tolower) {
+				return true
+			}
+		}
+		return false
+	}
+
+	qpath := source.String()
+	switch expr.Field {
+	case "nature":
+		return source.Natures()[expr.Value]
+	case "qpath":
+		return qutil.EMatch(expr.Value, qpath)
+	case "qdir":
+		qdir, _ := qutil.QPartition(qpath)
+		return qutil.EMatch(expr.Value, qdir)
+	case "project":
+		return qutil.EMatch(expr.Value, source.Project().String())
+	case "cu", "mu", "ctbefore", "ctafter", "mtbefore", "mtafter":
+		meta, err := qmeta.Meta{}.New(source.Release().String(), qpath)
+		if err != nil {
+			return false
+		}
+		switch expr.Field {
+		case "cu":
+			return qfnmatch.Match(expr.Value, meta.Cu)
+		case "mu":
+			return qfnmatch.Match(expr.Value, meta.Mu)
+		case "ctbefore":
+			return strings.Compare(expr.Value, meta.Ct) == 1
+		case "ctafter":
+			return strings.Compare(expr.Value, meta.Ct) == -1
+		case "mtbefore":
+			return strings.Compare(expr.Value, meta.Mt) == 1
+		case "mtafter":
+			return strings.Compare(expr.Value, meta.Mt) == -1
+		}
+	}
+
+	blob, err := source.Fetch()
+	if err != nil {
+		return false
+	}
+	switch expr.Field {
+	case "contains":
+		if tolower {
+			return bytes.Contains(bytes.ToLower(blob), []byte(strings.ToLower(expr.Value)))
+		}
+		return bytes.Contains(blob, []byte(expr.Value))
+	case "regexp":
+		if tolower {
+			blob = bytes.ToLower(blob)
+		}
+		return expr.rex.Match(blob)
+	case "object":
+		odd := true
+		for _, bobj := range qutil.ObjectSplitter(blob) {
+			odd = !odd
+			if !odd {
+				continue
+			}
+			obj, _ := qutil.DeNEDFU(string(bobj))
+			if qutil.OMatch(expr.Value, obj) {
+				return true
+			}
+		}
+	}
+	return false
+}
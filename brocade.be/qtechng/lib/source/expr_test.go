@@ -0,0 +1,58 @@
+package source
+
+import (
+	"testing"
+)
+
+func TestExprParse01(t *testing.T) {
+	cases := map[string]string{
+		`nature:mfile`: `nature:mfile`,
+		`nature:mfile AND mu:rphilips AND (contains:"^BCAT" OR object:m4_*) AND NOT qdir:/core/*`: `nature:mfile AND mu:rphilips AND (contains:^BCAT OR object:m4_*) AND NOT qdir:/core/*`,
+		`nature:mfile mu:rphilips`: `nature:mfile AND mu:rphilips`,
+		`a:b`:                      "",
+		`contains:"Hello World" or not nature:mfile`: `contains:"Hello World" OR NOT nature:mfile`,
+		`(nature:mfile`: "",
+		`contains:"abc`: "",
+		`regexp:"[a"`:   "",
+	}
+	for text, expected := range cases {
+		expr, err := ParseExpr(text)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("`%s` should not parse", text)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("`%s` should parse: %s", text, err)
+			continue
+		}
+		if expr.String() != expected {
+			t.Errorf("`%s`:\nfound   : %s\nexpected: %s", text, expr.String(), expected)
+		}
+	}
+}
+
+func TestExprQuery01(t *testing.T) {
+	r := "9.99"
+	makeqRelease(r)
+
+	cases := map[string]int{
+		`contains:"Hello World"`:                       9,
+		`contains:f1.txt OR contains:f2.bin`:           6,
+		`contains:"Hello World" AND NOT qdir:/a/b`:     3,
+		`contains:"Hello World" AND NOT qdir:/a/b/c/*`: 6,
+		`project:/a1/b1 AND mu:rphilips`:               3,
+		`regexp:"f[12]\\." AND qpath:/a/*`:             4,
+	}
+	for text, expected := range cases {
+		query := &Query{
+			Release: r,
+			Expr:    text,
+		}
+		sources := query.Run()
+		if len(sources) != expected {
+			t.Errorf("`%s`: found %d, expected %d", text, len(sources), expected)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package source
+
+import (
+	"sort"
+	"strings"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qobject "brocade.be/qtechng/lib/object"
+	qproject "brocade.be/qtechng/lib/project"
+	qserver "brocade.be/qtechng/lib/server"
+)
+
+// Impact reports what is affected by a change of objects
+type Impact struct {
+	Release  string         `json:"version"`
+	Objects  []string       `json:"objects"`
+	Depends  []string       `json:"dependentobjects"`
+	Sources  []string       `json:"sources"`
+	Routines []string       `json:"mroutines"`
+	Projects map[string]int `json:"projects"`
+}
+
+// ObjectImpact computes the impact of a change of objects:
+// the dependent objects, the dependent sources and the M routines
+// which have to be recompiled and reinstalled, with their number per project.
+func ObjectImpact(r string, objs ...string) (impact *Impact, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := release.Exists(); !ok {
+		err = &qerror.QError{
+			Ref:     []string{"source.impact.notexists"},
+			Version: release.String(),
+			Msg:     []string{"Version `" + release.String() + "` does not exists"},
+		}
+		return nil, err
+	}
+	r = release.String()
+	mdeps, err := qobject.GetDependenciesDeep(release, objs...)
+	if err != nil {
+		return nil, err
+	}
+	impact = &Impact{
+		Release:  r,
+		Objects:  objs,
+		Depends:  make([]string, 0),
+		Sources:  make([]string, 0),
+		Routines: make([]string, 0),
+		Projects: make(map[string]int),
+	}
+	found := make(map[string]bool)
+	for _, deps := range mdeps {
+		for _, dep := range deps {
+			if found[dep] {
+				continue
+			}
+			found[dep] = true
+			if !strings.HasPrefix(dep, "/") {
+				impact.Depends = append(impact.Depends, dep)
+				continue
+			}
+			impact.Sources = append(impact.Sources, dep)
+		}
+	}
+	sort.Strings(impact.Depends)
+	sort.Strings(impact.Sources)
+
+	for _, qpath := range impact.Sources {
+		source, err := Source{}.New(r, qpath, true)
+		if err != nil {
+			continue
+		}
+		if !source.Natures()["mumps"] {
+			continue
+		}
+		impact.Routines = append(impact.Routines, qpath)
+		p := "/"
+		proj := qproject.GetProject(r, qpath, true)
+		if proj != nil {
+			p = proj.String()
+		}
+		impact.Projects[p]++
+	}
+	return impact, nil
+}
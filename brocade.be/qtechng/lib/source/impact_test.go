@@ -0,0 +1,84 @@
+package source
+
+import (
+	"strings"
+	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+	qobject "brocade.be/qtechng/lib/object"
+)
+
+func TestImpact01(t *testing.T) {
+	r := "9.92"
+	proj := "/a/b/c"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+
+	fdata := func(p string) ([]byte, error) {
+		switch p {
+		case proj + "/acat.d":
+			return dfile1(), nil
+		case proj + "/zcat.m":
+			return []byte("zcat\t; About: test\n\tm4_getCatGenStatus(.RA,\"c:lvd:1\")\n\tq\n"), nil
+		default:
+			return []byte("m4_setCatGenStatus(1,2,3)"), nil
+		}
+	}
+	fmeta := func(p string) qmeta.Meta { return qmeta.Meta{} }
+	qpaths := []string{proj + "/acat.d", proj + "/zcat.m", proj + "/my.txt"}
+	_, errs := StoreList("install", r, qpaths, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Errorf(errs.Error())
+		return
+	}
+
+	graph, err := qobject.GetGraph(release, "", []string{"m4"})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	edges := make(map[string]string)
+	for _, edge := range graph.Edges {
+		edges[edge.From+" "+edge.To] = edge.Kind
+	}
+	if edges[proj+"/acat.d m4_getCatGenStatus"] != "defines" {
+		t.Errorf("acat.d should define m4_getCatGenStatus: %v", edges)
+	}
+	if edges[proj+"/zcat.m m4_getCatGenStatus"] != "uses" {
+		t.Errorf("zcat.m should use m4_getCatGenStatus: %v", edges)
+	}
+	if edges["m4_setCatGenStatus m4_CO"] != "uses" {
+		t.Errorf("m4_setCatGenStatus should use m4_CO: %v", edges)
+	}
+	dot := graph.DOT()
+	if !strings.Contains(dot, `"/a/b/c/zcat.m" -> "m4_getCatGenStatus"`) {
+		t.Errorf("Wrong DOT: %s", dot)
+	}
+	graphml := graph.GraphML()
+	if !strings.Contains(graphml, `<edge source="/a/b/c/zcat.m" target="m4_getCatGenStatus">`) {
+		t.Errorf("Wrong GraphML: %s", graphml)
+	}
+
+	graph, _ = qobject.GetGraph(release, "/x", nil)
+	if len(graph.Edges) != 0 {
+		t.Errorf("Graph of other project should be empty: %v", graph.Edges)
+	}
+
+	impact, err := ObjectImpact(r, "m4_getCatGenStatus", "m4_CO")
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if strings.Join(impact.Routines, " ") != proj+"/zcat.m" {
+		t.Errorf("Wrong routines: %v", impact.Routines)
+	}
+	if impact.Projects[proj] != 1 {
+		t.Errorf("Wrong projects: %v", impact.Projects)
+	}
+	if strings.Join(impact.Depends, " ") != "m4_setCatGenStatus" {
+		t.Errorf("Wrong dependent objects: %v", impact.Depends)
+	}
+	if len(impact.Sources) != 3 {
+		t.Errorf("Wrong sources: %v", impact.Sources)
+	}
+}
@@ -0,0 +1,460 @@
+package source
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	qparallel "brocade.be/base/parallel"
+	qerror "brocade.be/qtechng/lib/error"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// The index of a release lives in `{release}/index`:
+// 256 shards, each shard is a JSON file with an IndexEntry per qpath.
+// An entry is only trusted if size and modification time of the
+// source file still match: stale or missing entries never exclude a source.
+
+var indexLocks = new(sync.Map)
+
+// IndexEntry index information on a source
+type IndexEntry struct {
+	QPath    string   `json:"qpath"`
+	Digest   string   `json:"digest"`
+	Size     int64    `json:"size"`
+	ModTime  int64    `json:"modtime"`
+	Natures  []string `json:"natures"`
+	Cu       string   `json:"cu"`
+	Mu       string   `json:"mu"`
+	Ct       string   `json:"ct"`
+	Mt       string   `json:"mt"`
+	Trigrams string   `json:"trigrams"`
+}
+
+// IndexCheck result of a consistency check of the index
+type IndexCheck struct {
+	Release  string   `json:"version"`
+	Sources  int      `json:"sources"`
+	Entries  int      `json:"entries"`
+	Missing  []string `json:"missing"`
+	Stale    []string `json:"stale"`
+	Orphaned []string `json:"orphaned"`
+}
+
+type indexShard map[string]*IndexEntry
+
+// Index (re)calculates the index entry of a source
+func (source *Source) Index() (entry *IndexEntry, err error) {
+	version := source.Release()
+	fs := version.FS()
+	qpath := source.String()
+	fi, e := fs.Stat(qpath)
+	if e != nil {
+		err = &qerror.QError{
+			Ref:     []string{"source.index.stat"},
+			Version: version.String(),
+			QPath:   qpath,
+			Msg:     []string{"Cannot stat file: " + e.Error()},
+		}
+		return nil, err
+	}
+	blob, err := source.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	entry = &IndexEntry{
+		QPath:   qpath,
+		Digest:  qutil.Digest(blob),
+		Size:    fi.Size(),
+		ModTime: fi.ModTime().UnixNano(),
+	}
+	natures := source.Natures()
+	for nature := range natures {
+		entry.Natures = append(entry.Natures, nature)
+	}
+	sort.Strings(entry.Natures)
+
+	meta, e := qmeta.Meta{}.New(version.String(), qpath)
+	if e == nil {
+		entry.Cu = meta.Cu
+		entry.Mu = meta.Mu
+		entry.Ct = meta.Ct
+		entry.Mt = meta.Mt
+	}
+	if natures["text"] {
+		entry.Trigrams = base64.StdEncoding.EncodeToString(trigramFilter(blob))
+	}
+	return entry, nil
+}
+
+// UpdateIndex stores the actual index entry of a source
+func (source *Source) UpdateIndex() error {
+	entry, err := source.Index()
+	if err != nil {
+		return err
+	}
+	r := source.Release().String()
+	return updateShard(r, source.String(), func(shard indexShard) bool {
+		shard[entry.QPath] = entry
+		return true
+	})
+}
+
+// UnlinkIndex removes a source from the index
+func (source *Source) UnlinkIndex() error {
+	r := source.Release().String()
+	qpath := source.String()
+	return updateShard(r, qpath, func(shard indexShard) bool {
+		if shard[qpath] == nil {
+			return false
+		}
+		delete(shard, qpath)
+		return true
+	})
+}
+
+// invalidateIndex removes the entries under a directory:
+// natures depend on the configuration of the project.
+func invalidateIndex(r string, qdir string) {
+	release, err := qserver.Release{}.New(r, false)
+	if err != nil {
+		return
+	}
+	prefix := strings.TrimSuffix(qdir, "/") + "/"
+	fs := release.FS("/index")
+	for _, name := range fs.Dir("/", true, false) {
+		lock, _ := indexLocks.LoadOrStore(release.String()+" "+strings.TrimPrefix(name, "/"), new(sync.Mutex))
+		lock.(*sync.Mutex).Lock()
+		shard := make(indexShard)
+		blob, e := fs.ReadFile(name)
+		if e == nil && json.Unmarshal(blob, &shard) == nil {
+			changed := false
+			for qpath := range shard {
+				if strings.HasPrefix(qpath, prefix) {
+					delete(shard, qpath)
+					changed = true
+				}
+			}
+			if changed {
+				fs.Store(name, shard, "")
+			}
+		}
+		lock.(*sync.Mutex).Unlock()
+	}
+}
+
+// RebuildIndex reconstructs the index of a release from scratch
+func RebuildIndex(r string) (err error) {
+	release, err := qserver.Release{}.New(r, false)
+	if err != nil {
+		return err
+	}
+	r = release.String()
+	qpaths := release.QPaths()
+	fn := func(n int) (interface{}, error) {
+		source, err := Source{}.New(r, qpaths[n], true)
+		if err != nil {
+			return nil, err
+		}
+		return source.Index()
+	}
+	resultlist, errorlist := qparallel.NMap(len(qpaths), -1, fn)
+
+	shards := make(map[string]indexShard)
+	for i, res := range resultlist {
+		if errorlist[i] != nil || res == nil {
+			continue
+		}
+		entry := res.(*IndexEntry)
+		name := shardName(entry.QPath)
+		if shards[name] == nil {
+			shards[name] = make(indexShard)
+		}
+		shards[name][entry.QPath] = entry
+	}
+
+	fs := release.FS("/index")
+	for _, name := range fs.Dir("/", true, false) {
+		fs.Remove(name)
+	}
+	for name, shard := range shards {
+		_, _, _, e := fs.Store("/"+name, shard, "")
+		if e != nil {
+			err = &qerror.QError{
+				Ref:     []string{"source.index.rebuild"},
+				Version: r,
+				Msg:     []string{"Cannot store index: " + e.Error()},
+			}
+			return err
+		}
+	}
+	errslice := qerror.NewErrorSlice()
+	for _, e := range errorlist {
+		if e != nil {
+			errslice = append(errslice, e)
+		}
+	}
+	if len(errslice) != 0 {
+		return errslice
+	}
+	return nil
+}
+
+// CheckIndex verifies the index of a release against the sources on disk
+func CheckIndex(r string) (check *IndexCheck, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	r = release.String()
+	qpaths := release.QPaths()
+	entries := loadIndex(release)
+	check = &IndexCheck{
+		Release:  r,
+		Sources:  len(qpaths),
+		Entries:  len(entries),
+		Missing:  make([]string, 0),
+		Stale:    make([]string, 0),
+		Orphaned: make([]string, 0),
+	}
+	found := make(map[string]bool)
+	for _, qpath := range qpaths {
+		found[qpath] = true
+		entry := entries[qpath]
+		if entry == nil {
+			check.Missing = append(check.Missing, qpath)
+			continue
+		}
+		source, e := Source{}.New(r, qpath, true)
+		if e != nil {
+			check.Stale = append(check.Stale, qpath)
+			continue
+		}
+		actual, e := source.Index()
+		if e != nil || actual.Digest != entry.Digest || actual.Mt != entry.Mt || actual.Mu != entry.Mu || strings.Join(actual.Natures, " ") != strings.Join(entry.Natures, " ") {
+			check.Stale = append(check.Stale, qpath)
+		}
+	}
+	for qpath := range entries {
+		if !found[qpath] {
+			check.Orphaned = append(check.Orphaned, qpath)
+		}
+	}
+	sort.Strings(check.Missing)
+	sort.Strings(check.Stale)
+	sort.Strings(check.Orphaned)
+	return check, nil
+}
+
+// narrow removes the qpaths which, according to the index, cannot match the query
+func (query *Query) narrow(release *qserver.Release, qpaths []string) []string {
+	needles := query.indexNeedles()
+	meta := query.CtBefore != "" || query.CtAfter != "" || query.MtBefore != "" || query.MtAfter != "" || len(query.Cu) != 0 || len(query.Mu) != 0
+	if len(needles) == 0 && !meta && len(query.Natures) == 0 && !query.Mumps {
+		return qpaths
+	}
+	if query.index == nil {
+		query.index = loadIndex(release)
+	}
+	entries := query.index
+	if len(entries) == 0 {
+		return qpaths
+	}
+	fs := release.FS()
+	keep := make([]string, 0, len(qpaths))
+	for _, qpath := range qpaths {
+		entry := entries[qpath]
+		if entry == nil {
+			keep = append(keep, qpath)
+			continue
+		}
+		fi, err := fs.Stat(qpath)
+		if err != nil || fi.Size() != entry.Size || fi.ModTime().UnixNano() != entry.ModTime {
+			keep = append(keep, qpath)
+			continue
+		}
+		if entry.match(query, needles) {
+			keep = append(keep, qpath)
+		}
+	}
+	return keep
+}
+
+// indexNeedles gives the trigrams to look for: regular expressions are not used
+func (query *Query) indexNeedles() [][]byte {
+	if query.Regexp || len(query.Contains) == 0 {
+		return nil
+	}
+	needles := make([][]byte, 0)
+	for _, needle := range query.Contains {
+		if len(needle) < 3 {
+			continue
+		}
+		needles = append(needles, bytes.ToLower([]byte(needle)))
+	}
+	return needles
+}
+
+func (entry *IndexEntry) match(query *Query, needles [][]byte) bool {
+	if len(query.Cu) != 0 && !query.cuid[entry.Cu] {
+		return false
+	}
+	if len(query.Mu) != 0 && !query.muid[entry.Mu] {
+		return false
+	}
+	if query.CtBefore != "" && strings.Compare(query.CtBefore, entry.Ct) != 1 {
+		return false
+	}
+	if query.CtAfter != "" && strings.Compare(query.CtAfter, entry.Ct) != -1 {
+		return false
+	}
+	if query.MtBefore != "" && strings.Compare(query.MtBefore, entry.Mt) != 1 {
+		return false
+	}
+	if query.MtAfter != "" && strings.Compare(query.MtAfter, entry.Mt) != -1 {
+		return false
+	}
+	if len(query.Natures) != 0 || query.Mumps {
+		natures := make(map[string]bool)
+		for _, nature := range entry.Natures {
+			natures[nature] = true
+		}
+		if query.Mumps && !natures["mumps"] {
+			return false
+		}
+		if len(query.Natures) != 0 {
+			ok := false
+			for nature := range natures {
+				if query.natures[nature] {
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				return false
+			}
+		}
+	}
+	if len(needles) == 0 || entry.Trigrams == "" {
+		return true
+	}
+	filter, err := base64.StdEncoding.DecodeString(entry.Trigrams)
+	if err != nil || len(filter) == 0 {
+		return true
+	}
+	for _, needle := range needles {
+		if !trigramTest(filter, needle) {
+			return false
+		}
+	}
+	return true
+}
+
+func loadIndex(release *qserver.Release) (entries map[string]*IndexEntry) {
+	fs := release.FS("/index")
+	names := fs.Dir("/", true, false)
+	fn := func(n int) (interface{}, error) {
+		shard := make(indexShard)
+		blob, err := fs.ReadFile(names[n])
+		if err != nil {
+			return shard, err
+		}
+		err = json.Unmarshal(blob, &shard)
+		return shard, err
+	}
+	resultlist, _ := qparallel.NMap(len(names), -1, fn)
+	entries = make(map[string]*IndexEntry)
+	for _, res := range resultlist {
+		for qpath, entry := range res.(indexShard) {
+			entries[qpath] = entry
+		}
+	}
+	return entries
+}
+
+func updateShard(r string, qpath string, change func(shard indexShard) bool) error {
+	release, err := qserver.Release{}.New(r, false)
+	if err != nil {
+		return err
+	}
+	r = release.String()
+	name := shardName(qpath)
+	lock, _ := indexLocks.LoadOrStore(r+" "+name, new(sync.Mutex))
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	fs := release.FS("/index")
+	shard := make(indexShard)
+	blob, e := fs.ReadFile("/" + name)
+	if e == nil {
+		json.Unmarshal(blob, &shard)
+	}
+	if !change(shard) {
+		return nil
+	}
+	_, _, _, e = fs.Store("/"+name, shard, "")
+	if e != nil {
+		err = &qerror.QError{
+			Ref:     []string{"source.index.store"},
+			Version: r,
+			QPath:   qpath,
+			Msg:     []string{"Cannot store index: " + e.Error()},
+		}
+		return err
+	}
+	return nil
+}
+
+func shardName(qpath string) string {
+	return qutil.Digest([]byte(qpath))[:2] + ".json"
+}
+
+// trigramFilter builds a bloom filter with the trigrams of the lowercased blob
+func trigramFilter(blob []byte) []byte {
+	blob = bytes.ToLower(blob)
+	trigrams := make(map[uint32]bool)
+	for i := 0; i+2 < len(blob); i++ {
+		trigrams[uint32(blob[i])<<16|uint32(blob[i+1])<<8|uint32(blob[i+2])] = true
+	}
+	// about 10 bits per trigram, rounded to a power of 2
+	size := 64
+	for size*8 < 10*len(trigrams) {
+		size *= 2
+	}
+	filter := make([]byte, size)
+	bits := uint64(size * 8)
+	for tri := range trigrams {
+		h1, h2 := trigramHash(tri)
+		filter[(h1%bits)/8] |= 1 << ((h1 % bits) % 8)
+		filter[(h2%bits)/8] |= 1 << ((h2 % bits) % 8)
+	}
+	return filter
+}
+
+func trigramTest(filter []byte, needle []byte) bool {
+	bits := uint64(len(filter) * 8)
+	for i := 0; i+2 < len(needle); i++ {
+		tri := uint32(needle[i])<<16 | uint32(needle[i+1])<<8 | uint32(needle[i+2])
+		h1, h2 := trigramHash(tri)
+		if filter[(h1%bits)/8]&(1<<((h1%bits)%8)) == 0 {
+			return false
+		}
+		if filter[(h2%bits)/8]&(1<<((h2%bits)%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func trigramHash(tri uint32) (uint64, uint64) {
+	x := uint64(tri) + 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x = x ^ (x >> 31)
+	return x & 0xffffffff, x >> 32
+}
@@ -0,0 +1,69 @@
+package source
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIndex01(t *testing.T) {
+	r := "9.99"
+	makeqRelease(r)
+
+	check, err := CheckIndex(r)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	// project configurations are created without Source.Store
+	for _, qpath := range check.Missing {
+		if !strings.HasSuffix(qpath, "/brocade.json") {
+			t.Errorf("Index should contain `%s`", qpath)
+			return
+		}
+	}
+	if len(check.Stale) != 0 || len(check.Orphaned) != 0 {
+		t.Errorf("Index should be consistent: %v", check)
+		return
+	}
+
+	query := &Query{
+		Release:  r,
+		Contains: []string{"f2.bin"},
+	}
+	sources := query.Run()
+	if len(sources) != 3 {
+		t.Errorf("Should find 3 sources: %d", len(sources))
+		return
+	}
+
+	query = &Query{
+		Release:  r,
+		Contains: []string{"not in any source"},
+	}
+	release, _ := Source{}.New(r, "/a/b/f1.txt", true)
+	query.Harmonise()
+	qpaths := query.narrow(release.Release(), []string{"/a/b/f1.txt", "/a1/b1/f2.bin"})
+	if len(qpaths) != 0 {
+		t.Errorf("Index should exclude all sources: %v", qpaths)
+		return
+	}
+
+	err = RebuildIndex(r)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	check, _ = CheckIndex(r)
+	if check.Entries != check.Sources {
+		t.Errorf("Index should contain all sources: %v", check)
+	}
+}
+
+func TestTrigram01(t *testing.T) {
+	filter := trigramFilter([]byte("Hello World\nBye Moon"))
+	for _, needle := range []string{"hello", "world\nbye", "moon"} {
+		if !trigramTest(filter, []byte(needle)) {
+			t.Errorf("Should contain `%s`", needle)
+		}
+	}
+}
@@ -30,12 +30,12 @@ import (
 // - a file of type 'install.py' or 'release.py ' causes the project to be installed.
 
 func Install(batchid string, sources []*Source, warnings bool, logme *log.Logger, badsources map[string]bool) (err error) {
-	if len(sources) == 0 {
-		return nil
-	}
-	qtechType := qregistry.Registry["qtechng-type"]
-	sr := sources[0].Release().String()
+	return install(batchid, sources, nil, warnings, logme, badsources)
+}
 
+// installRelease is the version which is installed on this machine
+func installRelease() string {
+	qtechType := qregistry.Registry["qtechng-type"]
 	r := ""
 	if strings.ContainsRune(qtechType, 'B') {
 		r = "0.00"
@@ -43,7 +43,17 @@ func Install(batchid string, sources []*Source, warnings bool, logme *log.Logger
 	if strings.ContainsRune(qtechType, 'P') {
 		r = qserver.Canon(qregistry.Registry["brocade-release"])
 	}
+	return r
+}
 
+// install installs the sources.
+// If inprojs is not nil, only the install.py of these projects are executed.
+func install(batchid string, sources []*Source, inprojs map[string]bool, warnings bool, logme *log.Logger, badsources map[string]bool) (err error) {
+	if len(sources) == 0 {
+		return nil
+	}
+	sr := sources[0].Release().String()
+	r := installRelease()
 	if r != sr {
 		return nil
 	}
@@ -146,7 +156,16 @@ func Install(batchid string, sources []*Source, warnings bool, logme *log.Logger
 	}
 
 	// install projects
-	zfiles, count, e := installInstallfiles(batchid, projs, qsources, msources, logme)
+	inprojects := projs
+	if inprojs != nil {
+		inprojects = make([]*qproject.Project, 0)
+		for _, proj := range projs {
+			if inprojs[proj.String()] {
+				inprojects = append(inprojects, proj)
+			}
+		}
+	}
+	zfiles, count, e := installInstallfiles(batchid, inprojects, qsources, msources, logme)
 	if len(e) != 0 {
 		errs = append(errs, e...)
 	}
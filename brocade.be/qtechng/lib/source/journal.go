@@ -0,0 +1,405 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qobject "brocade.be/qtechng/lib/object"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// Journal holds the state of a list of sources before a batch changes them.
+// As long as the journal exists on disk, the batch is not committed:
+// rolling back the journal restores the release to its state before the batch.
+type Journal struct {
+	ID      string          `json:"id"`
+	Release string          `json:"version"`
+	Batch   string          `json:"batchid"`
+	Host    string          `json:"host"`
+	PID     int             `json:"pid"`
+	Time    string          `json:"time"`
+	Entries []*JournalEntry `json:"entries"`
+}
+
+// JournalEntry is the state of a qpath before the batch
+type JournalEntry struct {
+	QPath   string     `json:"qpath"`
+	Exists  bool       `json:"exists"`
+	Content []byte     `json:"content"`
+	Meta    qmeta.Meta `json:"meta"`
+}
+
+// NewJournal registers the state of the qpaths in a release.
+// The journal is saved to disk before it is returned: if a running batch
+// holds one of the qpaths, NewJournal waits until that batch is finished.
+// The state is taken after the wait: it includes the changes of that batch.
+func NewJournal(r string, batchid string, qpaths []string) (journal *Journal, err error) {
+	release, err := qserver.Release{}.New(r, false)
+	if err != nil {
+		return nil, err
+	}
+	r = release.String()
+	host, _ := os.Hostname()
+	h := time.Now()
+	journal = &Journal{
+		ID:      strconv.FormatInt(h.UnixNano(), 10) + "-" + strconv.Itoa(os.Getpid()),
+		Release: r,
+		Batch:   batchid,
+		Host:    host,
+		PID:     os.Getpid(),
+		Time:    h.Format(time.RFC3339),
+		Entries: make([]*JournalEntry, 0, len(qpaths)),
+	}
+	for _, qpath := range qpaths {
+		journal.Entries = append(journal.Entries, &JournalEntry{QPath: qutil.Canon(qpath)})
+	}
+	err = journal.register()
+	if err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// journalWait is the time a batch waits for running batches on the same qpaths
+var journalWait = 10 * time.Minute
+
+// register saves the journal as soon as no running batch holds one of its qpaths.
+// Journals left behind by crashed processes are rolled back first.
+func (journal *Journal) register() error {
+	deadline := time.Now().Add(journalWait)
+	for {
+		busy, err := journal.claim()
+		if err != nil || busy == nil {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return &qerror.QError{
+				Ref:     []string{"source.journal.busy"},
+				Version: journal.Release,
+				Msg:     []string{"Batch `" + busy.Batch + "` (pid " + strconv.Itoa(busy.PID) + " on " + busy.Host + ") is changing the same files"},
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// claim saves the journal if its qpaths are free: otherwise, the busy journal is returned
+func (journal *Journal) claim() (busy *Journal, err error) {
+	unlock, err := lockJournals(journal.Release)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, err = RecoverJournals(journal.Release)
+	if err != nil {
+		return nil, err
+	}
+	journals, err := Journals(journal.Release)
+	if err != nil {
+		return nil, err
+	}
+	mine := make(map[string]bool)
+	for _, qpath := range journal.QPaths() {
+		mine[qpath] = true
+	}
+	for _, other := range journals {
+		for _, qpath := range other.QPaths() {
+			if mine[qpath] {
+				return other, nil
+			}
+		}
+	}
+	err = journal.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return nil, journal.save()
+}
+
+// snapshot registers the content and the meta of the qpaths in the journal
+func (journal *Journal) snapshot() error {
+	release, err := qserver.Release{}.New(journal.Release, false)
+	if err != nil {
+		return err
+	}
+	fs := release.FS()
+	for _, entry := range journal.Entries {
+		content, e := fs.ReadFile(entry.QPath)
+		entry.Exists = e == nil
+		entry.Content = content
+		entry.Meta = qmeta.Meta{}
+		if !entry.Exists {
+			continue
+		}
+		pmeta, _ := qmeta.Meta{}.New(journal.Release, entry.QPath)
+		if pmeta != nil {
+			entry.Meta = *pmeta
+		}
+	}
+	return nil
+}
+
+// lockJournals locks the journals of a release against other processes.
+// A lock older than a minute is left behind by a crashed process.
+func lockJournals(r string) (unlock func(), err error) {
+	release, err := qserver.Release{}.New(r, false)
+	if err != nil {
+		return nil, err
+	}
+	lock, err := release.FS("/journal").RealPath("/lock")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(lock), 0o770)
+	}
+	deadline := time.Now().Add(time.Minute)
+	for err == nil {
+		e := os.Mkdir(lock, 0o770)
+		if e == nil {
+			return func() { os.Remove(lock) }, nil
+		}
+		fi, es := os.Stat(lock)
+		switch {
+		case es == nil && time.Since(fi.ModTime()) > time.Minute:
+			os.Remove(lock)
+		case !os.IsExist(e) || time.Now().After(deadline):
+			err = e
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return nil, &qerror.QError{
+		Ref:     []string{"source.journal.lock"},
+		Version: r,
+		Msg:     []string{"Cannot lock journals: " + err.Error()},
+	}
+}
+
+// Journals lists the journals of a release which are not committed or rolled back
+func Journals(r string) (journals []*Journal, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	fs := release.FS("/journal")
+	journals = make([]*Journal, 0)
+	for _, name := range fs.Dir("/", true, false) {
+		if !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		journal := new(Journal)
+		blob, e := fs.ReadFile("/" + name)
+		if e == nil {
+			e = json.Unmarshal(blob, journal)
+		}
+		if e != nil || journal.ID == "" {
+			err = &qerror.QError{
+				Ref:     []string{"source.journals.load"},
+				Version: release.String(),
+				Msg:     []string{"Cannot load journal `" + name + "`"},
+			}
+			return nil, err
+		}
+		journals = append(journals, journal)
+	}
+	sort.Slice(journals, func(i, j int) bool { return journals[i].ID < journals[j].ID })
+	return journals, nil
+}
+
+// RecoverJournals rolls back the journals of a release which were left behind by a crashed process.
+// Journals of running processes are not touched.
+func RecoverJournals(r string) (recovered []string, err error) {
+	journals, err := Journals(r)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	errslice := qerror.NewErrorSlice()
+	for _, journal := range journals {
+		if journal.Host == host && (journal.PID == os.Getpid() || processAlive(journal.PID)) {
+			continue
+		}
+		e := journal.Rollback()
+		if e != nil {
+			errslice = append(errslice, e)
+			continue
+		}
+		recovered = append(recovered, journal.ID)
+	}
+	if len(errslice) != 0 {
+		return recovered, errslice
+	}
+	return recovered, nil
+}
+
+// QPaths of the journal
+func (journal *Journal) QPaths() []string {
+	qpaths := make([]string, len(journal.Entries))
+	for i, entry := range journal.Entries {
+		qpaths[i] = entry.QPath
+	}
+	return qpaths
+}
+
+// Commit accepts all changes: the journal is removed
+func (journal *Journal) Commit() error {
+	return journal.remove()
+}
+
+// Rollback restores all sources in the journal to their previous state.
+// Sources are restored before object files, configuration files come last.
+// On success, the journal is removed.
+func (journal *Journal) Rollback() (err error) {
+	release, err := qserver.Release{}.New(journal.Release, false)
+	if err != nil {
+		return err
+	}
+	r := release.String()
+	order := func(entry *JournalEntry) int {
+		switch {
+		case strings.HasSuffix(entry.QPath, "/brocade.json"):
+			return 2
+		}
+		switch path.Ext(entry.QPath) {
+		case ".d", ".i", ".l":
+			return 1
+		}
+		return 0
+	}
+	entries := make([]*JournalEntry, len(journal.Entries))
+	copy(entries, journal.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		oi, oj := order(entries[i]), order(entries[j])
+		if oi != oj {
+			return oi < oj
+		}
+		return entries[i].QPath > entries[j].QPath
+	})
+
+	fs := release.FS()
+	errslice := qerror.NewErrorSlice()
+	for _, entry := range entries {
+		current, e := fs.ReadFile(entry.QPath)
+		exists := e == nil
+		if !exists && !entry.Exists {
+			continue
+		}
+		if !entry.Exists {
+			source, e := Source{}.New(r, entry.QPath, false)
+			if e == nil {
+				e = source.Waste()
+			}
+			if e != nil {
+				errslice = append(errslice, e)
+			}
+			continue
+		}
+		if exists && bytes.Equal(current, entry.Content) {
+			pmeta, _ := qmeta.Meta{}.New(r, entry.QPath)
+			if pmeta != nil && *pmeta == entry.Meta {
+				continue
+			}
+		}
+		source, e := Source{}.New(r, entry.QPath, false)
+		if e != nil {
+			errslice = append(errslice, e)
+			continue
+		}
+		var added []qobject.Object
+		if exists && source.Natures()["objectfile"] {
+			added = source.addedObjects(current, entry.Content)
+		}
+		_, _, _, e = source.Store(qmeta.Meta{Digest: qutil.Digest(current)}, entry.Content, false)
+		if e != nil {
+			errslice = append(errslice, e)
+			continue
+		}
+		meta := entry.Meta
+		_, e = meta.Store(r, entry.QPath)
+		if e != nil {
+			errslice = append(errslice, e)
+		}
+		// the index has the meta of the batch
+		source.UpdateIndex()
+		if len(added) != 0 {
+			_, errorlist := WasteObjList(added)
+			for _, e := range errorlist {
+				if e != nil {
+					errslice = append(errslice, e)
+				}
+			}
+		}
+	}
+	if len(errslice) != 0 {
+		err = &qerror.QError{
+			Ref:     []string{"source.journal.rollback"},
+			Version: r,
+			Msg:     []string{"Cannot roll back journal `" + journal.ID + "` of batch `" + journal.Batch + "`"},
+		}
+		return append(qerror.ErrorSlice{err}, errslice...)
+	}
+	return journal.remove()
+}
+
+// addedObjects lists the objects defined in current which are not in previous
+func (source *Source) addedObjects(current []byte, previous []byte) (added []qobject.Object) {
+	objfile, _ := source.objectFile(current)
+	if objfile == nil {
+		return nil
+	}
+	before := make(map[string]bool)
+	pobjfile, _ := source.objectFile(previous)
+	if pobjfile != nil {
+		for _, obj := range pobjfile.Objects() {
+			before[obj.String()] = true
+		}
+	}
+	for _, obj := range objfile.Objects() {
+		if !before[obj.String()] {
+			added = append(added, obj)
+		}
+	}
+	return added
+}
+
+func (journal *Journal) save() error {
+	release, err := qserver.Release{}.New(journal.Release, false)
+	if err != nil {
+		return err
+	}
+	blob, _ := json.Marshal(journal)
+	_, _, _, err = release.FS("/journal").Store("/"+journal.ID+".json", blob, "")
+	if err != nil {
+		return &qerror.QError{
+			Ref:     []string{"source.journal.save"},
+			Version: journal.Release,
+			Msg:     []string{"Cannot save journal: " + err.Error()},
+		}
+	}
+	return nil
+}
+
+func (journal *Journal) remove() error {
+	release, err := qserver.Release{}.New(journal.Release, false)
+	if err != nil {
+		return err
+	}
+	_, err = release.FS("/journal").Waste("/" + journal.ID + ".json")
+	if err != nil {
+		return &qerror.QError{
+			Ref:     []string{"source.journal.remove"},
+			Version: journal.Release,
+			Msg:     []string{"Cannot remove journal: " + err.Error()},
+		}
+	}
+	return nil
+}
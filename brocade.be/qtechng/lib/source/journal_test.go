@@ -0,0 +1,220 @@
+package source
+
+import (
+	"testing"
+	"time"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+func TestJournal01(t *testing.T) {
+	r := "9.97"
+	makeqRelease(r)
+
+	f1 := "/a/b/f1.txt"
+	f2 := "/a/b/f2.bin"
+	f4 := "/a/b/f4.txt"
+	before := make(map[string][]byte)
+	for _, p := range []string{f1, f2} {
+		source, _ := Source{}.New(r, p, true)
+		blob, _ := source.Fetch()
+		before[p] = blob
+	}
+	data := map[string]string{
+		f1: "Hello World f1.txt\nGood Night Moon",
+		f2: "Hello World f2.bin\nGood Night Moon",
+		f4: "Hello World f4.txt",
+	}
+	fdata := func(p string) ([]byte, error) { return []byte(data[p]), nil }
+
+	// f2 has a wrong digest: nothing is stored
+	fmeta := func(p string) qmeta.Meta {
+		if p == f1 {
+			return qmeta.Meta{Digest: qutil.Digest(before[f1])}
+		}
+		return qmeta.Meta{Digest: "wrong"}
+	}
+	_, errs := StoreList("install", r, []string{f1, f2, f4}, false, fmeta, fdata, false)
+	if errs == nil {
+		t.Errorf("Batch should fail")
+		return
+	}
+	for _, p := range []string{f1, f2} {
+		source, _ := Source{}.New(r, p, true)
+		blob, _ := source.Fetch()
+		if string(blob) != string(before[p]) {
+			t.Errorf("`%s` should not be changed: `%s`", p, blob)
+		}
+	}
+	release, _ := qserver.Release{}.New(r, true)
+	if ok, _ := release.FS().Exists(f4); ok {
+		t.Errorf("`%s` should not exist", f4)
+	}
+
+	// all digests are correct
+	fmeta = func(p string) qmeta.Meta {
+		return qmeta.Meta{Digest: qutil.Digest(before[p])}
+	}
+	results, errs := StoreList("install", r, []string{f1, f2, f4}, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Errorf(errs.Error())
+		return
+	}
+	if len(results) != 3 {
+		t.Errorf("3 sources should be stored: %v", results)
+	}
+	journals, _ := Journals(r)
+	if len(journals) != 0 {
+		t.Errorf("No journals should be left: %v", journals)
+	}
+}
+
+func TestJournal02(t *testing.T) {
+	r := "9.97"
+	makeqRelease(r)
+
+	f1 := "/a/b/f1.txt"
+	f4 := "/a/b/f4.txt"
+	source, _ := Source{}.New(r, f1, false)
+	blob, _ := source.Fetch()
+	original := string(blob)
+
+	// simulates a crash after storing
+	journal, err := NewJournal(r, "crash", []string{f1, f4})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	_, _, _, err = source.Store(qmeta.Meta{Digest: qutil.Digest(blob)}, "Crashed", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	source4, _ := Source{}.New(r, f4, false)
+	_, _, _, err = source4.Store(qmeta.Meta{}, "Crashed", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	// a running process keeps its journal
+	recovered, err := RecoverJournals(r)
+	if err != nil || len(recovered) != 0 {
+		t.Errorf("Journal of running process should be kept: %v %v", recovered, err)
+		return
+	}
+
+	journal.PID = -1
+	journal.save()
+	recovered, err = RecoverJournals(r)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if len(recovered) != 1 || recovered[0] != journal.ID {
+		t.Errorf("Journal should be recovered: %v", recovered)
+		return
+	}
+	source, _ = Source{}.New(r, f1, true)
+	blob, _ = source.Fetch()
+	if string(blob) != original {
+		t.Errorf("`%s` should be restored: `%s`", f1, blob)
+	}
+	meta, _ := qmeta.Meta{}.New(r, f1)
+	if meta.Mu != "rphilips" {
+		t.Errorf("Meta of `%s` should be restored: %v", f1, meta)
+	}
+	release, _ := qserver.Release{}.New(r, true)
+	if ok, _ := release.FS().Exists(f4); ok {
+		t.Errorf("`%s` should be removed", f4)
+	}
+	entry := loadIndex(release)[f1]
+	if entry == nil || entry.Mu != "rphilips" || entry.Digest != qutil.Digest([]byte(original)) {
+		t.Errorf("Index of `%s` should be restored: %v", f1, entry)
+	}
+}
+
+func TestJournal03(t *testing.T) {
+	r := "9.97"
+	makeqRelease(r)
+	wait := journalWait
+	journalWait = 200 * time.Millisecond
+	defer func() { journalWait = wait }()
+
+	running, err := NewJournal(r, "running", []string{"/a/b/f1.txt", "/a/b/f2.bin"})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	_, err = NewJournal(r, "overlap", []string{"/a/b/f4.txt", "/a/b/f2.bin"})
+	if err == nil {
+		t.Errorf("Batch on the same files should wait for the running batch")
+	}
+	other, err := NewJournal(r, "other", []string{"/a/b/f4.txt"})
+	if err != nil {
+		t.Errorf("Batch on other files should not wait: %s", err)
+		return
+	}
+	other.Commit()
+
+	done := make(chan error)
+	go func() {
+		journalWait = 10 * time.Second
+		journal, err := NewJournal(r, "after", []string{"/a/b/f2.bin"})
+		if err == nil {
+			err = journal.Commit()
+		}
+		done <- err
+	}()
+	time.Sleep(300 * time.Millisecond)
+	running.Commit()
+	if err := <-done; err != nil {
+		t.Errorf("Batch should run after the running batch: %s", err)
+	}
+	journals, _ := Journals(r)
+	if len(journals) != 0 {
+		t.Errorf("No journals should be left: %v", journals)
+	}
+}
+
+func TestJournal04(t *testing.T) {
+	r := "9.97"
+	makeqRelease(r)
+
+	f1 := "/a/b/f1.txt"
+	running, err := NewJournal(r, "running", []string{f1})
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+
+	// the failing batch waits for the running batch
+	done := make(chan error)
+	go func() {
+		journal, err := NewJournal(r, "failing", []string{f1, "/a/b/f4.txt"})
+		if err == nil {
+			err = journal.Rollback()
+		}
+		done <- err
+	}()
+	time.Sleep(300 * time.Millisecond)
+	source, _ := Source{}.New(r, f1, false)
+	blob, _ := source.Fetch()
+	_, _, _, err = source.Store(qmeta.Meta{Digest: qutil.Digest(blob)}, "Committed by running", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	running.Commit()
+	if err := <-done; err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	source, _ = Source{}.New(r, f1, true)
+	blob, _ = source.Fetch()
+	if string(blob) != "Committed by running" {
+		t.Errorf("Rollback should keep the changes of the running batch: `%s`", blob)
+	}
+}
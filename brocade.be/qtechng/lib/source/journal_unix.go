@@ -0,0 +1,22 @@
+//go:build !windows
+// +build !windows
+
+package source
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive tests if a process is still running
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package source
+
+import (
+	"os"
+)
+
+// processAlive tests if a process is still running
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -155,7 +155,7 @@ func LintList(version string, paths []string, warnings bool) (infos []error, met
 	return
 }
 
-// Fetch haalt de data op
+// Lint checks a source with the registered lint rules (see RegisterLintRule)
 func (source *Source) Lint(lintdir string, warnings bool) (info error, err error) {
 	if lintdir == "" {
 		lintdir = qregistry.Registry["scratch-dir"]
@@ -169,97 +169,17 @@ func (source *Source) Lint(lintdir string, warnings bool) (info error, err error
 	if err != nil {
 		return nil, err
 	}
-
-	if natures["text"] {
-		_, _, e := qutil.NoUTF8(bytes.NewReader(body))
-		if e != nil {
-			info = fmt.Errorf("`%s` contains non UTF-8 charcter", source.String())
-			return
-		}
-	}
-
-	buffer := new(bytes.Buffer)
-
-	about := ""
-	switch {
-	case strings.HasSuffix(source.String(), "/brocade.json"):
-		buffer.Write(body)
-		info, err := source.LintBrocadeJson(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case natures["lfile"]:
-		about = qutil.AboutLine(body)
-		err = source.Resolve("r", nil, nil, buffer, true)
-		if err != nil {
-			return nil, err
-		}
-		info, err := source.LintL(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case natures["dfile"]:
-		about = qutil.AboutLine(body)
-		err = source.Resolve("rl", nil, nil, buffer, true)
-		if err != nil {
-			return nil, err
-		}
-		info, err := source.LintD(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case natures["ifile"]:
-		about = qutil.AboutLine(body)
-		err = source.Resolve("rlm", nil, nil, buffer, true)
-		if err != nil {
-			return nil, err
-		}
-		info, err := source.LintI(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case natures["bfile"]:
-		about = qutil.AboutLine(body)
-		err = source.Resolve("rilm", nil, nil, buffer, true)
-		if err != nil {
-			return nil, err
-		}
-		info, err := source.LintB(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	}
-	if natures["mfile"] {
-		about = qutil.AboutLine(body)
-		err = source.MFileToMumps("lint", buffer)
-	}
-	if !natures["mfile"] {
-		err = source.Resolve("rilm", nil, nil, buffer, false)
-	}
+	errs, err := source.lintCheck(body, lintdir, warnings)
 	if err != nil {
 		return nil, err
 	}
-	ext := path.Ext(source.String())
-	switch ext {
-	case ".php", ".phtml":
-		info, err := source.LintPHP(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".rst":
-		info, err := source.LintRST(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".yaml", ".yml":
-		info, err := source.LintYAML(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".json":
-		info, err := source.LintJSON(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".xml":
-		info, err := source.LintXML(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".py":
-		info, err := source.LintPy(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".x":
-		about = qutil.AboutLine(body)
-		info, err := source.LintX(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	case ".m":
-		info, err := source.LintM(buffer, warnings, lintdir, about)
-		return source.LintResult(info), err
-	}
-
-	return nil, nil
-
+	switch len(errs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return errs[0], nil
+	}
+	return qerror.ErrorSlice(errs), nil
 }
 
 // LintResult combines a lintresult
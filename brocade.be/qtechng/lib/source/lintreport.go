@@ -0,0 +1,309 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// LintFix is a replacement on a line which fixes a finding
+type LintFix struct {
+	Description string
+	StartColumn int // first column to replace (1-based)
+	EndColumn   int // column after the replaced text
+	Text        string
+}
+
+// LintOutcome is the lint result of a file
+type LintOutcome struct {
+	QPath string
+	File  string  // local file, if available
+	Body  []byte  // content of the file, if available: needed for fixes
+	Errs  []error // findings
+}
+
+// LintIssue is a finding in a LintOutcome
+type LintIssue struct {
+	RuleID   string
+	Severity string // "error", "warning" or "info"
+	Lineno   int
+	Msg      string
+}
+
+// ParseLintInfo converts the info of a lint transport to errors.
+// The info is "OK", the JSON representation of one or more errors or a message.
+func ParseLintInfo(info string) []error {
+	info = strings.TrimSpace(info)
+	switch strings.ToUpper(info) {
+	case "", "OK", "NOLINT":
+		return nil
+	}
+	maps := make([]map[string]interface{}, 0)
+	if json.Unmarshal([]byte(info), &maps) != nil {
+		m := make(map[string]interface{})
+		if json.Unmarshal([]byte(info), &m) != nil {
+			return []error{errors.New(info)}
+		}
+		maps = append(maps, m)
+	}
+	errs := make([]error, 0, len(maps))
+	for _, m := range maps {
+		qerr := &qerror.QError{}
+		if ref, ok := m["ref"].(string); ok {
+			qerr.Ref = strings.Split(ref, " ; ")
+		}
+		qerr.Version, _ = m["version"].(string)
+		qerr.QPath, _ = m["qpath"].(string)
+		qerr.File, _ = m["file"].(string)
+		switch lineno := m["lineno"].(type) {
+		case string:
+			qerr.Lineno, _ = strconv.Atoi(lineno)
+		case float64:
+			qerr.Lineno = int(lineno)
+		}
+		switch msg := m["message"].(type) {
+		case string:
+			qerr.Msg = []string{msg}
+		case []interface{}:
+			for _, x := range msg {
+				s, ok := x.(string)
+				if !ok {
+					b, _ := json.Marshal(x)
+					s = string(b)
+				}
+				qerr.Msg = append(qerr.Msg, s)
+			}
+		}
+		errs = append(errs, qerr)
+	}
+	return errs
+}
+
+// Issues lists the findings of an outcome.
+// Findings of registered lint rules get the ID and severity of the rule.
+func (outcome LintOutcome) Issues() []LintIssue {
+	issues := make([]LintIssue, 0)
+	for _, err := range qerror.FlattenErrors(qerror.ErrorSlice(outcome.Errs), "") {
+		if err == nil {
+			continue
+		}
+		var qerr *qerror.QError
+		switch v := err.(type) {
+		case *qerror.QError:
+			qerr = v
+		case qerror.QError:
+			qerr = &v
+		case qerror.StringError:
+			errs := ParseLintInfo(string(v.Msg))
+			issues = append(issues, LintOutcome{Errs: errs}.Issues()...)
+			continue
+		}
+		if qerr == nil {
+			issues = append(issues, LintIssue{RuleID: "lint", Severity: "error", Msg: err.Error()})
+			continue
+		}
+		issue := LintIssue{
+			RuleID:   "lint",
+			Severity: "error",
+			Lineno:   qerr.Lineno,
+			Msg:      strings.Join(qerr.Msg, "\n"),
+		}
+		if len(qerr.Ref) > 1 && qerr.Ref[0] == "lint.message" {
+			issue.RuleID = qerr.Ref[1]
+		}
+		if rule := GetLintRule(issue.RuleID); rule != nil {
+			issue.Severity = rule.Severity
+		} else if strings.EqualFold(qerr.Type, "warning") || strings.EqualFold(qerr.Type, "info") {
+			issue.Severity = strings.ToLower(qerr.Type)
+		}
+		if issue.Lineno < 0 {
+			issue.Lineno = 0
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// uri is the location of the file in a report
+func (outcome LintOutcome) uri() string {
+	if outcome.File != "" {
+		return strings.TrimSuffix(qutil.FileURL(outcome.File, "", 0), "#")
+	}
+	return strings.TrimPrefix(outcome.QPath, "/")
+}
+
+// name is the name of the file in a report
+func (outcome LintOutcome) name() string {
+	if outcome.QPath != "" {
+		return outcome.QPath
+	}
+	return outcome.File
+}
+
+// fix computes the fix of an issue with the content of the file
+func (outcome LintOutcome) fix(issue LintIssue) *LintFix {
+	rule := GetLintRule(issue.RuleID)
+	if rule == nil || rule.Fix == nil || issue.Lineno < 1 || outcome.Body == nil {
+		return nil
+	}
+	lines := strings.Split(string(outcome.Body), "\n")
+	if issue.Lineno > len(lines) {
+		return nil
+	}
+	return rule.Fix(strings.TrimSuffix(lines[issue.Lineno-1], "\r"))
+}
+
+// LintSARIF renders lint outcomes as a SARIF 2.1.0 log
+func LintSARIF(outcomes []LintOutcome) []byte {
+	type object = map[string]interface{}
+	level := func(severity string) string {
+		switch severity {
+		case "warning":
+			return "warning"
+		case "info":
+			return "note"
+		}
+		return "error"
+	}
+
+	results := make([]object, 0)
+	used := make(map[string]bool)
+	for _, outcome := range outcomes {
+		uri := outcome.uri()
+		for _, issue := range outcome.Issues() {
+			used[issue.RuleID] = true
+			location := object{
+				"artifactLocation": object{"uri": uri},
+			}
+			if issue.Lineno > 0 {
+				location["region"] = object{"startLine": issue.Lineno}
+			}
+			msg := issue.Msg
+			if msg == "" {
+				msg = issue.RuleID
+			}
+			result := object{
+				"ruleId":    issue.RuleID,
+				"level":     level(issue.Severity),
+				"message":   object{"text": msg},
+				"locations": []object{{"physicalLocation": location}},
+			}
+			if fix := outcome.fix(issue); fix != nil {
+				result["fixes"] = []object{{
+					"description": object{"text": fix.Description},
+					"artifactChanges": []object{{
+						"artifactLocation": object{"uri": uri},
+						"replacements": []object{{
+							"deletedRegion": object{
+								"startLine":   issue.Lineno,
+								"startColumn": fix.StartColumn,
+								"endColumn":   fix.EndColumn,
+							},
+							"insertedContent": object{"text": fix.Text},
+						}},
+					}},
+				}}
+			}
+			results = append(results, result)
+		}
+	}
+
+	ids := make([]string, 0, len(used))
+	for id := range used {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	rules := make([]object, 0, len(ids))
+	for _, id := range ids {
+		rule := object{"id": id}
+		if r := GetLintRule(id); r != nil {
+			rule["shortDescription"] = object{"text": r.Message}
+			rule["defaultConfiguration"] = object{"level": level(r.Severity)}
+		}
+		rules = append(rules, rule)
+	}
+
+	log := object{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []object{{
+			"tool": object{
+				"driver": object{
+					"name":           "qtechng",
+					"informationUri": "https://dev.anet.be/brocade",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	}
+	blob, _ := json.MarshalIndent(log, "", "    ")
+	return blob
+}
+
+// LintJUnit renders lint outcomes as JUnit XML.
+// Every file is a test case which fails if there are findings with severity "error".
+// Other findings are reported in the output of the test case.
+func LintJUnit(outcomes []LintOutcome) []byte {
+	escape := func(s string) string {
+		buf := new(bytes.Buffer)
+		xml.EscapeText(buf, []byte(s))
+		return buf.String()
+	}
+	format := func(issue LintIssue) string {
+		s := issue.RuleID
+		if issue.Lineno > 0 {
+			s += " (line " + strconv.Itoa(issue.Lineno) + ")"
+		}
+		return s + ": " + issue.Msg
+	}
+
+	cases := new(bytes.Buffer)
+	failures := 0
+	for _, outcome := range outcomes {
+		name := outcome.name()
+		classname := "qtechng.lint"
+		if i := strings.LastIndex(name, "/"); i > 0 {
+			classname = strings.ReplaceAll(strings.Trim(name[:i], "/"), "/", ".")
+		}
+		errs := make([]string, 0)
+		others := make([]string, 0)
+		for _, issue := range outcome.Issues() {
+			if issue.Severity == "error" {
+				errs = append(errs, format(issue))
+			} else {
+				others = append(others, issue.Severity+" "+format(issue))
+			}
+		}
+		fmt.Fprintf(cases, "    <testcase classname=\"%s\" name=\"%s\">\n", escape(classname), escape(name))
+		if len(errs) != 0 {
+			failures++
+			message := errs[0]
+			if i := strings.IndexByte(message, '\n'); i > 0 {
+				message = message[:i]
+			}
+			fmt.Fprintf(cases, "      <failure message=\"%s\" type=\"lint\">%s</failure>\n", escape(message), escape(strings.Join(errs, "\n")))
+		}
+		if len(others) != 0 {
+			fmt.Fprintf(cases, "      <system-out>%s</system-out>\n", escape(strings.Join(others, "\n")))
+		}
+		fmt.Fprintln(cases, "    </testcase>")
+	}
+
+	buffer := new(bytes.Buffer)
+	fmt.Fprintln(buffer, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintf(buffer, "<testsuites name=\"qtechng lint\" tests=\"%d\" failures=\"%d\">\n", len(outcomes), failures)
+	fmt.Fprintf(buffer, "  <testsuite name=\"qtechng lint\" tests=\"%d\" failures=\"%d\" errors=\"0\">\n", len(outcomes), failures)
+	buffer.Write(cases.Bytes())
+	fmt.Fprintln(buffer, "  </testsuite>")
+	fmt.Fprintln(buffer, "</testsuites>")
+	return buffer.Bytes()
+}
@@ -0,0 +1,62 @@
+package source
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	qerror "brocade.be/qtechng/lib/error"
+)
+
+func TestLintReport01(t *testing.T) {
+	info := qerror.ErrorSlice{
+		&qerror.QError{Ref: []string{"lint.message", "M011"}, QPath: "/a/b/f.m", Lineno: 2, Msg: []string{"Line has trailing whitespace"}},
+		&qerror.QError{Ref: []string{"lint.message", "M001"}, QPath: "/a/b/f.m", Msg: []string{"Does not compile"}},
+	}.Error()
+	errs := ParseLintInfo(info)
+	if len(errs) != 2 {
+		t.Errorf("Should be 2 errors: %v", errs)
+		return
+	}
+	outcomes := []LintOutcome{
+		{QPath: "/a/b/f.m", Body: []byte("f ; About: f\n\tq  \n"), Errs: errs},
+		{QPath: "/a/b/g.m", Errs: ParseLintInfo("OK")},
+	}
+	issues := outcomes[0].Issues()
+	if len(issues) != 2 || issues[0].RuleID != "M011" || issues[0].Severity != "warning" || issues[0].Lineno != 2 {
+		t.Errorf("Wrong issues: %v", issues)
+	}
+
+	sarif := make(map[string]interface{})
+	err := json.Unmarshal(LintSARIF(outcomes), &sarif)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	run := sarif["runs"].([]interface{})[0].(map[string]interface{})
+	results := run["results"].([]interface{})
+	if sarif["version"] != "2.1.0" || len(results) != 2 {
+		t.Errorf("Wrong SARIF: %v", sarif)
+		return
+	}
+	result := results[0].(map[string]interface{})
+	if result["ruleId"] != "M011" || result["level"] != "warning" || result["fixes"] == nil {
+		t.Errorf("Wrong SARIF result: %v", result)
+	}
+	blob, _ := json.Marshal(result["fixes"])
+	if !strings.Contains(string(blob), `"startColumn":3`) || !strings.Contains(string(blob), `"endColumn":5`) {
+		t.Errorf("Wrong fix: %s", blob)
+	}
+	rules := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})["rules"].([]interface{})
+	if len(rules) != 2 {
+		t.Errorf("Wrong rules: %v", rules)
+	}
+
+	junit := string(LintJUnit(outcomes))
+	if !strings.Contains(junit, `<testsuite name="qtechng lint" tests="2" failures="1" errors="0">`) {
+		t.Errorf("Wrong JUnit: %s", junit)
+	}
+	if !strings.Contains(junit, `<testcase classname="a.b" name="/a/b/f.m">`) || !strings.Contains(junit, "warning M011 (line 2)") {
+		t.Errorf("Wrong JUnit: %s", junit)
+	}
+}
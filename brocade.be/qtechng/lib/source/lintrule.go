@@ -0,0 +1,218 @@
+package source
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	qfnmatch "brocade.be/base/fnmatch"
+	qerror "brocade.be/qtechng/lib/error"
+)
+
+// LintRule is a check on sources.
+// Rules are registered with RegisterLintRule, typically in an init function.
+type LintRule struct {
+	ID       string // stable identification, e.g. "M012"
+	Severity string // "error", "warning" or "info"
+	Message  string // description of the rule
+	Disabled bool   // the rule only applies to projects which enable it in brocade.json
+	Stop     bool   // if the rule fails, no further rules are checked
+	Applies  func(source *Source) bool
+	Check    func(ctx *LintContext) (findings []LintFinding, err error)
+	Fix      func(line string) *LintFix // optional: fixes a finding on a line
+}
+
+// LintContext is the input of a lint rule
+type LintContext struct {
+	Source   *Source
+	Body     []byte // content of the source
+	Lintdir  string // directory for temporary files
+	Warnings bool   // external linters should report warnings
+}
+
+// LintFinding is a violation of a lint rule.
+// A finding without line number applies to the whole source.
+type LintFinding struct {
+	Lineno int
+	Msg    string
+}
+
+var lintRules = make(map[string]*LintRule)
+var lintOrder = make([]string, 0)
+var lintMutex = new(sync.RWMutex)
+
+// RegisterLintRule adds a rule to the registry.
+// The rules are applied in the order of registration.
+func RegisterLintRule(rule *LintRule) error {
+	if rule.ID == "" || rule.Check == nil {
+		return &qerror.QError{
+			Ref: []string{"source.lintrule.invalid"},
+			Msg: []string{"Lint rule needs an ID and a Check function"},
+		}
+	}
+	switch rule.Severity {
+	case "error", "warning", "info":
+	case "":
+		rule.Severity = "error"
+	default:
+		return &qerror.QError{
+			Ref: []string{"source.lintrule.severity"},
+			Msg: []string{"Lint rule `" + rule.ID + "` has an invalid severity `" + rule.Severity + "`"},
+		}
+	}
+	lintMutex.Lock()
+	defer lintMutex.Unlock()
+	if lintRules[rule.ID] != nil {
+		return &qerror.QError{
+			Ref: []string{"source.lintrule.double"},
+			Msg: []string{"Lint rule `" + rule.ID + "` is already registered"},
+		}
+	}
+	lintRules[rule.ID] = rule
+	lintOrder = append(lintOrder, rule.ID)
+	return nil
+}
+
+// GetLintRule returns the rule with a given ID
+func GetLintRule(id string) *LintRule {
+	lintMutex.RLock()
+	defer lintMutex.RUnlock()
+	return lintRules[id]
+}
+
+// LintRules returns all registered rules, sorted on ID
+func LintRules() []*LintRule {
+	rules := registeredLintRules()
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// LintRules returns the rules which apply to the source, in order of registration.
+// The project configuration can enable (`lintenable`) or disable (`lintdisable`) rules.
+func (source *Source) LintRules() []*LintRule {
+	config, _ := source.Project().LoadConfig()
+	match := func(patterns []string, id string) bool {
+		for _, pattern := range patterns {
+			if qfnmatch.Match(pattern, id) {
+				return true
+			}
+		}
+		return false
+	}
+	rules := make([]*LintRule, 0)
+	for _, rule := range registeredLintRules() {
+		id := rule.ID
+		if rule.Disabled && !match(config.LintEnable, id) {
+			continue
+		}
+		if match(config.LintDisable, id) {
+			continue
+		}
+		if rule.Applies != nil && !rule.Applies(source) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// registeredLintRules returns the rules in order of registration
+func registeredLintRules() []*LintRule {
+	lintMutex.RLock()
+	defer lintMutex.RUnlock()
+	rules := make([]*LintRule, len(lintOrder))
+	for i, id := range lintOrder {
+		rules[i] = lintRules[id]
+	}
+	return rules
+}
+
+var nolintRegexp = regexp.MustCompile(`qtech:nolint(-file)?((?:[ \t]*,?[ \t]*[A-Z]+[0-9]+\b)*)`)
+
+// lintSuppressions finds the `qtech:nolint` comments in a source.
+// `qtech:nolint M012,M011` suppresses findings on the same line,
+// `qtech:nolint-file M012` suppresses findings in the whole source.
+// Without IDs, all rules are suppressed.
+func lintSuppressions(body []byte) (lines map[int][]string, file []string) {
+	if !bytes.Contains(body, []byte("qtech:nolint")) {
+		return nil, nil
+	}
+	lines = make(map[int][]string)
+	for i, line := range strings.Split(string(body), "\n") {
+		for _, match := range nolintRegexp.FindAllStringSubmatch(line, -1) {
+			ids := strings.FieldsFunc(match[2], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+			if len(ids) == 0 {
+				ids = []string{"*"}
+			}
+			if match[1] != "" {
+				file = append(file, ids...)
+				continue
+			}
+			lines[i+1] = append(lines[i+1], ids...)
+		}
+	}
+	return lines, file
+}
+
+func lintSuppressed(id string, lineno int, lines map[int][]string, file []string) bool {
+	for _, sid := range file {
+		if sid == "*" || sid == id {
+			return true
+		}
+	}
+	if lineno == 0 {
+		return false
+	}
+	for _, sid := range lines[lineno] {
+		if sid == "*" || sid == id {
+			return true
+		}
+	}
+	return false
+}
+
+// lintCheck applies the rules to a source.
+// Without warnings, only rules with severity "error" are applied.
+func (source *Source) lintCheck(body []byte, lintdir string, warnings bool) (errs []error, err error) {
+	lines, file := lintSuppressions(body)
+	ctx := &LintContext{
+		Source:   source,
+		Body:     body,
+		Lintdir:  lintdir,
+		Warnings: warnings,
+	}
+	for _, rule := range source.LintRules() {
+		if !warnings && rule.Severity != "error" {
+			continue
+		}
+		findings, err := rule.Check(ctx)
+		if err != nil {
+			return nil, err
+		}
+		failed := false
+		for _, finding := range findings {
+			if lintSuppressed(rule.ID, finding.Lineno, lines, file) {
+				continue
+			}
+			failed = true
+			msg := finding.Msg
+			if msg == "" {
+				msg = rule.Message
+			}
+			errs = append(errs, &qerror.QError{
+				Ref:     []string{"lint.message", rule.ID},
+				Type:    strings.ToUpper(rule.Severity),
+				Version: source.Release().String(),
+				QPath:   source.String(),
+				Lineno:  finding.Lineno,
+				Msg:     []string{msg},
+			})
+		}
+		if failed && rule.Stop {
+			break
+		}
+	}
+	return errs, nil
+}
@@ -0,0 +1,209 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// lintFunc is a linter which reports "OK" or a message
+type lintFunc func(source *Source, buffer *bytes.Buffer, warnings bool, lintdir string, about string) (info string, err error)
+
+// builtinLintRules are the checks on the structure of the sources.
+// Per source, at most one of these rules (besides UTF001) applies.
+var builtinLintRules = []*LintRule{
+	{
+		ID:       "UTF001",
+		Severity: "error",
+		Message:  "Text contains non UTF-8 characters",
+		Stop:     true,
+		Applies:  func(source *Source) bool { return source.Natures()["text"] },
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			_, _, e := qutil.NoUTF8(bytes.NewReader(ctx.Body))
+			if e != nil {
+				return []LintFinding{{Msg: fmt.Sprintf("`%s` contains non UTF-8 charcter", ctx.Source.String())}}, nil
+			}
+			return nil, nil
+		},
+	},
+	{
+		ID:       "CFG001",
+		Severity: "error",
+		Message:  "Project configuration is not valid",
+		Applies:  isBrocadeJSON,
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			return lintInfo(ctx.Source.LintBrocadeJson(bytes.NewBuffer(ctx.Body), ctx.Warnings, ctx.Lintdir, ""))
+		},
+	},
+	{
+		ID:       "L001",
+		Severity: "error",
+		Message:  "Language file is not valid",
+		Applies:  lintNature("lfile"),
+		Check:    lintResolved("r", (*Source).LintL),
+	},
+	{
+		ID:       "D001",
+		Severity: "error",
+		Message:  "Macro file is not valid",
+		Applies:  lintNature("dfile"),
+		Check:    lintResolved("rl", (*Source).LintD),
+	},
+	{
+		ID:       "I001",
+		Severity: "error",
+		Message:  "Include file is not valid",
+		Applies:  lintNature("ifile"),
+		Check:    lintResolved("rlm", (*Source).LintI),
+	},
+	{
+		ID:       "B001",
+		Severity: "error",
+		Message:  "Brocade file is not valid",
+		Applies:  lintNature("bfile"),
+		Check:    lintResolved("rilm", (*Source).LintB),
+	},
+	{
+		ID:       "PHP001",
+		Severity: "error",
+		Message:  "PHP does not compile",
+		Applies:  lintExt(".php", ".phtml"),
+		Check:    lintOther((*Source).LintPHP),
+	},
+	{
+		ID:       "RST001",
+		Severity: "error",
+		Message:  "reStructuredText is not valid",
+		Applies:  lintExt(".rst"),
+		Check:    lintOther((*Source).LintRST),
+	},
+	{
+		ID:       "YAML001",
+		Severity: "error",
+		Message:  "YAML is not valid",
+		Applies:  lintExt(".yaml", ".yml"),
+		Check:    lintOther((*Source).LintYAML),
+	},
+	{
+		ID:       "JSON001",
+		Severity: "error",
+		Message:  "JSON is not valid",
+		Applies:  lintExt(".json"),
+		Check:    lintOther((*Source).LintJSON),
+	},
+	{
+		ID:       "XML001",
+		Severity: "error",
+		Message:  "XML is not valid",
+		Applies:  lintExt(".xml"),
+		Check:    lintOther((*Source).LintXML),
+	},
+	{
+		ID:       "PY001",
+		Severity: "error",
+		Message:  "Python does not compile",
+		Applies:  lintExt(".py"),
+		Check:    lintOther((*Source).LintPy),
+	},
+	{
+		ID:       "X001",
+		Severity: "error",
+		Message:  "Widget file is not valid",
+		Applies:  lintExt(".x"),
+		Check:    lintOther((*Source).LintX),
+	},
+	{
+		ID:       "M001",
+		Severity: "error",
+		Message:  "M routine does not compile",
+		Applies:  lintExt(".m"),
+		Check:    lintOther((*Source).LintM),
+	},
+}
+
+func init() {
+	for _, rule := range builtinLintRules {
+		RegisterLintRule(rule)
+	}
+}
+
+// lintInfo converts the result of a linter to findings
+func lintInfo(info string, err error) ([]LintFinding, error) {
+	if err != nil {
+		return nil, err
+	}
+	if info == "OK" || info == "" {
+		return nil, nil
+	}
+	return []LintFinding{{Msg: info}}, nil
+}
+
+func isBrocadeJSON(source *Source) bool {
+	return strings.HasSuffix(source.String(), "/brocade.json")
+}
+
+func lintNature(nature string) func(source *Source) bool {
+	return func(source *Source) bool {
+		return !isBrocadeJSON(source) && source.Natures()[nature]
+	}
+}
+
+// lintExt applies to sources with one of the extensions
+// which are not a brocade.json or a l-, d-, i- or b-file
+func lintExt(exts ...string) func(source *Source) bool {
+	return func(source *Source) bool {
+		if isBrocadeJSON(source) {
+			return false
+		}
+		natures := source.Natures()
+		if natures["lfile"] || natures["dfile"] || natures["ifile"] || natures["bfile"] {
+			return false
+		}
+		ext := path.Ext(source.String())
+		for _, e := range exts {
+			if e == ext {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// lintResolved lints an object file after resolving the objects in what
+func lintResolved(what string, linter lintFunc) func(ctx *LintContext) ([]LintFinding, error) {
+	return func(ctx *LintContext) ([]LintFinding, error) {
+		buffer := new(bytes.Buffer)
+		err := ctx.Source.Resolve(what, nil, nil, buffer, true)
+		if err != nil {
+			return nil, err
+		}
+		return lintInfo(linter(ctx.Source, buffer, ctx.Warnings, ctx.Lintdir, qutil.AboutLine(ctx.Body)))
+	}
+}
+
+// lintOther lints a source after resolving all objects.
+// M files are converted to M code first.
+func lintOther(linter lintFunc) func(ctx *LintContext) ([]LintFinding, error) {
+	return func(ctx *LintContext) ([]LintFinding, error) {
+		source := ctx.Source
+		buffer := new(bytes.Buffer)
+		about := ""
+		var err error
+		if source.Natures()["mfile"] {
+			about = qutil.AboutLine(ctx.Body)
+			err = source.MFileToMumps("lint", buffer)
+		} else {
+			err = source.Resolve("rilm", nil, nil, buffer, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if path.Ext(source.String()) == ".x" {
+			about = qutil.AboutLine(ctx.Body)
+		}
+		return lintInfo(linter(source, buffer, ctx.Warnings, ctx.Lintdir, about))
+	}
+}
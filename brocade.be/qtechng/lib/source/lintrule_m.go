@@ -0,0 +1,122 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rules on the style of M routines
+
+func init() {
+	RegisterLintRule(&LintRule{
+		ID:       "M011",
+		Severity: "warning",
+		Message:  "Line has trailing whitespace",
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			return mLines(ctx.Body, func(line string) string {
+				if strings.TrimRight(line, " \t") != line {
+					return "Line has trailing whitespace"
+				}
+				return ""
+			}), nil
+		},
+		Fix: func(line string) *LintFix {
+			trimmed := strings.TrimRight(line, " \t")
+			if trimmed == line {
+				return nil
+			}
+			return &LintFix{
+				Description: "Remove trailing whitespace",
+				StartColumn: len([]rune(trimmed)) + 1,
+				EndColumn:   len([]rune(line)) + 1,
+			}
+		},
+	})
+	RegisterLintRule(&LintRule{
+		ID:       "M012",
+		Severity: "warning",
+		Message:  "Debugging command left in routine",
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			return mLines(ctx.Body, func(line string) string {
+				for _, command := range mCommands(mCode(line)) {
+					if mDebugCommands[strings.ToLower(command)] {
+						return fmt.Sprintf("Debugging command `%s` left in routine", command)
+					}
+				}
+				return ""
+			}), nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		ID:       "M013",
+		Severity: "info",
+		Message:  "Line is longer than 120 characters",
+		Disabled: true,
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			return mLines(ctx.Body, func(line string) string {
+				if n := len([]rune(line)); n > 120 {
+					return fmt.Sprintf("Line is longer than 120 characters: %d", n)
+				}
+				return ""
+			}), nil
+		},
+	})
+}
+
+var mDebugCommands = map[string]bool{
+	"b": true, "break": true, "zb": true, "zbreak": true,
+	"zwr": true, "zwrite": true, "zsh": true, "zshow": true,
+}
+
+// mCommands returns the commands in the code part of an M line (see mCode), without postconditionals.
+// Commands and arguments are separated by a single space: an argumentless command is
+// followed by two spaces.
+func mCommands(code string) (commands []string) {
+	code = strings.TrimLeft(code, " \t.")
+	for i, field := range strings.Split(code, " ") {
+		if i%2 != 0 || field == "" {
+			continue
+		}
+		command, _, _ := strings.Cut(field, ":")
+		commands = append(commands, command)
+	}
+	return commands
+}
+
+// mLines applies check to every line of an M routine
+func mLines(body []byte, check func(line string) string) (findings []LintFinding) {
+	for i, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		msg := check(line)
+		if msg != "" {
+			findings = append(findings, LintFinding{Lineno: i + 1, Msg: msg})
+		}
+	}
+	return findings
+}
+
+// mCode returns the code part of an M line: without label, strings and comment.
+// The command part starts with whitespace.
+func mCode(line string) string {
+	i := strings.IndexAny(line, " \t")
+	if i < 0 {
+		return ""
+	}
+	code := new(strings.Builder)
+	instring := false
+	for _, r := range line[i:] {
+		switch {
+		case r == '"':
+			instring = !instring
+		case instring:
+		case r == ';':
+			return code.String()
+		default:
+			code.WriteRune(r)
+		}
+	}
+	return code.String()
+}
@@ -0,0 +1,368 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	qregistry "brocade.be/base/registry"
+	qmfile "brocade.be/qtechng/lib/file/mfile"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// Rules on the semantics of M routines: based on the syntax tree of qmfile.Parse
+
+func init() {
+	RegisterLintRule(&LintRule{
+		ID:       "M021",
+		Severity: "warning",
+		Message:  "Reference to a label which does not exist",
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			routine := mParse(ctx)
+			index := releaseMIndex(ctx.Source.Release().String())
+			findings := make([]LintFinding, 0)
+			for _, line := range routine.Lines {
+				for _, ref := range line.Refs() {
+					if ref.Label == "" {
+						continue
+					}
+					switch {
+					case ref.Routine == "" || ref.Routine == routine.Name:
+						if routine.Label(ref.Label) != nil {
+							continue
+						}
+					case index[ref.Routine] == nil || index[ref.Routine][ref.Label]:
+						continue
+					}
+					findings = append(findings, LintFinding{
+						Lineno: line.Lineno,
+						Msg:    fmt.Sprintf("Label `%s` in `%s` does not exist", ref.Label, ref.String()),
+					})
+				}
+			}
+			return findings, nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		ID:       "M022",
+		Severity: "warning",
+		Message:  "Reference to a routine which is not in the release",
+		Disabled: true,
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			routine := mParse(ctx)
+			index := releaseMIndex(ctx.Source.Release().String())
+			findings := make([]LintFinding, 0)
+			for _, line := range routine.Lines {
+				for _, ref := range line.Refs() {
+					if ref.Routine == "" || ref.Routine == routine.Name || index[ref.Routine] != nil {
+						continue
+					}
+					findings = append(findings, LintFinding{
+						Lineno: line.Lineno,
+						Msg:    fmt.Sprintf("Routine `%s` in `%s` is not in the release", ref.Routine, ref.String()),
+					})
+				}
+			}
+			return findings, nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		ID:       "M023",
+		Severity: "warning",
+		Message:  "NEW-ed variable is not used",
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			return mUnusedNew(mParse(ctx)), nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		ID:       "M024",
+		Severity: "warning",
+		Message:  "Code is unreachable",
+		Applies:  lintExt(".m"),
+		Check: func(ctx *LintContext) ([]LintFinding, error) {
+			return mUnreachable(mParse(ctx)), nil
+		},
+	})
+	RegisterLintRule(&LintRule{
+		ID:       "M025",
+		Severity: "warning",
+		Message:  "Routine exceeds the limits of the M engine",
+		Applies:  lintExt(".m"),
+		Check:    mLimitCheck,
+	})
+}
+
+// mParse builds the syntax tree of the M routine in the lint context
+func mParse(ctx *LintContext) *qmfile.Routine {
+	_, base := qutil.QPartition(ctx.Source.String())
+	return qmfile.Parse(strings.TrimSuffix(base, ".m"), ctx.Body)
+}
+
+// mIndex is the set of labels per M routine in a release
+type mIndex map[string]map[string]bool
+
+type mIndexEntry struct {
+	index mIndex
+	made  time.Time
+}
+
+var mIndexCache = new(sync.Map)
+
+// releaseMIndex returns the labels of the M routines in a release.
+// The index is cached for a minute: lint checks sources in batches.
+func releaseMIndex(r string) mIndex {
+	if entry, ok := mIndexCache.Load(r); ok && time.Since(entry.(mIndexEntry).made) < time.Minute {
+		return entry.(mIndexEntry).index
+	}
+	index := make(mIndex)
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return index
+	}
+	for _, qpath := range release.FS().Glob("/", []string{"*.m"}, true) {
+		source, err := Source{}.New(r, qpath, true)
+		if err != nil {
+			continue
+		}
+		body, err := source.Fetch()
+		if err != nil {
+			continue
+		}
+		_, base := qutil.QPartition(qpath)
+		routine := qmfile.Parse(strings.TrimSuffix(base, ".m"), body)
+		labels := make(map[string]bool)
+		for _, label := range routine.Labels {
+			labels[label.Name] = true
+		}
+		index[routine.Name] = labels
+	}
+	mIndexCache.Store(r, mIndexEntry{index: index, made: time.Now()})
+	return index
+}
+
+// mUnusedNew finds the variables which are NEW-ed but not used in the rest of the label.
+// Labels with XECUTE or indirection are skipped.
+func mUnusedNew(routine *qmfile.Routine) (findings []LintFinding) {
+	type newed struct {
+		name   string
+		lineno int
+	}
+	news := make([]newed, 0)
+	used := make(map[string]bool)
+	skip := false
+	flush := func() {
+		if !skip {
+			for _, n := range news {
+				if !used[n.name] {
+					findings = append(findings, LintFinding{
+						Lineno: n.lineno,
+						Msg:    fmt.Sprintf("Variable `%s` is NEW-ed but not used", n.name),
+					})
+				}
+			}
+		}
+		news = news[:0]
+		used = make(map[string]bool)
+		skip = false
+	}
+	for _, line := range routine.Lines {
+		if line.Label != nil {
+			flush()
+			for _, formal := range line.Label.Formals {
+				used[formal] = true
+			}
+		}
+		for _, cmd := range line.Commands {
+			if cmd.Name == "XECUTE" || strings.Contains(cmd.Postcond, "@") || strings.Contains(strings.Join(cmd.Args, ","), "@") {
+				skip = true
+			}
+			if cmd.Name != "NEW" {
+				for _, name := range cmd.Names() {
+					used[name] = true
+				}
+				continue
+			}
+			for _, name := range qmfile.Names(cmd.Postcond) {
+				used[name] = true
+			}
+			for _, arg := range cmd.Args {
+				names := qmfile.Names(arg)
+				if len(names) == 1 && names[0] == arg {
+					news = append(news, newed{arg, line.Lineno})
+				}
+			}
+		}
+	}
+	flush()
+	return findings
+}
+
+// mTerminates returns the command which unconditionally ends the execution of a line:
+// QUIT, HALT or GOTO without postconditional and not preceded by IF, ELSE or FOR
+func mTerminates(line *qmfile.Line) string {
+	for _, cmd := range line.Commands {
+		switch cmd.Name {
+		case "IF", "ELSE", "FOR":
+			return ""
+		case "QUIT", "HALT":
+			if cmd.Postcond == "" {
+				return cmd.Name
+			}
+		case "GOTO":
+			if cmd.Postcond != "" || len(cmd.Args) != 1 {
+				continue
+			}
+			if _, rest := qmfile.ParseEntryRef(cmd.Args[0]); !strings.Contains(rest, ":") {
+				return cmd.Name
+			}
+		}
+	}
+	return ""
+}
+
+// mUnreachable finds code after an unconditional QUIT, HALT or GOTO which cannot be reached:
+// it has no label, is not the target of a `label+offset` reference and
+// is not a block of an argumentless DO.
+func mUnreachable(routine *qmfile.Routine) (findings []LintFinding) {
+	targets := make(map[*qmfile.Line]bool)
+	for _, line := range routine.Lines {
+		for _, ref := range line.Refs() {
+			if ref.Offset == 0 || (ref.Routine != "" && ref.Routine != routine.Name) {
+				continue
+			}
+			if target := routine.Target(ref.Label, ref.Offset); target != nil {
+				targets[target] = true
+			}
+		}
+	}
+	dead := false
+	reported := false
+	level := 0
+	block := -1
+	from := 0
+	command := ""
+	for _, line := range routine.Lines {
+		if line.Label == nil && len(line.Commands) == 0 {
+			continue
+		}
+		if line.Label != nil || targets[line] || (dead && line.Level < level) {
+			dead = false
+		}
+		if dead && block >= 0 && line.Level > block {
+			continue
+		}
+		if dead {
+			if !reported {
+				findings = append(findings, LintFinding{
+					Lineno: line.Lineno,
+					Msg:    fmt.Sprintf("Code is unreachable: it follows `%s` on line %d", command, from),
+				})
+				reported = true
+			}
+			continue
+		}
+		command = mTerminates(line)
+		if command == "" {
+			continue
+		}
+		dead = true
+		reported = false
+		level = line.Level
+		from = line.Lineno
+		block = -1
+		for _, cmd := range line.Commands {
+			if cmd.Name == "DO" && len(cmd.Args) == 0 {
+				block = line.Level
+			}
+		}
+	}
+	return findings
+}
+
+// mLimits are the limits of an M engine
+type mLimits struct {
+	Line int // maximum length of a line in bytes
+	Name int // maximum length of a label or routine name
+}
+
+var mEngineLimits = map[string]mLimits{
+	"gtm":     {Line: 8192, Name: 31},
+	"yottadb": {Line: 8192, Name: 31},
+	"cache":   {Line: 32767, Name: 31},
+	"iris":    {Line: 32767, Name: 31},
+	"ansi":    {Line: 255, Name: 8},
+}
+
+// mEngine returns the target M engine and its limits: registry values `m-engine`
+// (gtm, yottadb, cache, iris or ansi; default gtm) and `m-line-limit`
+func mEngine() (engine string, limits mLimits) {
+	engine = strings.ToLower(qregistry.Registry["m-engine"])
+	limits, ok := mEngineLimits[engine]
+	if !ok {
+		engine = "gtm"
+		limits = mEngineLimits[engine]
+	}
+	if n, err := strconv.Atoi(qregistry.Registry["m-line-limit"]); err == nil && n > 0 {
+		limits.Line = n
+	}
+	return engine, limits
+}
+
+// mLimitCheck checks the length of the routine name, the labels and the lines.
+// Lines are measured as they are sent to M. Lines which only exceed the limit
+// after macro expansion are reported without line number.
+func mLimitCheck(ctx *LintContext) ([]LintFinding, error) {
+	engine, limits := mEngine()
+	routine := mParse(ctx)
+	findings := make([]LintFinding, 0)
+	if len(routine.Name) > limits.Name {
+		findings = append(findings, LintFinding{
+			Msg: fmt.Sprintf("Routine name `%s` is longer than %d characters (%s)", routine.Name, limits.Name, engine),
+		})
+	}
+	for _, label := range routine.Labels {
+		if len(label.Name) > limits.Name {
+			findings = append(findings, LintFinding{
+				Lineno: label.Lineno,
+				Msg:    fmt.Sprintf("Label `%s` is longer than %d characters (%s)", label.Name, limits.Name, engine),
+			})
+		}
+	}
+	long := 0
+	for i, line := range bytes.Split(ctx.Body, []byte("\n")) {
+		code, comment := mdecomment(bytes.TrimRight(line, "\r"))
+		if len(comment) != 0 && comment[0] == byte('/') {
+			comment = []byte{}
+		}
+		n := len(mtransform(code, comment))
+		if n > limits.Line {
+			long++
+			findings = append(findings, LintFinding{
+				Lineno: i + 1,
+				Msg:    fmt.Sprintf("Line is %d bytes long: the limit is %d (%s)", n, limits.Line, engine),
+			})
+		}
+	}
+	if long != 0 {
+		return findings, nil
+	}
+	buffer := new(bytes.Buffer)
+	if ctx.Source.MFileToMumps("lint", buffer) != nil {
+		return findings, nil
+	}
+	for i, line := range bytes.Split(buffer.Bytes(), []byte("\n")) {
+		if n := len(line); n > limits.Line {
+			findings = append(findings, LintFinding{
+				Msg: fmt.Sprintf("Line %d of the M code is %d bytes long after macro expansion: the limit is %d (%s)", i+1, n, limits.Line, engine),
+			})
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,163 @@
+package source
+
+import (
+	"strings"
+	"testing"
+
+	qregistry "brocade.be/base/registry"
+	qerror "brocade.be/qtechng/lib/error"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+func init() {
+	RegisterLintRule(&LintRule{
+		ID:       "T901",
+		Severity: "error",
+		Message:  "TODO in text",
+		Applies:  func(source *Source) bool { return strings.HasSuffix(source.String(), ".todo") },
+		Check: func(ctx *LintContext) (findings []LintFinding, err error) {
+			for i, line := range strings.Split(string(ctx.Body), "\n") {
+				if strings.Contains(line, "TODO") {
+					findings = append(findings, LintFinding{Lineno: i + 1})
+				}
+			}
+			return findings, nil
+		},
+	})
+}
+
+func TestLintRule01(t *testing.T) {
+	r := "9.90"
+	proj := "/a/b/c"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+
+	p := proj + "/f.todo"
+	source, _ := Source{}.New(r, p, false)
+	_, _, _, err := source.Store(qmeta.Meta{}, "x\nTODO 1\nTODO 2 ;qtech:nolint T901\nTODO 3 ;qtech:nolint M011", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	info, err := source.Lint("", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	errs, ok := info.(qerror.ErrorSlice)
+	if !ok || len(errs) != 2 {
+		t.Errorf("Should be 2 findings: %v", info)
+		return
+	}
+	qerr := errs[0].(*qerror.QError)
+	if qerr.Ref[0] != "lint.message" || qerr.Ref[1] != "T901" || qerr.Lineno != 2 || qerr.Type != "ERROR" || qerr.Msg[0] != "TODO in text" {
+		t.Errorf("Wrong finding: %v", qerr)
+	}
+
+	// disabled in the project
+	cfg, _ := Source{}.New(r, proj+"/brocade.json", false)
+	blob, _ := cfg.Fetch()
+	_, _, _, err = cfg.Store(qmeta.Meta{Digest: qutil.Digest(blob)}, `{"lintdisable": ["T9*"]}`, false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	source, _ = Source{}.New(r, p, true)
+	info, err = source.Lint("", false)
+	if info != nil || err != nil {
+		t.Errorf("T901 should be disabled: %v %v", info, err)
+	}
+}
+
+func TestLintRule02(t *testing.T) {
+	body := []byte("test ; About: test\n\tzwrite  ; qtech:nolint M011 \n\ts x=\"zwrite\" ; zwrite\n\t. zb:x=1 s y=1\n")
+	findings, _ := GetLintRule("M012").Check(&LintContext{Body: body})
+	if len(findings) != 2 || findings[0].Lineno != 2 || findings[1].Lineno != 4 {
+		t.Errorf("Wrong M012 findings: %v", findings)
+	}
+	for _, line := range []string{"\td b", "\tg b", "\tk b", "\ts x=1 d b:x q", "\tw zwr,zsh", "b\tq"} {
+		findings, _ = GetLintRule("M012").Check(&LintContext{Body: []byte(line + "\n")})
+		if len(findings) != 0 {
+			t.Errorf("`%s` is not a debugging command: %v", line, findings)
+		}
+	}
+	for _, line := range []string{"\tb", "\tq:x  b", "\ts x=1 zsh \"*\"", "\t. . ZWRITE"} {
+		findings, _ = GetLintRule("M012").Check(&LintContext{Body: []byte(line + "\n")})
+		if len(findings) != 1 {
+			t.Errorf("`%s` is a debugging command: %v", line, findings)
+		}
+	}
+	findings, _ = GetLintRule("M011").Check(&LintContext{Body: body})
+	if len(findings) != 1 || findings[0].Lineno != 2 {
+		t.Errorf("Wrong M011 findings: %v", findings)
+	}
+	lines, file := lintSuppressions(body)
+	if !lintSuppressed("M011", 2, lines, file) || lintSuppressed("M012", 2, lines, file) {
+		t.Errorf("Wrong suppressions: %v", lines)
+	}
+	lines, file = lintSuppressions([]byte("; qtech:nolint-file M012, M011\n"))
+	if !lintSuppressed("M012", 5, lines, file) || !lintSuppressed("M011", 0, lines, file) || lintSuppressed("M013", 1, lines, file) {
+		t.Errorf("Wrong file suppressions: %v", file)
+	}
+	if GetLintRule("M013") == nil || !GetLintRule("M013").Disabled {
+		t.Errorf("M013 should be disabled by default")
+	}
+}
+
+func TestLintRule03(t *testing.T) {
+	r := "9.87"
+	proj := "/a/b"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+	sign, _ := Source{}.New(r, proj+"/sign.m", false)
+	_, _, _, err := sign.Store(qmeta.Meta{}, "// About: sign\n\ndef %Sign(x):\n    q x>0-(x<0)\n", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	body := `// About: main
+
+def main(list):
+    n i,j,k
+    f i=1:1:10 w $$%Sign^sign(i),$$%Abs^sign(i),! d show(.k)
+    g end+1
+    w "unreachable"
+    . w "also unreachable"
+show(x) d  q
+    . w x,!
+    q
+    d %Sign^other
+end q
+    q 1
+`
+	main, _ := Source{}.New(r, proj+"/main.m", false)
+	_, _, _, err = main.Store(qmeta.Meta{}, body, false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	ctx := &LintContext{Source: main, Body: []byte(body)}
+	findings, _ := GetLintRule("M021").Check(ctx)
+	if len(findings) != 1 || findings[0].Lineno != 5 || !strings.Contains(findings[0].Msg, "$$%Abs^sign") {
+		t.Errorf("Wrong M021 findings: %v", findings)
+	}
+	findings, _ = GetLintRule("M022").Check(ctx)
+	if len(findings) != 1 || findings[0].Lineno != 12 {
+		t.Errorf("Wrong M022 findings: %v", findings)
+	}
+	findings, _ = GetLintRule("M023").Check(ctx)
+	if len(findings) != 1 || findings[0].Lineno != 4 || !strings.Contains(findings[0].Msg, "`j`") {
+		t.Errorf("Wrong M023 findings: %v", findings)
+	}
+	findings, _ = GetLintRule("M024").Check(ctx)
+	if len(findings) != 2 || findings[0].Lineno != 7 || findings[1].Lineno != 11 {
+		t.Errorf("Wrong M024 findings: %v", findings)
+	}
+	qregistry.Registry["m-line-limit"] = "30"
+	findings, _ = GetLintRule("M025").Check(ctx)
+	delete(qregistry.Registry, "m-line-limit")
+	if len(findings) != 1 || findings[0].Lineno != 5 || !strings.Contains(findings[0].Msg, "the limit is 30 (gtm)") {
+		t.Errorf("Wrong M025 findings: %v", findings)
+	}
+}
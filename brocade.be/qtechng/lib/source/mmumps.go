@@ -17,6 +17,11 @@ func (mfile *Source) MFileToMumps(batchid string, buf *bytes.Buffer) error {
 	if err != nil {
 		return err
 	}
+	objectmap := make(map[string]qobject.Object)
+	err = mfile.CheckMacroCalls(content, objectmap)
+	if err != nil {
+		return err
+	}
 	bufnoc := new(bytes.Buffer)
 	qpath := mfile.String()
 	version := strings.TrimRight(qregistry.Registry["brocade-release"], "abcdefghijklmnopqrstuvwxyz- /")
@@ -81,7 +86,6 @@ func (mfile *Source) MFileToMumps(batchid string, buf *bytes.Buffer) error {
 	content = bufnoc.Bytes()
 	env := mfile.Env()
 	notreplace := mfile.NotReplace()
-	objectmap := make(map[string]qobject.Object)
 	bufmac := new(bytes.Buffer)
 	_, err = ResolveText(env, content, "rilm", notreplace, objectmap, nil, bufmac, "", mfile.String())
 
@@ -1,8 +1,13 @@
 package source
 
 import (
+	"bytes"
+	"strconv"
 	"strings"
 	"testing"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qmeta "brocade.be/qtechng/lib/meta"
 )
 
 func TestMtransform(t *testing.T) {
@@ -366,3 +371,59 @@ func TestMdecomment(t *testing.T) {
 	}
 
 }
+
+func TestMacroCalls01(t *testing.T) {
+	r := "9.84"
+	proj := "/a/b"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+	sources := [][2]string{
+		{proj + "/typed.d", `// About: macros
+
+macro setVar($var:lvn, $n:numeric=1, $text:lgcode=title):
+    '''
+    $synopsis: set a variable
+    $var: variable
+    $n: number
+    $text: lgcode
+    $example: m4_setVar(x,2)
+    '''
+    «s $var=$n_l4_E_$text»
+`},
+		{proj + "/typed.l", "// About: texts\n\nlgcode title:\n    E: Title\n"},
+		{proj + "/typed.m", `// About: calls
+
+main    m4_setVar(RAx,5)
+    // m4_setVar(1,a)
+    m4_setVar("a",b)
+    m4_setVar(x,1,text=nothere)
+    m4_setVar(x,1,2,3)
+    q
+`},
+	}
+	for _, s := range sources {
+		source, _ := Source{}.New(r, s[0], false)
+		_, _, _, err := source.Store(qmeta.Meta{}, s[1], false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, _ := Source{}.New(r, proj+"/typed.m", true)
+	err := source.MFileToMumps("test", new(bytes.Buffer))
+	if err == nil {
+		t.Fatal("Should fail")
+	}
+	errs := err.(qerror.ErrorSlice)
+	found := make([]string, len(errs))
+	for i, e := range errs {
+		qe := e.(*qerror.QError)
+		found[i] = strconv.Itoa(qe.Lineno) + ":" + qe.Ref[0]
+		if qe.QPath != proj+"/typed.m" {
+			t.Errorf("Wrong qpath: %s", qe.QPath)
+		}
+	}
+	expect := "5:source.macro.type 5:source.macro.type 6:source.macro.lgcode 7:parse.args.parse"
+	if strings.Join(found, " ") != expect {
+		t.Errorf("Wrong errors:\n%s\n%s", strings.Join(found, " "), err)
+	}
+}
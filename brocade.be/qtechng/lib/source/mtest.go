@@ -0,0 +1,371 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	qfs "brocade.be/base/fs"
+	qmumps "brocade.be/base/mumps"
+	qregistry "brocade.be/base/registry"
+	qerror "brocade.be/qtechng/lib/error"
+	qmfile "brocade.be/qtechng/lib/file/mfile"
+	qproject "brocade.be/qtechng/lib/project"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// M unit tests
+//
+// A test routine is an M file in a project with a basename starting with `t` (`t*.m`).
+// Every label starting with `test` and without formal parameters is a test
+// (e.g. `def testSign():`). Functions (`fn`) are not tests: they quit with a value.
+// The prefix `t4_` is not usable: it is the prefix of text substitutions and `_` is not allowed
+// in M labels.
+//
+// A test uses the assertions of the support routine `qtechtst`:
+//
+//   - d eq^qtechtst(actual,expected,message): actual and expected should be equal
+//   - d true^qtechtst(condition,message): condition should be true
+//   - d fail^qtechtst(message): unconditional failure
+//
+// A test passes if all its assertions succeed and no M error is raised.
+
+// MTestRoutine is the support routine for M unit tests: installed with the test routines
+const MTestRoutine = `qtechtst ;qtechng M unit test support
+ ;
+run(test) ;run a test: test is label^routine
+ n %QTcnt,%QTfail
+ s %QTcnt=0,%QTfail=0
+ w !,"##qtechng-start##",$C(9),test,!
+ d exec(test)
+ w !,"##qtechng-test##",$C(9),test,$C(9),$S(%QTfail=2:"error",%QTfail:"fail",1:"pass"),$C(9),%QTcnt,!
+ q
+ ;
+exec(test) ;execute a test and trap the errors
+ n $ES,$ET
+ s $ET="q:$ES  d error^qtechtst s $EC="""""
+ d @test
+ q
+ ;
+error ;an M error is raised
+ s %QTfail=2
+ w !,"##qtechng-error##",$C(9),$ST($ST(-1),"PLACE"),$C(9),$EC,!
+ q
+ ;
+eq(actual,expected,msg) ;actual should be equal to expected
+ d assert(actual=expected,$G(msg)_" (expected: "_expected_", actual: "_actual_")",$ST($ST-1,"PLACE"))
+ q
+ ;
+true(cond,msg) ;cond should be true
+ d assert(''cond,$G(msg),$ST($ST-1,"PLACE"))
+ q
+ ;
+fail(msg) ;unconditional failure
+ d assert(0,$G(msg),$ST($ST-1,"PLACE"))
+ q
+ ;
+assert(cond,msg,place) ;count the assertion and report a failure
+ s %QTcnt=$G(%QTcnt)+1
+ q:cond
+ s:'$G(%QTfail) %QTfail=1
+ w !,"##qtechng-assert##",$C(9),place,$C(9),msg,!
+ q
+`
+
+const (
+	mtestStart  = "##qtechng-start##"
+	mtestTest   = "##qtechng-test##"
+	mtestAssert = "##qtechng-assert##"
+	mtestError  = "##qtechng-error##"
+)
+
+// MTestResult is the outcome of an M unit test
+type MTestResult struct {
+	QPath    string   `json:"qpath"`
+	Routine  string   `json:"routine"`
+	Label    string   `json:"label"`
+	Status   string   `json:"status"` // "pass", "fail" or "error"
+	Asserts  int      `json:"asserts"`
+	Messages []string `json:"messages,omitempty"`
+	Output   []string `json:"output,omitempty"`
+}
+
+// Name returns the test as an M entryref: label^routine
+func (result MTestResult) Name() string {
+	return result.Label + "^" + result.Routine
+}
+
+// IsMTest checks if a qpath is an M test routine
+func IsMTest(qpath string) bool {
+	_, base := qutil.QPartition(qpath)
+	return strings.HasPrefix(base, "t") && strings.HasSuffix(base, ".m")
+}
+
+// MTestLabels returns the test labels in the body of an M file in qtechng format:
+// `def testSign():` as well as `testSign ;`
+func MTestLabels(body []byte) []string {
+	labels := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, label := range qmfile.Parse("", body).Labels {
+		if !strings.HasPrefix(label.Name, "test") || len(label.Formals) != 0 || label.Kind == "fn" || seen[label.Name] {
+			continue
+		}
+		seen[label.Name] = true
+		labels = append(labels, label.Name)
+	}
+	return labels
+}
+
+// MTestFind returns the test routines specified by args: projects or qpaths of M files
+func MTestFind(r string, args []string) (qpaths []string, err error) {
+	found := make(map[string]bool)
+	errs := make([]error, 0)
+	for _, arg := range args {
+		arg = qutil.Canon(arg)
+		if strings.HasSuffix(arg, ".m") {
+			if !IsMTest(arg) {
+				errs = append(errs, &qerror.QError{
+					Ref:     []string{"source.mtest.find.routine"},
+					Version: r,
+					QPath:   arg,
+					Msg:     []string{"Not a test routine: the basename should start with `t`"},
+				})
+				continue
+			}
+			found[arg] = true
+			continue
+		}
+		project, err := qproject.Project{}.New(r, arg, true)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, qpath := range project.QPaths([]string{"t*.m"}, true) {
+			found[qpath] = true
+		}
+	}
+	qpaths = make([]string, 0, len(found))
+	for qpath := range found {
+		qpaths = append(qpaths, qpath)
+	}
+	sort.Strings(qpaths)
+	if len(errs) != 0 {
+		return qpaths, qerror.ErrorSlice(errs)
+	}
+	return qpaths, nil
+}
+
+// MTestRun installs the test routines, and the M files of their projects, in the scratch UCI
+// and runs the tests. The scratch UCI is given by the registry values `m-test-db`
+// (the M database directory) and `m-test-rou-dir` (the routine directory).
+func MTestRun(r string, qpaths []string) (results []MTestResult, err error) {
+	mdb := qregistry.Registry["m-test-db"]
+	roudir := qregistry.Registry["m-test-rou-dir"]
+	if mdb == "" || roudir == "" {
+		err := &qerror.QError{
+			Ref:     []string{"source.mtest.registry"},
+			Version: r,
+			Msg:     []string{"Registry values `m-test-db` and `m-test-rou-dir` should specify the scratch UCI"},
+		}
+		return nil, err
+	}
+	batchid := "test"
+
+	// resolve and install the routines
+	errs := make([]error, 0)
+	install := make(map[string]*Source)
+	tests := make([]*Source, 0, len(qpaths))
+	for _, qpath := range qpaths {
+		source, err := Source{}.New(r, qpath, true)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tests = append(tests, source)
+		install[qpath] = source
+		for _, s := range source.Neighbours() {
+			if s != nil && strings.HasSuffix(s.String(), ".m") {
+				install[s.String()] = s
+			}
+		}
+	}
+	failed := make(map[string]string)
+	for qpath, source := range install {
+		buf := new(bytes.Buffer)
+		err := source.MFileToMumps(batchid, buf)
+		if err != nil {
+			failed[qpath] = err.Error()
+			continue
+		}
+		_, base := qutil.QPartition(qpath)
+		target := filepath.Join(roudir, base)
+		err = qfs.Store(target, buf, "process")
+		if err == nil {
+			err = qmumps.Compile(target, false)
+		}
+		if err != nil {
+			failed[qpath] = err.Error()
+		}
+	}
+	target := filepath.Join(roudir, "qtechtst.m")
+	err = qfs.Store(target, MTestRoutine, "process")
+	if err == nil {
+		err = qmumps.Compile(target, false)
+	}
+	if err != nil {
+		errs = append(errs, err)
+		return nil, qerror.ErrorSlice(errs)
+	}
+
+	// run the tests
+	results = make([]MTestResult, 0)
+	index := make(map[string]int)
+	buf := new(bytes.Buffer)
+	for _, source := range tests {
+		qpath := source.String()
+		body, err := source.Fetch()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		_, base := qutil.QPartition(qpath)
+		routine := strings.TrimSuffix(base, ".m")
+		for _, label := range MTestLabels(body) {
+			result := MTestResult{
+				QPath:   qpath,
+				Routine: routine,
+				Label:   label,
+			}
+			if msg, ok := failed[qpath]; ok {
+				result.Status = "error"
+				result.Messages = []string{msg}
+			} else {
+				fmt.Fprintf(buf, "d run^qtechtst(\"%s\")\n", result.Name())
+			}
+			index[result.Name()] = len(results)
+			results = append(results, result)
+		}
+	}
+	if buf.Len() != 0 {
+		out, err := qmumps.PipeOut(mdb, []*bytes.Buffer{buf})
+		parseMTestOutput(out, results, index)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status = "error"
+			results[i].Messages = append(results[i].Messages, "test did not complete")
+		}
+	}
+	if len(errs) != 0 {
+		return results, qerror.ErrorSlice(errs)
+	}
+	return results, nil
+}
+
+// parseMTestOutput reads the output of `qtechtst` and completes the results
+func parseMTestOutput(out []byte, results []MTestResult, index map[string]int) {
+	var current *MTestResult
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		fields := strings.Split(line, "\t")
+		switch fields[0] {
+		case mtestStart:
+			current = nil
+			if len(fields) > 1 {
+				if k, ok := index[fields[1]]; ok {
+					current = &results[k]
+				}
+			}
+		case mtestTest:
+			if current == nil || len(fields) < 4 {
+				continue
+			}
+			current.Status = fields[2]
+			current.Asserts, _ = strconv.Atoi(fields[3])
+			current = nil
+		case mtestAssert, mtestError:
+			if current == nil {
+				continue
+			}
+			msg := strings.Join(fields[1:], ": ")
+			if fields[0] == mtestError {
+				msg = "M error at " + msg
+			}
+			current.Messages = append(current.Messages, msg)
+		default:
+			if current != nil && strings.TrimSpace(line) != "" {
+				current.Output = append(current.Output, line)
+			}
+		}
+	}
+}
+
+// MTestJUnit renders the results of M unit tests as JUnit XML.
+// Every test routine is a test suite, every test label is a test case.
+func MTestJUnit(results []MTestResult) []byte {
+	escape := func(s string) string {
+		buf := new(bytes.Buffer)
+		xml.EscapeText(buf, []byte(s))
+		return buf.String()
+	}
+
+	suites := make([]string, 0)
+	byqpath := make(map[string][]MTestResult)
+	failures := 0
+	nerrors := 0
+	for _, result := range results {
+		if byqpath[result.QPath] == nil {
+			suites = append(suites, result.QPath)
+		}
+		byqpath[result.QPath] = append(byqpath[result.QPath], result)
+	}
+
+	buffer := new(bytes.Buffer)
+	for _, qpath := range suites {
+		cases := new(bytes.Buffer)
+		sfailures := 0
+		serrors := 0
+		classname := strings.ReplaceAll(strings.Trim(strings.TrimSuffix(qpath, ".m"), "/"), "/", ".")
+		for _, result := range byqpath[qpath] {
+			fmt.Fprintf(cases, "    <testcase classname=\"%s\" name=\"%s\" assertions=\"%d\">\n", escape(classname), escape(result.Label), result.Asserts)
+			message := ""
+			if len(result.Messages) != 0 {
+				message = result.Messages[0]
+			}
+			switch result.Status {
+			case "fail":
+				sfailures++
+				fmt.Fprintf(cases, "      <failure message=\"%s\" type=\"assert\">%s</failure>\n", escape(message), escape(strings.Join(result.Messages, "\n")))
+			case "error":
+				serrors++
+				fmt.Fprintf(cases, "      <error message=\"%s\" type=\"M\">%s</error>\n", escape(message), escape(strings.Join(result.Messages, "\n")))
+			}
+			if len(result.Output) != 0 {
+				fmt.Fprintf(cases, "      <system-out>%s</system-out>\n", escape(strings.Join(result.Output, "\n")))
+			}
+			fmt.Fprintln(cases, "    </testcase>")
+		}
+		failures += sfailures
+		nerrors += serrors
+		fmt.Fprintf(buffer, "  <testsuite name=\"%s\" tests=\"%d\" failures=\"%d\" errors=\"%d\">\n", escape(qpath), len(byqpath[qpath]), sfailures, serrors)
+		buffer.Write(cases.Bytes())
+		fmt.Fprintln(buffer, "  </testsuite>")
+	}
+
+	junit := new(bytes.Buffer)
+	fmt.Fprintln(junit, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintf(junit, "<testsuites name=\"qtechng test\" tests=\"%d\" failures=\"%d\" errors=\"%d\">\n", len(results), failures, nerrors)
+	junit.Write(buffer.Bytes())
+	fmt.Fprintln(junit, "</testsuites>")
+	return junit.Bytes()
+}
@@ -0,0 +1,98 @@
+package source
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMTest01(t *testing.T) {
+	body := []byte(`tsign ; About: tests of sign
+ ;
+testPositive ; positive numbers
+ d eq^qtechtst($$sign^sign(5),1,"sign of 5")
+ q
+testZero() ;
+ d true^qtechtst($$sign^sign(0)=0)
+ q
+test2(x) ; has parameters
+ q
+helper ;
+ q
+testPositive ; duplicate
+`)
+	labels := MTestLabels(body)
+	if strings.Join(labels, ",") != "testPositive,testZero" {
+		t.Errorf("Wrong labels: %v", labels)
+	}
+	body = []byte(`// About: tests of sign
+
+def testSign():
+    d eq^qtechtst($$%Sign^sign(5),1,"sign of 5")
+    q
+
+def testHelper(x):
+    q
+
+sub testLocal:
+    q
+
+fn test3() :
+    q 1
+`)
+	labels = MTestLabels(body)
+	if strings.Join(labels, ",") != "testSign,testLocal" {
+		t.Errorf("Wrong labels: %v", labels)
+	}
+	if !IsMTest("/a/b/tsign.m") || IsMTest("/a/t/sign.m") || IsMTest("/a/b/tsign.b") {
+		t.Errorf("Wrong test routine detection")
+	}
+}
+
+func TestMTest02(t *testing.T) {
+	results := []MTestResult{
+		{QPath: "/a/b/tsign.m", Routine: "tsign", Label: "testPositive"},
+		{QPath: "/a/b/tsign.m", Routine: "tsign", Label: "testZero"},
+		{QPath: "/a/b/tsign.m", Routine: "tsign", Label: "testCrash"},
+		{QPath: "/a/b/tsign.m", Routine: "tsign", Label: "testLost"},
+	}
+	index := make(map[string]int)
+	for i, result := range results {
+		index[result.Name()] = i
+	}
+	out := strings.Join([]string{
+		"GTM>",
+		"##qtechng-start##\ttestPositive^tsign",
+		"##qtechng-test##\ttestPositive^tsign\tpass\t1",
+		"##qtechng-start##\ttestZero^tsign",
+		"computing",
+		"##qtechng-assert##\ttestZero+1^tsign\tsign of 0 (expected: 0, actual: 1)",
+		"##qtechng-test##\ttestZero^tsign\tfail\t1",
+		"##qtechng-start##\ttestCrash^tsign",
+		"##qtechng-error##\tsign+2^sign\t,M9,Z150373210,",
+		"##qtechng-test##\ttestCrash^tsign\terror\t0",
+		"##qtechng-start##\ttestLost^tsign",
+		"GTM>",
+	}, "\n")
+	parseMTestOutput([]byte(out), results, index)
+	if results[0].Status != "pass" || results[0].Asserts != 1 || len(results[0].Messages) != 0 {
+		t.Errorf("Wrong result: %v", results[0])
+	}
+	if results[1].Status != "fail" || len(results[1].Messages) != 1 || results[1].Output[0] != "computing" {
+		t.Errorf("Wrong result: %v", results[1])
+	}
+	if results[2].Status != "error" || !strings.HasPrefix(results[2].Messages[0], "M error at sign+2^sign") {
+		t.Errorf("Wrong result: %v", results[2])
+	}
+	if results[3].Status != "" || len(results[3].Output) != 1 {
+		t.Errorf("Wrong result: %v", results[3])
+	}
+	results[3].Status = "error"
+
+	junit := string(MTestJUnit(results))
+	if !strings.Contains(junit, `<testsuite name="/a/b/tsign.m" tests="4" failures="1" errors="2">`) {
+		t.Errorf("Wrong JUnit: %s", junit)
+	}
+	if !strings.Contains(junit, `<testcase classname="a.b.tsign" name="testZero" assertions="1">`) || !strings.Contains(junit, `<failure message="testZero+1^tsign: sign of 0 (expected: 0, actual: 1)" type="assert">`) {
+		t.Errorf("Wrong JUnit: %s", junit)
+	}
+}
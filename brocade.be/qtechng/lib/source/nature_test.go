@@ -176,8 +176,7 @@ func TestNature04(t *testing.T) {
 }
 
 func makenRelease(r string, proj string) (release *qserver.Release, project *qproject.Project) {
-	release, _ = qserver.Release{}.New(r, false)
-	release.FS("/").RemoveAll("/")
+	release = removeRelease(r)
 	err := release.Init()
 	if err != nil {
 		fmt.Println("error server:", err)
@@ -0,0 +1,215 @@
+package source
+
+import (
+	"log"
+	"path"
+	"sort"
+	"strings"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qobject "brocade.be/qtechng/lib/object"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// InstallPlan describes an incremental installation:
+// only the sources affected by a change are installed.
+type InstallPlan struct {
+	Release      string   `json:"version"`
+	Batch        string   `json:"batchid"`
+	Installable  bool     `json:"installable"`
+	Changed      []string `json:"changed"`
+	Objects      []string `json:"objects"`
+	Routines     []string `json:"mroutines"`
+	Autofiles    []string `json:"autofiles"`
+	Widgets      []string `json:"widgets"`
+	Releasefiles []string `json:"releasefiles"`
+	Projects     []string `json:"installprojects"`
+	Skipped      []string `json:"skipped"`
+}
+
+// PlanInstall computes the incremental installation of the changed qpaths.
+// The changed objects are given in objs. If objs is nil, all objects defined
+// in the changed sources are considered to be changed.
+// The closure of these objects is taken from the object links:
+//   - M routines (*.m), autofiles (*.b, *.l) and widgets (*.x) which are changed or
+//     use a changed object, are installed
+//   - release.py is executed if it is changed
+//   - install.py of a project is executed if a source of the project is changed
+//     (data files and templates are deployed by install.py), or if install.py uses
+//     a changed object
+func PlanInstall(batchid string, r string, qpaths []string, objs []string) (plan *InstallPlan, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := release.Exists(); !ok {
+		err = &qerror.QError{
+			Ref:     []string{"source.plan.notexists"},
+			Version: release.String(),
+			Msg:     []string{"Version `" + release.String() + "` does not exists"},
+		}
+		return nil, err
+	}
+	r = release.String()
+	plan = &InstallPlan{
+		Release:      r,
+		Batch:        batchid,
+		Installable:  release.IsInstallable() && installRelease() == r,
+		Changed:      make([]string, 0),
+		Objects:      make([]string, 0),
+		Routines:     make([]string, 0),
+		Autofiles:    make([]string, 0),
+		Widgets:      make([]string, 0),
+		Releasefiles: make([]string, 0),
+		Projects:     make([]string, 0),
+		Skipped:      make([]string, 0),
+	}
+
+	// changed sources and objects
+	sources := make(map[string]*Source)
+	for _, qpath := range qpaths {
+		qpath = qutil.Canon(qpath)
+		if sources[qpath] != nil {
+			continue
+		}
+		source, err := Source{}.New(r, qpath, true)
+		if err != nil {
+			return nil, err
+		}
+		sources[qpath] = source
+		plan.Changed = append(plan.Changed, qpath)
+	}
+	sort.Strings(plan.Changed)
+
+	objsfound := make(map[string]bool)
+	for _, obj := range objs {
+		objsfound[obj] = true
+	}
+	for _, qpath := range plan.Changed {
+		if objs != nil {
+			break
+		}
+		source := sources[qpath]
+		if !source.Natures()["objectfile"] {
+			continue
+		}
+		blob, e := source.Fetch()
+		if e != nil {
+			continue
+		}
+		objfile, _ := source.objectFile(blob)
+		if objfile == nil {
+			continue
+		}
+		for _, obj := range objfile.Objects() {
+			objsfound[obj.String()] = true
+		}
+	}
+	for obj := range objsfound {
+		plan.Objects = append(plan.Objects, obj)
+	}
+	sort.Strings(plan.Objects)
+
+	// closure
+	mdeps, err := qobject.GetDependenciesDeep(release, plan.Objects...)
+	if err != nil {
+		return nil, err
+	}
+	affected := make(map[string]bool)
+	for _, qpath := range plan.Changed {
+		affected[qpath] = true
+	}
+	for _, deps := range mdeps {
+		for _, dep := range deps {
+			if strings.HasPrefix(dep, "/") {
+				affected[dep] = true
+			}
+		}
+	}
+	qps := make([]string, 0, len(affected))
+	for qpath := range affected {
+		qps = append(qps, qpath)
+	}
+	sort.Strings(qps)
+
+	// classification
+	projects := make(map[string]bool)
+	for _, qpath := range qps {
+		source := sources[qpath]
+		if source == nil {
+			source, err = Source{}.New(r, qpath, true)
+			if err != nil {
+				return nil, err
+			}
+			sources[qpath] = source
+		}
+		project := source.Project()
+		if project == nil || (plan.Installable && project.IsInstallable() != nil) {
+			plan.Skipped = append(plan.Skipped, qpath)
+			continue
+		}
+		changed := contains(plan.Changed, qpath)
+		if changed {
+			projects[project.String()] = true
+		}
+		natures := source.Natures()
+		switch {
+		case natures["install"]:
+			projects[project.String()] = true
+		case natures["release"] && changed:
+			plan.Releasefiles = append(plan.Releasefiles, qpath)
+		case natures["auto"] && path.Ext(qpath) == ".m":
+			plan.Routines = append(plan.Routines, qpath)
+		case natures["auto"] && path.Ext(qpath) == ".x":
+			plan.Widgets = append(plan.Widgets, qpath)
+		case natures["auto"] && (path.Ext(qpath) == ".b" || path.Ext(qpath) == ".l"):
+			plan.Autofiles = append(plan.Autofiles, qpath)
+		case changed:
+			// deployed by install.py
+		default:
+			plan.Skipped = append(plan.Skipped, qpath)
+		}
+	}
+	for project := range projects {
+		plan.Projects = append(plan.Projects, project)
+	}
+	sort.Strings(plan.Projects)
+	return plan, nil
+}
+
+// Execute runs the incremental installation
+func (plan *InstallPlan) Execute(warnings bool, logme *log.Logger, badsources map[string]bool) (err error) {
+	if !plan.Installable {
+		return nil
+	}
+	inprojs := make(map[string]bool)
+	qpaths := make([]string, 0)
+	for _, project := range plan.Projects {
+		inprojs[project] = true
+		qpaths = append(qpaths, project+"/install.py")
+	}
+	qpaths = append(qpaths, plan.Releasefiles...)
+	qpaths = append(qpaths, plan.Routines...)
+	qpaths = append(qpaths, plan.Autofiles...)
+	qpaths = append(qpaths, plan.Widgets...)
+
+	sources := make([]*Source, 0, len(qpaths))
+	for _, qpath := range qpaths {
+		source, e := Source{}.New(plan.Release, qpath, true)
+		if e != nil {
+			continue
+		}
+		if ok, _ := source.Release().FS().Exists(qpath); !ok {
+			continue
+		}
+		sources = append(sources, source)
+	}
+	return install(plan.Batch, sources, inprojs, warnings, logme, badsources)
+}
+
+// contains tells if s is an element of the sorted list
+func contains(list []string, s string) bool {
+	i := sort.SearchStrings(list, s)
+	return i < len(list) && list[i] == s
+}
@@ -0,0 +1,77 @@
+package source
+
+import (
+	"strings"
+	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+)
+
+func TestPlanInstall01(t *testing.T) {
+	r := "9.91"
+	proj := "/a/b/c"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+
+	fdata := func(p string) ([]byte, error) {
+		switch p {
+		case proj + "/acat.d":
+			return dfile1(), nil
+		case proj + "/zcat.m":
+			return []byte("zcat\t; About: test\n\tm4_getCatGenStatus(.RA,\"c:lvd:1\")\n\tq\n"), nil
+		case proj + "/other.m":
+			return []byte("other\t; About: test\n\tq\n"), nil
+		case proj + "/install.py":
+			return []byte("# m4_setCatGenStatus(1,2,3)\n"), nil
+		default:
+			return []byte("m4_setCatGenStatus(1,2,3)"), nil
+		}
+	}
+	fmeta := func(p string) qmeta.Meta { return qmeta.Meta{} }
+	qpaths := []string{proj + "/acat.d", proj + "/zcat.m", proj + "/other.m", proj + "/my.txt", proj + "/install.py"}
+	_, errs := StoreList("install", r, qpaths, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Errorf(errs.Error())
+		return
+	}
+
+	// all objects of acat.d
+	plan, err := PlanInstall("plan", r, []string{proj + "/acat.d"}, nil)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if plan.Installable {
+		t.Errorf("%s should not be installable", r)
+	}
+	if strings.Join(plan.Objects, " ") != "m4_getCatGenStatus m4_setCatGenStatus" {
+		t.Errorf("Wrong objects: %v", plan.Objects)
+	}
+	if strings.Join(plan.Routines, " ") != proj+"/zcat.m" {
+		t.Errorf("Wrong routines: %v", plan.Routines)
+	}
+	if strings.Join(plan.Projects, " ") != proj {
+		t.Errorf("install.py should be executed: %v", plan.Projects)
+	}
+	if strings.Join(plan.Skipped, " ") != proj+"/my.txt" {
+		t.Errorf("Wrong skipped: %v", plan.Skipped)
+	}
+
+	// only m4_getCatGenStatus changed
+	plan, _ = PlanInstall("plan", r, []string{proj + "/acat.d", proj + "/other.m"}, []string{"m4_getCatGenStatus"})
+	if strings.Join(plan.Routines, " ") != proj+"/other.m "+proj+"/zcat.m" {
+		t.Errorf("Wrong routines: %v", plan.Routines)
+	}
+	if strings.Join(plan.Projects, " ") != proj {
+		t.Errorf("install.py should be executed: %v", plan.Projects)
+	}
+
+	// only a data file changed
+	plan, _ = PlanInstall("plan", r, []string{proj + "/my.txt"}, []string{})
+	if len(plan.Routines) != 0 || len(plan.Skipped) != 0 {
+		t.Errorf("Nothing but install.py should be executed: %v", plan)
+	}
+	if strings.Join(plan.Projects, " ") != proj {
+		t.Errorf("install.py should deploy the data file: %v", plan.Projects)
+	}
+}
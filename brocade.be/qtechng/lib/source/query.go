@@ -42,6 +42,7 @@ type Query struct {
 	Mumps          bool                                     `json:"mumps"`
 	Installable    bool                                     `json:"installable"`
 	Rooted         bool                                     `json:"rooted"`
+	Expr           string                                   `json:"expr"`
 	Any            [](func(qpath string, blob []byte) bool) `json:"_any"`
 	All            [](func(qpath string, blob []byte) bool) `json:"_all"`
 	regexp         []*regexp.Regexp
@@ -53,6 +54,7 @@ type Query struct {
 	muid           map[string]bool
 	cuid           map[string]bool
 	pipe           []func(source *Source) bool
+	index          map[string]*IndexEntry
 }
 
 // SQuery tussenliggend formaat
@@ -78,6 +80,7 @@ type SQuery struct {
 	Mumps          bool     `json:"mumps"`
 	Installable    bool     `json:"installable"`
 	Rooted         bool     `json:"rooted"`
+	Expr           string   `json:"expr"`
 }
 
 // Copy SQuery simple query
@@ -103,6 +106,7 @@ func (squery SQuery) Copy() (query Query) {
 	query.Mumps = squery.Mumps
 	query.Installable = squery.Installable
 	query.Rooted = squery.Rooted
+	query.Expr = squery.Expr
 	return
 }
 
@@ -364,23 +368,26 @@ func (query *Query) Harmonise() {
 		pipe = append(pipe, f)
 	}
 
-	if query.CmpRelease == "" && len(query.Contains) == 0 && len(query.regexp) == 0 && len(query.Any) == 0 && len(query.All) == 0 {
+	if query.CmpRelease == "" && len(query.Contains) == 0 && len(query.regexp) == 0 && len(query.Any) == 0 && len(query.All) == 0 && query.Expr == "" {
 		query.pipe = pipe
 		return
 	}
 
 	if query.CmpRelease != "" {
 		f := func(source *Source) bool {
-			s, _ := Source{}.New(source.String(), query.CmpRelease, false)
-			blobs, errs := s.Fetch()
 			blob, err := source.Fetch()
+			s, errs := Source{}.New(query.CmpRelease, source.String(), true)
+			if errs != nil {
+				return err == nil
+			}
+			blobs, errs := s.Fetch()
 			if errs != nil && err != nil {
 				return false
 			}
 			if errs != nil || err != nil {
 				return true
 			}
-			return bytes.Equal(blob, blobs)
+			return !bytes.Equal(blob, blobs)
 		}
 		pipe = append(pipe, f)
 	}
@@ -501,6 +508,18 @@ func (query *Query) Harmonise() {
 		pipe = append(pipe, f)
 	}
 
+	// expression
+	if query.Expr != "" {
+		expr, err := ParseExpr(query.Expr)
+		f := func(source *Source) bool {
+			if err != nil {
+				return false
+			}
+			return expr == nil || expr.Test(source, query.ToLower)
+		}
+		pipe = append(pipe, f)
+	}
+
 	query.pipe = pipe
 }
 
@@ -610,6 +629,10 @@ func (query *Query) Run() []*Source {
 		qpaths = append(qpaths, ps...)
 	}
 
+	// the index narrows the candidates: the pipeline verifies
+	qpaths = query.narrow(release, qpaths)
+	sures = query.narrow(release, sures)
+
 	f2 := func(n int) (interface{}, error) {
 		qpath := qpaths[n]
 		source, e := Source{}.New(release.String(), qpath, true)
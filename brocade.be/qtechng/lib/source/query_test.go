@@ -525,8 +525,7 @@ func TestQuery05(t *testing.T) {
 }
 
 func makeqRelease(r string) (release *qserver.Release, project *qproject.Project) {
-	release, _ = qserver.Release{}.New(r, false)
-	release.FS("/").RemoveAll("/")
+	release = removeRelease(r)
 	err := release.Init()
 	if err != nil {
 		fmt.Println("error server:", err)
@@ -550,3 +549,24 @@ func makeqRelease(r string) (release *qserver.Release, project *qproject.Project
 	}
 	return
 }
+
+// removeRelease removes a release and forgets its cached sources and projects
+func removeRelease(r string) *qserver.Release {
+	release, _ := qserver.Release{}.New(r, false)
+	projects, _ := qproject.List(r, nil)
+	release.FS("/").RemoveAll("/")
+	prefix := release.String() + " "
+	sourceCache.Range(func(key, value interface{}) bool {
+		if strings.HasPrefix(key.(string), prefix) {
+			sourceCache.Delete(key)
+		}
+		return true
+	})
+	for _, proj := range projects {
+		project, err := qproject.Project{}.New(r, proj, false)
+		if err == nil {
+			project.Unlink()
+		}
+	}
+	return release
+}
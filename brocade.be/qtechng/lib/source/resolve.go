@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	qregistry "brocade.be/base/registry"
+	qerror "brocade.be/qtechng/lib/error"
 	qofile "brocade.be/qtechng/lib/file/ofile"
 	qobject "brocade.be/qtechng/lib/object"
 	qutil "brocade.be/qtechng/lib/util"
@@ -94,6 +96,11 @@ func (source *Source) Resolve(what string, objectmap map[string]qobject.Object,
 	if textmap == nil {
 		textmap = make(map[string]string)
 	}
+	err = source.CheckMacroCalls(body, objectmap)
+	if err != nil {
+		buffer.Write(body)
+		return err
+	}
 
 	if decomment {
 		body = qutil.Decomment(body).Bytes()
@@ -114,6 +121,11 @@ func (source *Source) Resolve(what string, objectmap map[string]qobject.Object,
 
 // ResolveText vervangt in een byte slice - geassocieerd met een bestand
 func ResolveText(env map[string]string, body []byte, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string) (lastlgalgo string, err error) {
+	return resolveText(env, body, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath, nil)
+}
+
+// resolveText is ResolveText with an optional trace of the expansions
+func resolveText(env map[string]string, body []byte, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string, trace *resolveTrace) (lastlgalgo string, err error) {
 	lastlgalgo = lgalgo
 	if what == "" {
 		what = "rlmit"
@@ -122,7 +134,7 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 		what = strings.ReplaceAll(what, "t", "")
 	}
 	if len(what) == 0 || !bytes.Contains(body, []byte("4_")) {
-		buffer.Write(body)
+		trace.write(buffer, body, body, 0, 1)
 		return
 	}
 
@@ -133,7 +145,7 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 	m4y := strings.Contains(what, "m")
 	l4y := strings.Contains(what, "l")
 	if !r4y && !i4y && !m4y && !l4y && !t4y {
-		buffer.Write(body)
+		trace.write(buffer, body, body, 0, 1)
 		return
 	}
 	r := env["%version"]
@@ -224,15 +236,21 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 
 	written := false
 	check = true
+	lineno := 1
+	offset := 0
 	for i, piece := range split {
+		at, pos := lineno, offset
+		lineno += bytes.Count(piece, []byte("\n"))
+		offset += len(piece)
 		check = !check
 		if !check {
 			if !written {
-				buffer.Write(piece)
+				trace.write(buffer, piece, body, pos, at)
 			}
 			written = false
 			continue
 		}
+		trace.markAt(at, body, pos)
 		spiece := string(piece)
 		if skip[spiece] {
 			buffer.Write(piece)
@@ -290,7 +308,9 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 		}
 		// i4
 		if i4y && strings.HasPrefix(spiece, "i4_") {
-			err = i4ResolveText(env, spiece, what, notreplace, objectmap, textmap, buffer, "", qpath)
+			trace.push(spiece, at)
+			err = i4ResolveText(env, spiece, what, notreplace, objectmap, textmap, buffer, "", qpath, trace)
+			trace.pop()
 			if err != nil {
 				return
 			}
@@ -298,7 +318,9 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 		}
 		// t4
 		if t4y && strings.HasPrefix(spiece, "t4_") {
-			err = t4ResolveText(env, spiece, what, notreplace, objectmap, textmap, buffer, "", qpath)
+			trace.push(spiece, at)
+			err = t4ResolveText(env, spiece, what, notreplace, objectmap, textmap, buffer, "", qpath, trace)
+			trace.pop()
 			if err != nil {
 				return
 			}
@@ -306,7 +328,9 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 		}
 		// l4
 		if l4y && strings.HasPrefix(spiece, "l4_") {
-			lgalgo, err = l4ResolveText(env, spiece, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath)
+			trace.push(spiece, at)
+			lgalgo, err = l4ResolveText(env, spiece, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath, trace)
+			trace.pop()
 			if err != nil {
 				return
 			}
@@ -314,7 +338,9 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 		}
 		// m4
 		if m4y && strings.HasPrefix(spiece, "m4_") {
-			err = m4ResolveText(env, spiece, string(split[i+1]), what, notreplace, objectmap, textmap, buffer, "", qpath)
+			trace.push(spiece, at)
+			err = m4ResolveText(env, spiece, string(split[i+1]), what, notreplace, objectmap, textmap, buffer, "", qpath, trace)
+			trace.pop()
 			if err != nil {
 				return
 			}
@@ -327,23 +353,23 @@ func ResolveText(env map[string]string, body []byte, what string, notreplace []s
 }
 
 // handles i4
-func i4ResolveText(env map[string]string, include string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string) (err error) {
+func i4ResolveText(env map[string]string, include string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string, trace *resolveTrace) (err error) {
 	object := objectmap[include].(*qofile.Include)
 	content := []byte(object.Content)
 
-	_, err = ResolveText(env, content, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath)
+	_, err = resolveText(env, content, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath, trace)
 	return err
 }
 
 // handles t4
-func t4ResolveText(env map[string]string, text string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string) (err error) {
+func t4ResolveText(env map[string]string, text string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string, trace *resolveTrace) (err error) {
 	content := []byte(textmap[text[3:]])
-	_, err = ResolveText(env, content, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath)
+	_, err = resolveText(env, content, what, notreplace, objectmap, textmap, buffer, lgalgo, qpath, trace)
 	return err
 }
 
 // handles l4
-func l4ResolveText(env map[string]string, lgcode string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lastlgalgo string, qpath string) (lgalgo string, err error) {
+func l4ResolveText(env map[string]string, lgcode string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lastlgalgo string, qpath string, trace *resolveTrace) (lgalgo string, err error) {
 	obj := lgcode
 	if strings.HasPrefix(obj, "l4_") && strings.Count(obj, "_") == 1 {
 		parts := strings.SplitN(obj, "_", 2)
@@ -359,12 +385,12 @@ func l4ResolveText(env map[string]string, lgcode string, what string, notreplace
 	what = strings.ReplaceAll(what, "m", "")
 	what = strings.ReplaceAll(what, "i", "")
 	what = strings.ReplaceAll(what, "t", "")
-	lgalgo, err = ResolveText(env, []byte(content), what, notreplace, objectmap, textmap, buffer, lgalgo, qpath)
+	lgalgo, err = resolveText(env, []byte(content), what, notreplace, objectmap, textmap, buffer, lgalgo, qpath, trace)
 	return
 }
 
 // handles m4
-func m4ResolveText(env map[string]string, macro string, extra string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string) (err error) {
+func m4ResolveText(env map[string]string, macro string, extra string, what string, notreplace []string, objectmap map[string]qobject.Object, textmap map[string]string, buffer *bytes.Buffer, lgalgo string, qpath string, trace *resolveTrace) (err error) {
 	obj := macro
 	object := objectmap[obj].(*qofile.Macro)
 	args, rest, err := object.Args(extra, qpath)
@@ -384,8 +410,151 @@ func m4ResolveText(env map[string]string, macro string, extra string, what strin
 
 	calc := object.Replacer(envex, "")
 	what = strings.ReplaceAll(what, "i", "")
+	trace.guard(object, envex)
+	trace.tail(len(calc), strings.Count(extra[:len(extra)-len(rest)], "\n"))
 
-	_, err = ResolveText(env, []byte(calc+rest), what, notreplace, objectmap, textmap, buffer, lgalgo, qpath)
+	_, err = resolveText(env, []byte(calc+rest), what, notreplace, objectmap, textmap, buffer, lgalgo, qpath, trace)
 
 	return err
 }
+
+// CheckMacroCalls checks the calls of macros in the body of the source against the signature
+// of the macros: the number and the names of the arguments and the types of the parameters.
+// Arguments of type `lgcode` should exist in the release.
+// The errors point to the line of the call in the source.
+func (source *Source) CheckMacroCalls(body []byte, objectmap map[string]qobject.Object) error {
+	if !bytes.Contains(body, []byte("m4_")) {
+		return nil
+	}
+	if objectmap == nil {
+		objectmap = make(map[string]qobject.Object)
+	}
+	r := source.Release().String()
+	qpath := source.String()
+	split := qutil.ObjectSplitter(macroCallText(qpath, body))
+
+	macros := make([]qobject.Object, 0)
+	for i, piece := range split {
+		name := string(piece)
+		if i%2 == 0 || !strings.HasPrefix(name, "m4_") || objectmap[name] != nil {
+			continue
+		}
+		macro := new(qofile.Macro)
+		macro.SetRelease(r)
+		macro.SetName(name[3:])
+		macros = append(macros, macro)
+	}
+	for k, v := range qobject.FetchList(macros) {
+		objectmap[k] = v
+	}
+
+	type lgcall struct {
+		lineno int
+		macro  string
+		param  string
+	}
+	lgcalls := make(map[string][]lgcall)
+	errs := make([]error, 0)
+	lineno := 1
+	for i, piece := range split {
+		at := lineno
+		lineno += bytes.Count(piece, []byte("\n"))
+		if i%2 == 0 || !bytes.HasPrefix(piece, []byte("m4_")) {
+			continue
+		}
+		macro, ok := objectmap[string(piece)].(*qofile.Macro)
+		if !ok || macro == nil {
+			continue
+		}
+		extra := ""
+		if i+1 < len(split) {
+			extra = string(split[i+1])
+		}
+		args, _, err := macro.Args(extra, qpath)
+		if err != nil {
+			if e, ok := err.(*qerror.QError); ok {
+				e.Version = r
+				e.Lineno = at
+			}
+			errs = append(errs, err)
+			continue
+		}
+		for _, msg := range macro.CheckArgs(args) {
+			errs = append(errs, &qerror.QError{
+				Ref:     []string{"source.macro.type"},
+				Version: r,
+				QPath:   qpath,
+				Lineno:  at,
+				Object:  macro.String(),
+				Type:    "Error",
+				Msg:     []string{msg},
+			})
+		}
+		for _, param := range macro.Params {
+			value := strings.TrimSpace(args[param.ID])
+			if param.Type != "lgcode" || value == "" || param.CheckValue(value) != "" {
+				continue
+			}
+			name := "l4_" + strings.TrimPrefix(value, "l4_")
+			lgcalls[name] = append(lgcalls[name], lgcall{at, macro.String(), param.ID})
+		}
+	}
+
+	if len(lgcalls) != 0 {
+		lgcodes := make([]qobject.Object, 0, len(lgcalls))
+		for name := range lgcalls {
+			lgcode := new(qofile.Lgcode)
+			lgcode.SetRelease(r)
+			lgcode.SetName(name[3:])
+			lgcodes = append(lgcodes, lgcode)
+		}
+		found := qobject.FetchList(lgcodes)
+		for name, calls := range lgcalls {
+			if found[name] != nil {
+				continue
+			}
+			for _, call := range calls {
+				errs = append(errs, &qerror.QError{
+					Ref:     []string{"source.macro.lgcode"},
+					Version: r,
+					QPath:   qpath,
+					Lineno:  call.lineno,
+					Object:  call.macro,
+					Type:    "Error",
+					Msg:     []string{"Parameter `" + call.param + "` of " + call.macro + ": lgcode `" + name + "` does not exist"},
+				})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*qerror.QError).Lineno < errs[j].(*qerror.QError).Lineno
+	})
+	return qerror.ErrorSlice(errs)
+}
+
+// macroCallText blanks the `//` comments in the body: they are not resolved.
+// The lines are kept in place.
+func macroCallText(qpath string, body []byte) []byte {
+	lines := bytes.Split(append([]byte{}, body...), []byte("\n"))
+	for i, line := range lines {
+		code := line
+		switch {
+		case bytes.HasPrefix(bytes.TrimSpace(line), []byte("//")):
+			code = nil
+		case strings.HasSuffix(qpath, ".m"):
+			c, comment := mdecomment(line)
+			if len(comment) != 0 && comment[0] == byte('/') {
+				code = c
+			}
+		case strings.HasSuffix(qpath, ".x"):
+			code, _ = xdecomment(line)
+		}
+		if len(code) != len(line) {
+			lines[i] = append(code[:len(code):len(code)], bytes.Repeat([]byte(" "), len(line)-len(code))...)
+		}
+	}
+	return bytes.Join(lines, []byte("\n"))
+}
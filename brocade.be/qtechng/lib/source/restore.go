@@ -0,0 +1,151 @@
+package source
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/hexops/gotextdiff"
+	"github.com/hexops/gotextdiff/myers"
+	"github.com/hexops/gotextdiff/span"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// Restoration reports the restore of a source to an earlier state
+type Restoration struct {
+	Release  string               `json:"version"`
+	QPath    string               `json:"qpath"`
+	At       string               `json:"at"`
+	Commit   *qserver.GitRevision `json:"commit"`
+	Revision *qserver.GitRevision `json:"revision"`
+	Action   string               `json:"action"`
+	Meta     *qmeta.Meta          `json:"meta"`
+	Diff     string               `json:"diff"`
+}
+
+// Restore brings a source back to its state at `at`: a batch id, a commit or a timestamp.
+// The content is taken from the Git history of the release. The restore itself is
+// a modification: it is stamped with the current time and uid, the restoring user.
+// The source is stored with StoreList, so objects and links are regenerated.
+// The batch id of the restore refers to the restored commit: `restore-<commit>-<timestamp>`.
+// If the source did not exist at that moment, it is removed.
+// With dryrun, nothing is changed: the restoration contains the differences
+// between the current and the restored content.
+func Restore(r string, qpath string, at string, uid string, dryrun bool) (restoration *Restoration, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	r = release.String()
+	qpath = qutil.Canon(qpath)
+	if !release.GitEnabled() {
+		err = &qerror.QError{
+			Ref:     []string{"source.restore.git"},
+			Version: r,
+			QPath:   qpath,
+			Msg:     []string{"Git version control is not enabled"},
+		}
+		return nil, err
+	}
+
+	commit, err := release.GitFind(at)
+	if err != nil {
+		return nil, err
+	}
+	content, revision, err := release.GitShow(commit.Commit, qpath)
+	if err != nil {
+		return nil, err
+	}
+	current, e := release.FS().ReadFile(qpath)
+	exists := e == nil
+	restoration = &Restoration{
+		Release:  r,
+		QPath:    qpath,
+		At:       at,
+		Commit:   commit,
+		Revision: revision,
+	}
+
+	switch {
+	case content == nil && !exists:
+		restoration.Action = "none"
+		return restoration, nil
+	case content == nil:
+		restoration.Action = "delete"
+	case !exists:
+		restoration.Action = "create"
+	case bytes.Equal(content, current):
+		restoration.Action = "none"
+		return restoration, nil
+	default:
+		restoration.Action = "update"
+	}
+
+	name1 := path.Join(r, qpath)
+	name2 := path.Join(r, qpath) + "@" + commit.Commit[:10]
+	if !exists {
+		name1 = "/dev/null"
+	}
+	if content == nil {
+		name2 = "/dev/null"
+	}
+	edits := myers.ComputeEdits(span.URIFromPath(name1), string(current), string(content))
+	restoration.Diff = fmt.Sprint(gotextdiff.ToUnified(name1, name2, string(current), edits))
+
+	if content != nil {
+		restoration.Meta = restoreMeta(release, qpath, uid)
+	}
+	if dryrun {
+		return restoration, nil
+	}
+
+	if content == nil {
+		err = WasteList(r, []string{qpath})
+		return restoration, err
+	}
+	meta := *restoration.Meta
+	if exists {
+		meta.Digest = qutil.Digest(current)
+	}
+	fmeta := func(string) qmeta.Meta { return meta }
+	fdata := func(string) ([]byte, error) { return content, nil }
+	results, err := StoreList("restore-"+commit.Commit[:10], r, []string{qpath}, false, fmeta, fdata, true)
+	if results[qpath] != nil {
+		restoration.Meta = results[qpath]
+	}
+	return restoration, err
+}
+
+// restoreMeta builds the meta information of a restored source: modified now by uid.
+// The creator is the author of the first revision.
+func restoreMeta(release *qserver.Release, qpath string, uid string) *qmeta.Meta {
+	meta := new(qmeta.Meta)
+	meta.Mu = uid
+	meta.Mt = time.Now().Format(time.RFC3339)
+	revisions, _ := release.GitLog(qpath)
+	if len(revisions) != 0 {
+		first := revisions[len(revisions)-1]
+		meta.Cu = first.Author
+		meta.Ct = gitTime(first.Time)
+	}
+	pmeta, _ := qmeta.Meta{}.New(release.String(), qpath)
+	if pmeta != nil && pmeta.Cu != "" {
+		meta.Cu = pmeta.Cu
+		meta.Ct = pmeta.Ct
+	}
+	return meta
+}
+
+// gitTime converts a git timestamp to the format of the meta information
+func gitTime(t string) string {
+	h, err := time.Parse(time.RFC3339, t)
+	if err != nil {
+		return t
+	}
+	return h.Local().Format(time.RFC3339)
+}
@@ -0,0 +1,119 @@
+package source
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	qregistry "brocade.be/base/registry"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+func TestRestore01(t *testing.T) {
+	qregistry.Registry["qtechng-git-enable"] = "1"
+	defer delete(qregistry.Registry, "qtechng-git-enable")
+	r := "9.95"
+	makeqRelease(r)
+	release, _ := qserver.Release{}.New(r, true)
+
+	f1 := "/a/b/f1.txt"
+	f4 := "/a/b/f4.txt"
+	data := map[string]string{
+		f1: "Version 1\n",
+	}
+	mu := "rphilips"
+	fmeta := func(p string) qmeta.Meta {
+		blob, _ := release.FS().ReadFile(p)
+		return qmeta.Meta{Digest: qutil.Digest(blob), Mu: mu}
+	}
+	fdata := func(p string) ([]byte, error) { return []byte(data[p]), nil }
+	_, errs := StoreList("first", r, []string{f1}, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Errorf(errs.Error())
+		return
+	}
+	mu = "mjeuris"
+	data[f1] = "Version 2\n"
+	data[f4] = "New\n"
+	_, errs = StoreList("second", r, []string{f1, f4}, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Errorf(errs.Error())
+		return
+	}
+	revisions, _ := release.GitLog(f1)
+	if len(revisions) < 2 {
+		t.Errorf("Should have at least 2 revisions: %v", revisions)
+		return
+	}
+	at := revisions[1].Batch
+
+	// dry run
+	restoration, err := Restore(r, f1, at, "wverbeke", true)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if restoration.Action != "update" || !strings.Contains(restoration.Diff, "-Version 2") || !strings.Contains(restoration.Diff, "+Version 1") {
+		t.Errorf("Bad dry run: %v", restoration)
+		return
+	}
+	blob, _ := release.FS().ReadFile(f1)
+	if string(blob) != "Version 2\n" {
+		t.Errorf("Dry run should not change `%s`: %s", f1, blob)
+		return
+	}
+
+	// restore
+	start := time.Now().Add(-time.Second).Format(time.RFC3339)
+	_, err = Restore(r, f1, at, "wverbeke", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	blob, _ = release.FS().ReadFile(f1)
+	if string(blob) != "Version 1\n" {
+		t.Errorf("`%s` should be restored: %s", f1, blob)
+	}
+	meta, _ := qmeta.Meta{}.New(r, f1)
+	if meta.Mu != "wverbeke" {
+		t.Errorf("Restore of `%s` should be a modification by the restoring user: %v", f1, meta)
+	}
+	if meta.Mt < start {
+		t.Errorf("Restore of `%s` should be stamped with the current time: %v", f1, meta)
+	}
+	restored, _ := release.GitFind(at)
+	revisions, _ = release.GitLog(f1)
+	if len(revisions) == 0 || !strings.HasPrefix(revisions[0].Batch, "restore-"+restored.Commit[:10]+"-") {
+		t.Errorf("Restore should refer to the restored commit: %v", revisions)
+	}
+
+	// did not exist
+	restoration, err = Restore(r, f4, at, "wverbeke", false)
+	if err != nil {
+		t.Errorf(err.Error())
+		return
+	}
+	if restoration.Action != "delete" {
+		t.Errorf("`%s` should be deleted: %v", f4, restoration)
+	}
+	if ok, _ := release.FS().Exists(f4); ok {
+		t.Errorf("`%s` should not exist", f4)
+	}
+
+	// ambiguous batch id
+	batchid := "install-2021-10-18"
+	for _, content := range []string{"Version 3\n", "Version 4\n"} {
+		data[f1] = content
+		_, errs = StoreList(batchid, r, []string{f1}, false, fmeta, fdata, false)
+		if errs != nil {
+			t.Errorf(errs.Error())
+			return
+		}
+	}
+	_, err = Restore(r, f1, batchid, "wverbeke", true)
+	if err == nil || !strings.Contains(err.Error(), "more than one") {
+		t.Errorf("Batch id `%s` should be ambiguous: %v", batchid, err)
+	}
+}
@@ -151,6 +151,15 @@ func (source *Source) Fetch() (content []byte, err error) {
 	return source.blob, nil
 }
 
+// WithContent returns a copy of the source with other content.
+// The copy is not cached: it is used to check content which is not stored (yet).
+func (source *Source) WithContent(body []byte) *Source {
+	copied := *source
+	copied.blob = body
+	copied.natures = nil
+	return &copied
+}
+
 // UnlinkObjects removes all objects associated with the file
 func (source Source) UnlinkObjects() {
 	natures := source.Natures()
@@ -265,6 +274,9 @@ func (source *Source) Waste() (err error) {
 
 	// Unlink 'unique'
 	version.UniqueUnlink(s)
+
+	// Unlink index
+	source.UnlinkIndex()
 	// Cache
 	pid := r + " " + s
 	sourceCache.Delete(pid)
@@ -279,142 +291,28 @@ func (source *Source) Store(meta qmeta.Meta, data interface{}, reset bool) (nmet
 	// hebben we te maken met een configuratiefile ?
 	s := source.String()
 	natures := source.Natures()
-	// Valid configuration file
-	if natures["config"] {
-		b, e := qutil.MakeBytes(data)
-		if e != nil {
-			err = &qerror.QError{
-				Ref:     []string{"source.store.config.bytes"},
-				Version: version.String(),
-				QPath:   source.String(),
-				Msg:     []string{"Cannot transform to bytes: `" + e.Error() + "`"},
-			}
-			return nmeta, false, chobjs, err
-		}
-		if !qproject.IsValidConfig(b) {
-			err = &qerror.QError{
-				Ref:     []string{"source.store.config.invalid"},
-				Version: version.String(),
-				QPath:   source.String(),
-				Msg:     []string{"Not a valid configuration file"},
-			}
-			return nmeta, false, chobjs, err
-		}
-		cfg := qproject.Config{}
-		json.Unmarshal(b, &cfg)
-		source.project.UpdateConfig(cfg)
-		qdir, _ := qutil.QPartition(s)
-		updateCache(version.String(), qdir)
-	}
-
-	// unique
-	ext := filepath.Ext(s)
-	uniques := strings.SplitN(qregistry.Registry["qtechng-unique-ext"], " ", -1)
-	unique := true
-	for _, unq := range uniques {
-		if unq == ext {
-			unique = false
-			break
-		}
-	}
-	if !unique && IsUnique(version, s) {
-		unique = true
-	}
-	if !unique {
-		config, _ := source.project.LoadConfig()
-		notuniques := config.NotUnique
-		if len(notuniques) > 0 {
-			relpath := s[len(source.project.String()):]
-			for _, nu := range notuniques {
-				if qfnmatch.Match(nu, relpath) {
-					unique = true
-					break
-				}
-			}
-		}
-	}
-	if !unique {
+	fdata, e := qutil.MakeBytes(data)
+	if e != nil {
 		err = &qerror.QError{
-			Ref:     []string{"source.store.notunique"},
+			Ref:     []string{"source.store.bytes"},
 			Version: version.String(),
 			QPath:   source.String(),
-			Msg:     []string{"Is not unique"},
+			Msg:     []string{"Cannot transform to bytes: `" + e.Error() + "`"},
 		}
 		return nmeta, false, chobjs, err
 	}
-
-	fdata, err := qutil.MakeBytes(data)
+	objfile, err := source.check(fdata)
 	if err != nil {
 		return nmeta, false, chobjs, err
 	}
-	var objfile qobject.OFile
-	ok := natures["objectfile"]
-	if ok {
-		switch {
-		case natures["dfile"]:
-			objfile = new(qofile.DFile)
-		case natures["ifile"]:
-			objfile = new(qofile.IFile)
-		case natures["lfile"]:
-			objfile = new(qofile.LFile)
-		}
-		objfile.SetEditFile(source.String())
-		objfile.SetRelease(version.String())
-		err = qobject.Loads(objfile, fdata, true)
-		if err != nil {
-			e := &qerror.QError{
-				Ref:     []string{"source.store.load.object"},
-				Version: version.String(),
-				QPath:   source.String(),
-				Msg:     []string{"Cannot load objects"},
-			}
-			err = qerror.QErrorTune(err, e)
-			return
-		}
-	}
-
-	var content []byte
-	if ok {
-		content, _ = source.Fetch()
-	}
-
-	if ok {
-
-		notdel, notadd, err1 := source.checkOrphanObjects(content, fdata)
-		if err1 != nil {
-			e := &qerror.QError{
-				Ref:     []string{"source.store.check.object"},
-				Version: version.String(),
-				QPath:   source.String(),
-				Msg:     []string{"Problems on checking the objects"},
-			}
-			err = qerror.QErrorTune(err1, e)
-			return
-		}
-		if len(notdel) != 0 {
-			err = &qerror.QError{
-				Ref:     []string{"source.store.orphan.object"},
-				Version: version.String(),
-				QPath:   source.String(),
-				Msg:     []string{"These objects cannot be removed: " + strings.Join(notdel, ", ")},
-			}
-			return
-		}
-		if len(notadd) != 0 {
-			rest := make([]string, len(notadd))
-			i := -1
-			for k, v := range notadd {
-				i++
-				rest[i] = k + " (" + v + ")"
-			}
-			err = &qerror.QError{
-				Ref:     []string{"source.store.orphan.object"},
-				Version: version.String(),
-				QPath:   source.String(),
-				Msg:     []string{"These objects exist already: " + strings.Join(rest, ", ")},
-			}
-			return
-		}
+	// Valid configuration file
+	if natures["config"] {
+		cfg := qproject.Config{}
+		json.Unmarshal(fdata, &cfg)
+		source.project.UpdateConfig(cfg)
+		qdir, _ := qutil.QPartition(s)
+		updateCache(version.String(), qdir)
+		invalidateIndex(version.String(), qdir)
 	}
 
 	// meta object
@@ -491,6 +389,9 @@ func (source *Source) Store(meta qmeta.Meta, data interface{}, reset bool) (nmet
 		}
 	}
 
+	// index
+	source.UpdateIndex()
+
 	if !natures["objectfile"] {
 		return
 	}
@@ -523,6 +424,132 @@ func (source *Source) Store(meta qmeta.Meta, data interface{}, reset bool) (nmet
 	return nmeta, changed, changedmap, errslice
 }
 
+// check verifies if data can be stored in the source: valid configuration,
+// uniqueness and the objects it defines. It does not change anything on disk.
+func (source *Source) check(fdata []byte) (objfile qobject.OFile, err error) {
+	version := source.Release()
+	s := source.String()
+	natures := source.Natures()
+	if natures["config"] && !qproject.IsValidConfig(fdata) {
+		err = &qerror.QError{
+			Ref:     []string{"source.store.config.invalid"},
+			Version: version.String(),
+			QPath:   s,
+			Msg:     []string{"Not a valid configuration file"},
+		}
+		return nil, err
+	}
+
+	// unique
+	ext := filepath.Ext(s)
+	uniques := strings.SplitN(qregistry.Registry["qtechng-unique-ext"], " ", -1)
+	unique := true
+	for _, unq := range uniques {
+		if unq == ext {
+			unique = false
+			break
+		}
+	}
+	if !unique && IsUnique(version, s) {
+		unique = true
+	}
+	if !unique {
+		config, _ := source.project.LoadConfig()
+		notuniques := config.NotUnique
+		if len(notuniques) > 0 {
+			relpath := s[len(source.project.String()):]
+			for _, nu := range notuniques {
+				if qfnmatch.Match(nu, relpath) {
+					unique = true
+					break
+				}
+			}
+		}
+	}
+	if !unique {
+		err = &qerror.QError{
+			Ref:     []string{"source.store.notunique"},
+			Version: version.String(),
+			QPath:   s,
+			Msg:     []string{"Is not unique"},
+		}
+		return nil, err
+	}
+
+	if !natures["objectfile"] {
+		return nil, nil
+	}
+	objfile, err = source.objectFile(fdata)
+	if err != nil {
+		e := &qerror.QError{
+			Ref:     []string{"source.store.load.object"},
+			Version: version.String(),
+			QPath:   s,
+			Msg:     []string{"Cannot load objects"},
+		}
+		return nil, qerror.QErrorTune(err, e)
+	}
+
+	content, _ := source.Fetch()
+	notdel, notadd, err := source.checkOrphanObjects(content, fdata)
+	if err != nil {
+		e := &qerror.QError{
+			Ref:     []string{"source.store.check.object"},
+			Version: version.String(),
+			QPath:   s,
+			Msg:     []string{"Problems on checking the objects"},
+		}
+		return nil, qerror.QErrorTune(err, e)
+	}
+	if len(notdel) != 0 {
+		err = &qerror.QError{
+			Ref:     []string{"source.store.orphan.object"},
+			Version: version.String(),
+			QPath:   s,
+			Msg:     []string{"These objects cannot be removed: " + strings.Join(notdel, ", ")},
+		}
+		return nil, err
+	}
+	if len(notadd) != 0 {
+		rest := make([]string, len(notadd))
+		i := -1
+		for k, v := range notadd {
+			i++
+			rest[i] = k + " (" + v + ")"
+		}
+		err = &qerror.QError{
+			Ref:     []string{"source.store.orphan.object"},
+			Version: version.String(),
+			QPath:   s,
+			Msg:     []string{"These objects exist already: " + strings.Join(rest, ", ")},
+		}
+		return nil, err
+	}
+	return objfile, nil
+}
+
+// objectFile loads the objects defined in data
+func (source *Source) objectFile(data []byte) (objfile qobject.OFile, err error) {
+	natures := source.Natures()
+	switch {
+	case natures["dfile"]:
+		objfile = new(qofile.DFile)
+	case natures["ifile"]:
+		objfile = new(qofile.IFile)
+	case natures["lfile"]:
+		objfile = new(qofile.LFile)
+	default:
+		return nil, nil
+	}
+	objfile.SetEditFile(source.String())
+	objfile.SetRelease(source.Release().String())
+	err = qobject.Loads(objfile, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return objfile, nil
+}
+
 // Neighbours add all sources from the same project
 func (source *Source) Neighbours() []*Source {
 	project := source.project
@@ -658,7 +685,11 @@ type storeeffect struct {
 	chobjs map[string]bool
 }
 
-// StoreList creates a list of projects.
+// StoreList stores a list of sources.
+// Unless reset is true, the list is stored as a whole: the previous state is kept in a journal,
+// all sources are validated (configuration, uniqueness, digest, objects) and
+// either all changes are committed or all are rolled back.
+// Journals left behind by a crashed process are rolled back first.
 func StoreList(batchid string, version string, paths []string, reset bool, fmeta func(string) qmeta.Meta, fdata func(string) ([]byte, error), warnings bool) (results map[string]*qmeta.Meta, errs error) {
 	if batchid == "" {
 		batchid = "install"
@@ -694,6 +725,24 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 	results = make(map[string]*qmeta.Meta)
 	oresults := make(map[string]map[string]bool)
 
+	// journal
+
+	var journal *Journal
+	if !reset {
+		journal, err = NewJournal(release.String(), batchid, paths)
+		if err != nil {
+			return results, err
+		}
+	}
+	rollback := func(errs error) error {
+		results = make(map[string]*qmeta.Meta)
+		e := journal.Rollback()
+		if e != nil {
+			return qerror.ErrorSlice{errs, e}
+		}
+		return errs
+	}
+
 	// Handle configuration files first
 
 	configs := make([]string, 0)
@@ -709,6 +758,7 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 	}
 
 	work := make([]string, 0)
+	blobs := make(map[string][]byte)
 
 	fn := func(n int) (interface{}, error) {
 		p := work[n]
@@ -717,10 +767,13 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 			return nil, err
 		}
 		var met qmeta.Meta
-		blob, e := fdata(p)
-
-		if e != nil {
-			return nil, e
+		blob, ok := blobs[p]
+		if !ok {
+			var e error
+			blob, e = fdata(p)
+			if e != nil {
+				return nil, e
+			}
 		}
 		if !reset {
 			met = fmeta(p)
@@ -733,6 +786,14 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 	badsources := make(map[string]bool)
 	if len(configs) > 0 {
 		sort.Strings(configs)
+		if journal != nil {
+			errs = checkList(version, configs, fmeta, fdata, blobs)
+			if errs != nil {
+				journal.remove()
+				results = make(map[string]*qmeta.Meta)
+				return
+			}
+		}
 		work = append(work, configs...)
 		resultlist, errorlist := qparallel.NMap(len(configs), 1, fn)
 		for i, r := range resultlist {
@@ -762,11 +823,22 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 			errs = nil
 		} else {
 			errs = qerror.ErrorSlice(errslice)
+			if journal != nil {
+				errs = rollback(errs)
+			}
 			return
 		}
 
 	}
 
+	if journal != nil {
+		errs = checkList(version, notconfigs, fmeta, fdata, blobs)
+		if errs != nil {
+			errs = rollback(errs)
+			return
+		}
+	}
+
 	work = work[:0]
 	work = append(work, notconfigs...)
 	resultlist, errorlist := qparallel.NMap(len(notconfigs), -1, fn)
@@ -789,7 +861,6 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 		p := notconfigs[i]
 		badsources[p] = true
 		errslice = append(errslice, e)
-		errslice = append(errslice, e)
 	}
 	if len(errslice) == 0 {
 		errs = nil
@@ -797,6 +868,29 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 		errs = qerror.ErrorSlice(errslice)
 	}
 
+	if journal != nil {
+		if errs != nil {
+			errs = rollback(errs)
+			return
+		}
+		err = journal.Commit()
+		if err != nil {
+			return results, err
+		}
+		author := ""
+		for _, p := range paths {
+			author = fmeta(p).Mu
+			if author != "" {
+				break
+			}
+		}
+		err = release.GitCommit(batchid, author, paths)
+		if err != nil {
+			errslice = append(errslice, err)
+			errs = qerror.ErrorSlice(errslice)
+		}
+	}
+
 	// installation
 
 	if !release.IsInstallable() {
@@ -820,6 +914,22 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 			objsfound[ob] = true
 		}
 	}
+	if qregistry.Registry["qtechng-install-incremental"] == "1" {
+		qpaths := make([]string, 0, len(sourcesfound))
+		for qp := range sourcesfound {
+			qpaths = append(qpaths, qp)
+		}
+		plan, err := PlanInstall(batchid, version, qpaths, objs)
+		if err == nil {
+			err = plan.Execute(warnings, nil, badsources)
+		}
+		if err != nil {
+			errslice = append(errslice, err)
+			errs = qerror.ErrorSlice(errslice)
+		}
+		return
+	}
+
 	mqpaths, err := qobject.GetDependenciesDeep(release, objs...)
 	if err != nil {
 		errs = err
@@ -849,6 +959,90 @@ func StoreList(batchid string, version string, paths []string, reset bool, fmeta
 	return
 }
 
+// checkList validates a list of sources before they are stored:
+// the data, the digest, the configuration, uniqueness and the objects.
+// An object cannot be defined in two sources of the list.
+// The data is kept in blobs.
+func checkList(version string, paths []string, fmeta func(string) qmeta.Meta, fdata func(string) ([]byte, error), blobs map[string][]byte) (errs error) {
+	type checkeffect struct {
+		blob    []byte
+		objects []string
+	}
+	fn := func(n int) (interface{}, error) {
+		p := paths[n]
+		source, err := Source{}.New(version, p, false)
+		if err != nil {
+			return nil, err
+		}
+		blob, err := fdata(p)
+		if err != nil {
+			return nil, err
+		}
+		digest := fmeta(p).Digest
+		if digest == "" {
+			digest = " "
+		}
+		current, e := source.Release().FS().ReadFile(source.String())
+		if e == nil && qutil.Digest(current) != digest {
+			err = &qerror.QError{
+				Ref:     []string{"source.storelist.digest"},
+				Version: version,
+				QPath:   p,
+				Msg:     []string{"Digest does not match: the source was modified in the repository"},
+			}
+			return nil, err
+		}
+		objfile, err := source.check(blob)
+		if err != nil {
+			return nil, err
+		}
+		effect := checkeffect{blob: blob}
+		if objfile != nil {
+			for _, obj := range objfile.Objects() {
+				effect.objects = append(effect.objects, obj.String())
+			}
+		}
+		return effect, nil
+	}
+
+	resultlist, errorlist := qparallel.NMap(len(paths), -1, fn)
+
+	errslice := qerror.NewErrorSlice()
+	for _, e := range errorlist {
+		if e != nil {
+			errslice = append(errslice, e)
+		}
+	}
+	if len(errslice) != 0 {
+		return errslice
+	}
+	defined := make(map[string]string)
+	for i, r := range resultlist {
+		p := paths[i]
+		effect := r.(checkeffect)
+		blobs[p] = effect.blob
+		for _, obj := range effect.objects {
+			other, ok := defined[obj]
+			if !ok {
+				defined[obj] = p
+				continue
+			}
+			err := &qerror.QError{
+				Ref:     []string{"source.storelist.object.conflict"},
+				Version: version,
+				QPath:   p,
+				Object:  obj,
+				Msg:     []string{"Object `" + obj + "` is also defined in `" + other + "`"},
+			}
+			errslice = append(errslice, err)
+		}
+	}
+	if len(errslice) != 0 {
+		return errslice
+	}
+	return nil
+}
+
 // TestForWasteList test of een lijst mag worden geschrapt
 func TestForWasteList(version string, paths []string, added []string, cfgs []string, except map[string]bool) (err error) {
 
@@ -1068,6 +1262,11 @@ func WasteList(version string, paths []string) (errs error) {
 		errslice = append(errslice, e)
 	}
 
+	err = release.GitCommit(qutil.Reference("delete"), "", paths)
+	if err != nil {
+		errslice = append(errslice, err)
+	}
+
 	if len(errslice) != 0 {
 		return errslice
 	}
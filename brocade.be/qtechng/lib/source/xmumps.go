@@ -7,6 +7,7 @@ import (
 	qmumps "brocade.be/base/mumps"
 	qerror "brocade.be/qtechng/lib/error"
 	qofile "brocade.be/qtechng/lib/file/ofile"
+	qxfile "brocade.be/qtechng/lib/file/xfile"
 	qobject "brocade.be/qtechng/lib/object"
 	qutil "brocade.be/qtechng/lib/util"
 )
@@ -28,10 +29,60 @@ func WidgetsListToMumps(batchid string, widgets []*qofile.Widget, buf *bytes.Buf
 
 // XFileToMumps schrijft een M file naar een buffer
 func (xfile *Source) XFileToMumps(batchid string, buf *bytes.Buffer) error {
+	widgets, err := xfile.xWidgets("")
+	if err != nil {
+		return err
+	}
+	errs := WidgetsListToMumps(batchid, widgets, buf)
+	if errs == nil {
+		return nil
+	}
+
+	return qerror.ErrorSlice(errs)
+}
+
+// XFilePreview renders the screens and formats of an X file as a static HTML page.
+// The lgcodes are resolved in language lg: a code, a key or a BCP-47 tag.
+func (xfile *Source) XFilePreview(lg string) (preview []byte, err error) {
+	qpath := xfile.String()
+	if !strings.HasSuffix(qpath, ".x") {
+		err := &qerror.QError{
+			Ref:     []string{"source.preview.xfile"},
+			Version: xfile.Release().String(),
+			QPath:   qpath,
+			Msg:     []string{"Only X files can be previewed"},
+		}
+		return nil, err
+	}
+	language := qutil.LookupLanguage(lg)
+	if language == nil {
+		err := &qerror.QError{
+			Ref:     []string{"source.preview.language"},
+			Version: xfile.Release().String(),
+			QPath:   qpath,
+			Msg:     []string{"Unknown language `" + lg + "`"},
+		}
+		return nil, err
+	}
+	widgets, err := xfile.xWidgets(language.Code)
+	if err != nil {
+		return nil, err
+	}
+	return qxfile.Preview(qpath, language.Tag, widgets), nil
+}
+
+// xWidgets returns the resolved screens and formats of an X file.
+// If lg is not empty, the lgcodes are resolved in the language with code lg.
+func (xfile *Source) xWidgets(lg string) (widgets []*qofile.Widget, err error) {
 	content, err := xfile.Fetch()
 
 	if err != nil {
-		return err
+		return nil, err
+	}
+	objectmap := make(map[string]qobject.Object)
+	err = xfile.CheckMacroCalls(content, objectmap)
+	if err != nil {
+		return nil, err
 	}
 	content = qutil.Decomment(content).Bytes()
 	bufnoc := new(bytes.Buffer)
@@ -60,13 +111,16 @@ func (xfile *Source) XFileToMumps(batchid string, buf *bytes.Buffer) error {
 		bufnoc.WriteRune('\n')
 	}
 	content = bufnoc.Bytes()
+	if lg != "" {
+		content = xlanguage(content, lg)
+	}
 	xf := new(qofile.XFile)
 	xf.SetEditFile(xfile.String())
 	xf.SetRelease(xfile.Release().String())
 	if len(content) != 0 {
 		err = qobject.Loads(xf, content, true)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 	objectlist := xf.Objects()
@@ -81,11 +135,10 @@ func (xfile *Source) XFileToMumps(batchid string, buf *bytes.Buffer) error {
 	}
 	env := xfile.Env()
 	notreplace := xfile.NotReplace()
-	objectmap := make(map[string]qobject.Object)
 	bufmac := new(bytes.Buffer)
 	_, err = ResolveText(env, content, "trilm", notreplace, objectmap, textmap, bufmac, "", xfile.String())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	content = bufmac.Bytes()
 
@@ -105,10 +158,10 @@ func (xfile *Source) XFileToMumps(batchid string, buf *bytes.Buffer) error {
 	content = buffer.Bytes()
 	err = qobject.Loads(xf, content, false)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	objectlist = xf.Objects()
-	widgets := make([]*qofile.Widget, 0)
+	widgets = make([]*qofile.Widget, 0)
 	for _, obj := range objectlist {
 		ty := obj.Type()
 		if ty == "text" {
@@ -116,12 +169,29 @@ func (xfile *Source) XFileToMumps(batchid string, buf *bytes.Buffer) error {
 		}
 		widgets = append(widgets, obj.(*qofile.Widget))
 	}
-	errs := WidgetsListToMumps(batchid, widgets, buf)
-	if errs == nil {
-		return nil
-	}
+	return widgets, nil
+}
 
-	return qerror.ErrorSlice(errs)
+// xlanguage sets the language of the lgcodes in content to the language with code lg:
+// `l4_E_name` and `l4_name` become `l4_{lg}_name`, `l4_Ejs_name` becomes `l4_{lg}js_name`
+func xlanguage(content []byte, lg string) []byte {
+	parts := qutil.ObjectSplitter(content)
+	for i, part := range parts {
+		if i%2 == 0 || !bytes.HasPrefix(part, []byte("l4_")) {
+			continue
+		}
+		name := string(part)
+		switch strings.Count(name, "_") {
+		case 1:
+			parts[i] = []byte("l4_" + lg + "_" + name[3:])
+		case 2:
+			canon, algo := qutil.DeNEDFU(name)
+			if algo != "" {
+				parts[i] = []byte("l4_" + lg + algo[1:] + "_" + canon[3:])
+			}
+		}
+	}
+	return bytes.Join(parts, nil)
 }
 
 func xdecomment(line []byte) ([]byte, []byte) {
@@ -1,7 +1,10 @@
 package source
 
 import (
+	"strings"
 	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
 )
 
 func TestXdecomment(t *testing.T) {
@@ -55,3 +58,33 @@ func TestXdecomment(t *testing.T) {
 	}
 
 }
+
+func TestXFilePreview01(t *testing.T) {
+	r := "9.85"
+	proj := "/a/b"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+	sources := map[string]string{
+		proj + "/preview.l": "// About: texts\n\nlgcode title:\n    N: Titel\n    E: Title\n",
+		proj + "/preview.x": "// About: screens\n\nscreen edit:\n<h1>l4_N_title</h1>\n<p>x4_varruntime(FDid)</p>\n",
+	}
+	for _, qpath := range []string{proj + "/preview.l", proj + "/preview.x"} {
+		source, _ := Source{}.New(r, qpath, false)
+		_, _, _, err := source.Store(qmeta.Meta{}, sources[qpath], false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, _ := Source{}.New(r, proj+"/preview.x", true)
+	preview, err := source.XFilePreview("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(preview)
+	if !strings.Contains(html, `<html lang="en">`) || !strings.Contains(html, "<h1>Title</h1>\n<p>[FDid]</p>") {
+		t.Errorf("Wrong preview:\n%s", html)
+	}
+	if _, err := source.XFilePreview("xx"); err == nil {
+		t.Errorf("Should fail on unknown language")
+	}
+}
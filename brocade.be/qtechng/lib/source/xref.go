@@ -0,0 +1,382 @@
+package source
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	qparallel "brocade.be/base/parallel"
+	qerror "brocade.be/qtechng/lib/error"
+	qmfile "brocade.be/qtechng/lib/file/mfile"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// The cross-reference database of a release lives in `{release}/xref/xref.json`:
+// an XrefRoutine per M file, built from the resolved M code.
+// An entry is rebuilt if the digest of the M file or of one of the objects it
+// depends on (macros, includes, lgcodes, also via other objects) changes.
+
+var xrefLocks = new(sync.Map)
+
+// Xref cross-reference database of the M routines in a release
+type Xref struct {
+	Release  string                  `json:"version"`
+	Routines map[string]*XrefRoutine `json:"routines"` // by qpath
+}
+
+// XrefRoutine cross-reference information of an M routine
+type XrefRoutine struct {
+	QPath   string       `json:"qpath"`
+	Routine string       `json:"routine"`
+	Digest  string       `json:"digest"` // digest of the M file and its objects (see xrefDigest)
+	Labels  []XrefLabel  `json:"labels"`
+	Calls   []XrefCall   `json:"calls"`
+	Globals []XrefGlobal `json:"globals"`
+	Objects []string     `json:"objects"` // lgcodes, macros, includes and text substitutions
+}
+
+// XrefLabel definition of a label
+type XrefLabel struct {
+	Label   string   `json:"label"`
+	Formals []string `json:"formals,omitempty"`
+	Place   string   `json:"place"` // label^routine
+}
+
+// XrefCall call site: DO, GOTO, JOB or extrinsic function
+type XrefCall struct {
+	Caller  string `json:"caller"` // place of the call: label+offset^routine
+	Callee  string `json:"callee"` // label^routine or ^routine
+	Command string `json:"command"`
+	QPath   string `json:"qpath,omitempty"`
+}
+
+// XrefGlobal reference to a global
+type XrefGlobal struct {
+	Place     string `json:"place"`
+	Global    string `json:"global"`
+	Reference string `json:"reference"`
+	Mode      string `json:"mode"` // "read", "set" or "kill"
+	QPath     string `json:"qpath,omitempty"`
+}
+
+// Xref calculates the cross-reference information of an M file
+func (source *Source) Xref() (entry *XrefRoutine, err error) {
+	qpath := source.String()
+	body, err := source.Fetch()
+	if err != nil {
+		return nil, err
+	}
+	buffer := new(bytes.Buffer)
+	err = source.MFileToMumps("xref", buffer)
+	if err != nil {
+		return nil, err
+	}
+	_, base := qutil.QPartition(qpath)
+	name := strings.TrimSuffix(base, ".m")
+	entry = &XrefRoutine{
+		QPath:   qpath,
+		Routine: name,
+		Digest:  xrefDigest(source.Release(), body),
+		Labels:  make([]XrefLabel, 0),
+		Calls:   make([]XrefCall, 0),
+		Globals: make([]XrefGlobal, 0),
+		Objects: make([]string, 0),
+	}
+
+	routine := qmfile.Parse(name, buffer.Bytes())
+	label := ""
+	offset := 0
+	for _, line := range routine.Lines {
+		if line.Label != nil {
+			label = line.Label.Name
+			offset = 0
+			if label == "ltechbeg" || label == "ltechend" {
+				// added by MFileToMumps
+				continue
+			}
+			entry.Labels = append(entry.Labels, XrefLabel{
+				Label:   label,
+				Formals: line.Label.Formals,
+				Place:   label + "^" + name,
+			})
+		} else {
+			offset++
+		}
+		place := label
+		if offset != 0 {
+			place += "+" + strconv.Itoa(offset)
+		}
+		place += "^" + name
+		for _, cmd := range line.Commands {
+			for _, ref := range cmd.Refs() {
+				if ref.Routine == "" {
+					ref.Routine = name
+				}
+				entry.Calls = append(entry.Calls, XrefCall{
+					Caller:  place,
+					Callee:  ref.Label + "^" + ref.Routine,
+					Command: xrefCommand(ref),
+				})
+			}
+			for _, ref := range cmd.Globals() {
+				entry.Globals = append(entry.Globals, XrefGlobal{
+					Place:     place,
+					Global:    ref.Name,
+					Reference: ref.Reference,
+					Mode:      ref.Mode,
+				})
+			}
+		}
+	}
+
+	objects := make(map[string]bool)
+	for i, bobj := range qutil.ObjectSplitter(body) {
+		if i%2 == 1 {
+			obj, _ := qutil.DeNEDFU(string(bobj))
+			objects[obj] = true
+		}
+	}
+	for obj := range objects {
+		entry.Objects = append(entry.Objects, obj)
+	}
+	sort.Strings(entry.Objects)
+	return entry, nil
+}
+
+// xrefDigest returns the digest of an M file and of the objects it depends on:
+// the objects used in the file and, recursively, the objects used in their definitions.
+func xrefDigest(release *qserver.Release, body []byte) string {
+	objects := func(blob []byte) (objs []string) {
+		for i, bobj := range qutil.ObjectSplitter(blob) {
+			if i%2 == 1 {
+				obj, _ := qutil.DeNEDFU(string(bobj))
+				objs = append(objs, obj)
+			}
+		}
+		return objs
+	}
+	digests := make(map[string]string)
+	todo := objects(body)
+	for len(todo) != 0 {
+		obj := todo[len(todo)-1]
+		todo = todo[:len(todo)-1]
+		if _, ok := digests[obj]; ok {
+			continue
+		}
+		digests[obj] = ""
+		fs, place := release.ObjectPlace(obj)
+		if place == "" {
+			continue
+		}
+		blob, err := fs.ReadFile(place)
+		if err != nil {
+			continue
+		}
+		digests[obj] = qutil.Digest(blob)
+		todo = append(todo, objects(blob)...)
+	}
+	names := make([]string, 0, len(digests))
+	for obj := range digests {
+		names = append(names, obj)
+	}
+	sort.Strings(names)
+	key := new(strings.Builder)
+	key.WriteString(qutil.Digest(body))
+	for _, obj := range names {
+		key.WriteString("\n" + obj + " " + digests[obj])
+	}
+	return qutil.Digest([]byte(key.String()))
+}
+
+func xrefCommand(ref qmfile.EntryRef) string {
+	if ref.Extrinsic {
+		return "$$"
+	}
+	return ref.Command
+}
+
+// LoadXref reads the cross-reference database of a release without updating it
+func LoadXref(r string) (xref *Xref, err error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	xref = &Xref{
+		Release:  release.String(),
+		Routines: make(map[string]*XrefRoutine),
+	}
+	blob, e := release.FS("/xref").ReadFile("/xref.json")
+	if e == nil {
+		json.Unmarshal(blob, xref)
+	}
+	return xref, nil
+}
+
+// BuildXref updates the cross-reference database of a release:
+// entries of changed M files are rebuilt, entries of deleted M files are removed.
+// With reset, all entries are rebuilt.
+func BuildXref(r string, reset bool) (xref *Xref, errs error) {
+	release, err := qserver.Release{}.New(r, false)
+	if err != nil {
+		return nil, err
+	}
+	r = release.String()
+	if ok, _ := release.Exists(); !ok {
+		err := &qerror.QError{
+			Ref:     []string{"source.xref.version"},
+			Version: r,
+			Msg:     []string{"Version does not exist"},
+		}
+		return nil, err
+	}
+	lock, _ := xrefLocks.LoadOrStore(r, new(sync.Mutex))
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	xref, err = LoadXref(r)
+	if err != nil {
+		return nil, err
+	}
+	if reset {
+		xref.Routines = make(map[string]*XrefRoutine)
+	}
+	qpaths := release.FS().Glob("/", []string{"*.m"}, true)
+	present := make(map[string]bool)
+	for _, qpath := range qpaths {
+		present[qpath] = true
+	}
+	changed := false
+	for qpath := range xref.Routines {
+		if !present[qpath] {
+			delete(xref.Routines, qpath)
+			changed = true
+		}
+	}
+
+	fn := func(n int) (interface{}, error) {
+		qpath := qpaths[n]
+		source, err := Source{}.New(r, qpath, true)
+		if err != nil {
+			return nil, err
+		}
+		if old := xref.Routines[qpath]; old != nil {
+			body, err := source.Fetch()
+			if err == nil && xrefDigest(release, body) == old.Digest {
+				return nil, nil
+			}
+		}
+		return source.Xref()
+	}
+	resultlist, errorlist := qparallel.NMap(len(qpaths), -1, fn)
+	errlist := make([]error, 0)
+	for i, result := range resultlist {
+		if errorlist[i] != nil {
+			errlist = append(errlist, errorlist[i])
+			continue
+		}
+		entry, ok := result.(*XrefRoutine)
+		if !ok || entry == nil {
+			continue
+		}
+		xref.Routines[qpaths[i]] = entry
+		changed = true
+	}
+	if changed {
+		_, _, _, e := release.FS("/xref").Store("/xref.json", xref, "")
+		if e != nil {
+			err := &qerror.QError{
+				Ref:     []string{"source.xref.store"},
+				Version: r,
+				Msg:     []string{"Cannot store cross-reference database: " + e.Error()},
+			}
+			errlist = append(errlist, err)
+		}
+	}
+	if len(errlist) != 0 {
+		return xref, qerror.ErrorSlice(errlist)
+	}
+	return xref, nil
+}
+
+// routines returns the entries sorted on qpath
+func (xref *Xref) routines() []*XrefRoutine {
+	entries := make([]*XrefRoutine, 0, len(xref.Routines))
+	for _, entry := range xref.Routines {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].QPath < entries[j].QPath })
+	return entries
+}
+
+// Callers returns the call sites of `label^routine` or, for `^routine`, of all labels in the routine
+func (xref *Xref) Callers(callee string) []XrefCall {
+	if !strings.Contains(callee, "^") {
+		callee = "^" + callee
+	}
+	calls := make([]XrefCall, 0)
+	for _, entry := range xref.routines() {
+		for _, call := range entry.Calls {
+			if call.Callee == callee || (strings.HasPrefix(callee, "^") && strings.HasSuffix(call.Callee, callee)) {
+				call.QPath = entry.QPath
+				calls = append(calls, call)
+			}
+		}
+	}
+	return calls
+}
+
+// GlobalUsers returns the references to a global with a given mode ("read", "set", "kill" or "" for all).
+// The global is a name (`^BCAT`) or a prefix of the reference (`^BCAT("isbn"`).
+func (xref *Xref) GlobalUsers(global string, mode string) []XrefGlobal {
+	if !strings.HasPrefix(global, "^") {
+		global = "^" + global
+	}
+	withsubs := strings.Contains(global, "(")
+	refs := make([]XrefGlobal, 0)
+	for _, entry := range xref.routines() {
+		for _, ref := range entry.Globals {
+			if mode != "" && ref.Mode != mode {
+				continue
+			}
+			if withsubs && !strings.HasPrefix(ref.Reference, global) {
+				continue
+			}
+			if !withsubs && ref.Global != global {
+				continue
+			}
+			ref.QPath = entry.QPath
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// Unused returns the labels which are never called: the first label of a routine is the
+// entry point of `^routine` and is not reported. Labels reached with an offset
+// or by indirection are not detected.
+func (xref *Xref) Unused(project string) []XrefLabel {
+	called := make(map[string]bool)
+	for _, entry := range xref.Routines {
+		for _, call := range entry.Calls {
+			called[call.Callee] = true
+		}
+	}
+	project = strings.TrimSuffix(project, "/")
+	labels := make([]XrefLabel, 0)
+	for _, entry := range xref.routines() {
+		if project != "" && !strings.HasPrefix(entry.QPath, project+"/") {
+			continue
+		}
+		for i, label := range entry.Labels {
+			if i == 0 || called[label.Place] {
+				continue
+			}
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
@@ -0,0 +1,116 @@
+package source
+
+import (
+	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+func TestXref01(t *testing.T) {
+	r := "9.86"
+	proj := "/a/b"
+	release, _ := makeRelease(r, proj)
+	r = release.String()
+	sources := map[string]string{
+		proj + "/sign.m": "// About: sign\n\ndef %Sign(x):\n    q x>0-(x<0)\n\ndef %Abs(x):\n    q $s(x<0:-x,1:x)\n",
+		proj + "/main.m": "// About: main\n\nmain    s ^ZTMP(\"sign\",1)=$$%Sign^sign(^BCAT(1))\n    d show k ^ZTMP(\"sign\")\n    q\nshow    w l4_text,!\n    q\n",
+	}
+	for qpath, body := range sources {
+		source, _ := Source{}.New(r, qpath, false)
+		_, _, _, err := source.Store(qmeta.Meta{}, body, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	xref, err := BuildXref(r, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(xref.Routines) != 2 {
+		t.Fatalf("Should be 2 routines: %v", xref.Routines)
+	}
+	main := xref.Routines[proj+"/main.m"]
+	if len(main.Objects) != 1 || main.Objects[0] != "l4_text" {
+		t.Errorf("Wrong objects: %v", main.Objects)
+	}
+
+	calls := xref.Callers("%Sign^sign")
+	if len(calls) != 1 || calls[0].Caller != "main^main" || calls[0].Command != "$$" || calls[0].QPath != proj+"/main.m" {
+		t.Errorf("Wrong callers: %v", calls)
+	}
+	if calls := xref.Callers("show^main"); len(calls) != 1 || calls[0].Caller != "main+1^main" {
+		t.Errorf("Wrong callers: %v", calls)
+	}
+	if calls := xref.Callers("^sign"); len(calls) != 1 {
+		t.Errorf("Wrong callers: %v", calls)
+	}
+
+	refs := xref.GlobalUsers("^ZTMP", "")
+	if len(refs) != 2 || refs[0].Mode != "set" || refs[1].Mode != "kill" || refs[1].Reference != `^ZTMP("sign")` {
+		t.Errorf("Wrong references: %v", refs)
+	}
+	if refs := xref.GlobalUsers(`^ZTMP("sign",`, "set"); len(refs) != 1 {
+		t.Errorf("Wrong references: %v", refs)
+	}
+	if refs := xref.GlobalUsers("BCAT", "read"); len(refs) != 1 || refs[0].Place != "main^main" {
+		t.Errorf("Wrong references: %v", refs)
+	}
+
+	unused := xref.Unused(proj)
+	if len(unused) != 2 || unused[0].Place != "main^main" || unused[1].Place != "%Abs^sign" {
+		t.Errorf("Wrong unused labels: %v", unused)
+	}
+
+	// incremental
+	source, _ := Source{}.New(r, proj+"/sign.m", false)
+	blob, _ := source.Fetch()
+	_, _, _, err = source.Store(qmeta.Meta{Digest: qutil.Digest(blob)}, "// About: sign\n\ndef %Sign(x):\n    q $$%Abs(x)/x\n\ndef %Abs(x):\n    q $s(x<0:-x,1:x)\n", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xref, _ = BuildXref(r, false)
+	if unused := xref.Unused(""); len(unused) != 1 {
+		t.Errorf("Wrong unused labels: %v", unused)
+	}
+	xref, _ = LoadXref(r)
+	if calls := xref.Callers("%Abs^sign"); len(calls) != 1 {
+		t.Errorf("Wrong stored database: %v", calls)
+	}
+
+	// a changed macro changes the entries of the routines using it
+	macro := func(global string) string {
+		return `"""
+About: temporary values
+"""
+
+macro setTmp($x):
+    '''
+    $synopsis: Set a temporary value
+    $x: value
+    $example: m4_setTmp(1)
+    '''
+    «s ` + global + `("tmp")=$x»
+`
+	}
+	dsource, _ := Source{}.New(r, proj+"/tmp.d", false)
+	if _, _, _, err = dsource.Store(qmeta.Meta{}, macro("^ZTMP"), false); err != nil {
+		t.Fatal(err)
+	}
+	use, _ := Source{}.New(r, proj+"/use.m", false)
+	if _, _, _, err = use.Store(qmeta.Meta{}, "// About: use\n\nuse    m4_setTmp(1)\n    q\n", false); err != nil {
+		t.Fatal(err)
+	}
+	xref, _ = BuildXref(r, false)
+	if refs := xref.GlobalUsers("^ZTMP", "set"); len(refs) != 2 {
+		t.Errorf("Wrong references: %v", refs)
+	}
+	blob, _ = dsource.Fetch()
+	if _, _, _, err = dsource.Store(qmeta.Meta{Digest: qutil.Digest(blob)}, macro("^ZTMP2"), false); err != nil {
+		t.Fatal(err)
+	}
+	xref, _ = BuildXref(r, false)
+	if refs := xref.GlobalUsers("^ZTMP2", "set"); len(refs) != 1 || refs[0].QPath != proj+"/use.m" {
+		t.Errorf("Entry should be rebuilt after a change of the macro: %v", refs)
+	}
+}
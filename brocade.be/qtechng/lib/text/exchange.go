@@ -0,0 +1,258 @@
+package text
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	qerror "brocade.be/qtechng/lib/error"
+	qlfile "brocade.be/qtechng/lib/file/lfile"
+	qmeta "brocade.be/qtechng/lib/meta"
+	qobject "brocade.be/qtechng/lib/object"
+	qserver "brocade.be/qtechng/lib/server"
+	qsource "brocade.be/qtechng/lib/source"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// Catalog is a set of lgcodes to be translated from one language to another (BCP-47 tags).
+// It is exchanged with translators as XLIFF 2.0 or as a gettext PO file.
+type Catalog struct {
+	Release string
+	Source  string
+	Target  string
+	Units   []Unit
+}
+
+// Unit is a lgcode in a catalog
+type Unit struct {
+	ID     string // lgcode (without l4_)
+	QPath  string // L-file
+	Lineno int
+	Nature string
+	Alias  string
+	Digest string // digest of the L-file at export
+	Source string // text in the source language
+	Target string // text in the target language
+}
+
+// Location returns the place of the lgcode: qpath:lineno
+func (unit Unit) Location() string {
+	if unit.Lineno == 0 {
+		return unit.QPath
+	}
+	return unit.QPath + ":" + strconv.Itoa(unit.Lineno)
+}
+
+func (unit *Unit) setLocation(location string) {
+	k := strings.LastIndex(location, ":")
+	if k == -1 {
+		unit.QPath = location
+		return
+	}
+	lineno, err := strconv.Atoi(location[k+1:])
+	if err != nil {
+		unit.QPath = location
+		return
+	}
+	unit.QPath = location[:k]
+	unit.Lineno = lineno
+}
+
+// lfiles returns the L-files in a project or, if project is empty, in the release
+func lfiles(release *qserver.Release, project string) []string {
+	project = strings.TrimSuffix(project, "/")
+	qpaths := make([]string, 0)
+	for _, qpath := range release.FS().Glob("/", []string{"*.l"}, true) {
+		if project == "" || strings.HasPrefix(qpath, project+"/") {
+			qpaths = append(qpaths, qpath)
+		}
+	}
+	sort.Strings(qpaths)
+	return qpaths
+}
+
+// loadLFile reads and parses an L-file in the repository
+func loadLFile(r string, qpath string) (lfile *qlfile.LFile, blob []byte, err error) {
+	source, err := qsource.Source{}.New(r, qpath, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err = source.Fetch()
+	if err != nil {
+		return nil, nil, err
+	}
+	lfile = new(qlfile.LFile)
+	lfile.SetEditFile(qpath)
+	lfile.SetRelease(r)
+	err = qobject.Loads(lfile, blob, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	lfile.SetObjects(lfile.Objects())
+	return lfile, blob, nil
+}
+
+// ExportCatalog collects the lgcodes of the L-files in a project (or in the release, if project is empty).
+// Lgcodes without a text in the source language are skipped, as are lgcodes which are already
+// translated if emptyonly is true.
+func ExportCatalog(r string, project string, from string, to string, emptyonly bool) (*Catalog, error) {
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	lgfrom := qutil.LookupLanguage(from)
+	lgto := qutil.LookupLanguage(to)
+	if lgfrom == nil || lgto == nil {
+		err := &qerror.QError{
+			Ref:     []string{"text.export.language"},
+			Version: r,
+			Msg:     []string{"Unknown language in `" + from + "` -> `" + to + "`"},
+		}
+		return nil, err
+	}
+	catalog := &Catalog{
+		Release: release.String(),
+		Source:  lgfrom.Tag,
+		Target:  lgto.Tag,
+		Units:   make([]Unit, 0),
+	}
+	errs := make([]error, 0)
+	for _, qpath := range lfiles(release, project) {
+		lfile, blob, err := loadLFile(r, qpath)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		digest := qutil.Digest(blob)
+		for _, lgcode := range lfile.Lgcodes {
+			source := lgcode.Translation(lgfrom.Code)
+			target := lgcode.Translation(lgto.Code)
+			if source == "" || (emptyonly && target != "") {
+				continue
+			}
+			lineno, _ := strconv.Atoi(lgcode.Line)
+			catalog.Units = append(catalog.Units, Unit{
+				ID:     lgcode.ID,
+				QPath:  qpath,
+				Lineno: lineno,
+				Nature: lgcode.Nature,
+				Alias:  lgcode.Alias,
+				Digest: digest,
+				Source: source,
+				Target: target,
+			})
+		}
+	}
+	if len(errs) != 0 {
+		return catalog, qerror.ErrorSlice(errs)
+	}
+	return catalog, nil
+}
+
+// ImportCatalog writes the translations in a catalog to the originating L-files.
+// The status of every unit is counted:
+//
+//   - IMPORTED: the translation is stored
+//   - UNCHANGED: the translation is equal to the translation in the repository
+//   - EMPTY: there is no translation
+//   - MISSING: the lgcode is not in the L-file
+//   - CONFLICT: the L-file is changed since the export
+//   - LFILE: the L-file cannot be read
+//
+// A conflict is reported as an error.
+func ImportCatalog(r string, catalog *Catalog, uid string) (numbers map[string]int, errs error) {
+	numbers = make(map[string]int)
+	lgto := qutil.LookupLanguage(catalog.Target)
+	if lgto == nil {
+		err := &qerror.QError{
+			Ref:     []string{"text.import.language"},
+			Version: r,
+			Msg:     []string{"Unknown target language `" + catalog.Target + "`"},
+		}
+		return nil, err
+	}
+	units := make(map[string][]Unit)
+	qpaths := make([]string, 0)
+	for _, unit := range catalog.Units {
+		if units[unit.QPath] == nil {
+			qpaths = append(qpaths, unit.QPath)
+		}
+		units[unit.QPath] = append(units[unit.QPath], unit)
+	}
+	sort.Strings(qpaths)
+
+	errlist := make([]error, 0)
+	changed := make([]string, 0)
+	blobs := make(map[string][]byte)
+	digests := make(map[string]string)
+	for _, qpath := range qpaths {
+		lfile, blob, err := loadLFile(r, qpath)
+		if err != nil {
+			numbers["LFILE"] += len(units[qpath])
+			errlist = append(errlist, err)
+			continue
+		}
+		digest := qutil.Digest(blob)
+		if exported := units[qpath][0].Digest; exported != "" && exported != digest {
+			numbers["CONFLICT"] += len(units[qpath])
+			err := &qerror.QError{
+				Ref:     []string{"text.import.conflict"},
+				Version: r,
+				QPath:   qpath,
+				Type:    "Error",
+				Msg:     []string{"L-file is changed since the export"},
+			}
+			errlist = append(errlist, err)
+			continue
+		}
+		lgcodes := make(map[string]*qlfile.Lgcode)
+		for _, lgcode := range lfile.Lgcodes {
+			lgcodes[lgcode.ID] = lgcode
+		}
+		count := 0
+		for _, unit := range units[qpath] {
+			lgcode := lgcodes[unit.ID]
+			target := qutil.Simplify(unit.Target, false)
+			switch {
+			case lgcode == nil:
+				numbers["MISSING"]++
+			case strings.TrimSpace(target) == "":
+				numbers["EMPTY"]++
+			case lgcode.Translation(lgto.Code) == target:
+				numbers["UNCHANGED"]++
+			default:
+				lgcode.SetTranslation(lgto.Code, target)
+				numbers["IMPORTED"]++
+				count++
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		changed = append(changed, qpath)
+		blobs[qpath] = lfile.Format().Bytes()
+		digests[qpath] = digest
+	}
+	if len(changed) != 0 {
+		t := time.Now().Format(time.RFC3339)
+		fmeta := func(qpath string) qmeta.Meta {
+			return qmeta.Meta{
+				Mt:     t,
+				Mu:     uid,
+				Digest: digests[qpath],
+			}
+		}
+		fdata := func(qpath string) ([]byte, error) {
+			return blobs[qpath], nil
+		}
+		_, err := qsource.StoreList("install", r, changed, false, fmeta, fdata, false)
+		if err != nil {
+			errlist = append(errlist, err)
+		}
+	}
+	if len(errlist) != 0 {
+		return numbers, qerror.ErrorSlice(errlist)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,124 @@
+package text
+
+import (
+	"strings"
+	"testing"
+
+	qmeta "brocade.be/qtechng/lib/meta"
+	qproject "brocade.be/qtechng/lib/project"
+	qserver "brocade.be/qtechng/lib/server"
+	qsource "brocade.be/qtechng/lib/source"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+func makeLFile(t *testing.T, r string, qpath string, data string) {
+	release, _ := qserver.Release{}.New(r, false)
+	if ok, _ := release.Exists(); !ok {
+		release.Init()
+		project, _ := qproject.Project{}.New(r, "/a/b", false)
+		project.Init(qmeta.Meta{})
+	}
+	fdata := func(p string) ([]byte, error) { return []byte(data), nil }
+	fmeta := func(p string) qmeta.Meta {
+		blob, _ := release.FS().ReadFile(p)
+		return qmeta.Meta{Digest: qutil.Digest(blob)}
+	}
+	_, errs := qsource.StoreList("install", r, []string{qpath}, false, fmeta, fdata, false)
+	if errs != nil {
+		t.Fatal(errs)
+	}
+}
+
+func TestExchange01(t *testing.T) {
+	r := "9.88"
+	release, _ := qserver.Release{}.New(r, false)
+	release.FS("/").RemoveAll("/")
+	makeLFile(t, r, "/a/b/c.l", `lgcode save:
+    N: Bewaren
+    E: Save
+
+lgcode warning:
+    Nature: html
+    N: «Opgelet: <b>"cijfers"</b>
+in de naam»
+`)
+	catalog, err := ExportCatalog(r, "/a/b", "nl", "fr", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Units) != 2 || catalog.Units[1].Nature != "html" || catalog.Units[1].Lineno != 5 {
+		t.Fatalf("Wrong catalog: %v", catalog)
+	}
+
+	blob, err := catalog.XLIFF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(blob), `srcLang="nl" trgLang="fr"`) || !strings.Contains(string(blob), `<note category="location">/a/b/c.l:5</note>`) {
+		t.Errorf("Wrong XLIFF:\n%s", blob)
+	}
+	fromxliff, err := ParseXLIFF(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob = catalog.PO()
+	frompo, err := ParsePO(blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*Catalog{fromxliff, frompo} {
+		if c.Target != "fr" || c.Source != "nl" || c.Release != catalog.Release || len(c.Units) != 2 {
+			t.Fatalf("Wrong round trip: %v", c)
+		}
+		for i := range c.Units {
+			if c.Units[i] != catalog.Units[i] {
+				t.Errorf("Wrong unit:\n%v\n%v\n%s", c.Units[i], catalog.Units[i], blob)
+			}
+		}
+	}
+
+	frompo.Units[0].Target = "Sauvegarder"
+	numbers, err := ImportCatalog(r, frompo, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if numbers["IMPORTED"] != 1 || numbers["EMPTY"] != 1 {
+		t.Errorf("Wrong numbers: %v", numbers)
+	}
+	source, _ := qsource.Source{}.New(r, "/a/b/c.l", true)
+	body, _ := source.Fetch()
+	if !strings.Contains(string(body), "F: Sauvegarder") {
+		t.Errorf("Not imported:\n%s", body)
+	}
+
+	fromxliff.Units[0].Target = "Enregistrer"
+	numbers, err = ImportCatalog(r, fromxliff, "test")
+	if err == nil || numbers["CONFLICT"] != 2 {
+		t.Errorf("Should be a conflict: %v", numbers)
+	}
+}
+
+func TestExchange02(t *testing.T) {
+	r := "9.82"
+	release, _ := qserver.Release{}.New(r, false)
+	release.FS("/").RemoveAll("/")
+	makeLFile(t, r, "/a/b/p.l", "// About: Translations of the catalogue\n\nlgcode save:\n    N: Bewaren\n\nlgcode cancel:\n    N: Annuleren\n")
+	for _, target := range []string{"Sauvegarder", "Annuler"} {
+		catalog, err := ExportCatalog(r, "/a/b", "nl", "fr", true)
+		if err != nil || len(catalog.Units) == 0 {
+			t.Fatalf("Wrong catalog: %v %v", catalog, err)
+		}
+		catalog.Units[0].Target = target
+		if _, err = ImportCatalog(r, catalog, "test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source, _ := qsource.Source{}.New(r, "/a/b/p.l", true)
+	body, _ := source.Fetch()
+	if strings.Count(string(body), "About:") != 1 || !strings.HasPrefix(string(body), "// About: Translations of the catalogue\n\nlgcode ") {
+		t.Errorf("Preamble should be kept once:\n%s", body)
+	}
+	if !strings.Contains(string(body), "F: Sauvegarder") || !strings.Contains(string(body), "F: Annuler") {
+		t.Errorf("Not imported:\n%s", body)
+	}
+}
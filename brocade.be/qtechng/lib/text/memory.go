@@ -0,0 +1,219 @@
+package text
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	qfs "brocade.be/base/fs"
+	qregistry "brocade.be/base/registry"
+	qserver "brocade.be/qtechng/lib/server"
+	qutil "brocade.be/qtechng/lib/util"
+)
+
+// Memory is a translation memory: a set of texts which are translations of each other.
+// The texts are keyed by language key (see qutil.Languages).
+type Memory struct {
+	Threshold float64 // minimal score of a fuzzy match
+	entries   []map[string]string
+	exact     map[string]map[string][]int // language key -> normalised text -> entries
+	mutex     sync.RWMutex
+}
+
+// NewMemory creates an empty translation memory.
+// The threshold for fuzzy matches is the registry value `qtechng-translation-memory-threshold` (default 0.85).
+func NewMemory() *Memory {
+	threshold, err := strconv.ParseFloat(qregistry.Registry["qtechng-translation-memory-threshold"], 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		threshold = 0.85
+	}
+	return &Memory{
+		Threshold: threshold,
+		exact:     make(map[string]map[string][]int),
+	}
+}
+
+var memoryMutex = new(sync.Mutex)
+var memories = make(map[string]releaseMemory)
+
+// releaseMemory is a cached translation memory of a release with the signature
+// of the lgcode files it is built from
+type releaseMemory struct {
+	memory    *Memory
+	signature string
+}
+
+// ReleaseMemory returns the translation memory of all lgcodes in a release.
+// The memory is rebuilt if the lgcode files have changed since it was built.
+func ReleaseMemory(r string) (*Memory, error) {
+	memoryMutex.Lock()
+	defer memoryMutex.Unlock()
+	release, err := qserver.Release{}.New(r, true)
+	if err != nil {
+		return nil, err
+	}
+	fs := release.FS("/")
+	dir, _ := fs.RealPath("/object/l4")
+	files, _ := qfs.Find(dir, []string{"obj.json"}, true, true, false)
+	sort.Strings(files)
+	signature := new(strings.Builder)
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(signature, "%s %d %d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	if cached, ok := memories[r]; ok && cached.signature == signature.String() {
+		return cached.memory, nil
+	}
+	memory := NewMemory()
+	for _, file := range files {
+		data, err := qfs.Fetch(file)
+		if err != nil {
+			continue
+		}
+		m := make(map[string]string)
+		if json.Unmarshal(data, &m) != nil {
+			continue
+		}
+		memory.Add(m)
+	}
+	memories[r] = releaseMemory{memory: memory, signature: signature.String()}
+	return memory, nil
+}
+
+// Add adds a set of translations (keyed by language key) to the memory.
+// Keys which are not languages (e.g. "id", "source") are ignored.
+func (memory *Memory) Add(texts map[string]string) {
+	entry := make(map[string]string)
+	for _, lg := range qutil.Languages() {
+		text := strings.TrimSpace(texts[lg.Key])
+		if text != "" {
+			entry[lg.Key] = text
+		}
+	}
+	if len(entry) < 2 {
+		return
+	}
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	n := len(memory.entries)
+	memory.entries = append(memory.entries, entry)
+	for key, text := range entry {
+		index := memory.exact[key]
+		if index == nil {
+			index = make(map[string][]int)
+			memory.exact[key] = index
+		}
+		norm := normalise(text)
+		index[norm] = append(index[norm], n)
+	}
+}
+
+// Len returns the number of entries in the memory
+func (memory *Memory) Len() int {
+	memory.mutex.RLock()
+	defer memory.mutex.RUnlock()
+	return len(memory.entries)
+}
+
+// Lookup finds the translation of a text in the memory.
+// An exact match (case and whitespace are ignored) is preferred over the best fuzzy match.
+// The languages are given by BCP-47 tag, code or key.
+func (memory *Memory) Lookup(text string, from string, to string) (tr Translation, ok bool) {
+	lgfrom := qutil.LookupLanguage(from)
+	lgto := qutil.LookupLanguage(to)
+	if lgfrom == nil || lgto == nil || lgfrom.Key == lgto.Key {
+		return tr, false
+	}
+	norm := normalise(text)
+	if norm == "" {
+		return tr, false
+	}
+	memory.mutex.RLock()
+	defer memory.mutex.RUnlock()
+	for _, n := range memory.exact[lgfrom.Key][norm] {
+		entry := memory.entries[n]
+		if entry[lgto.Key] != "" {
+			return Translation{Text: entry[lgto.Key], Score: 1, Match: entry[lgfrom.Key]}, true
+		}
+	}
+	source := []rune(norm)
+	best := 0.0
+	for n, entry := range memory.entries {
+		if entry[lgfrom.Key] == "" || entry[lgto.Key] == "" {
+			continue
+		}
+		candidate := []rune(normalise(entry[lgfrom.Key]))
+		longest := len(source)
+		if len(candidate) > longest {
+			longest = len(candidate)
+		}
+		diff := len(source) - len(candidate)
+		if diff < 0 {
+			diff = -diff
+		}
+		if 1-float64(diff)/float64(longest) < memory.Threshold {
+			continue
+		}
+		score := 1 - float64(levenshtein(source, candidate))/float64(longest)
+		if score >= memory.Threshold && score > best {
+			best = score
+			tr = Translation{Text: memory.entries[n][lgto.Key], Score: score, Match: entry[lgfrom.Key]}
+			ok = true
+		}
+	}
+	return tr, ok
+}
+
+// Translate makes Memory a Translator
+func (memory *Memory) Translate(text string, from string, to string) (Translation, error) {
+	tr, ok := memory.Lookup(text, from, to)
+	if !ok {
+		return tr, errors.New("no match in the translation memory")
+	}
+	return tr, nil
+}
+
+// normalise makes a text comparable: entities are resolved, whitespace is collapsed and case is ignored
+func normalise(text string) string {
+	text = qutil.Simplify(text, false)
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
+
+// levenshtein computes the edit distance between two texts
+func levenshtein(a []rune, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a int, b int, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,70 @@
+package text
+
+import (
+	"testing"
+
+	qserver "brocade.be/qtechng/lib/server"
+)
+
+func TestMemory01(t *testing.T) {
+	memory := NewMemory()
+	memory.Threshold = 0.85
+	memory.Add(map[string]string{"id": "lg1", "dut": "Opgelet ! Er staan cijfers in de auteursnaam", "eng": "Note! There are numbers in the author name"})
+	memory.Add(map[string]string{"id": "lg2", "dut": "Bewaren", "eng": "Save", "fre": "Sauvegarder"})
+	memory.Add(map[string]string{"id": "lg3", "dut": "Annuleren"})
+	if memory.Len() != 2 {
+		t.Errorf("Should be 2 entries: %d", memory.Len())
+	}
+
+	tr, err := memory.Translate("  bewaren ", "nl-NL", "fr-FR")
+	if err != nil || tr.Text != "Sauvegarder" || tr.Score != 1 {
+		t.Errorf("Exact match: %v %v", tr, err)
+	}
+
+	tr, err = memory.Translate("Opgelet! Er staan cijfers in de auteursnaam", "nl", "eng")
+	if err != nil || tr.Text != "Note! There are numbers in the author name" || tr.Score >= 1 || tr.Score < 0.85 {
+		t.Errorf("Fuzzy match: %v %v", tr, err)
+	}
+
+	_, err = memory.Translate("Annuleren", "nl", "en")
+	if err == nil {
+		t.Errorf("Annuleren should not be found")
+	}
+
+	_, err = memory.Translate("Bewaren", "nl", "de")
+	if err == nil {
+		t.Errorf("Bewaren has no German translation")
+	}
+}
+
+func TestLevenshtein01(t *testing.T) {
+	if d := levenshtein([]rune("kitten"), []rune("sitting")); d != 3 {
+		t.Errorf("Distance should be 3: %d", d)
+	}
+	if d := levenshtein([]rune(""), []rune("abc")); d != 3 {
+		t.Errorf("Distance should be 3: %d", d)
+	}
+}
+
+func TestMemory02(t *testing.T) {
+	r := "9.83"
+	release, _ := qserver.Release{}.New(r, false)
+	release.FS("/").RemoveAll("/")
+	makeLFile(t, r, "/a/b/m.l", "lgcode save:\n    N: Bewaren\n    E: Save\n")
+	memory, err := ReleaseMemory(r)
+	if err != nil || memory.Len() != 1 {
+		t.Fatalf("Should be 1 entry: %v", err)
+	}
+	makeLFile(t, r, "/a/b/m.l", "lgcode save:\n    N: Bewaren\n    E: Save\n\nlgcode cancel:\n    N: Annuleren\n    E: Cancel\n")
+	memory, _ = ReleaseMemory(r)
+	if memory.Len() != 2 {
+		t.Errorf("Memory should be rebuilt after a change of the lgcodes: %d", memory.Len())
+	}
+	tr, err := memory.Translate("Annuleren", "nl", "en")
+	if err != nil || tr.Text != "Cancel" {
+		t.Errorf("New lgcode should be found: %v %v", tr, err)
+	}
+	if again, _ := ReleaseMemory(r); again != memory {
+		t.Errorf("Memory should be cached")
+	}
+}
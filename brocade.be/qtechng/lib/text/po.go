@@ -0,0 +1,217 @@
+package text
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// gettext PO (https://www.gnu.org/software/gettext/manual/html_node/PO-Files.html)
+
+// PO renders the catalog as a gettext PO file: the lgcode is the message context,
+// the location is a reference, nature, alias and digest are extracted comments.
+func (catalog *Catalog) PO() []byte {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("# lgcodes\n")
+	buffer.WriteString("msgid \"\"\n")
+	buffer.WriteString("msgstr \"\"\n")
+	header := []string{
+		"Project-Id-Version: qtechng " + catalog.Release,
+		"Language: " + catalog.Target,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+		"X-Source-Language: " + catalog.Source,
+		"X-Qtechng-Release: " + catalog.Release,
+	}
+	for _, h := range header {
+		buffer.WriteString(poQuote(h+"\n") + "\n")
+	}
+	for _, unit := range catalog.Units {
+		buffer.WriteString("\n")
+		if unit.Nature != "" {
+			fmt.Fprintf(buffer, "#. nature: %s\n", unit.Nature)
+		}
+		if unit.Alias != "" {
+			fmt.Fprintf(buffer, "#. alias: %s\n", unit.Alias)
+		}
+		if unit.Digest != "" {
+			fmt.Fprintf(buffer, "#. digest: %s\n", unit.Digest)
+		}
+		fmt.Fprintf(buffer, "#: %s\n", unit.Location())
+		poWrite(buffer, "msgctxt", unit.ID)
+		poWrite(buffer, "msgid", unit.Source)
+		poWrite(buffer, "msgstr", unit.Target)
+	}
+	return buffer.Bytes()
+}
+
+// ParsePO reads a PO file made by PO
+func ParsePO(blob []byte) (*Catalog, error) {
+	catalog := &Catalog{Units: make([]Unit, 0)}
+	scanner := bufio.NewScanner(bytes.NewReader(blob))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	unit := Unit{}
+	keyword := ""
+	values := make(map[string]*strings.Builder)
+	lineno := 0
+	flush := func() {
+		if values["msgid"] == nil {
+			unit = Unit{}
+			values = make(map[string]*strings.Builder)
+			return
+		}
+		get := func(key string) string {
+			if values[key] == nil {
+				return ""
+			}
+			return values[key].String()
+		}
+		unit.ID = get("msgctxt")
+		unit.Source = get("msgid")
+		unit.Target = get("msgstr")
+		if unit.Source == "" && unit.ID == "" {
+			for _, line := range strings.Split(unit.Target, "\n") {
+				k := strings.Index(line, ":")
+				if k == -1 {
+					continue
+				}
+				value := strings.TrimSpace(line[k+1:])
+				switch strings.TrimSpace(line[:k]) {
+				case "Language":
+					catalog.Target = value
+				case "X-Source-Language":
+					catalog.Source = value
+				case "X-Qtechng-Release":
+					catalog.Release = value
+				}
+			}
+		} else {
+			catalog.Units = append(catalog.Units, unit)
+		}
+		unit = Unit{}
+		values = make(map[string]*strings.Builder)
+	}
+	for scanner.Scan() {
+		lineno++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			flush()
+			keyword = ""
+		case strings.HasPrefix(line, "#~"):
+			continue
+		case strings.HasPrefix(line, "#."):
+			comment := strings.TrimSpace(line[2:])
+			k := strings.Index(comment, ":")
+			if k == -1 {
+				continue
+			}
+			value := strings.TrimSpace(comment[k+1:])
+			switch comment[:k] {
+			case "nature":
+				unit.Nature = value
+			case "alias":
+				unit.Alias = value
+			case "digest":
+				unit.Digest = value
+			}
+		case strings.HasPrefix(line, "#:"):
+			unit.setLocation(strings.TrimSpace(line[2:]))
+		case strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "\""):
+			if keyword == "" {
+				return nil, fmt.Errorf("line %d: string without keyword", lineno)
+			}
+			s, err := poUnquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineno, err.Error())
+			}
+			values[keyword].WriteString(s)
+		default:
+			k := strings.IndexAny(line, " \t")
+			if k == -1 {
+				return nil, fmt.Errorf("line %d: missing string", lineno)
+			}
+			keyword = line[:k]
+			switch keyword {
+			case "msgctxt", "msgid", "msgstr":
+			case "msgid_plural", "msgstr[0]":
+				return nil, fmt.Errorf("line %d: plural forms are not supported", lineno)
+			default:
+				return nil, fmt.Errorf("line %d: unknown keyword `%s`", lineno, keyword)
+			}
+			if keyword == "msgctxt" && values["msgid"] != nil {
+				flush()
+			}
+			s, err := poUnquote(strings.TrimSpace(line[k:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s", lineno, err.Error())
+			}
+			values[keyword] = new(strings.Builder)
+			values[keyword].WriteString(s)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	if catalog.Target == "" && len(catalog.Units) == 0 {
+		return nil, errors.New("not a PO file")
+	}
+	return catalog, nil
+}
+
+// poWrite writes a keyword with its string: multi-line strings are split on newlines
+func poWrite(buffer *bytes.Buffer, keyword string, s string) {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		fmt.Fprintf(buffer, "%s %s\n", keyword, poQuote(s))
+		return
+	}
+	fmt.Fprintf(buffer, "%s \"\"\n", keyword)
+	lines := strings.SplitAfter(s, "\n")
+	for _, line := range lines {
+		if line != "" {
+			buffer.WriteString(poQuote(line) + "\n")
+		}
+	}
+}
+
+func poQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+func poUnquote(s string) (string, error) {
+	if len(s) < 2 || !strings.HasPrefix(s, `"`) || !strings.HasSuffix(s, `"`) {
+		return "", errors.New("string should be quoted")
+	}
+	s = s[1 : len(s)-1]
+	if !strings.ContainsRune(s, '\\') {
+		return s, nil
+	}
+	builder := new(strings.Builder)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			builder.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		default:
+			builder.WriteByte(s[i])
+		}
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,77 @@
+package text
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Translation is the result of a translator
+type Translation struct {
+	Text  string  `json:"translation"`
+	Score float64 `json:"score"`           // 1 for an exact match, less for a fuzzy match
+	Match string  `json:"match,omitempty"` // the text which matched in a translation memory
+}
+
+// Translator translates text. The languages are BCP-47 tags.
+type Translator interface {
+	Translate(text string, from string, to string) (Translation, error)
+}
+
+// TranslatorFunc makes a Translator out of a translation function (e.g. GoogleTranslate)
+type TranslatorFunc func(text string, from string, to string) (string, error)
+
+// Translate calls the function
+func (f TranslatorFunc) Translate(text string, from string, to string) (Translation, error) {
+	tr, err := f(text, from, to)
+	if err != nil {
+		return Translation{}, err
+	}
+	if tr == "" {
+		return Translation{}, errors.New("no translation found")
+	}
+	return Translation{Text: tr, Score: 1}, nil
+}
+
+// TranslatorFactory creates a translator for a release
+type TranslatorFactory func(release string) (Translator, error)
+
+var translatorMutex = new(sync.Mutex)
+
+var translators = map[string]TranslatorFactory{
+	"google": func(string) (Translator, error) { return TranslatorFunc(GoogleTranslate), nil },
+	"deepl":  func(string) (Translator, error) { return TranslatorFunc(DeepLTranslate), nil },
+	"memory": func(r string) (Translator, error) { return ReleaseMemory(r) },
+}
+
+// RegisterTranslator makes a translation backend available under a name
+func RegisterTranslator(name string, factory TranslatorFactory) {
+	translatorMutex.Lock()
+	defer translatorMutex.Unlock()
+	translators[name] = factory
+}
+
+// Translators returns the names of the available translation backends
+func Translators() []string {
+	translatorMutex.Lock()
+	defer translatorMutex.Unlock()
+	names := make([]string, 0, len(translators))
+	for name := range translators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewTranslator creates the translation backend with a name
+func NewTranslator(name string, release string) (Translator, error) {
+	name = strings.TrimSpace(name)
+	translatorMutex.Lock()
+	factory, ok := translators[name]
+	translatorMutex.Unlock()
+	if !ok {
+		return nil, errors.New("unknown translation service `" + name + "`")
+	}
+	return factory(release)
+}
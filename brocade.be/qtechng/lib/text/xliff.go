@@ -0,0 +1,159 @@
+package text
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// XLIFF 2.0 (http://docs.oasis-open.org/xliff/xliff-core/v2.0/xliff-core-v2.0.html)
+
+const xliffNamespace = "urn:oasis:names:tc:xliff:document:2.0"
+
+type xliffDocument struct {
+	XMLName xml.Name    `xml:"urn:oasis:names:tc:xliff:document:2.0 xliff"`
+	Version string      `xml:"version,attr"`
+	SrcLang string      `xml:"srcLang,attr"`
+	TrgLang string      `xml:"trgLang,attr,omitempty"`
+	Files   []xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	ID       string      `xml:"id,attr"`
+	Original string      `xml:"original,attr,omitempty"`
+	Notes    []xliffNote `xml:"notes>note,omitempty"`
+	Units    []xliffUnit `xml:"unit"`
+}
+
+type xliffUnit struct {
+	ID      string       `xml:"id,attr"`
+	Name    string       `xml:"name,attr,omitempty"`
+	Notes   []xliffNote  `xml:"notes>note,omitempty"`
+	Segment xliffSegment `xml:"segment"`
+}
+
+type xliffNote struct {
+	Category string `xml:"category,attr,omitempty"`
+	Text     string `xml:",chardata"`
+}
+
+type xliffSegment struct {
+	Source string  `xml:"source"`
+	Target *string `xml:"target"`
+}
+
+// XLIFF renders the catalog as an XLIFF 2.0 document: a <file> per L-file, a <unit> per lgcode
+func (catalog *Catalog) XLIFF() ([]byte, error) {
+	doc := xliffDocument{
+		Version: "2.0",
+		SrcLang: catalog.Source,
+		TrgLang: catalog.Target,
+		Files:   make([]xliffFile, 0),
+	}
+	files := make(map[string]int)
+	for _, unit := range catalog.Units {
+		k, ok := files[unit.QPath]
+		if !ok {
+			k = len(doc.Files)
+			files[unit.QPath] = k
+			file := xliffFile{
+				ID:       "f" + strconv.Itoa(k+1),
+				Original: unit.QPath,
+			}
+			if catalog.Release != "" {
+				file.Notes = append(file.Notes, xliffNote{Category: "release", Text: catalog.Release})
+			}
+			if unit.Digest != "" {
+				file.Notes = append(file.Notes, xliffNote{Category: "digest", Text: unit.Digest})
+			}
+			doc.Files = append(doc.Files, file)
+		}
+		file := &doc.Files[k]
+		xunit := xliffUnit{
+			ID:   "u" + strconv.Itoa(len(file.Units)+1),
+			Name: unit.ID,
+			Segment: xliffSegment{
+				Source: unit.Source,
+			},
+		}
+		if unit.Target != "" {
+			target := unit.Target
+			xunit.Segment.Target = &target
+		}
+		xunit.Notes = append(xunit.Notes, xliffNote{Category: "location", Text: unit.Location()})
+		if unit.Nature != "" {
+			xunit.Notes = append(xunit.Notes, xliffNote{Category: "nature", Text: unit.Nature})
+		}
+		if unit.Alias != "" {
+			xunit.Notes = append(xunit.Notes, xliffNote{Category: "alias", Text: unit.Alias})
+		}
+		file.Units = append(file.Units, xunit)
+	}
+	buffer := new(bytes.Buffer)
+	buffer.WriteString(xml.Header)
+	encoder := xml.NewEncoder(buffer)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	buffer.WriteString("\n")
+	return buffer.Bytes(), nil
+}
+
+// ParseXLIFF reads an XLIFF 2.0 document made by XLIFF
+func ParseXLIFF(blob []byte) (*Catalog, error) {
+	doc := xliffDocument{}
+	err := xml.Unmarshal(blob, &doc)
+	if err != nil {
+		return nil, err
+	}
+	if doc.XMLName.Space != xliffNamespace || !strings.HasPrefix(doc.Version, "2.") {
+		return nil, errors.New("not an XLIFF 2.0 document")
+	}
+	catalog := &Catalog{
+		Source: doc.SrcLang,
+		Target: doc.TrgLang,
+		Units:  make([]Unit, 0),
+	}
+	for _, file := range doc.Files {
+		digest := ""
+		for _, note := range file.Notes {
+			switch note.Category {
+			case "digest":
+				digest = strings.TrimSpace(note.Text)
+			case "release":
+				catalog.Release = strings.TrimSpace(note.Text)
+			}
+		}
+		for _, xunit := range file.Units {
+			unit := Unit{
+				ID:     xunit.Name,
+				QPath:  file.Original,
+				Digest: digest,
+				Source: xunit.Segment.Source,
+			}
+			if unit.ID == "" {
+				unit.ID = xunit.ID
+			}
+			if xunit.Segment.Target != nil {
+				unit.Target = *xunit.Segment.Target
+			}
+			for _, note := range xunit.Notes {
+				text := strings.TrimSpace(note.Text)
+				switch note.Category {
+				case "location":
+					unit.setLocation(text)
+				case "nature":
+					unit.Nature = text
+				case "alias":
+					unit.Alias = text
+				}
+			}
+			catalog.Units = append(catalog.Units, unit)
+		}
+	}
+	return catalog, nil
+}
@@ -0,0 +1,126 @@
+package util
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	qregistry "brocade.be/base/registry"
+)
+
+// Language is a language of the lgcodes
+type Language struct {
+	Tag  string `json:"tag"`  // BCP-47 tag, e.g. "es"
+	Code string `json:"code"` // code in *.l files and in l4_ constructions: one uppercase letter, e.g. "S"
+	Key  string `json:"key"`  // key in the repository, in M and in exports, e.g. "spa"
+}
+
+// builtinLanguages are always available: existing lgcodes use them
+var builtinLanguages = []Language{
+	{Tag: "nl", Code: "N", Key: "dut"},
+	{Tag: "en", Code: "E", Key: "eng"},
+	{Tag: "fr", Code: "F", Key: "fre"},
+	{Tag: "de", Code: "D", Key: "ger"},
+	{Tag: "und", Code: "U", Key: "unv"},
+}
+
+var bcp47Regexp = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z]{4})?(-[a-zA-Z]{2}|-[0-9]{3})?(-[a-zA-Z0-9]{5,8}|-[0-9][a-zA-Z0-9]{3})*$`)
+var codeRegexp = regexp.MustCompile(`^[A-Z]$`)
+var keyRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+var languageMutex = new(sync.Mutex)
+var languageConfig = "?"
+var languageList []Language
+
+// Languages returns the languages of the lgcodes: the built-in languages
+// (N, E, F, D and U) followed by the languages in the registry value `lgcode-languages`.
+// This is a JSON object keyed by BCP-47 tag:
+//
+//	{"es": {"code": "S", "key": "spa"}, "it": {"code": "I", "key": "ita"}}
+//
+// Invalid entries (bad tag, code or key, or a code or key which is already used) are ignored.
+func Languages() []Language {
+	languageMutex.Lock()
+	defer languageMutex.Unlock()
+	config := qregistry.Registry["lgcode-languages"]
+	if config == languageConfig {
+		return languageList
+	}
+	languageConfig = config
+	languageList = append([]Language(nil), builtinLanguages...)
+	if strings.TrimSpace(config) == "" {
+		return languageList
+	}
+	extra := make(map[string]Language)
+	if json.Unmarshal([]byte(config), &extra) != nil {
+		return languageList
+	}
+	tags := make([]string, 0, len(extra))
+	for tag := range extra {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		lg := extra[tag]
+		lg.Tag = tag
+		if !bcp47Regexp.MatchString(lg.Tag) || !codeRegexp.MatchString(lg.Code) || !keyRegexp.MatchString(lg.Key) {
+			continue
+		}
+		used := false
+		for _, l := range languageList {
+			if strings.EqualFold(l.Tag, lg.Tag) || l.Code == lg.Code || l.Key == lg.Key {
+				used = true
+				break
+			}
+		}
+		if !used {
+			languageList = append(languageList, lg)
+		}
+	}
+	return languageList
+}
+
+// LanguageCodes returns the codes of all languages, e.g. "NEFDU"
+func LanguageCodes() string {
+	codes := ""
+	for _, lg := range Languages() {
+		codes += lg.Code
+	}
+	return codes
+}
+
+// LookupLanguage finds a language by code ("S"), key ("spa") or BCP-47 tag ("es", "es-ES").
+// The lookup on tag ignores case and falls back on the primary language subtag.
+func LookupLanguage(s string) *Language {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	languages := Languages()
+	for _, lg := range languages {
+		if lg.Code == s || lg.Key == s || strings.EqualFold(lg.Tag, s) {
+			lg := lg
+			return &lg
+		}
+	}
+	primary := strings.SplitN(strings.ReplaceAll(s, "_", "-"), "-", 2)[0]
+	for _, lg := range languages {
+		if strings.EqualFold(lg.Tag, primary) {
+			lg := lg
+			return &lg
+		}
+	}
+	return nil
+}
+
+// IsBuiltinLanguage tells if a language code is one of N, E, F, D or U
+func IsBuiltinLanguage(code string) bool {
+	for _, lg := range builtinLanguages {
+		if lg.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -556,7 +556,7 @@ func lgobble(rest []byte) (obj string) {
 			return ""
 		}
 		algo := parts[0][1:]
-		if !strings.ContainsRune("NEFDU", rune(obj[0])) {
+		if !strings.ContainsRune(LanguageCodes(), rune(obj[0])) {
 			return ""
 		}
 		if algo != "" && algo != "js" && algo != "py" && algo != "php" {
@@ -1203,12 +1203,12 @@ func Joiner(joiner string) string {
 	return delim
 }
 
-// DeNEDU removes language part of lgcode
+// DeNEDFU removes language part of lgcode (see Languages)
 func DeNEDFU(objname string) (canon string, lg string) {
 	if strings.HasPrefix(objname, "l4_") && strings.Count(objname, "_") == 2 {
 		parts := strings.SplitN(objname, "_", 3)
 		remove := parts[1]
-		if strings.IndexAny(remove, "NEDFU") == 0 {
+		if strings.IndexAny(remove, LanguageCodes()) == 0 {
 			remove = remove[1:]
 			if remove == "" || remove == "php" || remove == "py" || remove == "js" {
 				parts := strings.SplitN(objname, "_", 3)